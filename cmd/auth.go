@@ -5,9 +5,12 @@ This file is part of CLI application nippo-cli.
 package cmd
 
 import (
+	"github.com/c18t/nippo-cli/internal/adapter/controller"
 	"github.com/spf13/cobra"
 )
 
+var auth controller.AuthController
+
 // authCmd represents the auth command
 var authCmd = &cobra.Command{
 	Use:   "auth",
@@ -22,10 +25,27 @@ The command will:
 2. Open a browser for Google OAuth authentication
 3. Save the token to token.json in the data directory
 
+Only read-only access to Google Drive is requested, which is enough for build.
+Use --write to also grant write access for format. Scopes granted before are
+kept, so the token never loses access.
+
+On machines without a browser, use --device to sign in from another device
+with a verification URL and code instead. It needs an OAuth client of the
+"TVs and Limited Input devices" type in credentials.json. Google allows only a
+few Drive scopes for this flow, so it can fail with invalid_scope; use a
+service account then.
+
+When service_account_key is set in the [auth] section of nippo.toml, the
+service account is used and no token is saved. This command then only
+verifies that the key can be used.
+
 You can re-run this command anytime to refresh your authentication.`,
 }
 
 func init() {
 	authCmd.RunE = createAuthCommand()
 	rootCmd.AddCommand(authCmd)
+
+	authCmd.Flags().BoolVarP(&auth.Params().Device, "device", "", false, "sign in from another device with a verification code")
+	authCmd.Flags().BoolVarP(&auth.Params().Write, "write", "", false, "also grant write access to Google Drive, which format needs")
 }
@@ -11,5 +11,6 @@ import (
 func createAuthCommand() core.RunEFunc {
 	cmd, err := do.Invoke[controller.AuthController](inject.InjectorAuth)
 	cobra.CheckErr(err)
+	auth = cmd
 	return cmd.Exec
 }
@@ -0,0 +1,29 @@
+/*
+Copyright © 2023 ɯ̹t͡ɕʲi <xc18tx@gmail.com>
+This file is part of CLI application nippo-cli.
+*/
+package cmd
+
+import (
+	"github.com/c18t/nippo-cli/internal/adapter/controller"
+	"github.com/spf13/cobra"
+)
+
+var authLogout controller.AuthLogoutController
+
+// authLogoutCmd represents the auth logout command
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Revoke and delete the saved Google Drive token",
+	Long: `Revoke the OAuth token saved by 'nippo auth' with Google and delete token.json.
+
+A service account key is not revoked. Remove service_account_key from the
+[auth] section of nippo.toml to stop using it.`,
+}
+
+func init() {
+	authLogoutCmd.RunE = createAuthLogoutCommand()
+	authCmd.AddCommand(authLogoutCmd)
+
+	authLogoutCmd.Flags().BoolVarP(&authLogout.Params().JSON, "json", "", false, "print the result as JSON")
+}
@@ -0,0 +1,30 @@
+/*
+Copyright © 2023 ɯ̹t͡ɕʲi <xc18tx@gmail.com>
+This file is part of CLI application nippo-cli.
+*/
+package cmd
+
+import (
+	"github.com/c18t/nippo-cli/internal/adapter/controller"
+	"github.com/spf13/cobra"
+)
+
+var authStatus controller.AuthStatusController
+
+// authStatusCmd represents the auth status command
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the Google Drive credentials in use",
+	Long: `Show the Google Drive credentials in use.
+
+Prints the auth mode, the account, the granted scopes, when the access token
+expires and whether a refresh token is saved. An expired access token is
+refreshed and saved to token.json.`,
+}
+
+func init() {
+	authStatusCmd.RunE = createAuthStatusCommand()
+	authCmd.AddCommand(authStatusCmd)
+
+	authStatusCmd.Flags().BoolVarP(&authStatus.Params().JSON, "json", "", false, "print the status as JSON")
+}
@@ -5,27 +5,29 @@ This file is part of CLI application nippo-cli.
 package cmd
 
 import (
+	"github.com/c18t/nippo-cli/internal/adapter/controller"
 	"github.com/spf13/cobra"
 )
 
+var build controller.BuildController
+
 // buildCmd represents the build command
 var buildCmd = &cobra.Command{
 	Use:   "build",
 	Short: "Build nippo site",
-	Long:  ``,
+	Long: `Build command downloads the changed nippo and renders the site.
+
+Only the pages affected by changed nippo are rendered again: the page of the
+day, the pages of the days around it, the month archive, the index, the feed
+and the sitemap. A change of the templates or the site settings rebuilds the
+whole site.
+
+Use --force to rebuild the whole site anyway.`,
 }
 
 func init() {
 	buildCmd.RunE = createBuildCommand()
 	rootCmd.AddCommand(buildCmd)
 
-	// Here you will define your flags and configuration settings.
-
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// buildCmd.PersistentFlags().String("foo", "", "A help for foo")
-
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// buildCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	buildCmd.Flags().BoolVarP(&build.Params().Force, "force", "", false, "rebuild every page")
 }
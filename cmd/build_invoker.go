@@ -11,5 +11,6 @@ import (
 func createBuildCommand() core.RunEFunc {
 	cmd, err := do.Invoke[controller.BuildController](inject.InjectorBuild)
 	cobra.CheckErr(err)
+	build = cmd
 	return cmd.Exec
 }
@@ -21,6 +21,9 @@ func TestCommandsWithoutConfig(t *testing.T) {
 		{"clean", false},
 		{"update", false},
 		{"format", false},
+		{"new", false},
+		{"pull", false},
+		{"push", false},
 	}
 
 	for _, tt := range tests {
@@ -87,6 +90,19 @@ func TestAuthCmdShort(t *testing.T) {
 	}
 }
 
+func TestAuthSubcommands(t *testing.T) {
+	for _, name := range []string{"status", "logout"} {
+		sub, _, err := authCmd.Find([]string{name})
+		if err != nil || sub.Name() != name {
+			t.Errorf("auth %s is not registered", name)
+			continue
+		}
+		if sub.Flags().Lookup("json") == nil {
+			t.Errorf("auth %s should have a --json flag", name)
+		}
+	}
+}
+
 func TestBuildCmdUse(t *testing.T) {
 	if buildCmd.Use != "build" {
 		t.Errorf("buildCmd.Use = %q, want %q", buildCmd.Use, "build")
@@ -167,6 +183,49 @@ func TestFormatCmdShort(t *testing.T) {
 	}
 }
 
+func TestNewCmdUse(t *testing.T) {
+	if newCmd.Use != "new [date]" {
+		t.Errorf("newCmd.Use = %q, want %q", newCmd.Use, "new [date]")
+	}
+	if err := newCmd.Args(newCmd, []string{"2024-01-15", "2024-01-16"}); err == nil {
+		t.Error("newCmd should accept at most one date")
+	}
+}
+
+func TestPullPushCmdFlags(t *testing.T) {
+	if pullCmd.Flags().Lookup("dir") == nil {
+		t.Error("pullCmd should have --dir flag")
+	}
+	for _, name := range []string{"dir", "on-conflict"} {
+		if pushCmd.Flags().Lookup(name) == nil {
+			t.Errorf("pushCmd should have --%s flag", name)
+		}
+	}
+}
+
+func TestBuildCmdFlags(t *testing.T) {
+	if buildCmd.Flags().Lookup("force") == nil {
+		t.Error("buildCmd should have --force flag")
+	}
+}
+
+func TestFormatCmdFlags(t *testing.T) {
+	for _, name := range []string{"dry-run", "json"} {
+		if formatCmd.Flags().Lookup(name) == nil {
+			t.Errorf("formatCmd should have --%s flag", name)
+		}
+	}
+}
+
+func TestFormatSubcommands(t *testing.T) {
+	for _, name := range []string{"revert", "history"} {
+		sub, _, err := formatCmd.Find([]string{name})
+		if err != nil || sub.Name() != name {
+			t.Errorf("format %s is not registered", name)
+		}
+	}
+}
+
 func TestUpdateCmdUse(t *testing.T) {
 	if updateCmd.Use != "update" {
 		t.Errorf("updateCmd.Use = %q, want %q", updateCmd.Use, "update")
@@ -198,7 +257,7 @@ func TestSubcommandsRegistered(t *testing.T) {
 		commandNames[cmd.Name()] = true
 	}
 
-	expectedCommands := []string{"auth", "build", "clean", "deploy", "doctor", "format", "init", "update"}
+	expectedCommands := []string{"auth", "build", "clean", "deploy", "doctor", "format", "init", "new", "pull", "push", "update"}
 	for _, name := range expectedCommands {
 		if !commandNames[name] {
 			t.Errorf("expected subcommand %q to be registered", name)
@@ -5,25 +5,43 @@ This file is part of CLI application nippo-cli.
 package cmd
 
 import (
+	"github.com/c18t/nippo-cli/internal/adapter/controller"
 	"github.com/spf13/cobra"
 )
 
+var format controller.FormatController
+
 // formatCmd represents the format command
 var formatCmd = &cobra.Command{
 	Use:   "format",
 	Short: "Manage front-matter in nippo files",
 	Long: `Format command manages YAML front-matter in nippo Markdown files on Google Drive.
 
-This command:
+By default, this command:
 - Adds front-matter to files that don't have it
 - Adds 'created' field if missing (using Drive's createdTime)
 - Replaces 'updated: now' placeholder with Drive's modifiedTime
 
+Set 'rules' in the [format] section of nippo.toml to choose the fixes, such as
+trailing_whitespace, final_newline, heading_levels, spaces and sort_tags.
+
 Files are only uploaded when changes are made. The command tracks the last format
-timestamp and only processes files modified since then.`,
+timestamp and only processes files modified since then.
+
+A file edited elsewhere between its download and upload is not overwritten. It is
+reported as a conflict and processed again on the next run.
+
+Use --dry-run to preview the changes as unified diffs without uploading anything.
+Add --json to list the planned changes for scripts.
+
+The originals of the changed files are backed up in the cache directory. Run
+'nippo format history' to list past runs and 'nippo format revert' to undo one.`,
 }
 
 func init() {
 	formatCmd.RunE = createFormatCommand()
 	rootCmd.AddCommand(formatCmd)
+
+	formatCmd.Flags().BoolVarP(&format.Params().DryRun, "dry-run", "", false, "show the changes as diffs without uploading them")
+	formatCmd.Flags().BoolVarP(&format.Params().JSON, "json", "", false, "output the planned changes as JSON (requires --dry-run)")
 }
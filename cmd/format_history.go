@@ -0,0 +1,23 @@
+/*
+Copyright © 2023 ɯ̹t͡ɕʲi <xc18tx@gmail.com>
+This file is part of CLI application nippo-cli.
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// formatHistoryCmd represents the format history command
+var formatHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List past format runs and the files they changed",
+	Long: `List the 'nippo format' runs that changed files, newest first, with the files
+each one changed and why. Pass a run ID to 'nippo format revert' to undo it.`,
+	Args: cobra.NoArgs,
+}
+
+func init() {
+	formatHistoryCmd.RunE = createFormatHistoryCommand()
+	formatCmd.AddCommand(formatHistoryCmd)
+}
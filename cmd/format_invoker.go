@@ -11,5 +11,6 @@ import (
 func createFormatCommand() core.RunEFunc {
 	cmd, err := do.Invoke[controller.FormatController](inject.InjectorFormat)
 	cobra.CheckErr(err)
+	format = cmd
 	return cmd.Exec
 }
@@ -0,0 +1,26 @@
+/*
+Copyright © 2023 ɯ̹t͡ɕʲi <xc18tx@gmail.com>
+This file is part of CLI application nippo-cli.
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// formatRevertCmd represents the format revert command
+var formatRevertCmd = &cobra.Command{
+	Use:   "revert [run-id]",
+	Short: "Restore the files changed by a format run",
+	Long: `Restore the originals of the files changed by a 'nippo format' run from its backup.
+
+Without a run ID, the latest run that hasn't been reverted is restored. Files
+edited since the run are skipped, so no later edit is lost. Run
+'nippo format history' to see the past runs.`,
+	Args: cobra.MaximumNArgs(1),
+}
+
+func init() {
+	formatRevertCmd.RunE = createFormatRevertCommand()
+	formatCmd.AddCommand(formatRevertCmd)
+}
@@ -0,0 +1,40 @@
+/*
+Copyright © 2023 ɯ̹t͡ɕʲi <xc18tx@gmail.com>
+This file is part of CLI application nippo-cli.
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// newCmd represents the new command
+var newCmd = &cobra.Command{
+	Use:   "new [date]",
+	Short: "Create a nippo from a template",
+	Long: `New command creates the nippo of a date on Google Drive, today unless a date
+is given in YYYY-MM-DD format.
+
+The nippo is rendered from new.md in the data directory, or the file set with
+template in the [new] section of nippo.toml. The template can use these
+placeholders:
+
+  {{.Date}}     2024-01-15
+  {{.Year}}     2024
+  {{.Month}}    01
+  {{.Day}}      15
+  {{.Weekday}}  Monday
+  {{.Title}}    01/15 mon
+
+The 'created' field of the front-matter is set to the current time of the date.
+Set subfolder in the [new] section to "year" or "month" to put the nippo into
+folders such as 2024/ or 2024/01/, which are created when missing.
+
+An existing nippo of the date is never overwritten.`,
+	Args: cobra.MaximumNArgs(1),
+}
+
+func init() {
+	newCmd.RunE = createNewCommand()
+	rootCmd.AddCommand(newCmd)
+}
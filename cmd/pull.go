@@ -0,0 +1,35 @@
+/*
+Copyright © 2023 ɯ̹t͡ɕʲi <xc18tx@gmail.com>
+This file is part of CLI application nippo-cli.
+*/
+package cmd
+
+import (
+	"github.com/c18t/nippo-cli/internal/adapter/controller"
+	"github.com/spf13/cobra"
+)
+
+var pull controller.PullController
+
+// pullCmd represents the pull command
+var pullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Download nippo into a local working directory",
+	Long: `Pull command mirrors the nippo on Google Drive into a local working directory,
+so they can be edited with any editor and uploaded again with push.
+
+The working directory is set with path in the [workdir] section of nippo.toml,
+or with --dir. Subfolders are mirrored as folders of the same path.
+
+Files edited locally since the last pull are never overwritten; push them
+first. Local copies of nippo deleted on Google Drive are removed unless they
+were edited. The state of the last pull is kept in .nippo-pull.json in the
+working directory.`,
+}
+
+func init() {
+	pullCmd.RunE = createPullCommand()
+	rootCmd.AddCommand(pullCmd)
+
+	pullCmd.Flags().StringVarP(&pull.Params().Dir, "dir", "", "", "working directory to pull into instead of workdir.path")
+}
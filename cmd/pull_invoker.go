@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"github.com/c18t/nippo-cli/internal/adapter/controller"
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/inject"
+	"github.com/samber/do/v2"
+	"github.com/spf13/cobra"
+)
+
+func createPullCommand() core.RunEFunc {
+	cmd, err := do.Invoke[controller.PullController](inject.InjectorPull)
+	cobra.CheckErr(err)
+	pull = cmd
+	return cmd.Exec
+}
@@ -0,0 +1,37 @@
+/*
+Copyright © 2023 ɯ̹t͡ɕʲi <xc18tx@gmail.com>
+This file is part of CLI application nippo-cli.
+*/
+package cmd
+
+import (
+	"github.com/c18t/nippo-cli/internal/adapter/controller"
+	"github.com/spf13/cobra"
+)
+
+var push controller.PushController
+
+// pushCmd represents the push command
+var pushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Upload local changes from the working directory",
+	Long: `Push command uploads the nippo edited in the working directory since the last
+pull, and creates the new ones named YYYY-MM-DD.md on Google Drive, in the
+subfolder set in the [new] section of nippo.toml.
+
+A nippo that was also changed on Google Drive since the last pull is a
+conflict. For each conflict you are asked to keep the local file, keep the
+remote one, or write both, which saves the remote file next to the local one
+as YYYY-MM-DD.remote.md to merge by hand and push again. Use --on-conflict
+with local, remote or both to resolve every conflict without asking.
+
+Files deleted locally are not deleted on Google Drive.`,
+}
+
+func init() {
+	pushCmd.RunE = createPushCommand()
+	rootCmd.AddCommand(pushCmd)
+
+	pushCmd.Flags().StringVarP(&push.Params().Dir, "dir", "", "", "working directory to push from instead of workdir.path")
+	pushCmd.Flags().StringVarP(&push.Params().OnConflict, "on-conflict", "", "", "resolve conflicts without asking: local, remote or both")
+}
@@ -7,7 +7,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
-type AuthParams struct{}
+type AuthParams struct {
+	Device bool
+	Write  bool
+}
 
 type AuthController interface {
 	core.Controller
@@ -35,6 +38,6 @@ func (c *authController) Params() *AuthParams {
 }
 
 func (c *authController) Exec(cmd *cobra.Command, args []string) (err error) {
-	c.bus.Handle(&port.AuthUseCaseInputData{})
+	c.bus.Handle(&port.AuthUseCaseInputData{Device: c.params.Device, Write: c.params.Write})
 	return
 }
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+	"github.com/spf13/cobra"
+)
+
+type AuthLogoutParams struct {
+	JSON bool
+}
+
+type AuthLogoutController interface {
+	core.Controller
+	Params() *AuthLogoutParams
+}
+
+type authLogoutController struct {
+	bus    port.AuthLogoutUseCaseBus
+	params *AuthLogoutParams
+}
+
+func NewAuthLogoutController(i do.Injector) (AuthLogoutController, error) {
+	bus, err := do.Invoke[port.AuthLogoutUseCaseBus](i)
+	if err != nil {
+		return nil, err
+	}
+	return &authLogoutController{
+		bus:    bus,
+		params: &AuthLogoutParams{},
+	}, nil
+}
+
+func (c *authLogoutController) Params() *AuthLogoutParams {
+	return c.params
+}
+
+func (c *authLogoutController) Exec(cmd *cobra.Command, args []string) (err error) {
+	c.bus.Handle(&port.AuthLogoutUseCaseInputData{JSON: c.params.JSON})
+	return
+}
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+	"github.com/spf13/cobra"
+)
+
+type AuthStatusParams struct {
+	JSON bool
+}
+
+type AuthStatusController interface {
+	core.Controller
+	Params() *AuthStatusParams
+}
+
+type authStatusController struct {
+	bus    port.AuthStatusUseCaseBus
+	params *AuthStatusParams
+}
+
+func NewAuthStatusController(i do.Injector) (AuthStatusController, error) {
+	bus, err := do.Invoke[port.AuthStatusUseCaseBus](i)
+	if err != nil {
+		return nil, err
+	}
+	return &authStatusController{
+		bus:    bus,
+		params: &AuthStatusParams{},
+	}, nil
+}
+
+func (c *authStatusController) Params() *AuthStatusParams {
+	return c.params
+}
+
+func (c *authStatusController) Exec(cmd *cobra.Command, args []string) (err error) {
+	c.bus.Handle(&port.AuthStatusUseCaseInputData{JSON: c.params.JSON})
+	return
+}
@@ -8,6 +8,7 @@ import (
 )
 
 type BuildParams struct {
+	Force bool
 }
 
 type BuildController interface {
@@ -36,6 +37,6 @@ func (c *buildController) Params() *BuildParams {
 }
 
 func (c *buildController) Exec(cmd *cobra.Command, args []string) (err error) {
-	c.bus.Handle(&port.BuildCommandUseCaseInputData{})
+	c.bus.Handle(&port.BuildCommandUseCaseInputData{Force: c.params.Force})
 	return
 }
@@ -45,10 +45,36 @@ func (m *mockUpdateUseCaseBus) Handle(input port.UpdateUseCaseInputData) {
 
 type mockFormatUseCaseBus struct {
 	handleCalled bool
+	input        port.FormatUseCaseInputData
 }
 
 func (m *mockFormatUseCaseBus) Handle(input port.FormatUseCaseInputData) {
 	m.handleCalled = true
+	m.input = input
+}
+
+type mockCreateUseCaseBus struct {
+	input port.CreateUseCaseInputData
+}
+
+func (m *mockCreateUseCaseBus) Handle(input port.CreateUseCaseInputData) {
+	m.input = input
+}
+
+type mockPullUseCaseBus struct {
+	input port.PullUseCaseInputData
+}
+
+func (m *mockPullUseCaseBus) Handle(input port.PullUseCaseInputData) {
+	m.input = input
+}
+
+type mockPushUseCaseBus struct {
+	input port.PushUseCaseInputData
+}
+
+func (m *mockPushUseCaseBus) Handle(input port.PushUseCaseInputData) {
+	m.input = input
 }
 
 type mockInitUseCaseBus struct {
@@ -71,10 +97,12 @@ func (m *mockRootUseCaseBus) Handle(input port.RootUseCaseInputData) {
 
 type mockAuthUseCaseBus struct {
 	handleCalled bool
+	input        *port.AuthUseCaseInputData
 }
 
 func (m *mockAuthUseCaseBus) Handle(input *port.AuthUseCaseInputData) {
 	m.handleCalled = true
+	m.input = input
 }
 
 type mockDoctorUseCase struct {
@@ -300,6 +328,110 @@ func TestFormatController_Exec(t *testing.T) {
 	}
 }
 
+func TestFormatController_ExecDryRun(t *testing.T) {
+	mock := &mockFormatUseCaseBus{}
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (port.FormatUseCaseBus, error) {
+		return mock, nil
+	})
+
+	ctrl, _ := NewFormatController(injector)
+	ctrl.Params().JSON = true
+	if err := ctrl.Exec(&cobra.Command{}, []string{}); err == nil {
+		t.Error("Exec() expected error for --json without --dry-run")
+	}
+	if mock.handleCalled {
+		t.Error("Exec() should not format with --json alone")
+	}
+
+	ctrl.Params().DryRun = true
+	if err := ctrl.Exec(&cobra.Command{}, []string{}); err != nil {
+		t.Errorf("Exec() error = %v", err)
+	}
+	if input, ok := mock.input.(*port.FormatCommandUseCaseInputData); !ok || !input.DryRun || !input.JSON {
+		t.Errorf("Exec() passed %+v, want the --dry-run and --json flags", mock.input)
+	}
+}
+
+// Tests for CreateController
+
+func TestCreateController_Exec(t *testing.T) {
+	mock := &mockCreateUseCaseBus{}
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (port.CreateUseCaseBus, error) {
+		return mock, nil
+	})
+
+	ctrl, err := NewCreateController(injector)
+	if err != nil {
+		t.Fatalf("NewCreateController() error = %v", err)
+	}
+	if err := ctrl.Exec(&cobra.Command{}, []string{"2024-01-15"}); err != nil {
+		t.Errorf("Exec() error = %v", err)
+	}
+	if input, ok := mock.input.(*port.CreateCommandUseCaseInputData); !ok || input.Date != "2024-01-15" {
+		t.Errorf("Exec() passed %+v, want the date argument", mock.input)
+	}
+
+	if err := ctrl.Exec(&cobra.Command{}, []string{}); err != nil {
+		t.Errorf("Exec() error = %v", err)
+	}
+	if input := mock.input.(*port.CreateCommandUseCaseInputData); input.Date != "" {
+		t.Errorf("Exec() passed %+v, want no date without an argument", input)
+	}
+}
+
+// Tests for PullController and PushController
+
+func TestPullController_Exec(t *testing.T) {
+	mock := &mockPullUseCaseBus{}
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (port.PullUseCaseBus, error) {
+		return mock, nil
+	})
+
+	ctrl, err := NewPullController(injector)
+	if err != nil {
+		t.Fatalf("NewPullController() error = %v", err)
+	}
+	ctrl.Params().Dir = "/tmp/nippo"
+	if err := ctrl.Exec(&cobra.Command{}, []string{}); err != nil {
+		t.Errorf("Exec() error = %v", err)
+	}
+	if input, ok := mock.input.(*port.PullCommandUseCaseInputData); !ok || input.Dir != "/tmp/nippo" {
+		t.Errorf("Exec() passed %+v, want the --dir flag", mock.input)
+	}
+}
+
+func TestPushController_Exec(t *testing.T) {
+	mock := &mockPushUseCaseBus{}
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (port.PushUseCaseBus, error) {
+		return mock, nil
+	})
+
+	ctrl, err := NewPushController(injector)
+	if err != nil {
+		t.Fatalf("NewPushController() error = %v", err)
+	}
+	ctrl.Params().OnConflict = "remote"
+	if err := ctrl.Exec(&cobra.Command{}, []string{}); err != nil {
+		t.Errorf("Exec() error = %v", err)
+	}
+	if input, ok := mock.input.(*port.PushCommandUseCaseInputData); !ok || input.OnConflict != port.ConflictKeepRemote {
+		t.Errorf("Exec() passed %+v, want the --on-conflict flag", mock.input)
+	}
+
+	mock.input = nil
+	ctrl.Params().OnConflict = "theirs"
+	if err := ctrl.Exec(&cobra.Command{}, []string{}); err == nil {
+		t.Error("Exec() expected error for an invalid --on-conflict")
+	}
+	if mock.input != nil {
+		t.Error("Exec() should not push with an invalid --on-conflict")
+	}
+}
+
 // Tests for InitController
 
 func TestNewInitController(t *testing.T) {
@@ -633,6 +765,139 @@ func TestRootParams(t *testing.T) {
 	}
 }
 
+func TestAuthController_ExecDevice(t *testing.T) {
+	mock := &mockAuthUseCaseBus{}
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (port.AuthUseCaseBus, error) {
+		return mock, nil
+	})
+
+	ctrl, _ := NewAuthController(injector)
+	ctrl.Params().Device = true
+
+	if err := ctrl.Exec(&cobra.Command{}, []string{}); err != nil {
+		t.Errorf("Exec() error = %v", err)
+	}
+	if mock.input == nil || !mock.input.Device {
+		t.Errorf("Exec() input = %+v, want Device to be passed", mock.input)
+	}
+}
+
+type mockAuthStatusUseCaseBus struct {
+	input *port.AuthStatusUseCaseInputData
+}
+
+func (m *mockAuthStatusUseCaseBus) Handle(input *port.AuthStatusUseCaseInputData) {
+	m.input = input
+}
+
+type mockAuthLogoutUseCaseBus struct {
+	input *port.AuthLogoutUseCaseInputData
+}
+
+func (m *mockAuthLogoutUseCaseBus) Handle(input *port.AuthLogoutUseCaseInputData) {
+	m.input = input
+}
+
+type mockFormatRevertUseCaseBus struct {
+	input *port.FormatRevertUseCaseInputData
+}
+
+func (m *mockFormatRevertUseCaseBus) Handle(input *port.FormatRevertUseCaseInputData) {
+	m.input = input
+}
+
+type mockFormatHistoryUseCaseBus struct {
+	input *port.FormatHistoryUseCaseInputData
+}
+
+func (m *mockFormatHistoryUseCaseBus) Handle(input *port.FormatHistoryUseCaseInputData) {
+	m.input = input
+}
+
+func TestFormatRevertController_Exec(t *testing.T) {
+	mock := &mockFormatRevertUseCaseBus{}
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (port.FormatRevertUseCaseBus, error) {
+		return mock, nil
+	})
+
+	ctrl, err := NewFormatRevertController(injector)
+	if err != nil {
+		t.Fatalf("NewFormatRevertController() error = %v", err)
+	}
+	if err := ctrl.Exec(&cobra.Command{}, []string{}); err != nil {
+		t.Errorf("Exec() error = %v", err)
+	}
+	if mock.input == nil || mock.input.RunId != "" {
+		t.Errorf("Exec() input = %+v, want the latest run", mock.input)
+	}
+	if err := ctrl.Exec(&cobra.Command{}, []string{"20240115-100000"}); err != nil {
+		t.Errorf("Exec() error = %v", err)
+	}
+	if mock.input.RunId != "20240115-100000" {
+		t.Errorf("Exec() input = %+v, want the run ID to be passed", mock.input)
+	}
+}
+
+func TestFormatHistoryController_Exec(t *testing.T) {
+	mock := &mockFormatHistoryUseCaseBus{}
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (port.FormatHistoryUseCaseBus, error) {
+		return mock, nil
+	})
+
+	ctrl, err := NewFormatHistoryController(injector)
+	if err != nil {
+		t.Fatalf("NewFormatHistoryController() error = %v", err)
+	}
+	if err := ctrl.Exec(&cobra.Command{}, []string{}); err != nil {
+		t.Errorf("Exec() error = %v", err)
+	}
+	if mock.input == nil {
+		t.Error("Exec() should handle the history")
+	}
+}
+
+func TestAuthStatusController_Exec(t *testing.T) {
+	mock := &mockAuthStatusUseCaseBus{}
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (port.AuthStatusUseCaseBus, error) {
+		return mock, nil
+	})
+
+	ctrl, err := NewAuthStatusController(injector)
+	if err != nil {
+		t.Fatalf("NewAuthStatusController() error = %v", err)
+	}
+	ctrl.Params().JSON = true
+	if err := ctrl.Exec(&cobra.Command{}, []string{}); err != nil {
+		t.Errorf("Exec() error = %v", err)
+	}
+	if mock.input == nil || !mock.input.JSON {
+		t.Errorf("Exec() input = %+v, want JSON to be passed", mock.input)
+	}
+}
+
+func TestAuthLogoutController_Exec(t *testing.T) {
+	mock := &mockAuthLogoutUseCaseBus{}
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (port.AuthLogoutUseCaseBus, error) {
+		return mock, nil
+	})
+
+	ctrl, err := NewAuthLogoutController(injector)
+	if err != nil {
+		t.Fatalf("NewAuthLogoutController() error = %v", err)
+	}
+	if err := ctrl.Exec(&cobra.Command{}, []string{}); err != nil {
+		t.Errorf("Exec() error = %v", err)
+	}
+	if mock.input == nil || mock.input.JSON {
+		t.Errorf("Exec() input = %+v, want plain text", mock.input)
+	}
+}
+
 func TestAuthParams(t *testing.T) {
 	params := &AuthParams{}
 	// Verify struct can be created
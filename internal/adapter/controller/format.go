@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"fmt"
+
 	"github.com/c18t/nippo-cli/internal/core"
 	"github.com/c18t/nippo-cli/internal/usecase/port"
 	"github.com/samber/do/v2"
@@ -8,6 +10,8 @@ import (
 )
 
 type FormatParams struct {
+	DryRun bool
+	JSON   bool
 }
 
 type FormatController interface {
@@ -36,6 +40,9 @@ func (c *formatController) Params() *FormatParams {
 }
 
 func (c *formatController) Exec(cmd *cobra.Command, args []string) (err error) {
-	c.bus.Handle(&port.FormatCommandUseCaseInputData{})
+	if c.params.JSON && !c.params.DryRun {
+		return fmt.Errorf("--json requires --dry-run")
+	}
+	c.bus.Handle(&port.FormatCommandUseCaseInputData{DryRun: c.params.DryRun, JSON: c.params.JSON})
 	return
 }
@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+	"github.com/spf13/cobra"
+)
+
+type FormatHistoryController interface {
+	core.Controller
+}
+
+type formatHistoryController struct {
+	bus port.FormatHistoryUseCaseBus
+}
+
+func NewFormatHistoryController(i do.Injector) (FormatHistoryController, error) {
+	bus, err := do.Invoke[port.FormatHistoryUseCaseBus](i)
+	if err != nil {
+		return nil, err
+	}
+	return &formatHistoryController{
+		bus: bus,
+	}, nil
+}
+
+func (c *formatHistoryController) Exec(cmd *cobra.Command, args []string) (err error) {
+	c.bus.Handle(&port.FormatHistoryUseCaseInputData{})
+	return
+}
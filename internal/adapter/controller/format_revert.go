@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+	"github.com/spf13/cobra"
+)
+
+type FormatRevertController interface {
+	core.Controller
+}
+
+type formatRevertController struct {
+	bus port.FormatRevertUseCaseBus
+}
+
+func NewFormatRevertController(i do.Injector) (FormatRevertController, error) {
+	bus, err := do.Invoke[port.FormatRevertUseCaseBus](i)
+	if err != nil {
+		return nil, err
+	}
+	return &formatRevertController{
+		bus: bus,
+	}, nil
+}
+
+func (c *formatRevertController) Exec(cmd *cobra.Command, args []string) (err error) {
+	input := &port.FormatRevertUseCaseInputData{}
+	if len(args) > 0 {
+		input.RunId = args[0]
+	}
+	c.bus.Handle(input)
+	return
+}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+	"github.com/spf13/cobra"
+)
+
+type CreateParams struct {
+}
+
+type CreateController interface {
+	core.Controller
+	Params() *CreateParams
+}
+
+type createController struct {
+	bus    port.CreateUseCaseBus `do:""`
+	params *CreateParams
+}
+
+func NewCreateController(i do.Injector) (CreateController, error) {
+	bus, err := do.Invoke[port.CreateUseCaseBus](i)
+	if err != nil {
+		return nil, err
+	}
+	return &createController{
+		bus:    bus,
+		params: &CreateParams{},
+	}, nil
+}
+
+func (c *createController) Params() *CreateParams {
+	return c.params
+}
+
+func (c *createController) Exec(cmd *cobra.Command, args []string) (err error) {
+	input := &port.CreateCommandUseCaseInputData{}
+	if len(args) > 0 {
+		input.Date = args[0]
+	}
+	c.bus.Handle(input)
+	return
+}
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+	"github.com/spf13/cobra"
+)
+
+type PullParams struct {
+	Dir string
+}
+
+type PullController interface {
+	core.Controller
+	Params() *PullParams
+}
+
+type pullController struct {
+	bus    port.PullUseCaseBus `do:""`
+	params *PullParams
+}
+
+func NewPullController(i do.Injector) (PullController, error) {
+	bus, err := do.Invoke[port.PullUseCaseBus](i)
+	if err != nil {
+		return nil, err
+	}
+	return &pullController{
+		bus:    bus,
+		params: &PullParams{},
+	}, nil
+}
+
+func (c *pullController) Params() *PullParams {
+	return c.params
+}
+
+func (c *pullController) Exec(cmd *cobra.Command, args []string) (err error) {
+	c.bus.Handle(&port.PullCommandUseCaseInputData{Dir: c.params.Dir})
+	return
+}
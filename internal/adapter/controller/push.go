@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+	"github.com/spf13/cobra"
+)
+
+type PushParams struct {
+	Dir        string
+	OnConflict string
+}
+
+type PushController interface {
+	core.Controller
+	Params() *PushParams
+}
+
+type pushController struct {
+	bus    port.PushUseCaseBus `do:""`
+	params *PushParams
+}
+
+func NewPushController(i do.Injector) (PushController, error) {
+	bus, err := do.Invoke[port.PushUseCaseBus](i)
+	if err != nil {
+		return nil, err
+	}
+	return &pushController{
+		bus:    bus,
+		params: &PushParams{},
+	}, nil
+}
+
+func (c *pushController) Params() *PushParams {
+	return c.params
+}
+
+func (c *pushController) Exec(cmd *cobra.Command, args []string) (err error) {
+	onConflict, err := port.ParseConflictResolution(c.params.OnConflict)
+	if err != nil {
+		return err
+	}
+	c.bus.Handle(&port.PushCommandUseCaseInputData{Dir: c.params.Dir, OnConflict: onConflict})
+	return
+}
@@ -1,21 +1,22 @@
 package gateway
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
+	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/c18t/nippo-cli/internal/core"
 	"github.com/c18t/nippo-cli/internal/domain/repository"
 	"github.com/samber/do/v2"
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
 	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 )
 
@@ -33,22 +34,50 @@ func (t DriveFileTimestamp) String() string {
 	return t.t.Format(time.RFC3339)
 }
 
+// driveFileFields is the set of file fields requested from the Drive API
+const driveFileFields = "id, name, fileExtension, mimeType, createdTime, modifiedTime, md5Checksum, version, parents, trashed"
+
 type DriveFileProvider interface {
 	List(param *repository.QueryListParam) (*drive.FileList, error)
 	Download(string) ([]byte, error)
+	// Get returns the current metadata of the file
+	Get(fileId string) (*drive.File, error)
 	Update(fileId string, content []byte) error
+	// Create creates file with its name, parents and MIME type, and uploads content
+	// unless it is nil, as for folders. The created file is returned.
+	Create(file *drive.File, content []byte) (*drive.File, error)
+	GetStartPageToken() (string, error)
+	ListChanges(pageToken string) (*drive.ChangeList, error)
+	// OnRetry registers notify to be called whenever a call is retried
+	OnRetry(notify RetryNotifier)
+	// RequireAccess sets the access the command needs, read-only by default.
+	// It must be called before the first call to Drive.
+	RequireAccess(access DriveAccess)
 	Shutdown() error
 	HealthCheck() error
 }
 
 type driveFileProvider struct {
-	fs *drive.FilesService
+	// mu guards srv and the settings, as the provider is called from several
+	// workers at once
+	mu      sync.RWMutex
+	srv     *drive.Service
+	retry   *RetryPolicy // nil uses the [retry] config
+	onRetry RetryNotifier
+	access  DriveAccess
 }
 
 func NewDriveFileProvider(_ do.Injector) (DriveFileProvider, error) {
 	return &driveFileProvider{}, nil
 }
 
+// NewDriveFileProviderForService creates a provider that uses srv instead of
+// authenticating with the credentials in the data dir. It is used to point the
+// provider at another endpoint, such as a fake Drive server in tests.
+func NewDriveFileProviderForService(srv *drive.Service) DriveFileProvider {
+	return &driveFileProvider{srv: srv}
+}
+
 func (g *driveFileProvider) List(param *repository.QueryListParam) (*drive.FileList, error) {
 	fileService, err := g.getFileService()
 	if err != nil {
@@ -57,7 +86,7 @@ func (g *driveFileProvider) List(param *repository.QueryListParam) (*drive.FileL
 
 	query := g.queryBuilder(param)
 	listCall := fileService.List().
-		Fields("nextPageToken, files(id, name, fileExtension, mimeType, createdTime, modifiedTime)").
+		Fields(googleapi.Field(fmt.Sprintf("nextPageToken, files(%s)", driveFileFields))).
 		PageSize(100).
 		Q(query)
 	if param.OrderBy != "" {
@@ -67,7 +96,11 @@ func (g *driveFileProvider) List(param *repository.QueryListParam) (*drive.FileL
 		listCall = listCall.PageToken(param.PageToken)
 	}
 
-	res, err := listCall.Do()
+	var res *drive.FileList
+	err = g.withRetry("list files", func() (err error) {
+		res, err = listCall.Do()
+		return
+	})
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve files: %v", err)
 	}
@@ -79,16 +112,36 @@ func (g *driveFileProvider) Download(id string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	res, err := fileService.Get(id).Download()
+	var content []byte
+	err = g.withRetry("download "+id, func() error {
+		res, err := fileService.Get(id).Download()
+		if err != nil {
+			return err
+		}
+		defer func() { _, _ = io.Copy(io.Discard, res.Body); _ = res.Body.Close() }()
+		content, err = io.ReadAll(res.Body)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer func() { _, _ = io.Copy(io.Discard, res.Body); _ = res.Body.Close() }()
-	content, err := io.ReadAll(res.Body)
+	return content, nil
+}
+
+func (g *driveFileProvider) Get(fileId string) (*drive.File, error) {
+	fileService, err := g.getFileService()
 	if err != nil {
 		return nil, err
 	}
-	return content, nil
+	var file *drive.File
+	err = g.withRetry("get "+fileId, func() (err error) {
+		file, err = fileService.Get(fileId).Fields(googleapi.Field(driveFileFields)).Do()
+		return
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get file: %w", err)
+	}
+	return file, nil
 }
 
 func (g *driveFileProvider) Update(fileId string, content []byte) error {
@@ -97,11 +150,11 @@ func (g *driveFileProvider) Update(fileId string, content []byte) error {
 		return err
 	}
 
-	// Create a reader from the content
-	reader := strings.NewReader(string(content))
-
-	// Update the file content
-	_, err = fileService.Update(fileId, nil).Media(reader).Do()
+	// Update the file content, with a fresh reader for every attempt
+	err = g.withRetry("update "+fileId, func() error {
+		_, err := fileService.Update(fileId, nil).Media(bytes.NewReader(content)).Do()
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("unable to update file: %w", err)
 	}
@@ -109,57 +162,162 @@ func (g *driveFileProvider) Update(fileId string, content []byte) error {
 	return nil
 }
 
-func (g *driveFileProvider) getFileService() (*drive.FilesService, error) {
-	if g.fs != nil {
-		return g.fs, nil
+func (g *driveFileProvider) Create(file *drive.File, content []byte) (*drive.File, error) {
+	fileService, err := g.getFileService()
+	if err != nil {
+		return nil, err
 	}
 
-	dataDir := core.Cfg.GetDataDir()
-	tok, err := g.tokenFromFile(dataDir, filepath.Join(dataDir, "token.json"))
+	// Pick the ID up front, so retrying a create that went through
+	// fails instead of leaving a duplicate behind
+	var ids *drive.GeneratedIds
+	err = g.withRetry("generate file ID", func() (err error) {
+		ids, err = fileService.GenerateIds().Count(1).Space("drive").Do()
+		return
+	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("unable to create file: %w", err)
+	}
+	if len(ids.Ids) == 0 {
+		return nil, fmt.Errorf("unable to create file: no file ID was generated")
+	}
+	metadata := *file
+	metadata.Id = ids.Ids[0]
+
+	var created *drive.File
+	attempt := 0
+	err = g.withRetry("create "+file.Name, func() (err error) {
+		attempt++
+		call := fileService.Create(&metadata).Fields(googleapi.Field(driveFileFields))
+		if content != nil {
+			call = call.Media(bytes.NewReader(content))
+		}
+		created, err = call.Do()
+		var apiErr *googleapi.Error
+		if attempt > 1 && errors.As(err, &apiErr) && apiErr.Code == http.StatusConflict {
+			// An earlier attempt created the file after all
+			created, err = fileService.Get(metadata.Id).Fields(googleapi.Field(driveFileFields)).Do()
+		}
+		return
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create file: %w", err)
 	}
+	return created, nil
+}
 
-	credPath := filepath.Join(dataDir, "credentials.json")
-	b, err := os.ReadFile(credPath)
+// GetStartPageToken returns the page token that ListChanges starts from
+// to see changes made after this call.
+func (g *driveFileProvider) GetStartPageToken() (string, error) {
+	srv, err := g.getService()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf(`credentials.json not found
+		return "", err
+	}
+	var res *drive.StartPageToken
+	err = g.withRetry("get start page token", func() (err error) {
+		res, err = srv.Changes.GetStartPageToken().Do()
+		return
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve start page token: %w", err)
+	}
+	return res.StartPageToken, nil
+}
+
+// ListChanges returns one page of changes since pageToken, including removed and trashed files.
+// An expired or unknown token yields repository.ErrFullSyncRequired.
+func (g *driveFileProvider) ListChanges(pageToken string) (*drive.ChangeList, error) {
+	srv, err := g.getService()
+	if err != nil {
+		return nil, err
+	}
+	call := srv.Changes.List(pageToken).
+		Fields(googleapi.Field(fmt.Sprintf("nextPageToken, newStartPageToken, changes(fileId, removed, file(%s))", driveFileFields))).
+		IncludeRemoved(true).
+		PageSize(1000).
+		Spaces("drive")
+	var res *drive.ChangeList
+	err = g.withRetry("list changes", func() (err error) {
+		res, err = call.Do()
+		return
+	})
+	if err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && (apiErr.Code == http.StatusBadRequest || apiErr.Code == http.StatusNotFound || apiErr.Code == http.StatusGone) {
+			return nil, fmt.Errorf("%w: %v", repository.ErrFullSyncRequired, err)
+		}
+		return nil, fmt.Errorf("unable to retrieve changes: %w", err)
+	}
+	return res, nil
+}
 
-Please download the OAuth 2.0 Client ID credentials from Google Cloud Console:
+func (g *driveFileProvider) OnRetry(notify RetryNotifier) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onRetry = notify
+}
 
-1. Go to https://console.cloud.google.com/apis/credentials
-2. Create OAuth 2.0 Client ID (Application type: Desktop app)
-3. Download the credentials JSON file
-4. Save it to: %s
+func (g *driveFileProvider) RequireAccess(access DriveAccess) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.access = access
+}
+
+func (g *driveFileProvider) withRetry(operation string, fn func() error) error {
+	g.mu.RLock()
+	policy, notify := g.retry, g.onRetry
+	g.mu.RUnlock()
 
-Note: Run 'nippo init' to set up your environment`, credPath)
+	if policy == nil {
+		var cfg core.ConfigRetry
+		if core.Cfg != nil {
+			cfg = core.Cfg.Retry
 		}
-		return nil, fmt.Errorf("unable to read credentials file: %w", err)
+		p := NewRetryPolicy(cfg)
+		policy = &p
 	}
+	return policy.Do(operation, fn, notify)
+}
 
-	config, err := google.ConfigFromJSON(b, drive.DriveScope)
+func (g *driveFileProvider) getFileService() (*drive.FilesService, error) {
+	srv, err := g.getService()
 	if err != nil {
-		return nil, fmt.Errorf("unable to parse client secret file to config: %v", err)
+		return nil, err
+	}
+	return srv.Files, nil
+}
+
+// getService creates the Drive service on first use. The lock is held until it
+// is created, so the workers calling the provider in parallel share one.
+func (g *driveFileProvider) getService() (*drive.Service, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.srv != nil {
+		return g.srv, nil
 	}
 
-	client := config.Client(context.Background(), tok)
 	ctx := context.Background()
+	tokenSource, err := NewTokenSource(ctx, g.access)
+	if err != nil {
+		return nil, err
+	}
+	client := oauth2.NewClient(ctx, tokenSource)
 	srv, err := drive.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve Drive client: %v", err)
 	}
 
-	// Store the FilesService
-	g.fs = srv.Files
-	return g.fs, nil
+	g.srv = srv
+	return g.srv, nil
 }
 
 // Shutdown implements graceful shutdown for Drive API client
 func (g *driveFileProvider) Shutdown() error {
 	// Google Drive API client doesn't require explicit cleanup
-	// Setting fs to nil to allow garbage collection
-	g.fs = nil
+	// Setting srv to nil to allow garbage collection
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.srv = nil
 	return nil
 }
 
@@ -167,7 +325,7 @@ func (g *driveFileProvider) Shutdown() error {
 func (g *driveFileProvider) HealthCheck() error {
 	// Verify that the client can be initialized successfully
 	// This checks credentials and configuration without making an API call
-	_, err := g.getFileService()
+	_, err := g.getService()
 	if err != nil {
 		return fmt.Errorf("drive client health check failed: %w", err)
 	}
@@ -175,18 +333,6 @@ func (g *driveFileProvider) HealthCheck() error {
 	return nil
 }
 
-// Retrieves a token from a local file.
-func (g *driveFileProvider) tokenFromFile(baseDir, file string) (*oauth2.Token, error) {
-	f, err := core.SafeOpen(baseDir, file)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = f.Close() }()
-	tok := &oauth2.Token{}
-	err = json.NewDecoder(f).Decode(tok)
-	return tok, err
-}
-
 func (g *driveFileProvider) queryBuilder(param *repository.QueryListParam) string {
 	var sb strings.Builder
 	folderQuery := fmt.Sprintf("mimeType = '%s'", DriveFolderMimeType)
@@ -1,11 +1,20 @@
 package gateway
 
 import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/c18t/nippo-cli/internal/domain/repository"
 	"github.com/samber/do/v2"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
 )
 
 func TestNewDriveFileTimestamp(t *testing.T) {
@@ -70,6 +79,34 @@ func TestDriveFileProvider_Shutdown(t *testing.T) {
 	}
 }
 
+func TestDriveFileProvider_getServiceParallel(t *testing.T) {
+	provider := newFakeDriveProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"files": []}`))
+	}).(*driveFileProvider)
+	srv := provider.srv
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, err := provider.getService(); err != nil || got != srv {
+				t.Errorf("getService() = %p, %v, want the service in use", got, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := provider.Shutdown(); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	provider.mu.RLock()
+	defer provider.mu.RUnlock()
+	if provider.srv != nil {
+		t.Error("Shutdown() kept the service")
+	}
+}
+
 func TestDriveFileProvider_queryBuilder(t *testing.T) {
 	provider := &driveFileProvider{}
 
@@ -144,3 +181,257 @@ func TestDriveFolderMimeType(t *testing.T) {
 		t.Errorf("DriveFolderMimeType = %q, want %q", DriveFolderMimeType, expected)
 	}
 }
+
+// newFakeDriveProvider starts a fake Drive API server backed by handler
+// and returns a provider talking to it.
+func newFakeDriveProvider(t *testing.T, handler http.HandlerFunc) DriveFileProvider {
+	t.Helper()
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	srv, err := drive.NewService(context.Background(),
+		option.WithEndpoint(ts.URL+"/"),
+		option.WithHTTPClient(ts.Client()),
+	)
+	if err != nil {
+		t.Fatalf("drive.NewService() error = %v", err)
+	}
+	provider := NewDriveFileProviderForService(srv)
+	provider.(*driveFileProvider).retry = &RetryPolicy{
+		MaxAttempts:  3,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     time.Millisecond,
+		sleep:        func(time.Duration) {},
+	}
+	return provider
+}
+
+func TestDriveFileProvider_GetStartPageToken(t *testing.T) {
+	provider := newFakeDriveProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/changes/startPageToken" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"startPageToken": "100"}`))
+	})
+
+	token, err := provider.GetStartPageToken()
+	if err != nil {
+		t.Fatalf("GetStartPageToken() error = %v", err)
+	}
+	if token != "100" {
+		t.Errorf("GetStartPageToken() = %q, want %q", token, "100")
+	}
+}
+
+func TestDriveFileProvider_ListChanges(t *testing.T) {
+	provider := newFakeDriveProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/changes" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.URL.Query().Get("pageToken") {
+		case "100":
+			_, _ = w.Write([]byte(`{
+				"nextPageToken": "101",
+				"changes": [{"fileId": "f1", "file": {"id": "f1", "name": "2024-01-15.md", "md5Checksum": "abc", "parents": ["root"]}}]
+			}`))
+		case "101":
+			_, _ = w.Write([]byte(`{
+				"newStartPageToken": "102",
+				"changes": [{"fileId": "f2", "removed": true}]
+			}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": {"code": 400, "message": "Invalid Value"}}`))
+		}
+	})
+
+	res, err := provider.ListChanges("100")
+	if err != nil {
+		t.Fatalf("ListChanges() error = %v", err)
+	}
+	if res.NextPageToken != "101" || len(res.Changes) != 1 || res.Changes[0].File.Md5Checksum != "abc" {
+		t.Errorf("ListChanges(100) = %+v", res)
+	}
+
+	res, err = provider.ListChanges("101")
+	if err != nil {
+		t.Fatalf("ListChanges() error = %v", err)
+	}
+	if res.NewStartPageToken != "102" || !res.Changes[0].Removed {
+		t.Errorf("ListChanges(101) = %+v", res)
+	}
+
+	_, err = provider.ListChanges("expired")
+	if !errors.Is(err, repository.ErrFullSyncRequired) {
+		t.Errorf("ListChanges(expired) error = %v, want ErrFullSyncRequired", err)
+	}
+}
+
+func TestDriveFileProvider_ListChangesServerError(t *testing.T) {
+	provider := newFakeDriveProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error": {"code": 403, "message": "Forbidden"}}`))
+	})
+
+	_, err := provider.ListChanges("100")
+	if err == nil {
+		t.Fatal("ListChanges() expected error")
+	}
+	if errors.Is(err, repository.ErrFullSyncRequired) {
+		t.Error("a permission error should not ask for a full sync")
+	}
+}
+
+func TestDriveFileProvider_ListFromFakeServer(t *testing.T) {
+	provider := newFakeDriveProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/files" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"files": [{"id": "f1", "name": "2024-01-15.md", "md5Checksum": "abc"}]}`))
+	})
+
+	res, err := provider.List(&repository.QueryListParam{Folders: []string{"root"}})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(res.Files) != 1 || res.Files[0].Md5Checksum != "abc" {
+		t.Errorf("List() = %+v", res.Files)
+	}
+}
+
+func TestDriveFileProvider_RetriesRateLimit(t *testing.T) {
+	calls := 0
+	provider := newFakeDriveProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error": {"code": 429, "message": "Too Many Requests"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"startPageToken": "100"}`))
+	})
+
+	var events []RetryEvent
+	provider.OnRetry(func(event RetryEvent) {
+		events = append(events, event)
+	})
+
+	token, err := provider.GetStartPageToken()
+	if err != nil {
+		t.Fatalf("GetStartPageToken() error = %v", err)
+	}
+	if token != "100" {
+		t.Errorf("GetStartPageToken() = %q, want %q", token, "100")
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+	if len(events) != 1 {
+		t.Fatalf("retry events = %d, want 1", len(events))
+	}
+	if events[0].Wait != 7*time.Second {
+		t.Errorf("Wait = %v, want the Retry-After of 7s", events[0].Wait)
+	}
+}
+
+func TestDriveFileProvider_UpdateRetriesWithFullBody(t *testing.T) {
+	var bodies []int
+	provider := newFakeDriveProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, len(b))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error": {"code": 503, "message": "Unavailable"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": "f1"}`))
+	})
+
+	if err := provider.Update("f1", []byte("# nippo")); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("requests = %d, want 2", len(bodies))
+	}
+	if bodies[1] == 0 || bodies[1] != bodies[0] {
+		t.Errorf("retried body length = %d, want the same %d bytes as the first attempt", bodies[1], bodies[0])
+	}
+}
+
+func TestDriveFileProvider_Get(t *testing.T) {
+	provider := newFakeDriveProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Query().Get("fields"), "version") {
+			t.Errorf("fields = %q, want the revision fields", r.URL.Query().Get("fields"))
+		}
+		_, _ = w.Write([]byte(`{"id": "f1", "name": "2024-01-15.md", "md5Checksum": "abc", "version": "7"}`))
+	})
+
+	file, err := provider.Get("f1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if file.Md5Checksum != "abc" || file.Version != 7 {
+		t.Errorf("Get() = %+v, want the revision of the file", file)
+	}
+}
+
+func TestDriveFileProvider_GivesUpAfterMaxAttempts(t *testing.T) {
+	calls := 0
+	provider := newFakeDriveProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"error": {"code": 500, "message": "Backend Error"}}`))
+	})
+
+	if _, err := provider.Download("f1"); err == nil {
+		t.Fatal("Download() expected error")
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestDriveFileProvider_Create(t *testing.T) {
+	var created []string
+	provider := newFakeDriveProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/files/generateIds":
+			_, _ = w.Write([]byte(`{"ids": ["new-id"]}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/files"):
+			b, _ := io.ReadAll(r.Body)
+			created = append(created, string(b))
+			if len(created) == 1 {
+				// The file was created, but the response got lost
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte(`{"error": {"code": 503, "message": "Unavailable"}}`))
+				return
+			}
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error": {"code": 409, "message": "A file already exists with the provided ID."}}`))
+		case r.URL.Path == "/files/new-id":
+			_, _ = w.Write([]byte(`{"id": "new-id", "name": "2024-01-15.md", "parents": ["root"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	file, err := provider.Create(&drive.File{Name: "2024-01-15.md", Parents: []string{"root"}}, []byte("# nippo"))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if file.Id != "new-id" || file.Name != "2024-01-15.md" {
+		t.Errorf("Create() = %+v, want the file created by the first attempt", file)
+	}
+	if len(created) != 2 {
+		t.Fatalf("create requests = %d, want 2", len(created))
+	}
+	for _, body := range created {
+		if !strings.Contains(body, `"id":"new-id"`) || !strings.Contains(body, "# nippo") {
+			t.Errorf("create request = %q, want the generated ID and the content", body)
+		}
+	}
+}
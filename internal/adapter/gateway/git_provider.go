@@ -0,0 +1,236 @@
+package gateway
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/samber/do/v2"
+)
+
+// GitFile is a file in the tree of the source branch
+type GitFile struct {
+	// Path is the slash separated path from the repository root
+	Path string
+	// Blob is the object ID of the content, which changes whenever the content does
+	Blob string
+}
+
+// GitFileHistory holds the first and last commit times of a file
+type GitFileHistory struct {
+	Created  time.Time
+	Modified time.Time
+}
+
+type GitProvider interface {
+	// ListFiles lists the files under dir in the source branch
+	ListFiles(dir string) ([]GitFile, error)
+	// History returns the commit times of every file under dir, keyed by path
+	History(dir string) (map[string]GitFileHistory, error)
+	// Read returns the content of a file in the source branch
+	Read(filePath string) ([]byte, error)
+	// Commit writes content to filePath in the working tree and commits it to the
+	// source branch. The commit is published by Push.
+	Commit(filePath string, content []byte, message string) error
+	// Push pushes the commits made since the last push when the repository was
+	// cloned from a URL
+	Push() error
+}
+
+type gitProvider struct {
+	mu       sync.Mutex
+	synced   bool
+	unpushed bool
+}
+
+func NewGitProvider(_ do.Injector) (GitProvider, error) {
+	return &gitProvider{}, nil
+}
+
+func (g *gitProvider) ListFiles(dir string) ([]GitFile, error) {
+	if err := g.sync(); err != nil {
+		return nil, err
+	}
+	out, err := g.git("ls-tree", "-r", "-z", g.ref(), "--", dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []GitFile
+	for _, entry := range strings.Split(string(out), "\x00") {
+		// <mode> SP <type> SP <object> TAB <path>
+		meta, filePath, ok := strings.Cut(entry, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+		files = append(files, GitFile{Path: filePath, Blob: fields[2]})
+	}
+	return files, nil
+}
+
+func (g *gitProvider) History(dir string) (map[string]GitFileHistory, error) {
+	if err := g.sync(); err != nil {
+		return nil, err
+	}
+	out, err := g.git("log", "--no-renames", "--format=%x1e%aI", "--name-only", "-z", g.ref(), "--", dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commits are listed newest first, so the first time a path is seen is its
+	// last modification and the last time is its creation.
+	history := map[string]GitFileHistory{}
+	for _, record := range strings.Split(string(out), "\x1e") {
+		// With -z, the date and each path are terminated by NUL
+		fields := strings.FieldsFunc(record, func(r rune) bool { return r == 0 || r == '\n' })
+		if len(fields) == 0 {
+			continue
+		}
+		committed, err := time.Parse(time.RFC3339, fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse commit date %q: %w", fields[0], err)
+		}
+		for _, name := range fields[1:] {
+			h, seen := history[name]
+			if !seen {
+				h.Modified = committed
+			}
+			h.Created = committed
+			history[name] = h
+		}
+	}
+	return history, nil
+}
+
+func (g *gitProvider) Read(filePath string) ([]byte, error) {
+	if err := g.sync(); err != nil {
+		return nil, err
+	}
+	return g.git("cat-file", "blob", g.ref()+":"+filePath)
+}
+
+func (g *gitProvider) Commit(filePath string, content []byte, message string) error {
+	if err := g.sync(); err != nil {
+		return err
+	}
+	branch := core.Cfg.Source.Branch
+	if branch != "" {
+		current, err := g.git("symbolic-ref", "--quiet", "--short", "HEAD")
+		if err != nil || strings.TrimSpace(string(current)) != branch {
+			return fmt.Errorf("unable to commit %s: branch %s is not checked out in %s", filePath, branch, g.dir())
+		}
+	}
+	// Never overwrite edits that are not committed yet
+	if _, err := g.git("diff", "--quiet", "HEAD", "--", filePath); err != nil {
+		return fmt.Errorf("unable to commit %s: the file has uncommitted changes", filePath)
+	}
+
+	fullPath := filepath.Join(g.dir(), filepath.FromSlash(filePath))
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(fullPath, content, info.Mode().Perm()); err != nil {
+		return err
+	}
+	if _, err := g.git("add", "--", filePath); err != nil {
+		return err
+	}
+	if _, err := g.git("commit", "--quiet", "-m", message, "--", filePath); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	g.unpushed = true
+	g.mu.Unlock()
+	return nil
+}
+
+func (g *gitProvider) Push() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.unpushed || core.Cfg.Source.Url == "" {
+		return nil
+	}
+	if _, err := g.git("push", "--quiet", "origin", "HEAD"); err != nil {
+		return err
+	}
+	g.unpushed = false
+	return nil
+}
+
+// sync clones the source.url repository into the cache dir, or fast-forwards
+// an existing clone, once per run. A source.path repository is used as is.
+func (g *gitProvider) sync() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.synced {
+		return nil
+	}
+
+	source := core.Cfg.Source
+	if source.Url != "" {
+		dir := g.dir()
+		if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+			args := []string{"clone", "--quiet"}
+			if source.Branch != "" {
+				args = append(args, "--branch", source.Branch)
+			}
+			args = append(args, source.Url, dir)
+			if _, err := runGit("", args...); err != nil {
+				return err
+			}
+		} else {
+			if _, err := g.git("pull", "--quiet", "--ff-only"); err != nil {
+				return err
+			}
+		}
+	}
+	g.synced = true
+	return nil
+}
+
+func (g *gitProvider) dir() string {
+	return core.Cfg.GetSourceRepository()
+}
+
+// ref returns the branch to read, or HEAD for the checked out branch
+func (g *gitProvider) ref() string {
+	if branch := core.Cfg.Source.Branch; branch != "" {
+		return "refs/heads/" + path.Clean(branch)
+	}
+	return "HEAD"
+}
+
+func (g *gitProvider) git(args ...string) ([]byte, error) {
+	return runGit(g.dir(), args...)
+}
+
+func runGit(dir string, args ...string) ([]byte, error) {
+	command := args[0]
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	args = append([]string{"-c", "core.quotepath=false"}, args...)
+	cmd := exec.Command("git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %w: %s", command, err, msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", command, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,203 @@
+package gateway
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/samber/do/v2"
+)
+
+// newTestGitRepo creates a repository with two commits on main and points core.Cfg at it
+func newTestGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	repo := t.TempDir()
+	gitRun(t, repo, time.Time{}, "init", "--quiet", "--initial-branch=main")
+	gitRun(t, repo, time.Time{}, "config", "user.name", "nippo")
+	gitRun(t, repo, time.Time{}, "config", "user.email", "nippo@example.com")
+
+	writeGitFile(t, repo, "nippo/2024-01-15.md", "# 15")
+	writeGitFile(t, repo, "README.md", "# readme")
+	gitRun(t, repo, time.Time{}, "add", ".")
+	gitRun(t, repo, time.Date(2024, 1, 15, 21, 0, 0, 0, time.UTC), "commit", "--quiet", "-m", "first")
+
+	writeGitFile(t, repo, "nippo/2024-01-15.md", "# 15 edited")
+	writeGitFile(t, repo, "nippo/2024/2024-01-16.md", "# 16")
+	gitRun(t, repo, time.Time{}, "add", ".")
+	gitRun(t, repo, time.Date(2024, 1, 16, 21, 0, 0, 0, time.UTC), "commit", "--quiet", "-m", "second")
+
+	saved := core.Cfg
+	t.Cleanup(func() { core.Cfg = saved })
+	core.Cfg = &core.Config{Source: core.ConfigSource{Type: core.SourceTypeGit, Path: repo}}
+	return repo
+}
+
+func writeGitFile(t *testing.T, repo, name, content string) {
+	t.Helper()
+	path := filepath.Join(repo, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func gitRun(t *testing.T, repo string, date time.Time, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+	if !date.IsZero() {
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_DATE="+date.Format(time.RFC3339),
+			"GIT_COMMITTER_DATE="+date.Format(time.RFC3339))
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return string(out)
+}
+
+func TestGitProvider_ListFiles(t *testing.T) {
+	newTestGitRepo(t)
+	provider, _ := NewGitProvider(do.New())
+
+	files, err := provider.ListFiles("nippo")
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("ListFiles() = %+v, want 2 files", files)
+	}
+	if files[0].Path != "nippo/2024-01-15.md" || files[1].Path != "nippo/2024/2024-01-16.md" {
+		t.Errorf("ListFiles() paths = %s, %s", files[0].Path, files[1].Path)
+	}
+	if len(files[0].Blob) < 40 {
+		t.Errorf("Blob = %q, want an object ID", files[0].Blob)
+	}
+}
+
+func TestGitProvider_History(t *testing.T) {
+	newTestGitRepo(t)
+	provider, _ := NewGitProvider(do.New())
+
+	history, err := provider.History("nippo")
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	edited := history["nippo/2024-01-15.md"]
+	if !edited.Created.Equal(time.Date(2024, 1, 15, 21, 0, 0, 0, time.UTC)) {
+		t.Errorf("Created = %v, want the first commit", edited.Created)
+	}
+	if !edited.Modified.Equal(time.Date(2024, 1, 16, 21, 0, 0, 0, time.UTC)) {
+		t.Errorf("Modified = %v, want the last commit", edited.Modified)
+	}
+	added := history["nippo/2024/2024-01-16.md"]
+	if !added.Created.Equal(added.Modified) {
+		t.Errorf("history = %+v, want a single commit", added)
+	}
+	if _, ok := history["README.md"]; ok {
+		t.Error("History() should only include files under dir")
+	}
+}
+
+func TestGitProvider_ReadBranch(t *testing.T) {
+	repo := newTestGitRepo(t)
+	gitRun(t, repo, time.Time{}, "checkout", "--quiet", "-b", "draft")
+	writeGitFile(t, repo, "nippo/2024-01-15.md", "# draft")
+	gitRun(t, repo, time.Time{}, "commit", "--quiet", "-am", "draft")
+	core.Cfg.Source.Branch = "main"
+
+	provider, _ := NewGitProvider(do.New())
+	content, err := provider.Read("nippo/2024-01-15.md")
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if string(content) != "# 15 edited" {
+		t.Errorf("Read() = %q, want the content on main", content)
+	}
+}
+
+func TestGitProvider_Commit(t *testing.T) {
+	repo := newTestGitRepo(t)
+	provider, _ := NewGitProvider(do.New())
+
+	if err := provider.Commit("nippo/2024-01-15.md", []byte("---\ncreated: x\n---\n"), "Update 2024-01-15.md"); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if got := gitRun(t, repo, time.Time{}, "log", "-1", "--format=%s"); strings.TrimSpace(got) != "Update 2024-01-15.md" {
+		t.Errorf("last commit = %q", got)
+	}
+	if got := gitRun(t, repo, time.Time{}, "status", "--porcelain"); got != "" {
+		t.Errorf("working tree should be clean, got %q", got)
+	}
+}
+
+func TestGitProvider_CommitRefusesUncommittedChanges(t *testing.T) {
+	repo := newTestGitRepo(t)
+	writeGitFile(t, repo, "nippo/2024-01-15.md", "# work in progress")
+	provider, _ := NewGitProvider(do.New())
+
+	if err := provider.Commit("nippo/2024-01-15.md", []byte("# formatted"), "Update"); err == nil {
+		t.Fatal("Commit() expected error")
+	}
+	content, _ := os.ReadFile(filepath.Join(repo, "nippo", "2024-01-15.md"))
+	if string(content) != "# work in progress" {
+		t.Errorf("uncommitted edit was overwritten: %q", content)
+	}
+}
+
+func TestGitProvider_CommitRequiresCheckedOutBranch(t *testing.T) {
+	newTestGitRepo(t)
+	core.Cfg.Source.Branch = "publish"
+	provider, _ := NewGitProvider(do.New())
+
+	if err := provider.Commit("nippo/2024-01-15.md", []byte("# formatted"), "Update"); err == nil {
+		t.Error("Commit() expected error for a branch that is not checked out")
+	}
+}
+
+func TestGitProvider_CloneFromUrl(t *testing.T) {
+	origin := newTestGitRepo(t)
+	gitRun(t, origin, time.Time{}, "config", "receive.denyCurrentBranch", "updateInstead")
+	core.Cfg = &core.Config{Source: core.ConfigSource{Type: core.SourceTypeGit, Url: origin, Branch: "main"}}
+	core.Cfg.Paths.CacheDir = t.TempDir()
+
+	provider, _ := NewGitProvider(do.New())
+	files, err := provider.ListFiles("nippo")
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("ListFiles() = %+v, want 2 files", files)
+	}
+
+	clone := core.Cfg.GetSourceRepository()
+	gitRun(t, clone, time.Time{}, "config", "user.name", "nippo")
+	gitRun(t, clone, time.Time{}, "config", "user.email", "nippo@example.com")
+	if err := provider.Commit("nippo/2024-01-15.md", []byte("# pushed"), "Update 2024-01-15.md"); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if err := provider.Commit("nippo/2024/2024-01-16.md", []byte("# pushed"), "Update 2024-01-16.md"); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if got := gitRun(t, origin, time.Time{}, "show", "main:nippo/2024-01-15.md"); got == "# pushed" {
+		t.Error("Commit() should leave the push to Push()")
+	}
+	if err := provider.Push(); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if got := gitRun(t, origin, time.Time{}, "show", "main:nippo/2024-01-15.md"); got != "# pushed" {
+		t.Errorf("origin content = %q, want the pushed commit", got)
+	}
+}
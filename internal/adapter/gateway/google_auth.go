@@ -0,0 +1,265 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"golang.org/x/oauth2/jwt"
+	"google.golang.org/api/drive/v3"
+)
+
+// AuthMode is how nippo authenticates with Google Drive
+type AuthMode string
+
+const (
+	// AuthModeOAuth uses the OAuth client in credentials.json and the token saved by `nippo auth`
+	AuthModeOAuth AuthMode = "OAuth token"
+	// AuthModeServiceAccount uses the key file of auth.service_account_key
+	AuthModeServiceAccount AuthMode = "service account"
+)
+
+// DriveAccess is the access to Google Drive a command needs
+type DriveAccess int
+
+const (
+	// DriveReadOnly reads files, which is all build needs
+	DriveReadOnly DriveAccess = iota
+	// DriveReadWrite also updates files, as format does
+	DriveReadWrite
+)
+
+// Scope returns the narrowest OAuth scope that grants the access
+func (a DriveAccess) Scope() string {
+	if a == DriveReadWrite {
+		return drive.DriveScope
+	}
+	return drive.DriveReadonlyScope
+}
+
+// GrantedBy reports whether one of scopes grants the access
+func (a DriveAccess) GrantedBy(scopes []string) bool {
+	for _, scope := range scopes {
+		if scope == drive.DriveScope || (a == DriveReadOnly && scope == drive.DriveReadonlyScope) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthCommand returns the command that grants the access
+func (a DriveAccess) AuthCommand() string {
+	if a == DriveReadWrite {
+		return "nippo auth --write"
+	}
+	return "nippo auth"
+}
+
+func (a DriveAccess) String() string {
+	if a == DriveReadWrite {
+		return "read-write"
+	}
+	return "read-only"
+}
+
+// TokenScopes returns the scopes granted to tok, or nil when they are unknown
+func TokenScopes(tok *oauth2.Token) []string {
+	scope, _ := tok.Extra("scope").(string)
+	if scope == "" {
+		return nil
+	}
+	return strings.Fields(scope)
+}
+
+// CurrentAuthMode returns the auth mode selected by the config
+func CurrentAuthMode() AuthMode {
+	if core.Cfg != nil && core.Cfg.Auth.UsesServiceAccount() {
+		return AuthModeServiceAccount
+	}
+	return AuthModeOAuth
+}
+
+// NewTokenSource returns a token source of the current auth mode for access.
+// A service account key needs no prior `nippo auth` and is only issued tokens
+// for access; otherwise the saved OAuth token is refreshed with the client in
+// credentials.json, and must have been granted access.
+func NewTokenSource(ctx context.Context, access DriveAccess) (oauth2.TokenSource, error) {
+	if CurrentAuthMode() == AuthModeServiceAccount {
+		config, err := ReadServiceAccountKey(core.Cfg.GetServiceAccountKeyPath(), access)
+		if err != nil {
+			return nil, err
+		}
+		return config.TokenSource(ctx), nil
+	}
+
+	dataDir := core.Cfg.GetDataDir()
+	tokenPath := TokenPath(dataDir)
+	tok, err := TokenFromFile(dataDir, tokenPath)
+	if err != nil {
+		return nil, err
+	}
+	// Tokens saved before scopes were recorded had full access
+	if scopes := TokenScopes(tok); scopes != nil && !access.GrantedBy(scopes) {
+		return nil, fmt.Errorf("the saved token does not grant %s access to Google Drive. Run `%s` to grant it", access, access.AuthCommand())
+	}
+
+	credPath := filepath.Join(dataDir, "credentials.json")
+	b, err := os.ReadFile(credPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf(`credentials.json not found
+
+Please download the OAuth 2.0 Client ID credentials from Google Cloud Console:
+
+1. Go to https://console.cloud.google.com/apis/credentials
+2. Create OAuth 2.0 Client ID (Application type: Desktop app)
+3. Download the credentials JSON file
+4. Save it to: %s
+
+Note: Run 'nippo init' to set up your environment`, credPath)
+		}
+		return nil, fmt.Errorf("unable to read credentials file: %w", err)
+	}
+
+	config, err := OAuthConfigFromJSON(b, access)
+	if err != nil {
+		return nil, err
+	}
+	return &persistingTokenSource{
+		base:    config.TokenSource(ctx, tok),
+		baseDir: dataDir,
+		path:    tokenPath,
+		saved:   tok.AccessToken,
+		scope:   tok.Extra("scope"),
+	}, nil
+}
+
+// persistingTokenSource writes the token back to token.json whenever base
+// refreshes it, so the next run starts from the latest token
+type persistingTokenSource struct {
+	base    oauth2.TokenSource
+	baseDir string
+	path    string
+
+	mu    sync.Mutex
+	saved string // access token last written to path
+	scope any    // scopes of the saved token, kept when a refresh does not return them
+}
+
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+	tok, err := s.base.Token()
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tok.Extra("scope") == nil && s.scope != nil {
+		tok = tok.WithExtra(map[string]any{"scope": s.scope})
+	}
+	if tok.AccessToken != s.saved {
+		if err := SaveToken(s.baseDir, s.path, tok); err != nil {
+			return nil, err
+		}
+		s.saved = tok.AccessToken
+	}
+	return tok, nil
+}
+
+// OAuthConfigFromJSON parses the OAuth client in credentials.json to request
+// access. The device authorization endpoint is not part of the file, so
+// Google's is filled in.
+func OAuthConfigFromJSON(b []byte, access DriveAccess) (*oauth2.Config, error) {
+	config, err := google.ConfigFromJSON(b, access.Scope())
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse client secret file to config: %v", err)
+	}
+	if config.Endpoint.DeviceAuthURL == "" {
+		config.Endpoint.DeviceAuthURL = google.Endpoint.DeviceAuthURL
+	}
+	return config, nil
+}
+
+// ReadServiceAccountKey reads and parses the service account key file at keyPath
+// to issue tokens for access
+func ReadServiceAccountKey(keyPath string, access DriveAccess) (*jwt.Config, error) {
+	b, err := os.ReadFile(keyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("service account key not found: %s\n\nCheck `service_account_key` in the [auth] section of nippo.toml", keyPath)
+		}
+		return nil, fmt.Errorf("unable to read service account key: %w", err)
+	}
+	config, err := google.JWTConfigFromJSON(b, access.Scope())
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse service account key %s: %w", keyPath, err)
+	}
+	return config, nil
+}
+
+// TokenPath returns the path of the OAuth token saved in dataDir
+func TokenPath(dataDir string) string {
+	return filepath.Join(dataDir, "token.json")
+}
+
+// savedToken is the content of token.json: the token and the scopes granted to it
+type savedToken struct {
+	*oauth2.Token
+	Scope string `json:"scope,omitempty"`
+}
+
+// SaveToken writes token and its granted scopes to a temporary file and renames
+// it into place, so an interrupted write never leaves a truncated token behind.
+func SaveToken(baseDir, path string, token *oauth2.Token) error {
+	cleanPath := filepath.Clean(path)
+	if !core.IsPathSafe(baseDir, cleanPath) {
+		return fmt.Errorf("path traversal detected: %s is outside %s", path, baseDir)
+	}
+
+	b, err := json.Marshal(savedToken{Token: token, Scope: strings.Join(TokenScopes(token), " ")})
+	if err != nil {
+		return fmt.Errorf("unable to encode token: %w", err)
+	}
+
+	// CreateTemp creates the file readable by the owner only
+	tmp, err := os.CreateTemp(filepath.Dir(cleanPath), "token.json.*")
+	if err != nil {
+		return fmt.Errorf("unable to cache oauth token: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("unable to cache oauth token: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to close token file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), cleanPath); err != nil {
+		return fmt.Errorf("unable to cache oauth token: %w", err)
+	}
+	return nil
+}
+
+// TokenFromFile retrieves a token saved by `nippo auth` from a local file.
+func TokenFromFile(baseDir, file string) (*oauth2.Token, error) {
+	f, err := core.SafeOpen(baseDir, file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	saved := savedToken{Token: &oauth2.Token{}}
+	if err := json.NewDecoder(f).Decode(&saved); err != nil {
+		return nil, err
+	}
+	if saved.Scope != "" {
+		return saved.Token.WithExtra(map[string]any{"scope": saved.Scope}), nil
+	}
+	return saved.Token, nil
+}
@@ -0,0 +1,215 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/samber/do/v2"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+const (
+	googleTokenInfoURL = "https://oauth2.googleapis.com/tokeninfo"
+	googleRevokeURL    = "https://oauth2.googleapis.com/revoke"
+)
+
+// GoogleAuthStatus describes the credentials of the current auth mode
+type GoogleAuthStatus struct {
+	Mode AuthMode
+	// Path is token.json, or the service account key
+	Path string
+	// Authenticated reports whether credentials were found at Path
+	Authenticated   bool
+	Account         string
+	Scopes          []string
+	Expiry          time.Time
+	HasRefreshToken bool
+	// Problem explains why the credentials could not be used
+	Problem string
+}
+
+type GoogleAuthProvider interface {
+	// Status inspects the credentials of the current auth mode. A refreshed
+	// token is saved like any other API call would.
+	Status() (*GoogleAuthStatus, error)
+	// Logout revokes the saved OAuth token and deletes token.json.
+	// It returns false when there was no token.
+	Logout() (bool, error)
+}
+
+type googleAuthProvider struct {
+	client       *http.Client
+	tokenInfoURL string
+	revokeURL    string
+	driveOptions []option.ClientOption
+}
+
+func NewGoogleAuthProvider(_ do.Injector) (GoogleAuthProvider, error) {
+	return &googleAuthProvider{
+		client:       http.DefaultClient,
+		tokenInfoURL: googleTokenInfoURL,
+		revokeURL:    googleRevokeURL,
+	}, nil
+}
+
+func (g *googleAuthProvider) Status() (*GoogleAuthStatus, error) {
+	ctx := context.Background()
+	status := &GoogleAuthStatus{Mode: CurrentAuthMode()}
+
+	var tokenSource oauth2.TokenSource
+	if status.Mode == AuthModeServiceAccount {
+		status.Path = core.Cfg.GetServiceAccountKeyPath()
+		config, err := ReadServiceAccountKey(status.Path, DriveReadOnly)
+		if err != nil {
+			status.Problem = err.Error()
+			return status, nil
+		}
+		status.Authenticated = true
+		status.Account = config.Email
+		tokenSource = config.TokenSource(ctx)
+	} else {
+		dataDir := core.Cfg.GetDataDir()
+		status.Path = TokenPath(dataDir)
+		if _, err := TokenFromFile(dataDir, status.Path); err != nil {
+			if os.IsNotExist(err) {
+				return status, nil
+			}
+			return nil, fmt.Errorf("unable to read token: %w", err)
+		}
+		status.Authenticated = true
+		var err error
+		if tokenSource, err = NewTokenSource(ctx, DriveReadOnly); err != nil {
+			status.Problem = err.Error()
+			return status, nil
+		}
+	}
+
+	tok, err := tokenSource.Token()
+	if err != nil {
+		status.Problem = fmt.Sprintf("unable to refresh token: %v", err)
+		return status, nil
+	}
+	status.Expiry = tok.Expiry
+	status.HasRefreshToken = tok.RefreshToken != ""
+
+	info, err := g.tokenInfo(ctx, tok.AccessToken)
+	if err != nil {
+		status.Problem = err.Error()
+		return status, nil
+	}
+	status.Scopes = strings.Fields(info.Scope)
+	if status.Account == "" {
+		status.Account = info.Email
+	}
+	if status.Account == "" {
+		// The Drive scopes alone do not expose the email in the token info
+		if status.Account, err = g.driveUser(ctx, tokenSource); err != nil {
+			status.Problem = err.Error()
+		}
+	}
+	return status, nil
+}
+
+func (g *googleAuthProvider) Logout() (bool, error) {
+	if CurrentAuthMode() == AuthModeServiceAccount {
+		return false, fmt.Errorf("a service account key is configured, which nippo does not revoke. Remove `service_account_key` from the [auth] section to stop using it")
+	}
+
+	dataDir := core.Cfg.GetDataDir()
+	tokenPath := TokenPath(dataDir)
+	tok, err := TokenFromFile(dataDir, tokenPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("unable to read token: %w", err)
+	}
+
+	// Revoking the refresh token revokes the access tokens issued from it as well
+	token := tok.RefreshToken
+	if token == "" {
+		token = tok.AccessToken
+	}
+	if err := g.revoke(context.Background(), token); err != nil {
+		return false, err
+	}
+	if err := os.Remove(tokenPath); err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("unable to delete token: %w", err)
+	}
+	return true, nil
+}
+
+type googleTokenInfo struct {
+	Scope string `json:"scope"`
+	Email string `json:"email"`
+}
+
+func (g *googleAuthProvider) tokenInfo(ctx context.Context, accessToken string) (*googleTokenInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.tokenInfoURL+"?"+url.Values{"access_token": {accessToken}}.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve token info: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to retrieve token info: %s", res.Status)
+	}
+	info := &googleTokenInfo{}
+	if err := json.NewDecoder(res.Body).Decode(info); err != nil {
+		return nil, fmt.Errorf("unable to decode token info: %w", err)
+	}
+	return info, nil
+}
+
+func (g *googleAuthProvider) driveUser(ctx context.Context, tokenSource oauth2.TokenSource) (string, error) {
+	options := append([]option.ClientOption{option.WithTokenSource(tokenSource)}, g.driveOptions...)
+	srv, err := drive.NewService(ctx, options...)
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve Drive client: %v", err)
+	}
+	about, err := srv.About.Get().Fields("user(displayName, emailAddress)").Do()
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve account: %w", err)
+	}
+	if about.User == nil {
+		return "", nil
+	}
+	return about.User.EmailAddress, nil
+}
+
+func (g *googleAuthProvider) revoke(ctx context.Context, token string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.revokeURL, strings.NewReader(url.Values{"token": {token}}.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to revoke token: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	// An expired or already revoked token can be deleted all the same
+	var body struct {
+		Error string `json:"error"`
+	}
+	if res.StatusCode == http.StatusBadRequest && json.NewDecoder(res.Body).Decode(&body) == nil && body.Error == "invalid_token" {
+		return nil
+	}
+	return fmt.Errorf("unable to revoke token: %s", res.Status)
+}
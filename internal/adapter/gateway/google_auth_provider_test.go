@@ -0,0 +1,272 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/option"
+)
+
+// fakeGoogle serves the token, token info, revoke and Drive about endpoints
+type fakeGoogle struct {
+	server   *httptest.Server
+	refresh  int
+	revoked  []string
+	revokeOK bool
+}
+
+func newFakeGoogle(t *testing.T) *fakeGoogle {
+	t.Helper()
+	f := &fakeGoogle{revokeOK: true}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		switch r.URL.Path {
+		case "/token":
+			f.refresh++
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"access_token":"fresh-%d","token_type":"Bearer","expires_in":3600}`, f.refresh)
+		case "/tokeninfo":
+			_, _ = fmt.Fprint(w, `{"scope":"https://www.googleapis.com/auth/drive","expires_in":"3599"}`)
+		case "/about":
+			_, _ = fmt.Fprint(w, `{"user":{"displayName":"Nippo","emailAddress":"nippo@example.com"}}`)
+		case "/revoke":
+			f.revoked = append(f.revoked, r.Form.Get("token"))
+			if !f.revokeOK {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = fmt.Fprint(w, `{"error":"invalid_token"}`)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeGoogle) provider() *googleAuthProvider {
+	return &googleAuthProvider{
+		client:       f.server.Client(),
+		tokenInfoURL: f.server.URL + "/tokeninfo",
+		revokeURL:    f.server.URL + "/revoke",
+		driveOptions: []option.ClientOption{option.WithEndpoint(f.server.URL + "/")},
+	}
+}
+
+// setupOAuthToken points core.Cfg at a data dir holding credentials.json for
+// the fake server and tok as token.json
+func setupOAuthToken(t *testing.T, f *fakeGoogle, tok *oauth2.Token) string {
+	t.Helper()
+	saved := core.Cfg
+	t.Cleanup(func() { core.Cfg = saved })
+	core.Cfg = &core.Config{}
+	dataDir := t.TempDir()
+	core.Cfg.Paths.DataDir = dataDir
+
+	credentials := fmt.Sprintf(`{"installed":{"client_id":"id","client_secret":"secret","redirect_uris":["http://localhost"],"auth_uri":"%[1]s/auth","token_uri":"%[1]s/token"}}`, f.server.URL)
+	if err := os.WriteFile(filepath.Join(dataDir, "credentials.json"), []byte(credentials), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if tok != nil {
+		if err := SaveToken(dataDir, TokenPath(dataDir), tok); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dataDir
+}
+
+func TestSaveToken(t *testing.T) {
+	dataDir := t.TempDir()
+	tokenPath := TokenPath(dataDir)
+	if err := SaveToken(dataDir, tokenPath, &oauth2.Token{AccessToken: "a", RefreshToken: "r"}); err != nil {
+		t.Fatalf("SaveToken() error = %v", err)
+	}
+
+	info, err := os.Stat(tokenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("permission = %o, want 0600", perm)
+	}
+	tok, err := TokenFromFile(dataDir, tokenPath)
+	if err != nil || tok.AccessToken != "a" || tok.RefreshToken != "r" {
+		t.Errorf("TokenFromFile() = %+v, %v", tok, err)
+	}
+	if entries, _ := os.ReadDir(dataDir); len(entries) != 1 {
+		t.Errorf("data dir has %d entries, want no temporary files left", len(entries))
+	}
+
+	if err := SaveToken(dataDir, filepath.Join(dataDir, "..", "token.json"), &oauth2.Token{}); err == nil {
+		t.Error("SaveToken() expected error for a path outside the base dir")
+	}
+}
+
+func TestNewTokenSource_PersistsRefreshedToken(t *testing.T) {
+	f := newFakeGoogle(t)
+	dataDir := setupOAuthToken(t, f, &oauth2.Token{
+		AccessToken:  "expired",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(-time.Hour),
+	})
+
+	ts, err := NewTokenSource(context.Background(), DriveReadOnly)
+	if err != nil {
+		t.Fatalf("NewTokenSource() error = %v", err)
+	}
+	tok, err := ts.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if tok.AccessToken != "fresh-1" {
+		t.Errorf("AccessToken = %q, want the refreshed token", tok.AccessToken)
+	}
+
+	saved, err := TokenFromFile(dataDir, TokenPath(dataDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.AccessToken != "fresh-1" || saved.RefreshToken != "refresh" {
+		t.Errorf("token.json = %+v, want the refreshed token with the refresh token kept", saved)
+	}
+
+	// A valid token is reused without another refresh
+	if _, err := ts.Token(); err != nil || f.refresh != 1 {
+		t.Errorf("Token() refreshed %d times, want 1 (err = %v)", f.refresh, err)
+	}
+}
+
+func TestTokenFromFile_Scopes(t *testing.T) {
+	dataDir := t.TempDir()
+	tokenPath := TokenPath(dataDir)
+	tok := (&oauth2.Token{AccessToken: "a"}).WithExtra(map[string]any{"scope": "https://www.googleapis.com/auth/drive.readonly"})
+	if err := SaveToken(dataDir, tokenPath, tok); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := TokenFromFile(dataDir, tokenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scopes := TokenScopes(saved); len(scopes) != 1 || scopes[0] != "https://www.googleapis.com/auth/drive.readonly" {
+		t.Errorf("TokenScopes() = %v, want the saved scope", scopes)
+	}
+
+	// Tokens saved without scopes have unknown scopes
+	if err := SaveToken(dataDir, tokenPath, &oauth2.Token{AccessToken: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	saved, _ = TokenFromFile(dataDir, tokenPath)
+	if scopes := TokenScopes(saved); scopes != nil {
+		t.Errorf("TokenScopes() = %v, want nil", scopes)
+	}
+}
+
+func TestNewTokenSource_InsufficientScope(t *testing.T) {
+	f := newFakeGoogle(t)
+	setupOAuthToken(t, f, (&oauth2.Token{AccessToken: "valid", RefreshToken: "refresh"}).
+		WithExtra(map[string]any{"scope": "https://www.googleapis.com/auth/drive.readonly"}))
+
+	if _, err := NewTokenSource(context.Background(), DriveReadOnly); err != nil {
+		t.Errorf("NewTokenSource(DriveReadOnly) error = %v", err)
+	}
+	_, err := NewTokenSource(context.Background(), DriveReadWrite)
+	if err == nil || !strings.Contains(err.Error(), "nippo auth --write") {
+		t.Errorf("NewTokenSource(DriveReadWrite) error = %v, want a hint to grant write access", err)
+	}
+}
+
+func TestGoogleAuthProvider_Status(t *testing.T) {
+	f := newFakeGoogle(t)
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	dataDir := setupOAuthToken(t, f, &oauth2.Token{AccessToken: "valid", RefreshToken: "refresh", Expiry: expiry})
+
+	status, err := f.provider().Status()
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if !status.Authenticated || status.Problem != "" {
+		t.Fatalf("Status() = %+v, want authenticated", status)
+	}
+	if status.Mode != AuthModeOAuth || status.Path != TokenPath(dataDir) {
+		t.Errorf("Mode, Path = %q, %q", status.Mode, status.Path)
+	}
+	if status.Account != "nippo@example.com" {
+		t.Errorf("Account = %q, want the Drive user", status.Account)
+	}
+	if len(status.Scopes) != 1 || status.Scopes[0] != "https://www.googleapis.com/auth/drive" {
+		t.Errorf("Scopes = %v", status.Scopes)
+	}
+	if !status.Expiry.Equal(expiry) || !status.HasRefreshToken {
+		t.Errorf("Expiry, HasRefreshToken = %v, %v", status.Expiry, status.HasRefreshToken)
+	}
+}
+
+func TestGoogleAuthProvider_StatusNotAuthenticated(t *testing.T) {
+	f := newFakeGoogle(t)
+	setupOAuthToken(t, f, nil)
+
+	status, err := f.provider().Status()
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.Authenticated {
+		t.Errorf("Status() = %+v, want not authenticated without token.json", status)
+	}
+}
+
+func TestGoogleAuthProvider_Logout(t *testing.T) {
+	f := newFakeGoogle(t)
+	dataDir := setupOAuthToken(t, f, &oauth2.Token{AccessToken: "access", RefreshToken: "refresh"})
+	provider := f.provider()
+
+	loggedOut, err := provider.Logout()
+	if err != nil || !loggedOut {
+		t.Fatalf("Logout() = %v, %v", loggedOut, err)
+	}
+	if len(f.revoked) != 1 || f.revoked[0] != "refresh" {
+		t.Errorf("revoked = %v, want the refresh token", f.revoked)
+	}
+	if _, err := os.Stat(TokenPath(dataDir)); !os.IsNotExist(err) {
+		t.Error("token.json should be deleted")
+	}
+
+	// Nothing left to log out of
+	if loggedOut, err := provider.Logout(); err != nil || loggedOut {
+		t.Errorf("Logout() = %v, %v, want false without a token", loggedOut, err)
+	}
+}
+
+func TestGoogleAuthProvider_LogoutInvalidToken(t *testing.T) {
+	f := newFakeGoogle(t)
+	f.revokeOK = false
+	dataDir := setupOAuthToken(t, f, &oauth2.Token{AccessToken: "access"})
+
+	if loggedOut, err := f.provider().Logout(); err != nil || !loggedOut {
+		t.Fatalf("Logout() = %v, %v, want an invalid token to be deleted", loggedOut, err)
+	}
+	if len(f.revoked) != 1 || f.revoked[0] != "access" {
+		t.Errorf("revoked = %v, want the access token without a refresh token", f.revoked)
+	}
+	if _, err := os.Stat(TokenPath(dataDir)); !os.IsNotExist(err) {
+		t.Error("token.json should be deleted")
+	}
+}
+
+func TestGoogleAuthProvider_LogoutServiceAccount(t *testing.T) {
+	f := newFakeGoogle(t)
+	setupOAuthToken(t, f, nil)
+	core.Cfg.Auth.ServiceAccountKey = "key.json"
+
+	_, err := f.provider().Logout()
+	if err == nil || !strings.Contains(err.Error(), "service_account_key") {
+		t.Errorf("Logout() error = %v, want a hint to remove the key", err)
+	}
+}
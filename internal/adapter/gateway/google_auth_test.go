@@ -0,0 +1,154 @@
+package gateway
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"golang.org/x/oauth2/google"
+)
+
+func writeServiceAccountKey(t *testing.T, dir string) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: mustMarshalPKCS8(t, key)})
+	b, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "nippo",
+		"private_key_id": "key-id",
+		"private_key":    string(privateKey),
+		"client_email":   "nippo@nippo.iam.gserviceaccount.com",
+		"client_id":      "1234",
+		"token_uri":      "https://oauth2.googleapis.com/token",
+	})
+	keyPath := filepath.Join(dir, "service-account.json")
+	if err := os.WriteFile(keyPath, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return keyPath
+}
+
+func mustMarshalPKCS8(t *testing.T, key *rsa.PrivateKey) []byte {
+	t.Helper()
+	b, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestCurrentAuthMode(t *testing.T) {
+	saved := core.Cfg
+	t.Cleanup(func() { core.Cfg = saved })
+
+	core.Cfg = &core.Config{}
+	if got := CurrentAuthMode(); got != AuthModeOAuth {
+		t.Errorf("CurrentAuthMode() = %q, want %q", got, AuthModeOAuth)
+	}
+	core.Cfg.Auth.ServiceAccountKey = "key.json"
+	if got := CurrentAuthMode(); got != AuthModeServiceAccount {
+		t.Errorf("CurrentAuthMode() = %q, want %q", got, AuthModeServiceAccount)
+	}
+}
+
+func TestReadServiceAccountKey(t *testing.T) {
+	keyPath := writeServiceAccountKey(t, t.TempDir())
+
+	config, err := ReadServiceAccountKey(keyPath, DriveReadOnly)
+	if err != nil {
+		t.Fatalf("ReadServiceAccountKey() error = %v", err)
+	}
+	if config.Email != "nippo@nippo.iam.gserviceaccount.com" {
+		t.Errorf("Email = %q", config.Email)
+	}
+	if len(config.Scopes) != 1 || config.Scopes[0] != "https://www.googleapis.com/auth/drive.readonly" {
+		t.Errorf("Scopes = %v, want the read-only Drive scope", config.Scopes)
+	}
+
+	config, _ = ReadServiceAccountKey(keyPath, DriveReadWrite)
+	if len(config.Scopes) != 1 || config.Scopes[0] != "https://www.googleapis.com/auth/drive" {
+		t.Errorf("Scopes = %v, want the Drive scope", config.Scopes)
+	}
+}
+
+func TestDriveAccess_GrantedBy(t *testing.T) {
+	tests := []struct {
+		access DriveAccess
+		scopes []string
+		want   bool
+	}{
+		{DriveReadOnly, []string{"https://www.googleapis.com/auth/drive.readonly"}, true},
+		{DriveReadOnly, []string{"https://www.googleapis.com/auth/drive"}, true},
+		{DriveReadWrite, []string{"https://www.googleapis.com/auth/drive.readonly"}, false},
+		{DriveReadWrite, []string{"https://www.googleapis.com/auth/drive.readonly", "https://www.googleapis.com/auth/drive"}, true},
+		{DriveReadOnly, []string{"https://www.googleapis.com/auth/drive.file"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.access.GrantedBy(tt.scopes); got != tt.want {
+			t.Errorf("%v.GrantedBy(%v) = %v, want %v", tt.access, tt.scopes, got, tt.want)
+		}
+	}
+}
+
+func TestReadServiceAccountKey_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := ReadServiceAccountKey(filepath.Join(dir, "missing.json"), DriveReadOnly); err == nil {
+		t.Error("ReadServiceAccountKey() expected error for a missing key")
+	}
+
+	// An OAuth client is not a service account key
+	clientPath := filepath.Join(dir, "credentials.json")
+	if err := os.WriteFile(clientPath, []byte(`{"installed":{"client_id":"id","client_secret":"secret"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadServiceAccountKey(clientPath, DriveReadOnly); err == nil {
+		t.Error("ReadServiceAccountKey() expected error for an OAuth client")
+	}
+}
+
+func TestNewTokenSource_ServiceAccount(t *testing.T) {
+	saved := core.Cfg
+	t.Cleanup(func() { core.Cfg = saved })
+	core.Cfg = &core.Config{}
+	core.Cfg.Auth.ServiceAccountKey = writeServiceAccountKey(t, t.TempDir())
+	// No credentials.json or token.json is needed
+	core.Cfg.Paths.DataDir = t.TempDir()
+
+	if _, err := NewTokenSource(t.Context(), DriveReadOnly); err != nil {
+		t.Errorf("NewTokenSource() error = %v", err)
+	}
+}
+
+func TestNewTokenSource_OAuthRequiresToken(t *testing.T) {
+	saved := core.Cfg
+	t.Cleanup(func() { core.Cfg = saved })
+	core.Cfg = &core.Config{}
+	core.Cfg.Paths.DataDir = t.TempDir()
+
+	if _, err := NewTokenSource(t.Context(), DriveReadOnly); err == nil {
+		t.Error("NewTokenSource() expected error without token.json")
+	}
+}
+
+func TestOAuthConfigFromJSON_DeviceAuthURL(t *testing.T) {
+	config, err := OAuthConfigFromJSON([]byte(`{"installed":{"client_id":"id","client_secret":"secret","auth_uri":"https://accounts.google.com/o/oauth2/auth","token_uri":"https://oauth2.googleapis.com/token","redirect_uris":["http://localhost"]}}`), DriveReadOnly)
+	if err != nil {
+		t.Fatalf("OAuthConfigFromJSON() error = %v", err)
+	}
+	if config.Endpoint.DeviceAuthURL != google.Endpoint.DeviceAuthURL {
+		t.Errorf("DeviceAuthURL = %q, want %q", config.Endpoint.DeviceAuthURL, google.Endpoint.DeviceAuthURL)
+	}
+
+	if _, err := OAuthConfigFromJSON([]byte("invalid json"), DriveReadOnly); err == nil {
+		t.Error("OAuthConfigFromJSON() expected error for invalid JSON")
+	}
+}
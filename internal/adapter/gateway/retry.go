@@ -0,0 +1,164 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"google.golang.org/api/googleapi"
+)
+
+// RetryEvent describes a failed Drive API call that is about to be retried
+type RetryEvent struct {
+	Operation   string
+	Attempt     int
+	MaxAttempts int
+	Wait        time.Duration
+	Err         error
+}
+
+func (e RetryEvent) String() string {
+	return fmt.Sprintf("%s failed (%s), retrying in %s (attempt %d/%d)",
+		e.Operation, retryReason(e.Err), e.Wait.Round(100*time.Millisecond), e.Attempt+1, e.MaxAttempts)
+}
+
+// RetryNotifier is called before waiting for each retry.
+// It may be called from multiple goroutines at once.
+type RetryNotifier func(event RetryEvent)
+
+// RetryPolicy retries rate-limited and transient failures with jittered exponential backoff
+type RetryPolicy struct {
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+
+	sleep  func(time.Duration)
+	jitter func(time.Duration) time.Duration
+}
+
+// NewRetryPolicy creates a policy from the [retry] section of the config
+func NewRetryPolicy(cfg core.ConfigRetry) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:  cfg.GetMaxAttempts(),
+		InitialDelay: cfg.GetInitialDelay(),
+		MaxDelay:     cfg.GetMaxDelay(),
+	}
+}
+
+// Do runs fn until it succeeds, fails with an error that is not worth retrying,
+// or MaxAttempts is reached. The last error is returned.
+func (p RetryPolicy) Do(operation string, fn func() error, notify RetryNotifier) error {
+	sleep := p.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil {
+			return nil
+		}
+		retryable, retryAfter := isRetryable(err)
+		if !retryable || attempt >= p.MaxAttempts {
+			return err
+		}
+
+		wait := retryAfter
+		if wait <= 0 {
+			wait = p.backoff(attempt)
+		}
+		if notify != nil {
+			notify(RetryEvent{
+				Operation:   operation,
+				Attempt:     attempt,
+				MaxAttempts: p.MaxAttempts,
+				Wait:        wait,
+				Err:         err,
+			})
+		}
+		sleep(wait)
+	}
+}
+
+// backoff returns a random wait up to InitialDelay * 2^(attempt-1), capped at MaxDelay
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	ceiling := p.InitialDelay
+	for i := 1; i < attempt && ceiling < p.MaxDelay; i++ {
+		ceiling *= 2
+	}
+	if ceiling > p.MaxDelay {
+		ceiling = p.MaxDelay
+	}
+	if p.jitter != nil {
+		return p.jitter(ceiling)
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	// Full jitter spreads out concurrent workers that were limited at the same time
+	return time.Duration(rand.Int64N(int64(ceiling))) + 1
+}
+
+// isRetryable reports whether err is a rate limit or transient failure,
+// along with the wait requested by a Retry-After header, if any.
+func isRetryable(err error) (bool, time.Duration) {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		retryAfter := parseRetryAfter(apiErr.Header.Get("Retry-After"), time.Now())
+		switch {
+		case apiErr.Code == http.StatusTooManyRequests:
+			return true, retryAfter
+		case apiErr.Code >= http.StatusInternalServerError:
+			return true, retryAfter
+		case apiErr.Code == http.StatusForbidden && isRateLimitReason(apiErr):
+			return true, retryAfter
+		}
+		return false, 0
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true, 0
+	}
+	return false, 0
+}
+
+func isRateLimitReason(apiErr *googleapi.Error) bool {
+	for _, item := range apiErr.Errors {
+		if item.Reason == "rateLimitExceeded" || item.Reason == "userRateLimitExceeded" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRetryAfter reads a Retry-After value given either in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+func retryReason(err error) string {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		if apiErr.Code == http.StatusTooManyRequests || isRateLimitReason(apiErr) {
+			return "rate limited"
+		}
+		return fmt.Sprintf("HTTP %d", apiErr.Code)
+	}
+	return "timeout"
+}
@@ -0,0 +1,207 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"google.golang.org/api/googleapi"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func newTestRetryPolicy(maxAttempts int, waits *[]time.Duration) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:  maxAttempts,
+		InitialDelay: 100 * time.Millisecond,
+		MaxDelay:     time.Second,
+		sleep:        func(d time.Duration) { *waits = append(*waits, d) },
+		jitter:       func(ceiling time.Duration) time.Duration { return ceiling },
+	}
+}
+
+func TestNewRetryPolicy_Defaults(t *testing.T) {
+	p := NewRetryPolicy(core.ConfigRetry{})
+	if p.MaxAttempts != core.DefaultRetryMaxAttempts {
+		t.Errorf("MaxAttempts = %d, want %d", p.MaxAttempts, core.DefaultRetryMaxAttempts)
+	}
+	if p.InitialDelay != core.DefaultRetryInitialDelayMs*time.Millisecond {
+		t.Errorf("InitialDelay = %v", p.InitialDelay)
+	}
+	if p.MaxDelay != core.DefaultRetryMaxDelayMs*time.Millisecond {
+		t.Errorf("MaxDelay = %v", p.MaxDelay)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"too many requests", &googleapi.Error{Code: http.StatusTooManyRequests}, true},
+		{"server error", &googleapi.Error{Code: http.StatusBadGateway}, true},
+		{"rate limit exceeded", &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}}}, true},
+		{"user rate limit exceeded", &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "userRateLimitExceeded"}}}, true},
+		{"permission denied", &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "insufficientPermissions"}}}, false},
+		{"not found", &googleapi.Error{Code: http.StatusNotFound}, false},
+		{"timeout", timeoutError{}, true},
+		{"other error", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := isRetryable(tt.err); got != tt.want {
+				t.Errorf("isRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{"empty", "", 0},
+		{"seconds", "30", 30 * time.Second},
+		{"http date", now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{"past date", now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"invalid", "soon", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	var waits []time.Duration
+	p := newTestRetryPolicy(10, &waits)
+
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, w := range want {
+		if got := p.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestRetryPolicy_BackoffJitter(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for range 100 {
+		if got := p.backoff(3); got <= 0 || got > 400*time.Millisecond {
+			t.Fatalf("backoff(3) = %v, want within (0, 400ms]", got)
+		}
+	}
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	t.Run("succeeds after retries", func(t *testing.T) {
+		var waits []time.Duration
+		var events []RetryEvent
+		p := newTestRetryPolicy(5, &waits)
+
+		calls := 0
+		err := p.Do("list files", func() error {
+			calls++
+			if calls < 3 {
+				return &googleapi.Error{Code: http.StatusServiceUnavailable}
+			}
+			return nil
+		}, func(e RetryEvent) { events = append(events, e) })
+
+		if err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		if calls != 3 {
+			t.Errorf("calls = %d, want 3", calls)
+		}
+		if len(events) != 2 || events[0].Attempt != 1 || events[1].Attempt != 2 {
+			t.Errorf("events = %+v", events)
+		}
+		if len(waits) != 2 || waits[0] != 100*time.Millisecond || waits[1] != 200*time.Millisecond {
+			t.Errorf("waits = %v", waits)
+		}
+	})
+
+	t.Run("honors Retry-After", func(t *testing.T) {
+		var waits []time.Duration
+		p := newTestRetryPolicy(2, &waits)
+
+		header := http.Header{}
+		header.Set("Retry-After", "12")
+		calls := 0
+		_ = p.Do("download f1", func() error {
+			calls++
+			if calls == 1 {
+				return &googleapi.Error{Code: http.StatusTooManyRequests, Header: header}
+			}
+			return nil
+		}, nil)
+
+		if len(waits) != 1 || waits[0] != 12*time.Second {
+			t.Errorf("waits = %v, want [12s]", waits)
+		}
+	})
+
+	t.Run("stops at max attempts", func(t *testing.T) {
+		var waits []time.Duration
+		p := newTestRetryPolicy(3, &waits)
+
+		calls := 0
+		err := p.Do("update f1", func() error {
+			calls++
+			return &googleapi.Error{Code: http.StatusInternalServerError}
+		}, nil)
+
+		if err == nil {
+			t.Fatal("Do() expected error")
+		}
+		if calls != 3 {
+			t.Errorf("calls = %d, want 3", calls)
+		}
+		if len(waits) != 2 {
+			t.Errorf("waits = %v, want 2 waits", waits)
+		}
+	})
+
+	t.Run("does not retry permanent errors", func(t *testing.T) {
+		var waits []time.Duration
+		p := newTestRetryPolicy(5, &waits)
+
+		calls := 0
+		_ = p.Do("download f1", func() error {
+			calls++
+			return &googleapi.Error{Code: http.StatusNotFound}
+		}, nil)
+
+		if calls != 1 || len(waits) != 0 {
+			t.Errorf("calls = %d, waits = %v, want a single attempt", calls, waits)
+		}
+	})
+}
+
+func TestRetryEvent_String(t *testing.T) {
+	e := RetryEvent{
+		Operation:   "list files",
+		Attempt:     1,
+		MaxAttempts: 5,
+		Wait:        2 * time.Second,
+		Err:         &googleapi.Error{Code: http.StatusTooManyRequests},
+	}
+	want := "list files failed (rate limited), retrying in 2s (attempt 2/5)"
+	if got := e.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
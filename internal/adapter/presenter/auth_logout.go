@@ -0,0 +1,62 @@
+package presenter
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/c18t/nippo-cli/internal/adapter/presenter/view/tui"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+	"github.com/spf13/cobra"
+)
+
+// AuthLogoutJSONPresenterName is the DI name of the JSON AuthLogoutPresenter
+const AuthLogoutJSONPresenterName = "AuthLogoutJSONPresenter"
+
+type AuthLogoutPresenter interface {
+	Complete(output *port.AuthLogoutUseCaseOutputData)
+	Suspend(err error)
+}
+
+type authLogoutPresenter struct {
+	out io.Writer
+}
+
+// NewAuthLogoutPresenter creates the plain text presenter of `nippo auth logout`
+func NewAuthLogoutPresenter(_ do.Injector) (AuthLogoutPresenter, error) {
+	return &authLogoutPresenter{out: os.Stdout}, nil
+}
+
+func (p *authLogoutPresenter) Complete(output *port.AuthLogoutUseCaseOutputData) {
+	if output.LoggedOut {
+		_, _ = fmt.Fprintln(p.out, tui.SuccessStyle.Render("✓ Revoked the token and deleted "+output.Path))
+		return
+	}
+	_, _ = fmt.Fprintln(p.out, "Not authenticated: "+output.Path+" does not exist")
+}
+
+func (p *authLogoutPresenter) Suspend(err error) {
+	cobra.CheckErr(err)
+}
+
+type authLogoutJSONPresenter struct {
+	out io.Writer
+}
+
+// NewAuthLogoutJSONPresenter creates the JSON presenter of `nippo auth logout --json`
+func NewAuthLogoutJSONPresenter(_ do.Injector) (AuthLogoutPresenter, error) {
+	return &authLogoutJSONPresenter{out: os.Stdout}, nil
+}
+
+func (p *authLogoutJSONPresenter) Complete(output *port.AuthLogoutUseCaseOutputData) {
+	writeJSON(p.out, struct {
+		LoggedOut bool   `json:"logged_out"`
+		Path      string `json:"path"`
+	}{output.LoggedOut, output.Path})
+}
+
+func (p *authLogoutJSONPresenter) Suspend(err error) {
+	writeJSON(p.out, map[string]string{"error": err.Error()})
+	cobra.CheckErr(err)
+}
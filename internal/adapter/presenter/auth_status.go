@@ -0,0 +1,130 @@
+package presenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/c18t/nippo-cli/internal/adapter/presenter/view/tui"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+	"github.com/spf13/cobra"
+)
+
+// AuthStatusJSONPresenterName is the DI name of the JSON AuthStatusPresenter
+const AuthStatusJSONPresenterName = "AuthStatusJSONPresenter"
+
+type AuthStatusPresenter interface {
+	Show(output *port.AuthStatusUseCaseOutputData)
+	Suspend(err error)
+}
+
+type authStatusPresenter struct {
+	out io.Writer
+}
+
+// NewAuthStatusPresenter creates the plain text presenter of `nippo auth status`
+func NewAuthStatusPresenter(_ do.Injector) (AuthStatusPresenter, error) {
+	return &authStatusPresenter{out: os.Stdout}, nil
+}
+
+func (p *authStatusPresenter) Show(output *port.AuthStatusUseCaseOutputData) {
+	line := func(label, value string) {
+		_, _ = fmt.Fprintf(p.out, "  %-14s %s\n", label+":", value)
+	}
+
+	_, _ = fmt.Fprintln(p.out)
+	line("Auth mode", output.Mode)
+	if !output.Authenticated {
+		line("Status", tui.ErrorStyle.Render("not authenticated"))
+		line("Missing", output.Path)
+		_, _ = fmt.Fprintln(p.out)
+		_, _ = fmt.Fprintln(p.out, tui.DimStyle.Render("Run `nippo auth` to authenticate with Google Drive"))
+		return
+	}
+	if output.Problem != "" {
+		line("Status", tui.ErrorStyle.Render("invalid"))
+	} else {
+		line("Status", tui.SuccessStyle.Render("authenticated"))
+	}
+	line("Credentials", output.Path)
+	if output.Account != "" {
+		line("Account", output.Account)
+	}
+	if len(output.Scopes) > 0 {
+		line("Scopes", strings.Join(output.Scopes, " "))
+	}
+	if !output.Expiry.IsZero() {
+		line("Expires", output.Expiry.Local().Format("2006-01-02 15:04:05"))
+	}
+	line("Refresh token", yesNo(output.HasRefreshToken))
+	if output.Problem != "" {
+		_, _ = fmt.Fprintln(p.out)
+		_, _ = fmt.Fprintln(p.out, tui.ErrorStyle.Render(output.Problem))
+	}
+}
+
+func (p *authStatusPresenter) Suspend(err error) {
+	cobra.CheckErr(err)
+}
+
+type authStatusJSONPresenter struct {
+	out io.Writer
+}
+
+// NewAuthStatusJSONPresenter creates the JSON presenter of `nippo auth status --json`
+func NewAuthStatusJSONPresenter(_ do.Injector) (AuthStatusPresenter, error) {
+	return &authStatusJSONPresenter{out: os.Stdout}, nil
+}
+
+type authStatusJSON struct {
+	Mode            string     `json:"mode"`
+	Path            string     `json:"path"`
+	Authenticated   bool       `json:"authenticated"`
+	Account         string     `json:"account,omitempty"`
+	Scopes          []string   `json:"scopes"`
+	Expiry          *time.Time `json:"expiry,omitempty"`
+	HasRefreshToken bool       `json:"has_refresh_token"`
+	Problem         string     `json:"problem,omitempty"`
+}
+
+func (p *authStatusJSONPresenter) Show(output *port.AuthStatusUseCaseOutputData) {
+	status := authStatusJSON{
+		Mode:            output.Mode,
+		Path:            output.Path,
+		Authenticated:   output.Authenticated,
+		Account:         output.Account,
+		Scopes:          output.Scopes,
+		HasRefreshToken: output.HasRefreshToken,
+		Problem:         output.Problem,
+	}
+	if status.Scopes == nil {
+		status.Scopes = []string{}
+	}
+	if !output.Expiry.IsZero() {
+		status.Expiry = &output.Expiry
+	}
+	writeJSON(p.out, status)
+}
+
+func (p *authStatusJSONPresenter) Suspend(err error) {
+	writeJSON(p.out, map[string]string{"error": err.Error()})
+	cobra.CheckErr(err)
+}
+
+// writeJSON prints v as indented JSON
+func writeJSON(out io.Writer, v any) {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(v)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
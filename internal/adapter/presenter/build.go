@@ -13,6 +13,8 @@ import (
 const (
 	BuildIconSuccess = "✓"
 	BuildIconFailed  = "✗"
+	BuildIconDeleted = "-"
+	BuildIconSkipped = "!"
 )
 
 type BuildCommandPresenter interface {
@@ -24,7 +26,9 @@ type BuildCommandPresenter interface {
 	UpdateBuildProgress(filename string, fileId string)
 	StopBuildProgress()
 	IsBuildCancelled() bool
-	Summary(downloadedFiles []FileInfo, failedFiles []FileInfo, buildError error)
+	Warn(message string)
+	// Summary shows the synced files, the failed ones and the ones skipped for having no date
+	Summary(changedFiles []FileChange, failedFiles []FileInfo, skippedFiles []FileInfo, buildError error)
 }
 
 // FileInfo holds file name and ID for summary display
@@ -33,6 +37,13 @@ type FileInfo struct {
 	Id   string
 }
 
+// FileChange holds a synced file and how it changed for summary display
+type FileChange struct {
+	FileInfo
+	Kind    string // "added", "updated", "renamed" or "deleted"
+	OldName string // previous name of a renamed file
+}
+
 type buildCommandPresenter struct {
 	base             ConsolePresenter
 	buildProgressCtl *tui.BuildProgressController
@@ -84,16 +95,30 @@ func (p *buildCommandPresenter) IsBuildCancelled() bool {
 	return p.buildProgressCtl.IsCancelled()
 }
 
-func (p *buildCommandPresenter) Summary(downloadedFiles []FileInfo, failedFiles []FileInfo, buildError error) {
-	if len(downloadedFiles) > 0 {
+func (p *buildCommandPresenter) Warn(message string) {
+	p.buildProgressCtl.Warn(message)
+}
+
+func (p *buildCommandPresenter) Summary(changedFiles []FileChange, failedFiles []FileInfo, skippedFiles []FileInfo, buildError error) {
+	counts := map[string]int{}
+	if len(changedFiles) > 0 {
 		tui.Println("")
-		tui.Println(tui.SuccessStyle.Render("Downloaded files:"))
-		for _, f := range downloadedFiles {
-			tui.Println(fmt.Sprintf("  %s %s (%s)",
-				tui.SuccessStyle.Render(BuildIconSuccess),
-				f.Name,
-				tui.DimStyle.Render(f.Id),
-			))
+		tui.Println(tui.SuccessStyle.Render("Synced files:"))
+		for _, f := range changedFiles {
+			counts[f.Kind]++
+			icon := tui.SuccessStyle.Render(BuildIconSuccess)
+			if f.Kind == "deleted" {
+				icon = tui.DimStyle.Render(BuildIconDeleted)
+			}
+			name := f.Name
+			if f.OldName != "" {
+				name = fmt.Sprintf("%s → %s", f.OldName, f.Name)
+			}
+			line := fmt.Sprintf("  %s %s %s", icon, name, tui.DimStyle.Render("["+f.Kind+"]"))
+			if f.Id != "" {
+				line += fmt.Sprintf(" (%s)", tui.DimStyle.Render(f.Id))
+			}
+			tui.Println(line)
 		}
 	}
 
@@ -109,14 +134,29 @@ func (p *buildCommandPresenter) Summary(downloadedFiles []FileInfo, failedFiles
 		}
 	}
 
+	if len(skippedFiles) > 0 {
+		tui.Println("")
+		tui.Println(tui.WarningStyle.Render("Skipped files (no date in the name or front-matter):"))
+		for _, f := range skippedFiles {
+			tui.Println(fmt.Sprintf("  %s %s (%s)",
+				tui.WarningStyle.Render(BuildIconSkipped),
+				f.Name,
+				tui.DimStyle.Render(f.Id),
+			))
+		}
+	}
+
 	tui.Println("")
 
 	// Show build status
 	if buildError != nil {
 		tui.Println(fmt.Sprintf("Build failed: %s", tui.ErrorStyle.Render(buildError.Error())))
 	} else {
-		tui.Println(fmt.Sprintf("Build complete: %s downloaded, %s failed",
-			tui.SuccessStyle.Render(fmt.Sprintf("%d", len(downloadedFiles))),
+		tui.Println(fmt.Sprintf("Build complete: %s added, %s updated, %s renamed, %s deleted, %s failed",
+			tui.SuccessStyle.Render(fmt.Sprintf("%d", counts["added"])),
+			tui.SuccessStyle.Render(fmt.Sprintf("%d", counts["updated"])),
+			tui.SuccessStyle.Render(fmt.Sprintf("%d", counts["renamed"])),
+			tui.SuccessStyle.Render(fmt.Sprintf("%d", counts["deleted"])),
 			tui.ErrorStyle.Render(fmt.Sprintf("%d", len(failedFiles))),
 		))
 	}
@@ -14,6 +14,7 @@ const (
 	IconSuccess  = "✓"
 	IconFailed   = "✗"
 	IconNoChange = "○"
+	IconConflict = "!"
 )
 
 type FormatCommandPresenter interface {
@@ -25,7 +26,8 @@ type FormatCommandPresenter interface {
 	UpdateFormatProgress(output *port.FormatCommandUseCaseOutputData)
 	StopFormatProgress()
 	IsFormatCancelled() bool
-	Summary(successCount, noChangeCount, failedCount int, updatedFiles, failedFiles []FileInfo)
+	Warn(message string)
+	Summary(successCount, noChangeCount, failedCount, conflictCount int, updatedFiles, failedFiles, conflictFiles []FileInfo)
 }
 
 type formatCommandPresenter struct {
@@ -76,6 +78,10 @@ func (p *formatCommandPresenter) UpdateFormatProgress(output *port.FormatCommand
 	p.formatProgressCtl.UpdateFile(output.Filename, output.FileId, status, message)
 }
 
+func (p *formatCommandPresenter) Warn(message string) {
+	p.formatProgressCtl.Warn(message)
+}
+
 func (p *formatCommandPresenter) StopFormatProgress() {
 	p.formatProgressCtl.Stop()
 }
@@ -84,7 +90,7 @@ func (p *formatCommandPresenter) IsFormatCancelled() bool {
 	return p.formatProgressCtl.IsCancelled()
 }
 
-func (p *formatCommandPresenter) Summary(successCount, noChangeCount, failedCount int, updatedFiles, failedFiles []FileInfo) {
+func (p *formatCommandPresenter) Summary(successCount, noChangeCount, failedCount, conflictCount int, updatedFiles, failedFiles, conflictFiles []FileInfo) {
 	if len(updatedFiles) > 0 {
 		tui.Println("")
 		tui.Println(tui.SuccessStyle.Render("Updated files:"))
@@ -109,11 +115,24 @@ func (p *formatCommandPresenter) Summary(successCount, noChangeCount, failedCoun
 		}
 	}
 
+	if len(conflictFiles) > 0 {
+		tui.Println("")
+		tui.Println(tui.WarningStyle.Render("Changed remotely while formatting, run format again:"))
+		for _, f := range conflictFiles {
+			tui.Println(fmt.Sprintf("  %s %s (%s)",
+				tui.WarningStyle.Render(IconConflict),
+				f.Name,
+				tui.DimStyle.Render(f.Id),
+			))
+		}
+	}
+
 	tui.Println("")
-	tui.Println(fmt.Sprintf("Format complete: %s updated, %s unchanged, %s failed",
+	tui.Println(fmt.Sprintf("Format complete: %s updated, %s unchanged, %s failed, %s conflicted",
 		tui.SuccessStyle.Render(fmt.Sprintf("%d", successCount)),
 		tui.DimStyle.Render(fmt.Sprintf("%d", noChangeCount)),
 		tui.ErrorStyle.Render(fmt.Sprintf("%d", failedCount)),
+		tui.WarningStyle.Render(fmt.Sprintf("%d", conflictCount)),
 	))
 }
 
@@ -126,6 +145,8 @@ func convertStatus(status port.FormatFileStatus) tui.FormatFileStatus {
 		return tui.FormatFileStatusNoChange
 	case port.FormatFileStatusFailed:
 		return tui.FormatFileStatusFailed
+	case port.FormatFileStatusConflict:
+		return tui.FormatFileStatusConflict
 	default:
 		return tui.FormatFileStatusNoChange
 	}
@@ -0,0 +1,55 @@
+package presenter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/c18t/nippo-cli/internal/adapter/presenter/view/tui"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+	"github.com/spf13/cobra"
+)
+
+type FormatHistoryPresenter interface {
+	Show(output *port.FormatHistoryUseCaseOutputData)
+	Suspend(err error)
+}
+
+type formatHistoryPresenter struct {
+	out io.Writer
+}
+
+// NewFormatHistoryPresenter creates the presenter of `nippo format history`
+func NewFormatHistoryPresenter(_ do.Injector) (FormatHistoryPresenter, error) {
+	return &formatHistoryPresenter{out: os.Stdout}, nil
+}
+
+func (p *formatHistoryPresenter) Show(output *port.FormatHistoryUseCaseOutputData) {
+	if len(output.Runs) == 0 {
+		_, _ = fmt.Fprintln(p.out, "No format runs have changed any files yet.")
+		return
+	}
+	for i, run := range output.Runs {
+		if i > 0 {
+			_, _ = fmt.Fprintln(p.out)
+		}
+		header := fmt.Sprintf("%s  %s  %d file(s)", tui.InfoStyle.Render(run.Id), run.StartedAt.Local().Format("2006-01-02 15:04:05"), len(run.Files))
+		if !run.RevertedAt.IsZero() {
+			header += "  " + tui.WarningStyle.Render("reverted "+run.RevertedAt.Local().Format("2006-01-02 15:04:05"))
+		}
+		_, _ = fmt.Fprintln(p.out, header)
+		for _, file := range run.Files {
+			line := "  " + file.Name
+			if len(file.Reasons) > 0 {
+				line += " " + tui.DimStyle.Render("("+strings.Join(file.Reasons, ", ")+")")
+			}
+			_, _ = fmt.Fprintln(p.out, line)
+		}
+	}
+}
+
+func (p *formatHistoryPresenter) Suspend(err error) {
+	cobra.CheckErr(err)
+}
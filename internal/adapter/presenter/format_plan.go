@@ -0,0 +1,174 @@
+package presenter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/c18t/nippo-cli/internal/adapter/presenter/view/tui"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+	"github.com/spf13/cobra"
+)
+
+// FormatPlanJSONPresenterName is the DI name of the JSON FormatPlanPresenter
+const FormatPlanJSONPresenterName = "FormatPlanJSONPresenter"
+
+// FormatPlanPresenter shows what `nippo format --dry-run` would change
+type FormatPlanPresenter interface {
+	Progress(output *port.FormatCommandUseCaseOutputData)
+	StopProgress()
+	// Plan shows the planned change of a single file
+	Plan(output *port.FormatCommandUseCaseOutputData)
+	// Summary shows the totals of all planned changes
+	Summary(outputs []*port.FormatCommandUseCaseOutputData)
+	Suspend(err error)
+}
+
+type formatPlanPresenter struct {
+	base ConsolePresenter
+}
+
+// NewFormatPlanPresenter creates the presenter that prints a colored diff per file
+func NewFormatPlanPresenter(i do.Injector) (FormatPlanPresenter, error) {
+	base, err := do.Invoke[ConsolePresenter](i)
+	if err != nil {
+		return nil, err
+	}
+	return &formatPlanPresenter{base}, nil
+}
+
+func (p *formatPlanPresenter) Progress(output *port.FormatCommandUseCaseOutputData) {
+	p.base.Progress(output.Message)
+}
+
+func (p *formatPlanPresenter) StopProgress() {
+	p.base.StopProgress()
+}
+
+func (p *formatPlanPresenter) Plan(output *port.FormatCommandUseCaseOutputData) {
+	switch output.Status {
+	case port.FormatFileStatusSuccess:
+		tui.Println("")
+		tui.Println(fmt.Sprintf("%s %s (%s)", tui.SuccessStyle.Render(IconSuccess), output.Filename, tui.DimStyle.Render(output.Message)))
+		for _, line := range strings.SplitAfter(output.Diff, "\n") {
+			tui.Print(colorDiffLine(line))
+		}
+	case port.FormatFileStatusFailed:
+		tui.Println("")
+		tui.Println(fmt.Sprintf("%s %s (%s)", tui.ErrorStyle.Render(IconFailed), output.Filename, tui.ErrorStyle.Render(output.Message)))
+	}
+}
+
+// colorDiffLine colors a line of a unified diff by its prefix
+func colorDiffLine(line string) string {
+	text := strings.TrimSuffix(line, "\n")
+	if text == "" {
+		return line
+	}
+	var styled string
+	switch {
+	case strings.HasPrefix(text, "---"), strings.HasPrefix(text, "+++"):
+		styled = tui.DimStyle.Render(text)
+	case strings.HasPrefix(text, "@@"):
+		styled = tui.InfoStyle.Render(text)
+	case strings.HasPrefix(text, "+"):
+		styled = tui.SuccessStyle.Render(text)
+	case strings.HasPrefix(text, "-"):
+		styled = tui.ErrorStyle.Render(text)
+	default:
+		styled = text
+	}
+	return styled + line[len(text):]
+}
+
+func (p *formatPlanPresenter) Summary(outputs []*port.FormatCommandUseCaseOutputData) {
+	counts := countFormatStatuses(outputs)
+	tui.Println("")
+	tui.Println(fmt.Sprintf("Dry run: %s would be updated, %s unchanged, %s failed. Nothing was uploaded.",
+		tui.SuccessStyle.Render(fmt.Sprintf("%d", counts[port.FormatFileStatusSuccess])),
+		tui.DimStyle.Render(fmt.Sprintf("%d", counts[port.FormatFileStatusNoChange])),
+		tui.ErrorStyle.Render(fmt.Sprintf("%d", counts[port.FormatFileStatusFailed])),
+	))
+}
+
+func (p *formatPlanPresenter) Suspend(err error) {
+	p.base.Suspend(err)
+}
+
+func countFormatStatuses(outputs []*port.FormatCommandUseCaseOutputData) map[port.FormatFileStatus]int {
+	counts := map[port.FormatFileStatus]int{}
+	for _, output := range outputs {
+		counts[output.Status]++
+	}
+	return counts
+}
+
+type formatPlanJSONPresenter struct {
+	out io.Writer
+}
+
+// NewFormatPlanJSONPresenter creates the presenter of `nippo format --dry-run --json`
+func NewFormatPlanJSONPresenter(_ do.Injector) (FormatPlanPresenter, error) {
+	return &formatPlanJSONPresenter{out: os.Stdout}, nil
+}
+
+type formatPlanJSON struct {
+	Files   []formatPlanFileJSON  `json:"files"`
+	Summary formatPlanSummaryJSON `json:"summary"`
+}
+
+type formatPlanFileJSON struct {
+	Name    string   `json:"name"`
+	Id      string   `json:"id"`
+	Status  string   `json:"status"`
+	Reasons []string `json:"reasons,omitempty"`
+	Diff    string   `json:"diff,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+type formatPlanSummaryJSON struct {
+	Update    int `json:"update"`
+	Unchanged int `json:"unchanged"`
+	Failed    int `json:"failed"`
+}
+
+// Progress prints nothing, as only the JSON goes to stdout
+func (p *formatPlanJSONPresenter) Progress(output *port.FormatCommandUseCaseOutputData) {}
+
+func (p *formatPlanJSONPresenter) StopProgress() {}
+
+// Plan prints nothing, as Summary prints all planned changes as one document
+func (p *formatPlanJSONPresenter) Plan(output *port.FormatCommandUseCaseOutputData) {}
+
+func (p *formatPlanJSONPresenter) Summary(outputs []*port.FormatCommandUseCaseOutputData) {
+	plan := formatPlanJSON{Files: []formatPlanFileJSON{}}
+	for _, output := range outputs {
+		file := formatPlanFileJSON{Name: output.Filename, Id: output.FileId}
+		switch output.Status {
+		case port.FormatFileStatusSuccess:
+			file.Status = "update"
+			file.Reasons = output.Reasons
+			file.Diff = output.Diff
+		case port.FormatFileStatusFailed:
+			file.Status = "failed"
+			file.Error = output.Message
+		default:
+			file.Status = "unchanged"
+		}
+		plan.Files = append(plan.Files, file)
+	}
+	counts := countFormatStatuses(outputs)
+	plan.Summary = formatPlanSummaryJSON{
+		Update:    counts[port.FormatFileStatusSuccess],
+		Unchanged: counts[port.FormatFileStatusNoChange],
+		Failed:    counts[port.FormatFileStatusFailed],
+	}
+	writeJSON(p.out, plan)
+}
+
+func (p *formatPlanJSONPresenter) Suspend(err error) {
+	writeJSON(p.out, map[string]string{"error": err.Error()})
+	cobra.CheckErr(err)
+}
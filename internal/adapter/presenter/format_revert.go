@@ -0,0 +1,59 @@
+package presenter
+
+import (
+	"fmt"
+
+	"github.com/c18t/nippo-cli/internal/adapter/presenter/view/tui"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+)
+
+type FormatRevertPresenter interface {
+	Progress(output *port.FormatRevertUseCaseOutputData)
+	StopProgress()
+	Complete(output *port.FormatRevertUseCaseOutputData)
+	Suspend(err error)
+}
+
+type formatRevertPresenter struct {
+	base ConsolePresenter
+}
+
+func NewFormatRevertPresenter(i do.Injector) (FormatRevertPresenter, error) {
+	base, err := do.Invoke[ConsolePresenter](i)
+	if err != nil {
+		return nil, err
+	}
+	return &formatRevertPresenter{base}, nil
+}
+
+func (p *formatRevertPresenter) Progress(output *port.FormatRevertUseCaseOutputData) {
+	p.base.Progress(output.Message)
+}
+
+func (p *formatRevertPresenter) StopProgress() {
+	p.base.StopProgress()
+}
+
+func (p *formatRevertPresenter) Complete(output *port.FormatRevertUseCaseOutputData) {
+	p.base.StopProgress()
+	for _, file := range output.Files {
+		action := fmt.Sprintf("%-8s", file.Action)
+		switch file.Action {
+		case port.FormatRevertSkipped:
+			action = tui.WarningStyle.Render(action)
+		case port.FormatRevertFailed:
+			action = tui.ErrorStyle.Render(action)
+		}
+		line := "  " + action + " " + file.Name
+		if file.Detail != "" {
+			line += " " + tui.DimStyle.Render("("+file.Detail+")")
+		}
+		tui.Println(line)
+	}
+	p.base.Complete(output.Message)
+}
+
+func (p *formatRevertPresenter) Suspend(err error) {
+	p.base.Suspend(err)
+}
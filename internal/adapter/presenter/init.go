@@ -54,6 +54,10 @@ func (p *initCommandPresenter) Prompt(ch chan<- interface{}, output *port.InitSe
 			core.Cfg.Project.Branch,
 			core.Cfg.Project.TemplatePath,
 			core.Cfg.Project.AssetPath,
+			core.Cfg.Site.Title,
+			core.Cfg.Site.Author,
+			core.Cfg.Site.Description,
+			core.Cfg.Site.Language,
 		}
 	}
 
@@ -73,6 +77,14 @@ func (p *initCommandPresenter) Prompt(ch chan<- interface{}, output *port.InitSe
 		vm.Sequence = view.ConfigureProjectSequence_SelectTemplatePath
 	case port.InitSettingProjectAssetPath:
 		vm.Sequence = view.ConfigureProjectSequence_SelectAssetPath
+	case port.InitSettingSiteTitle:
+		vm.Sequence = view.ConfigureProjectSequence_InputSiteTitle
+	case port.InitSettingSiteAuthor:
+		vm.Sequence = view.ConfigureProjectSequence_InputSiteAuthor
+	case port.InitSettingSiteDescription:
+		vm.Sequence = view.ConfigureProjectSequence_InputSiteDescription
+	case port.InitSettingSiteLanguage:
+		vm.Sequence = view.ConfigureProjectSequence_InputSiteLanguage
 	case port.InitSettingConfirmGitWarning:
 		vm.Sequence = view.ConfigureProjectSequence_ConfirmGitWarning
 		vm.IsUnderGit = true
@@ -0,0 +1,45 @@
+package presenter
+
+import (
+	"reflect"
+
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+)
+
+type CreateCommandPresenter interface {
+	Progress(output *port.CreateCommandUseCaseOutputData)
+	StopProgress()
+	Complete(output *port.CreateCommandUseCaseOutputData)
+	Suspend(err error)
+}
+
+type createCommandPresenter struct {
+	base ConsolePresenter
+}
+
+func NewCreateCommandPresenter(i do.Injector) (CreateCommandPresenter, error) {
+	base, err := do.Invoke[ConsolePresenter](i)
+	if err != nil {
+		return nil, err
+	}
+	return &createCommandPresenter{base}, nil
+}
+
+func (p *createCommandPresenter) Progress(output *port.CreateCommandUseCaseOutputData) {
+	v := reflect.Indirect(reflect.ValueOf(output)).FieldByName("Message")
+	p.base.Progress(v.String())
+}
+
+func (p *createCommandPresenter) StopProgress() {
+	p.base.StopProgress()
+}
+
+func (p *createCommandPresenter) Complete(output *port.CreateCommandUseCaseOutputData) {
+	v := reflect.Indirect(reflect.ValueOf(output)).FieldByName("Message")
+	p.base.Complete(v.String())
+}
+
+func (p *createCommandPresenter) Suspend(err error) {
+	p.base.Suspend(err)
+}
@@ -1,7 +1,12 @@
 package presenter
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/c18t/nippo-cli/internal/adapter/presenter/view"
 	"github.com/c18t/nippo-cli/internal/core"
@@ -147,6 +152,82 @@ func TestCleanCommandPresenter_Complete(t *testing.T) {
 	}
 }
 
+// Tests for CreateCommandPresenter
+
+func TestCreateCommandPresenter_Complete(t *testing.T) {
+	mockBase := &mockConsolePresenter{}
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (ConsolePresenter, error) {
+		return mockBase, nil
+	})
+
+	p, err := NewCreateCommandPresenter(injector)
+	if err != nil {
+		t.Fatalf("NewCreateCommandPresenter() error = %v", err)
+	}
+	p.Progress(&port.CreateCommandUseCaseOutputData{Message: "Creating 2024-01-15.md..."})
+	p.Complete(&port.CreateCommandUseCaseOutputData{Message: "Created 2024-01-15.md"})
+
+	if mockBase.progressMessage != "Creating 2024-01-15.md..." {
+		t.Errorf("Progress message = %q", mockBase.progressMessage)
+	}
+	if mockBase.completeMessage != "Created 2024-01-15.md" {
+		t.Errorf("Complete message = %q", mockBase.completeMessage)
+	}
+}
+
+// Tests for PullCommandPresenter and PushCommandPresenter
+
+func TestPullCommandPresenter_Complete(t *testing.T) {
+	mockBase := &mockConsolePresenter{}
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (ConsolePresenter, error) {
+		return mockBase, nil
+	})
+
+	p, err := NewPullCommandPresenter(injector)
+	if err != nil {
+		t.Fatalf("NewPullCommandPresenter() error = %v", err)
+	}
+	p.Progress(&port.PullCommandUseCaseOutputData{Message: "Pulling..."})
+	p.Complete(&port.PullCommandUseCaseOutputData{
+		Message: "Pulled 1 file",
+		Files:   []port.WorkdirFileResult{{Name: "2024-01-15.md", Action: port.WorkdirFileDownloaded}},
+	})
+
+	if mockBase.progressMessage != "Pulling..." {
+		t.Errorf("Progress message = %q", mockBase.progressMessage)
+	}
+	if mockBase.completeMessage != "Pulled 1 file" {
+		t.Errorf("Complete message = %q", mockBase.completeMessage)
+	}
+}
+
+func TestPushCommandPresenter_Complete(t *testing.T) {
+	mockBase := &mockConsolePresenter{}
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (ConsolePresenter, error) {
+		return mockBase, nil
+	})
+
+	p, err := NewPushCommandPresenter(injector)
+	if err != nil {
+		t.Fatalf("NewPushCommandPresenter() error = %v", err)
+	}
+	p.Progress(&port.PushCommandUseCaseOutputData{Message: "Pushing..."})
+	p.Complete(&port.PushCommandUseCaseOutputData{
+		Message: "Pushed 1 file",
+		Files:   []port.WorkdirFileResult{{Name: "2024-01-15.md", Action: port.WorkdirFileBoth, Detail: "merge 2024-01-15.remote.md"}},
+	})
+
+	if mockBase.progressMessage != "Pushing..." {
+		t.Errorf("Progress message = %q", mockBase.progressMessage)
+	}
+	if mockBase.completeMessage != "Pushed 1 file" {
+		t.Errorf("Complete message = %q", mockBase.completeMessage)
+	}
+}
+
 // Tests for DeployCommandPresenter
 
 func TestNewDeployCommandPresenter(t *testing.T) {
@@ -246,6 +327,82 @@ func TestAuthPresenter_IsCancelled(t *testing.T) {
 	}
 }
 
+// Tests for AuthStatusPresenter and AuthLogoutPresenter
+
+func TestAuthStatusPresenter_Show(t *testing.T) {
+	var out bytes.Buffer
+	p := &authStatusPresenter{out: &out}
+
+	p.Show(&port.AuthStatusUseCaseOutputData{
+		Mode:            "OAuth token",
+		Path:            "/data/token.json",
+		Authenticated:   true,
+		Account:         "nippo@example.com",
+		Scopes:          []string{"https://www.googleapis.com/auth/drive"},
+		Expiry:          time.Date(2024, 1, 15, 21, 0, 0, 0, time.UTC),
+		HasRefreshToken: true,
+	})
+	for _, want := range []string{"OAuth token", "nippo@example.com", "https://www.googleapis.com/auth/drive", "Refresh token: yes"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Show() output does not contain %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	p.Show(&port.AuthStatusUseCaseOutputData{Mode: "OAuth token", Path: "/data/token.json"})
+	if !strings.Contains(out.String(), "nippo auth") {
+		t.Errorf("Show() should suggest `nippo auth` when not authenticated:\n%s", out.String())
+	}
+}
+
+func TestAuthStatusJSONPresenter_Show(t *testing.T) {
+	var out bytes.Buffer
+	p := &authStatusJSONPresenter{out: &out}
+
+	expiry := time.Date(2024, 1, 15, 21, 0, 0, 0, time.UTC)
+	p.Show(&port.AuthStatusUseCaseOutputData{
+		Mode:            "OAuth token",
+		Path:            "/data/token.json",
+		Authenticated:   true,
+		Account:         "nippo@example.com",
+		Expiry:          expiry,
+		HasRefreshToken: true,
+	})
+
+	var got map[string]any
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	if got["account"] != "nippo@example.com" || got["authenticated"] != true || got["has_refresh_token"] != true {
+		t.Errorf("JSON = %v", got)
+	}
+	if got["expiry"] != expiry.Format(time.RFC3339) {
+		t.Errorf("expiry = %v, want RFC 3339", got["expiry"])
+	}
+	if scopes, ok := got["scopes"].([]any); !ok || len(scopes) != 0 {
+		t.Errorf("scopes = %v, want an empty list", got["scopes"])
+	}
+	if _, ok := got["problem"]; ok {
+		t.Error("problem should be omitted when there is none")
+	}
+}
+
+func TestAuthLogoutPresenters_Complete(t *testing.T) {
+	var out bytes.Buffer
+	text := &authLogoutPresenter{out: &out}
+	text.Complete(&port.AuthLogoutUseCaseOutputData{LoggedOut: true, Path: "/data/token.json"})
+	if !strings.Contains(out.String(), "/data/token.json") {
+		t.Errorf("Complete() output = %q", out.String())
+	}
+
+	out.Reset()
+	jsonPresenter := &authLogoutJSONPresenter{out: &out}
+	jsonPresenter.Complete(&port.AuthLogoutUseCaseOutputData{Path: "/data/token.json"})
+	if strings.TrimSpace(out.String()) != "{\n  \"logged_out\": false,\n  \"path\": \"/data/token.json\"\n}" {
+		t.Errorf("Complete() JSON = %q", out.String())
+	}
+}
+
 // Tests for DoctorPresenter
 
 func TestNewDoctorPresenter(t *testing.T) {
@@ -302,11 +459,16 @@ func TestBuildCommandPresenter_Summary(t *testing.T) {
 
 	p, _ := NewBuildCommandPresenter(injector)
 
-	downloaded := []FileInfo{{Name: "file1.md", Id: "123"}}
+	changed := []FileChange{
+		{FileInfo: FileInfo{Name: "file1.md", Id: "123"}, Kind: "added"},
+		{FileInfo: FileInfo{Name: "file3.md", Id: "789"}, Kind: "renamed", OldName: "file2.md"},
+		{FileInfo: FileInfo{Name: "file4.md", Id: "000"}, Kind: "deleted"},
+	}
 	failed := []FileInfo{}
+	skipped := []FileInfo{{Name: "notes.md", Id: "999"}}
 
 	// Just verify it doesn't panic
-	p.Summary(downloaded, failed, nil)
+	p.Summary(changed, failed, skipped, nil)
 }
 
 func TestBuildCommandPresenter_SummaryWithError(t *testing.T) {
@@ -318,11 +480,11 @@ func TestBuildCommandPresenter_SummaryWithError(t *testing.T) {
 
 	p, _ := NewBuildCommandPresenter(injector)
 
-	downloaded := []FileInfo{}
+	changed := []FileChange{}
 	failed := []FileInfo{{Name: "fail.md", Id: "456"}}
 
 	// Just verify it doesn't panic with error
-	p.Summary(downloaded, failed, nil)
+	p.Summary(changed, failed, nil, nil)
 }
 
 // Tests for FormatCommandPresenter
@@ -356,7 +518,7 @@ func TestFormatCommandPresenter_Summary(t *testing.T) {
 	failed := []FileInfo{}
 
 	// Just verify it doesn't panic
-	p.Summary(1, 0, 0, updated, failed)
+	p.Summary(1, 0, 0, 1, updated, failed, []FileInfo{{Name: "file2.md", Id: "456"}})
 }
 
 func TestConvertStatus(t *testing.T) {
@@ -367,6 +529,7 @@ func TestConvertStatus(t *testing.T) {
 		{port.FormatFileStatusSuccess, 0},
 		{port.FormatFileStatusNoChange, 1},
 		{port.FormatFileStatusFailed, 2},
+		{port.FormatFileStatusConflict, 3},
 	}
 
 	for _, tt := range tests {
@@ -377,6 +540,88 @@ func TestConvertStatus(t *testing.T) {
 	}
 }
 
+// Tests for FormatPlanPresenter
+
+func TestColorDiffLine(t *testing.T) {
+	for _, line := range []string{"--- a/2024-01-15.md\n", "@@ -1,3 +1,4 @@\n", "+created: now\n", "-updated: now\n", " # title\n", "\n"} {
+		got := colorDiffLine(line)
+		if !strings.HasSuffix(got, "\n") || !strings.Contains(got, strings.TrimSpace(line)) {
+			t.Errorf("colorDiffLine(%q) = %q", line, got)
+		}
+	}
+	if got := colorDiffLine(" # title\n"); got != " # title\n" {
+		t.Errorf("colorDiffLine() should leave context lines as is, got %q", got)
+	}
+}
+
+func TestFormatPlanJSONPresenter_Summary(t *testing.T) {
+	var out bytes.Buffer
+	p := &formatPlanJSONPresenter{out: &out}
+
+	p.Summary([]*port.FormatCommandUseCaseOutputData{
+		{Filename: "2024-01-15.md", FileId: "file1", Status: port.FormatFileStatusSuccess, Message: "Added front-matter, Sorted tags", Reasons: []string{"Added front-matter", "Sorted tags"}, Diff: "--- a/2024-01-15.md\n"},
+		{Filename: "2024-01-16.md", FileId: "file2", Status: port.FormatFileStatusNoChange, Message: "No changes needed"},
+		{Filename: "2024-01-17.md", FileId: "file3", Status: port.FormatFileStatusFailed, Message: "malformed front-matter"},
+	})
+
+	var got struct {
+		Files   []map[string]any `json:"files"`
+		Summary map[string]int   `json:"summary"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	if len(got.Files) != 3 {
+		t.Fatalf("files = %v", got.Files)
+	}
+	if f := got.Files[0]; f["status"] != "update" || fmt.Sprint(f["reasons"]) != "[Added front-matter Sorted tags]" || f["diff"] != "--- a/2024-01-15.md\n" {
+		t.Errorf("files[0] = %v", f)
+	}
+	if f := got.Files[1]; f["status"] != "unchanged" || f["diff"] != nil {
+		t.Errorf("files[1] = %v", f)
+	}
+	if f := got.Files[2]; f["status"] != "failed" || f["error"] != "malformed front-matter" {
+		t.Errorf("files[2] = %v", f)
+	}
+	if got.Summary["update"] != 1 || got.Summary["unchanged"] != 1 || got.Summary["failed"] != 1 {
+		t.Errorf("summary = %v", got.Summary)
+	}
+}
+
+func TestFormatHistoryPresenter_Show(t *testing.T) {
+	var out bytes.Buffer
+	p := &formatHistoryPresenter{out: &out}
+
+	p.Show(&port.FormatHistoryUseCaseOutputData{})
+	if !strings.Contains(out.String(), "No format runs") {
+		t.Errorf("Show() = %q, want a note without runs", out.String())
+	}
+
+	out.Reset()
+	p.Show(&port.FormatHistoryUseCaseOutputData{Runs: []port.FormatHistoryRun{
+		{
+			Id:         "20240116-100000",
+			StartedAt:  time.Date(2024, 1, 16, 10, 0, 0, 0, time.UTC),
+			RevertedAt: time.Date(2024, 1, 17, 10, 0, 0, 0, time.UTC),
+			Files:      []port.FormatHistoryFile{{Name: "2024-01-16.md", Id: "file2"}},
+		},
+		{
+			Id:        "20240115-100000",
+			StartedAt: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+			Files:     []port.FormatHistoryFile{{Name: "2024-01-15.md", Id: "file1", Reasons: []string{"Added front-matter", "Sorted tags"}}},
+		},
+	}})
+	got := out.String()
+	for _, want := range []string{"20240116-100000", "reverted", "2024-01-16.md", "20240115-100000", "2024-01-15.md (Added front-matter, Sorted tags)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Show() = %q, want %q", got, want)
+		}
+	}
+	if strings.Count(got, "reverted") != 1 {
+		t.Errorf("Show() = %q, want only the first run reverted", got)
+	}
+}
+
 // Tests for InitSettingPresenter
 
 func TestNewInitSettingPresenter(t *testing.T) {
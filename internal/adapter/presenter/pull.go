@@ -0,0 +1,63 @@
+package presenter
+
+import (
+	"fmt"
+
+	"github.com/c18t/nippo-cli/internal/adapter/presenter/view/tui"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+)
+
+type PullCommandPresenter interface {
+	Progress(output *port.PullCommandUseCaseOutputData)
+	StopProgress()
+	Complete(output *port.PullCommandUseCaseOutputData)
+	Suspend(err error)
+}
+
+type pullCommandPresenter struct {
+	base ConsolePresenter
+}
+
+func NewPullCommandPresenter(i do.Injector) (PullCommandPresenter, error) {
+	base, err := do.Invoke[ConsolePresenter](i)
+	if err != nil {
+		return nil, err
+	}
+	return &pullCommandPresenter{base}, nil
+}
+
+func (p *pullCommandPresenter) Progress(output *port.PullCommandUseCaseOutputData) {
+	p.base.Progress(output.Message)
+}
+
+func (p *pullCommandPresenter) StopProgress() {
+	p.base.StopProgress()
+}
+
+func (p *pullCommandPresenter) Complete(output *port.PullCommandUseCaseOutputData) {
+	p.base.StopProgress()
+	printWorkdirFiles(output.Files)
+	p.base.Complete(output.Message)
+}
+
+func (p *pullCommandPresenter) Suspend(err error) {
+	p.base.Suspend(err)
+}
+
+// printWorkdirFiles prints what pull or push did with each file,
+// highlighting the files that need attention
+func printWorkdirFiles(files []port.WorkdirFileResult) {
+	for _, file := range files {
+		action := fmt.Sprintf("%-10s", file.Action)
+		switch file.Action {
+		case port.WorkdirFileKept, port.WorkdirFileBoth, port.WorkdirFileSkipped:
+			action = tui.WarningStyle.Render(action)
+		}
+		line := "  " + action + " " + file.Name
+		if file.Detail != "" {
+			line += " " + tui.DimStyle.Render("("+file.Detail+")")
+		}
+		tui.Println(line)
+	}
+}
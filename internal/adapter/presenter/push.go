@@ -0,0 +1,68 @@
+package presenter
+
+import (
+	"fmt"
+
+	"github.com/c18t/nippo-cli/internal/adapter/presenter/view/tui"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+)
+
+type PushCommandPresenter interface {
+	Progress(output *port.PushCommandUseCaseOutputData)
+	StopProgress()
+	Complete(output *port.PushCommandUseCaseOutputData)
+	Suspend(err error)
+	// ResolveConflict asks how to resolve the conflict of the file name
+	ResolveConflict(name string) (port.ConflictResolution, error)
+}
+
+type pushCommandPresenter struct {
+	base ConsolePresenter
+}
+
+func NewPushCommandPresenter(i do.Injector) (PushCommandPresenter, error) {
+	base, err := do.Invoke[ConsolePresenter](i)
+	if err != nil {
+		return nil, err
+	}
+	return &pushCommandPresenter{base}, nil
+}
+
+func (p *pushCommandPresenter) Progress(output *port.PushCommandUseCaseOutputData) {
+	p.base.Progress(output.Message)
+}
+
+func (p *pushCommandPresenter) StopProgress() {
+	p.base.StopProgress()
+}
+
+func (p *pushCommandPresenter) Complete(output *port.PushCommandUseCaseOutputData) {
+	p.base.StopProgress()
+	printWorkdirFiles(output.Files)
+	p.base.Complete(output.Message)
+}
+
+func (p *pushCommandPresenter) Suspend(err error) {
+	p.base.Suspend(err)
+}
+
+func (p *pushCommandPresenter) ResolveConflict(name string) (port.ConflictResolution, error) {
+	p.base.StopProgress()
+	key, err := tui.RunChoice(fmt.Sprintf("%s was changed both locally and remotely.", name), []tui.Choice{
+		{Key: "l", Label: "keep local"},
+		{Key: "r", Label: "keep remote"},
+		{Key: "b", Label: "write both"},
+	})
+	if err != nil {
+		return "", err
+	}
+	switch key {
+	case "l":
+		return port.ConflictKeepLocal, nil
+	case "r":
+		return port.ConflictKeepRemote, nil
+	default:
+		return port.ConflictWriteBoth, nil
+	}
+}
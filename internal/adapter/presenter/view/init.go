@@ -45,6 +45,10 @@ const (
 	ConfigureProjectSequence_SelectTemplatePath
 	ConfigureProjectSequence_SelectAssetPath
 	ConfigureProjectSequence_ConfirmGitWarning
+	ConfigureProjectSequence_InputSiteTitle
+	ConfigureProjectSequence_InputSiteAuthor
+	ConfigureProjectSequence_InputSiteDescription
+	ConfigureProjectSequence_InputSiteLanguage
 )
 
 type ConfigureProjectViewModel struct {
@@ -102,6 +106,14 @@ func (v *configureProjectView) Update(vm *ConfigureProjectViewModel) {
 		vm.Input <- v.cachedValues[4]
 	case ConfigureProjectSequence_SelectAssetPath:
 		vm.Input <- v.cachedValues[5]
+	case ConfigureProjectSequence_InputSiteTitle:
+		vm.Input <- v.cachedValues[6]
+	case ConfigureProjectSequence_InputSiteAuthor:
+		vm.Input <- v.cachedValues[7]
+	case ConfigureProjectSequence_InputSiteDescription:
+		vm.Input <- v.cachedValues[8]
+	case ConfigureProjectSequence_InputSiteLanguage:
+		vm.Input <- v.cachedValues[9]
 	case ConfigureProjectSequence_ConfirmGitWarning:
 		vm.Input <- v.cachedConfirmations[1]
 	}
@@ -133,13 +145,15 @@ func (v *configureProjectView) runWizard(vm *ConfigureProjectViewModel) {
 		"main",
 		"/templates",
 		"/assets",
+		core.DefaultSiteTitle,
+		"your name",
+		core.DefaultSiteDescription,
+		core.DefaultSiteLanguage,
 	}
 
 	// Get existing config values (will be pre-filled if non-empty)
-	existingValues := make([]string, 6)
-	if len(vm.DefaultValues) >= 6 {
-		copy(existingValues, vm.DefaultValues)
-	}
+	existingValues := make([]string, len(placeholders))
+	copy(existingValues, vm.DefaultValues)
 
 	steps := []tui.WizardStep{
 		{
@@ -172,6 +186,26 @@ func (v *configureProjectView) runWizard(vm *ConfigureProjectViewModel) {
 			Placeholder:  placeholders[5],
 			InitialValue: existingValues[5],
 		},
+		{
+			Label:        "input site title",
+			Placeholder:  placeholders[6],
+			InitialValue: existingValues[6],
+		},
+		{
+			Label:        "input author name",
+			Placeholder:  placeholders[7],
+			InitialValue: existingValues[7],
+		},
+		{
+			Label:        "input site description",
+			Placeholder:  placeholders[8],
+			InitialValue: existingValues[8],
+		},
+		{
+			Label:        "input site language",
+			Placeholder:  placeholders[9],
+			InitialValue: existingValues[9],
+		},
 	}
 
 	values, err := tui.RunWizard(steps)
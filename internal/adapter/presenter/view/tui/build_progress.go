@@ -132,6 +132,18 @@ func (c *BuildProgressController) Stop() {
 	}
 }
 
+// Warn prints a warning above the progress view, or directly when it is not running
+func (c *BuildProgressController) Warn(message string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.running || c.program == nil {
+		PrintWarning(message)
+		return
+	}
+	c.program.Println(WarningStyle.Render(message))
+}
+
 func (c *BuildProgressController) IsCancelled() bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -21,6 +21,7 @@ const (
 	FormatFileStatusSuccess  FormatFileStatus = iota // Successfully updated
 	FormatFileStatusNoChange                         // No changes needed
 	FormatFileStatusFailed                           // Failed to process/upload
+	FormatFileStatusConflict                         // Changed remotely while processing
 )
 
 type FormatProgressController struct {
@@ -149,6 +150,18 @@ func (c *FormatProgressController) Stop() {
 	}
 }
 
+// Warn prints a warning above the progress view, or directly when it is not running
+func (c *FormatProgressController) Warn(message string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.running || c.program == nil {
+		PrintWarning(message)
+		return
+	}
+	c.program.Println(WarningStyle.Render(message))
+}
+
 func (c *FormatProgressController) IsCancelled() bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -256,8 +269,8 @@ func (m formatProgressModel) View() string {
 		for _, file := range m.processedFiles {
 			icon, style := getFormatStatusStyle(file.status)
 			line := fmt.Sprintf("    %s %s (%s)", style.Render(icon), DimStyle.Render(file.name), DimStyle.Render(file.id))
-			if file.status == FormatFileStatusFailed && file.message != "" {
-				line += fmt.Sprintf(" - %s", ErrorStyle.Render(file.message))
+			if (file.status == FormatFileStatusFailed || file.status == FormatFileStatusConflict) && file.message != "" {
+				line += fmt.Sprintf(" - %s", style.Render(file.message))
 			}
 			b.WriteString(line + "\n")
 		}
@@ -274,6 +287,8 @@ func getFormatStatusStyle(status FormatFileStatus) (string, lipgloss.Style) {
 		return "○", DimStyle
 	case FormatFileStatusFailed:
 		return "✗", ErrorStyle
+	case FormatFileStatusConflict:
+		return "!", WarningStyle
 	default:
 		return "?", DimStyle
 	}
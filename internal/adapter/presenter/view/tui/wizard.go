@@ -236,3 +236,89 @@ func RunConfirm(prompt string, defaultYes bool) (bool, error) {
 
 	return result.confirmed, nil
 }
+
+// Choice is an option of a ChoiceModel, selected by pressing Key
+type Choice struct {
+	Key   string
+	Label string
+}
+
+// ChoiceModel is a prompt that selects one of several options by a single key
+type ChoiceModel struct {
+	prompt    string
+	choices   []Choice
+	selected  int
+	answered  bool
+	cancelled bool
+	err       error
+}
+
+// NewChoiceModel creates a new choice prompt
+func NewChoiceModel(prompt string, choices []Choice) ChoiceModel {
+	return ChoiceModel{
+		prompt:  prompt,
+		choices: choices,
+	}
+}
+
+// Init initializes the choice model
+func (m ChoiceModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the choice model
+func (m ChoiceModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			m.cancelled = true
+			m.err = fmt.Errorf("input cancelled")
+			return m, tea.Quit
+		}
+		for i, choice := range m.choices {
+			if strings.EqualFold(msg.String(), choice.Key) {
+				m.selected = i
+				m.answered = true
+				return m, tea.Quit
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m ChoiceModel) hint() string {
+	options := make([]string, len(m.choices))
+	for i, choice := range m.choices {
+		options[i] = fmt.Sprintf("[%s] %s", choice.Key, choice.Label)
+	}
+	return DimStyle.Render(strings.Join(options, " "))
+}
+
+// View renders the choice prompt
+func (m ChoiceModel) View() string {
+	if m.answered {
+		return PromptStyle.Render(m.prompt) + " " + m.hint() + " " + SuccessStyle.Render(m.choices[m.selected].Label+"\n")
+	}
+	if m.cancelled {
+		return PromptStyle.Render(m.prompt) + " " + m.hint() + " " + WarningStyle.Render("(cancelled)\n")
+	}
+	return PromptStyle.Render(m.prompt) + " " + m.hint() + " "
+}
+
+// RunChoice runs an interactive choice prompt and returns the key of the selected option
+func RunChoice(prompt string, choices []Choice) (string, error) {
+	m := NewChoiceModel(prompt, choices)
+	p := tea.NewProgram(m)
+
+	finalModel, err := p.Run()
+	if err != nil {
+		return "", err
+	}
+
+	result := finalModel.(ChoiceModel)
+	if result.err != nil {
+		return "", result.err
+	}
+
+	return result.choices[result.selected].Key, nil
+}
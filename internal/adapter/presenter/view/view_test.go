@@ -106,6 +106,10 @@ func TestConfigureProjectSequence(t *testing.T) {
 		{ConfigureProjectSequence_SelectTemplatePath, 5},
 		{ConfigureProjectSequence_SelectAssetPath, 6},
 		{ConfigureProjectSequence_ConfirmGitWarning, 7},
+		{ConfigureProjectSequence_InputSiteTitle, 8},
+		{ConfigureProjectSequence_InputSiteAuthor, 9},
+		{ConfigureProjectSequence_InputSiteDescription, 10},
+		{ConfigureProjectSequence_InputSiteLanguage, 11},
 	}
 
 	for _, tt := range tests {
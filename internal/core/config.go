@@ -1,11 +1,13 @@
 package core
 
 import (
+	"cmp"
 	"encoding"
 	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
+	"runtime"
 	"strings"
 	"time"
 
@@ -33,6 +35,15 @@ type Config struct {
 	LastFormatTimestamp      time.Time     `mapstructure:"last_format_timestamp"`
 	Project                  ConfigProject `mapstructure:"project"`
 	Paths                    ConfigPaths   `mapstructure:"path"`
+	Sync                     ConfigSync    `mapstructure:"sync"`
+	Build                    ConfigBuild   `mapstructure:"build"`
+	Retry                    ConfigRetry   `mapstructure:"retry"`
+	Source                   ConfigSource  `mapstructure:"source"`
+	Auth                     ConfigAuth    `mapstructure:"auth"`
+	New                      ConfigNew     `mapstructure:"new"`
+	Workdir                  ConfigWorkdir `mapstructure:"workdir"`
+	Format                   ConfigFormat  `mapstructure:"format"`
+	Site                     ConfigSite    `mapstructure:"site"`
 }
 
 type ConfigProject struct {
@@ -42,6 +53,21 @@ type ConfigProject struct {
 	Branch        string `mapstructure:"branch"`
 	TemplatePath  string `mapstructure:"template_path"`
 	AssetPath     string `mapstructure:"asset_path"`
+	// Timezone is the IANA time zone nippo are written in, such as "Asia/Tokyo".
+	// The local time zone of the machine is used when empty.
+	Timezone string `mapstructure:"timezone"`
+}
+
+// GetLocation returns the time zone dates and timestamps of nippo are computed in
+func (p ConfigProject) GetLocation() (*time.Location, error) {
+	if p.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q in [project] section of nippo.toml: %w", p.Timezone, err)
+	}
+	return loc, nil
 }
 
 type ConfigPaths struct {
@@ -49,6 +75,306 @@ type ConfigPaths struct {
 	CacheDir string `mapstructure:"cache_dir"`
 }
 
+// DefaultSyncConcurrency is the number of parallel workers used to download
+// and cache nippo files when sync.concurrency is not configured.
+const DefaultSyncConcurrency = 4
+
+// Sync modes for sync.mode
+const (
+	// SyncModeChanges follows the Drive change log and lists all folders only when needed
+	SyncModeChanges = "changes"
+	// SyncModeFull lists all folders on every sync
+	SyncModeFull = "full"
+)
+
+type ConfigSync struct {
+	Concurrency int    `mapstructure:"concurrency"`
+	Mode        string `mapstructure:"mode"`
+}
+
+// IsFullSync reports whether every sync should list all folders
+func (s ConfigSync) IsFullSync() bool {
+	return s.Mode == SyncModeFull
+}
+
+// GetConcurrency returns the configured number of sync workers,
+// falling back to DefaultSyncConcurrency for unset or invalid values.
+func (s ConfigSync) GetConcurrency() int {
+	if s.Concurrency <= 0 {
+		return DefaultSyncConcurrency
+	}
+	return s.Concurrency
+}
+
+type ConfigBuild struct {
+	Concurrency int `mapstructure:"concurrency"`
+}
+
+// GetConcurrency returns the configured number of pages rendered in parallel,
+// falling back to the number of CPUs Go may use for unset or invalid values.
+func (b ConfigBuild) GetConcurrency() int {
+	if b.Concurrency <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return b.Concurrency
+}
+
+// Defaults for retrying Google Drive API calls
+const (
+	DefaultRetryMaxAttempts    = 5
+	DefaultRetryInitialDelayMs = 500
+	DefaultRetryMaxDelayMs     = 30000
+)
+
+// ConfigRetry limits how rate-limited or failed Drive API calls are retried
+type ConfigRetry struct {
+	// MaxAttempts is the total number of attempts per call, including the first one
+	MaxAttempts    int `mapstructure:"max_attempts"`
+	InitialDelayMs int `mapstructure:"initial_delay_ms"`
+	MaxDelayMs     int `mapstructure:"max_delay_ms"`
+}
+
+// GetMaxAttempts returns the configured attempts, falling back to the default for unset values
+func (r ConfigRetry) GetMaxAttempts() int {
+	if r.MaxAttempts <= 0 {
+		return DefaultRetryMaxAttempts
+	}
+	return r.MaxAttempts
+}
+
+// GetInitialDelay returns the backoff before the first retry
+func (r ConfigRetry) GetInitialDelay() time.Duration {
+	if r.InitialDelayMs <= 0 {
+		return DefaultRetryInitialDelayMs * time.Millisecond
+	}
+	return time.Duration(r.InitialDelayMs) * time.Millisecond
+}
+
+// GetMaxDelay returns the upper bound of the backoff between retries
+func (r ConfigRetry) GetMaxDelay() time.Duration {
+	if r.MaxDelayMs <= 0 {
+		return DefaultRetryMaxDelayMs * time.Millisecond
+	}
+	return time.Duration(r.MaxDelayMs) * time.Millisecond
+}
+
+// Nippo source types for source.type
+const (
+	// SourceTypeDrive reads nippo from the Google Drive folder of project.drive_folder_id
+	SourceTypeDrive = "drive"
+	// SourceTypeLocal reads nippo from the directory of source.path
+	SourceTypeLocal = "local"
+	// SourceTypeGit reads nippo from a branch of the git repository at source.path or source.url
+	SourceTypeGit = "git"
+)
+
+// gitSourceDirName is the directory in the cache dir a source.url repository is cloned to
+const gitSourceDirName = "git-source"
+
+// ConfigSource selects where nippo are read from and written back to
+type ConfigSource struct {
+	Type string `mapstructure:"type"`
+	// Path is the nippo directory used by the local source, or the repository used
+	// by the git source. Relative paths are resolved relative to the config directory.
+	Path string `mapstructure:"path"`
+	// Url is a remote repository the git source clones instead of using Path
+	Url string `mapstructure:"url"`
+	// Branch is the branch the git source reads, the checked out branch when empty
+	Branch string `mapstructure:"branch"`
+	// Dir is the directory within the git repository that holds the nippo
+	Dir string `mapstructure:"dir"`
+	// NamePatterns are the patterns tried in order to find the date of a nippo in
+	// its path, such as "YYYY-MM-DD" or "YYYY/MM/DD". Defaults to "YYYY-MM-DD".
+	NamePatterns []string `mapstructure:"name_patterns"`
+}
+
+// IsLocal reports whether nippo are read from a local directory
+func (s ConfigSource) IsLocal() bool {
+	return s.Type == SourceTypeLocal
+}
+
+// IsGit reports whether nippo are read from a git repository
+func (s ConfigSource) IsGit() bool {
+	return s.Type == SourceTypeGit
+}
+
+// IsDrive reports whether nippo are read from Google Drive
+func (s ConfigSource) IsDrive() bool {
+	return !s.IsLocal() && !s.IsGit()
+}
+
+// GetType returns the source type, SourceTypeDrive when unset
+func (s ConfigSource) GetType() string {
+	switch {
+	case s.IsLocal():
+		return SourceTypeLocal
+	case s.IsGit():
+		return SourceTypeGit
+	default:
+		return SourceTypeDrive
+	}
+}
+
+// DisplayName returns a human readable name of the source for progress messages
+func (s ConfigSource) DisplayName() string {
+	switch {
+	case s.IsLocal():
+		return "local folder"
+	case s.IsGit():
+		return "git repository"
+	default:
+		return "Google Drive"
+	}
+}
+
+// ConfigAuth selects how nippo authenticates with Google Drive
+type ConfigAuth struct {
+	// ServiceAccountKey is the JSON key file of a service account. When set, it is
+	// used instead of the OAuth token saved by `nippo auth`. Relative paths are
+	// resolved relative to the config directory.
+	ServiceAccountKey string `mapstructure:"service_account_key"`
+}
+
+// UsesServiceAccount reports whether a service account key is configured
+func (a ConfigAuth) UsesServiceAccount() bool {
+	return a.ServiceAccountKey != ""
+}
+
+// DefaultNewTemplate is the template file in the data dir that `nippo new` renders
+// when new.template is not configured
+const DefaultNewTemplate = "new.md"
+
+// Subfolder layouts for new.subfolder
+const (
+	// NewSubfolderYear puts new nippo into a folder per year, such as 2024/
+	NewSubfolderYear = "year"
+	// NewSubfolderMonth puts new nippo into a folder per month, such as 2024/01/
+	NewSubfolderMonth = "month"
+)
+
+// ConfigNew configures the nippo created by `nippo new`
+type ConfigNew struct {
+	// Template is the markdown template of new nippo. Relative paths are
+	// resolved relative to the data directory.
+	Template string `mapstructure:"template"`
+	// Subfolder is the folder layout below the source folder, flat when empty
+	Subfolder string `mapstructure:"subfolder"`
+}
+
+// GetSubfolder returns the slash-separated folder a new nippo for date is put
+// into, relative to the source folder
+func (n ConfigNew) GetSubfolder(date time.Time) (string, error) {
+	switch n.Subfolder {
+	case "":
+		return "", nil
+	case NewSubfolderYear:
+		return date.Format("2006"), nil
+	case NewSubfolderMonth:
+		return date.Format("2006/01"), nil
+	default:
+		return "", fmt.Errorf("unknown subfolder layout: %s. Set `subfolder` in the [new] section to %q or %q", n.Subfolder, NewSubfolderYear, NewSubfolderMonth)
+	}
+}
+
+// GetNewTemplatePath returns the resolved path of the template for new nippo
+func (c *Config) GetNewTemplatePath() string {
+	template := c.New.Template
+	if template == "" {
+		template = DefaultNewTemplate
+	}
+	return ResolvePath(template, c.GetDataDir())
+}
+
+// ConfigWorkdir configures the working directory of `nippo pull` and `nippo push`
+type ConfigWorkdir struct {
+	// Path is the directory nippo are pulled into. Relative paths are resolved
+	// relative to the config directory.
+	Path string `mapstructure:"path"`
+}
+
+// GetWorkdir returns the resolved working directory, preferring dir over workdir.path
+func (c *Config) GetWorkdir(dir string) (string, error) {
+	if dir != "" {
+		return filepath.Abs(ExpandPath(dir))
+	}
+	if c.Workdir.Path == "" {
+		return "", fmt.Errorf("working directory is not configured. Set `path` in the [workdir] section of nippo.toml or pass --dir")
+	}
+	return ResolvePath(c.Workdir.Path, c.GetConfigDir()), nil
+}
+
+// ConfigFormat selects the rules `nippo format` applies
+type ConfigFormat struct {
+	// Rules are the names of the format rules applied in order. The front-matter
+	// fixes are applied when empty.
+	Rules []string `mapstructure:"rules"`
+	// HeadingTopLevel is the level the heading_levels rule gives the top headings
+	HeadingTopLevel int `mapstructure:"heading_top_level"`
+	// FrontMatterFormat is "yaml" to have the front_matter rule convert TOML and
+	// JSON front-matter to YAML. Each file keeps its format when empty or "keep".
+	FrontMatterFormat string `mapstructure:"front_matter_format"`
+}
+
+// Defaults for the [site] section
+const (
+	DefaultSiteTitle            = "nippo"
+	DefaultSiteLanguage         = "ja"
+	DefaultSiteDescription      = "Daily reports."
+	DefaultSiteDayDescription   = "Daily report for {{.Date}}."
+	DefaultSiteMonthDescription = "Daily reports for {{.Date}}."
+	DefaultSitePageTitle        = "{{.Subject}} / {{.Title}}"
+	DefaultSiteOgpImage         = "/nippo_ogp.png"
+	DefaultSiteFeedEntries      = 20
+	DefaultSiteExcerptLength    = 120
+)
+
+// Feed formats for site.feed_format
+const (
+	SiteFeedAtom = "atom"
+	SiteFeedRSS  = "rss"
+)
+
+// ConfigSite describes the site `nippo build` generates. The descriptions and
+// the page title are Go templates of .Title, .Author, .Date, the date or month
+// of the page, and .Subject, the title of its nippo or else the date.
+type ConfigSite struct {
+	Title    string `mapstructure:"title"`
+	Author   string `mapstructure:"author"`
+	Language string `mapstructure:"language"`
+	// Description describes the site, on the index page and in the feed
+	Description      string `mapstructure:"description"`
+	DayDescription   string `mapstructure:"day_description"`
+	MonthDescription string `mapstructure:"month_description"`
+	// PageTitle is the title of the day and month pages and the feed entries
+	PageTitle string `mapstructure:"page_title"`
+	// OgpImage is the image shared with the pages, a path on the site or a URL
+	OgpImage    string `mapstructure:"ogp_image"`
+	FeedEntries int    `mapstructure:"feed_entries"`
+	FeedFormat  string `mapstructure:"feed_format"`
+	// ExcerptLength is the length in Japanese characters of the excerpt that
+	// describes a nippo without a summary, a half-width character counting as half
+	ExcerptLength int `mapstructure:"excerpt_length"`
+}
+
+// WithDefaults returns the settings with the defaults for unset values. The
+// author and the OGP image are left out of the pages when empty.
+func (s ConfigSite) WithDefaults() ConfigSite {
+	s.Title = cmp.Or(s.Title, DefaultSiteTitle)
+	s.Language = cmp.Or(s.Language, DefaultSiteLanguage)
+	s.Description = cmp.Or(s.Description, DefaultSiteDescription)
+	s.DayDescription = cmp.Or(s.DayDescription, DefaultSiteDayDescription)
+	s.MonthDescription = cmp.Or(s.MonthDescription, DefaultSiteMonthDescription)
+	s.PageTitle = cmp.Or(s.PageTitle, DefaultSitePageTitle)
+	s.FeedFormat = cmp.Or(s.FeedFormat, SiteFeedAtom)
+	if s.FeedEntries <= 0 {
+		s.FeedEntries = DefaultSiteFeedEntries
+	}
+	if s.ExcerptLength <= 0 {
+		s.ExcerptLength = DefaultSiteExcerptLength
+	}
+	return s
+}
+
 // InitConfig initializes the global configuration.
 // If the config file is not found, Cfg is initialized with defaults
 // and ErrConfigNotFound is returned. The caller can check for this
@@ -129,6 +455,50 @@ func (c *Config) GetCacheDir() string {
 	return c.cacheDir
 }
 
+// GetSourceFolder returns the folder nippo are listed from: the resolved
+// source.path for the local source, the directory within the repository for
+// the git source, otherwise the Drive folder ID.
+func (c *Config) GetSourceFolder() (string, error) {
+	if c.Source.IsGit() {
+		if c.Source.Path == "" && c.Source.Url == "" {
+			return "", fmt.Errorf("source repository is not configured. Set `path` or `url` in the [source] section of nippo.toml")
+		}
+		dir := filepath.ToSlash(filepath.Clean(c.Source.Dir))
+		if filepath.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, "../") {
+			return "", fmt.Errorf("source dir must be a path within the repository: %s", c.Source.Dir)
+		}
+		return dir, nil
+	}
+	if c.Source.IsLocal() {
+		if c.Source.Path == "" {
+			return "", fmt.Errorf("source path is not configured. Set `path` in the [source] section of nippo.toml")
+		}
+		return ResolvePath(c.Source.Path, c.GetConfigDir()), nil
+	}
+	if c.Project.DriveFolderId == "" {
+		return "", fmt.Errorf("drive folder ID is not configured. Run `nippo init` to configure")
+	}
+	return c.Project.DriveFolderId, nil
+}
+
+// GetSourceRepository returns the working tree of the git source: the clone in
+// the cache dir when source.url is set, otherwise the resolved source.path.
+func (c *Config) GetSourceRepository() string {
+	if c.Source.Url != "" {
+		return filepath.Join(c.GetCacheDir(), gitSourceDirName)
+	}
+	return ResolvePath(c.Source.Path, c.GetConfigDir())
+}
+
+// GetServiceAccountKeyPath returns the resolved path of auth.service_account_key,
+// or an empty string when no key is configured
+func (c *Config) GetServiceAccountKeyPath() string {
+	if !c.Auth.UsesServiceAccount() {
+		return ""
+	}
+	return ResolvePath(c.Auth.ServiceAccountKey, c.GetConfigDir())
+}
+
 func (c *Config) ResetLastUpdateCheckTimestamp() {
 	c.LastUpdateCheckTimestamp = c.getDefaultLastUpdateCheckTimestamp()
 }
@@ -149,6 +519,22 @@ func (c *Config) LoadConfig(filePath string) error {
 
 	// set default value
 	viper.SetDefault("last_update_check_timestamp", c.getDefaultLastUpdateCheckTimestamp())
+	viper.SetDefault("sync.concurrency", DefaultSyncConcurrency)
+	viper.SetDefault("sync.mode", SyncModeChanges)
+	viper.SetDefault("retry.max_attempts", DefaultRetryMaxAttempts)
+	viper.SetDefault("retry.initial_delay_ms", DefaultRetryInitialDelayMs)
+	viper.SetDefault("retry.max_delay_ms", DefaultRetryMaxDelayMs)
+	viper.SetDefault("source.type", SourceTypeDrive)
+	viper.SetDefault("site.title", DefaultSiteTitle)
+	viper.SetDefault("site.language", DefaultSiteLanguage)
+	viper.SetDefault("site.description", DefaultSiteDescription)
+	viper.SetDefault("site.day_description", DefaultSiteDayDescription)
+	viper.SetDefault("site.month_description", DefaultSiteMonthDescription)
+	viper.SetDefault("site.page_title", DefaultSitePageTitle)
+	viper.SetDefault("site.ogp_image", DefaultSiteOgpImage)
+	viper.SetDefault("site.feed_entries", DefaultSiteFeedEntries)
+	viper.SetDefault("site.feed_format", SiteFeedAtom)
+	viper.SetDefault("site.excerpt_length", DefaultSiteExcerptLength)
 
 	viper.SetEnvPrefix("NIPPO")
 	viper.AutomaticEnv()
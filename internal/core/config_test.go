@@ -3,7 +3,10 @@ package core
 import (
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -275,6 +278,200 @@ func TestConfig_EnvironmentVariableExpansion(t *testing.T) {
 	}
 }
 
+func TestConfig_GetSourceFolder(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "drive folder",
+			config:   Config{Project: ConfigProject{DriveFolderId: "folder-id"}},
+			expected: "folder-id",
+		},
+		{
+			name:    "drive folder not configured",
+			config:  Config{},
+			wantErr: true,
+		},
+		{
+			name:     "local absolute path",
+			config:   Config{Source: ConfigSource{Type: SourceTypeLocal, Path: "/srv/nippo"}},
+			expected: "/srv/nippo",
+		},
+		{
+			name: "local relative path resolves to config dir",
+			config: Config{
+				configDir: "/config/dir",
+				Source:    ConfigSource{Type: SourceTypeLocal, Path: "nippo"},
+			},
+			expected: filepath.Join("/config/dir", "nippo"),
+		},
+		{
+			name:     "git repository root",
+			config:   Config{Source: ConfigSource{Type: SourceTypeGit, Path: "/srv/repo"}},
+			expected: ".",
+		},
+		{
+			name:     "git directory within the repository",
+			config:   Config{Source: ConfigSource{Type: SourceTypeGit, Url: "https://example.com/nippo.git", Dir: "entries/"}},
+			expected: "entries",
+		},
+		{
+			name:    "git directory outside the repository",
+			config:  Config{Source: ConfigSource{Type: SourceTypeGit, Path: "/srv/repo", Dir: "../other"}},
+			wantErr: true,
+		},
+		{
+			name:    "git repository not configured",
+			config:  Config{Source: ConfigSource{Type: SourceTypeGit}},
+			wantErr: true,
+		},
+		{
+			name: "local path not configured",
+			config: Config{
+				Project: ConfigProject{DriveFolderId: "folder-id"},
+				Source:  ConfigSource{Type: SourceTypeLocal},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.GetSourceFolder()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetSourceFolder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("GetSourceFolder() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConfigSource_DisplayName(t *testing.T) {
+	if got := (ConfigSource{}).DisplayName(); got != "Google Drive" {
+		t.Errorf("DisplayName() = %q, want %q", got, "Google Drive")
+	}
+	if got := (ConfigSource{Type: SourceTypeLocal}).DisplayName(); got != "local folder" {
+		t.Errorf("DisplayName() = %q, want %q", got, "local folder")
+	}
+	if got := (ConfigSource{Type: SourceTypeGit}).DisplayName(); got != "git repository" {
+		t.Errorf("DisplayName() = %q, want %q", got, "git repository")
+	}
+}
+
+func TestConfigProject_GetLocation(t *testing.T) {
+	loc, err := (ConfigProject{}).GetLocation()
+	if err != nil || loc != time.Local {
+		t.Errorf("GetLocation() = %v, %v, want local time when unset", loc, err)
+	}
+	loc, err = (ConfigProject{Timezone: "Asia/Tokyo"}).GetLocation()
+	if err != nil || loc.String() != "Asia/Tokyo" {
+		t.Errorf("GetLocation() = %v, %v, want Asia/Tokyo", loc, err)
+	}
+	if _, err := (ConfigProject{Timezone: "Mars/Olympus"}).GetLocation(); err == nil || !strings.Contains(err.Error(), "timezone") {
+		t.Errorf("GetLocation() error = %v, want an invalid timezone error", err)
+	}
+}
+
+func TestConfigBuild_GetConcurrency(t *testing.T) {
+	if got := (ConfigBuild{}).GetConcurrency(); got != runtime.GOMAXPROCS(0) {
+		t.Errorf("GetConcurrency() = %d, want the number of CPUs when unset", got)
+	}
+	if got := (ConfigBuild{Concurrency: 3}).GetConcurrency(); got != 3 {
+		t.Errorf("GetConcurrency() = %d, want 3", got)
+	}
+}
+
+func TestConfigSite_WithDefaults(t *testing.T) {
+	site := ConfigSite{}.WithDefaults()
+	if site.Title != DefaultSiteTitle || site.PageTitle != DefaultSitePageTitle || site.FeedEntries != DefaultSiteFeedEntries || site.FeedFormat != SiteFeedAtom || site.ExcerptLength != DefaultSiteExcerptLength {
+		t.Errorf("WithDefaults() = %+v, want the defaults", site)
+	}
+	if site.Author != "" || site.OgpImage != "" {
+		t.Errorf("WithDefaults() = %+v, want no author and OGP image", site)
+	}
+	site = ConfigSite{Title: "My nippo", FeedEntries: 5}.WithDefaults()
+	if site.Title != "My nippo" || site.FeedEntries != 5 {
+		t.Errorf("WithDefaults() = %+v, want the configured values kept", site)
+	}
+}
+
+func TestConfig_GetSourceRepository(t *testing.T) {
+	cfg := Config{configDir: "/config/dir", Source: ConfigSource{Type: SourceTypeGit, Path: "repo"}}
+	if got := cfg.GetSourceRepository(); got != filepath.Join("/config/dir", "repo") {
+		t.Errorf("GetSourceRepository() = %q, want the resolved path", got)
+	}
+
+	cfg = Config{Source: ConfigSource{Type: SourceTypeGit, Url: "https://example.com/nippo.git"}}
+	cfg.Paths.CacheDir = "/cache"
+	if got := cfg.GetSourceRepository(); got != filepath.Join("/cache", "git-source") {
+		t.Errorf("GetSourceRepository() = %q, want the clone in the cache dir", got)
+	}
+}
+
+func TestConfig_GetServiceAccountKeyPath(t *testing.T) {
+	cfg := Config{configDir: "/config/dir"}
+	if got := cfg.GetServiceAccountKeyPath(); got != "" {
+		t.Errorf("GetServiceAccountKeyPath() = %q, want empty without a key", got)
+	}
+
+	cfg.Auth.ServiceAccountKey = "keys/nippo.json"
+	if got := cfg.GetServiceAccountKeyPath(); got != filepath.Join("/config/dir", "keys", "nippo.json") {
+		t.Errorf("GetServiceAccountKeyPath() = %q, want the resolved path", got)
+	}
+}
+
+func TestConfig_GetNewTemplatePath(t *testing.T) {
+	cfg := Config{dataDir: "/data/dir"}
+	if got := cfg.GetNewTemplatePath(); got != filepath.Join("/data/dir", DefaultNewTemplate) {
+		t.Errorf("GetNewTemplatePath() = %q, want new.md in the data dir", got)
+	}
+
+	cfg.New.Template = "templates/daily.md"
+	if got := cfg.GetNewTemplatePath(); got != filepath.Join("/data/dir", "templates", "daily.md") {
+		t.Errorf("GetNewTemplatePath() = %q, want the resolved path", got)
+	}
+}
+
+func TestConfig_GetWorkdir(t *testing.T) {
+	cfg := Config{configDir: "/config/dir"}
+	if _, err := cfg.GetWorkdir(""); err == nil {
+		t.Error("GetWorkdir() expected error without a working directory")
+	}
+
+	cfg.Workdir.Path = "nippo"
+	if got, err := cfg.GetWorkdir(""); err != nil || got != filepath.Join("/config/dir", "nippo") {
+		t.Errorf("GetWorkdir() = %q, %v, want the resolved path", got, err)
+	}
+	if got, err := cfg.GetWorkdir("/tmp/nippo"); err != nil || got != "/tmp/nippo" {
+		t.Errorf("GetWorkdir() = %q, %v, want the given directory", got, err)
+	}
+}
+
+func TestConfigNew_GetSubfolder(t *testing.T) {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		subfolder string
+		want      string
+		wantErr   bool
+	}{
+		{"", "", false},
+		{NewSubfolderYear, "2024", false},
+		{NewSubfolderMonth, "2024/01", false},
+		{"week", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ConfigNew{Subfolder: tt.subfolder}.GetSubfolder(date)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("GetSubfolder() with %q = %q, %v, want %q", tt.subfolder, got, err, tt.want)
+		}
+	}
+}
+
 func TestConfig_ResetLastUpdateCheckTimestamp(t *testing.T) {
 	cfg := &Config{}
 	cfg.LastUpdateCheckTimestamp = cfg.getDefaultLastUpdateCheckTimestamp().Add(24 * 60 * 60 * 1000000000)
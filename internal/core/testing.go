@@ -28,7 +28,7 @@ type TestEnv struct {
 //	    defer env.Cleanup()
 //	    // ... test code ...
 //	}
-func SetupTestEnv(t *testing.T) *TestEnv {
+func SetupTestEnv(t testing.TB) *TestEnv {
 	t.Helper()
 
 	// Reset viper state to prevent contamination from previous tests
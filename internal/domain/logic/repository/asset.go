@@ -6,6 +6,7 @@ import (
 
 	"github.com/c18t/nippo-cli/internal/adapter/gateway"
 	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/domain/model"
 	i "github.com/c18t/nippo-cli/internal/domain/repository"
 	"github.com/samber/do/v2"
 )
@@ -26,18 +27,55 @@ func NewAssetRepository(injector do.Injector) (i.AssetRepository, error) {
 
 func (r *assetRepository) CleanNippoCache() error {
 	outputDir := filepath.Join(core.Cfg.GetCacheDir(), "md")
-	return r.clean(&i.QueryListParam{
+	if err := r.clean(&i.QueryListParam{
 		Folders:        []string{outputDir},
 		FileExtensions: []string{"md"},
-	})
+	}); err != nil {
+		return err
+	}
+	// The manifest describes the cached files, so it goes with them
+	err := os.Remove(syncManifestPath())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
 }
 
 func (r *assetRepository) CleanBuildCache() error {
 	outputDir := filepath.Join(core.Cfg.GetCacheDir(), "output")
-	return r.clean(&i.QueryListParam{
+	if err := r.clean(&i.QueryListParam{
 		Folders:        []string{outputDir},
 		FileExtensions: []string{"html"},
-	})
+	}); err != nil {
+		return err
+	}
+	// The pages and feeds of the terms are in a folder for each taxonomy
+	for _, taxonomy := range []string{model.TaxonomyTags, model.TaxonomyCategories} {
+		if err := os.RemoveAll(filepath.Join(outputDir, taxonomy)); err != nil {
+			return err
+		}
+	}
+	// The manifest describes the built pages, so it goes with them
+	err := os.Remove(buildManifestPath())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// RemoveBuildPages removes the pages of paths, such as 20240115 or tags/go,
+// from the output, with the feed of a term
+func (r *assetRepository) RemoveBuildPages(paths []string) error {
+	outputDir := filepath.Join(core.Cfg.GetCacheDir(), "output")
+	for _, path := range paths {
+		for _, ext := range []string{".html", ".xml"} {
+			err := os.Remove(filepath.Join(outputDir, filepath.FromSlash(path)+ext))
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func (r *assetRepository) clean(query *i.QueryListParam) error {
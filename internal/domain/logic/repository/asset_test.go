@@ -57,6 +57,11 @@ func TestAssetRepository_CleanNippoCache(t *testing.T) {
 		}
 	}
 
+	manifestPath := filepath.Join(tmpDir, syncManifestFileName)
+	if err := os.WriteFile(manifestPath, []byte(`{"version":1,"files":{}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
 	// Set up global config
 	core.Cfg = &core.Config{}
 	core.Cfg.Paths.CacheDir = tmpDir
@@ -85,6 +90,9 @@ func TestAssetRepository_CleanNippoCache(t *testing.T) {
 			t.Errorf("File %s should have been deleted", name)
 		}
 	}
+	if _, err := os.Stat(manifestPath); !os.IsNotExist(err) {
+		t.Error("Sync manifest should have been deleted")
+	}
 }
 
 func TestAssetRepository_CleanBuildCache(t *testing.T) {
@@ -106,6 +114,18 @@ func TestAssetRepository_CleanBuildCache(t *testing.T) {
 			t.Fatal(err)
 		}
 	}
+	tagDir := filepath.Join(outputDir, "tags")
+	if err := os.MkdirAll(tagDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tagDir, "go.html"), []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manifestPath := filepath.Join(tmpDir, buildManifestFileName)
+	if err := os.WriteFile(manifestPath, []byte(`{"version":1,"entries":{}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
 
 	// Set up global config
 	core.Cfg = &core.Config{}
@@ -135,6 +155,51 @@ func TestAssetRepository_CleanBuildCache(t *testing.T) {
 			t.Errorf("File %s should have been deleted", name)
 		}
 	}
+	if _, err := os.Stat(tagDir); !os.IsNotExist(err) {
+		t.Error("The folder of the tag pages should have been deleted")
+	}
+	if _, err := os.Stat(manifestPath); !os.IsNotExist(err) {
+		t.Error("Build manifest should have been deleted")
+	}
+}
+
+func TestAssetRepository_RemoveBuildPages(t *testing.T) {
+	tmpDir := t.TempDir()
+	core.Cfg = &core.Config{}
+	core.Cfg.Paths.CacheDir = tmpDir
+
+	outputDir := filepath.Join(tmpDir, "output")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(outputDir, "tags"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"20240115.html", "20240116.html", "tags/go.html", "tags/go.xml"} {
+		if err := os.WriteFile(filepath.Join(outputDir, name), []byte("content"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (gateway.LocalFileProvider, error) {
+		return &mockLocalFileProvider{}, nil
+	})
+	repo, _ := NewAssetRepository(injector)
+	if err := repo.RemoveBuildPages([]string{"20240115", "202401", "tags/go"}); err != nil {
+		t.Fatalf("RemoveBuildPages() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "20240115.html")); !os.IsNotExist(err) {
+		t.Error("20240115.html should have been deleted")
+	}
+	for _, name := range []string{"tags/go.html", "tags/go.xml"} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should have been deleted", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "20240116.html")); err != nil {
+		t.Errorf("20240116.html should have been kept: %v", err)
+	}
 }
 
 func TestAssetRepository_CleanEmptyDir(t *testing.T) {
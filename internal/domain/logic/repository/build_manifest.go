@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/domain/model"
+	i "github.com/c18t/nippo-cli/internal/domain/repository"
+	"github.com/samber/do/v2"
+)
+
+const buildManifestFileName = "build-manifest.json"
+
+type buildManifestRepository struct{}
+
+func NewBuildManifestRepository(_ do.Injector) (i.BuildManifestRepository, error) {
+	return &buildManifestRepository{}, nil
+}
+
+func buildManifestPath() string {
+	return filepath.Join(core.Cfg.GetCacheDir(), buildManifestFileName)
+}
+
+// Load reads the manifest from the cache dir. A missing manifest is not an
+// error; an empty one is returned instead, which makes the next build a full one.
+func (r *buildManifestRepository) Load() (*model.BuildManifest, error) {
+	b, err := os.ReadFile(buildManifestPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &model.BuildManifest{Entries: map[string]string{}}, nil
+		}
+		return nil, fmt.Errorf("unable to read build manifest: %w", err)
+	}
+
+	manifest := &model.BuildManifest{}
+	if err := json.Unmarshal(b, manifest); err != nil {
+		return nil, fmt.Errorf("unable to parse build manifest: %w", err)
+	}
+	if manifest.Entries == nil {
+		manifest.Entries = map[string]string{}
+	}
+	return manifest, nil
+}
+
+// Save writes the manifest once the site is built
+func (r *buildManifestRepository) Save(manifest *model.BuildManifest) error {
+	return saveManifest(buildManifestPath(), manifest)
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/domain/model"
+	"github.com/samber/do/v2"
+)
+
+func TestBuildManifestRepository_LoadMissing(t *testing.T) {
+	core.Cfg = &core.Config{}
+	core.Cfg.Paths.CacheDir = t.TempDir()
+
+	repo, _ := NewBuildManifestRepository(do.New())
+	manifest, err := repo.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if manifest.Version != 0 || len(manifest.Entries) != 0 {
+		t.Errorf("Load() = %+v, want an empty manifest without version", manifest)
+	}
+}
+
+func TestBuildManifestRepository_SaveAndLoad(t *testing.T) {
+	tmpDir := t.TempDir()
+	core.Cfg = &core.Config{}
+	core.Cfg.Paths.CacheDir = tmpDir
+
+	repo, _ := NewBuildManifestRepository(do.New())
+	manifest := model.NewBuildManifest()
+	manifest.Templates = "templates"
+	manifest.Entries["2024-01-15"] = "abc"
+	if err := repo.Save(manifest); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 1 || entries[0].Name() != buildManifestFileName {
+		t.Errorf("cache dir should only contain the manifest, got %v", entries)
+	}
+
+	loaded, err := repo.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Version != model.BuildManifestVersion || loaded.Templates != "templates" || loaded.Entries["2024-01-15"] != "abc" {
+		t.Errorf("Load() = %+v", loaded)
+	}
+}
+
+func TestBuildManifestRepository_LoadCorrupt(t *testing.T) {
+	tmpDir := t.TempDir()
+	core.Cfg = &core.Config{}
+	core.Cfg.Paths.CacheDir = tmpDir
+
+	if err := os.WriteFile(filepath.Join(tmpDir, buildManifestFileName), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, _ := NewBuildManifestRepository(do.New())
+	if _, err := repo.Load(); err == nil {
+		t.Error("Load() should fail on a corrupt manifest")
+	}
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/domain/model"
+	i "github.com/c18t/nippo-cli/internal/domain/repository"
+	"github.com/samber/do/v2"
+)
+
+const (
+	formatBackupDirName       = "format-backup"
+	formatRunManifestFileName = "manifest.json"
+)
+
+type formatBackupRepository struct{}
+
+func NewFormatBackupRepository(_ do.Injector) (i.FormatBackupRepository, error) {
+	return &formatBackupRepository{}, nil
+}
+
+func formatBackupDir() string {
+	return filepath.Join(core.Cfg.GetCacheDir(), formatBackupDirName)
+}
+
+// formatRunDir returns the directory of the run of id, which may come from the command line
+func formatRunDir(id string) (string, error) {
+	if id == "" || id == "." || id == ".." || filepath.Base(id) != id {
+		return "", fmt.Errorf("invalid format run ID: %q", id)
+	}
+	return filepath.Join(formatBackupDir(), id), nil
+}
+
+func (r *formatBackupRepository) Backup(run *model.FormatRun, file *model.FormatRunFile, content []byte) error {
+	dir, err := formatRunDir(run.Id)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, file.Backup)
+	if !core.IsPathSafe(dir, path) {
+		return fmt.Errorf("path traversal detected: %s is outside %s", file.Backup, dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("unable to back up %s: %w", file.Name, err)
+	}
+	return nil
+}
+
+func (r *formatBackupRepository) ReadBackup(run *model.FormatRun, file *model.FormatRunFile) ([]byte, error) {
+	dir, err := formatRunDir(run.Id)
+	if err != nil {
+		return nil, err
+	}
+	f, err := core.SafeOpen(dir, filepath.Join(dir, file.Backup))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the backup of %s: %w", file.Name, err)
+	}
+	defer func() { _ = f.Close() }()
+	return io.ReadAll(f)
+}
+
+// Discard removes the backup of file, whose update failed, so the run never
+// restores a change that didn't happen
+func (r *formatBackupRepository) Discard(run *model.FormatRun, file *model.FormatRunFile) error {
+	dir, err := formatRunDir(run.Id)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, file.Backup)
+	if !core.IsPathSafe(dir, path) {
+		return fmt.Errorf("path traversal detected: %s is outside %s", file.Backup, dir)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("unable to discard the backup of %s: %w", file.Name, err)
+	}
+	return nil
+}
+
+// Save writes the manifest through a temporary file, so an interrupted format
+// never leaves a truncated manifest behind.
+func (r *formatBackupRepository) Save(run *model.FormatRun) error {
+	dir, err := formatRunDir(run.Id)
+	if err != nil {
+		return err
+	}
+	return saveManifest(filepath.Join(dir, formatRunManifestFileName), run)
+}
+
+func (r *formatBackupRepository) Load(id string) (*model.FormatRun, error) {
+	dir, err := formatRunDir(id)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(filepath.Join(dir, formatRunManifestFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", i.ErrFormatRunNotFound, id)
+		}
+		return nil, fmt.Errorf("unable to read format run manifest: %w", err)
+	}
+
+	run := &model.FormatRun{}
+	if err := json.Unmarshal(b, run); err != nil {
+		return nil, fmt.Errorf("unable to parse format run manifest of %s: %w", id, err)
+	}
+	return run, nil
+}
+
+// List skips run directories without a manifest, such as those of a run
+// interrupted before its first upload
+func (r *formatBackupRepository) List() ([]model.FormatRun, error) {
+	entries, err := os.ReadDir(formatBackupDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to read format backups: %w", err)
+	}
+
+	var runs []model.FormatRun
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		run, err := r.Load(entry.Name())
+		if err != nil {
+			if errors.Is(err, i.ErrFormatRunNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	sort.Slice(runs, func(a, b int) bool {
+		return runs[a].StartedAt.After(runs[b].StartedAt)
+	})
+	return runs, nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/domain/model"
+	i "github.com/c18t/nippo-cli/internal/domain/repository"
+	"github.com/samber/do/v2"
+	"google.golang.org/api/drive/v3"
+)
+
+func TestFormatBackupRepository_BackupAndLoad(t *testing.T) {
+	core.Cfg = &core.Config{}
+	core.Cfg.Paths.CacheDir = t.TempDir()
+
+	repo, _ := NewFormatBackupRepository(do.New())
+	run := model.NewFormatRun(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), "drive", "folder1")
+	file := run.NextFile(&drive.File{Id: "file1", Name: "2024/2024-01-15.md"}, []string{"Added created field"})
+	if err := repo.Backup(run, &file, []byte("# original\n")); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	run.Files = append(run.Files, file)
+	if err := repo.Save(run); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := repo.Load(run.Id)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Id != "20240115-100000.000" || loaded.Folder != "folder1" || len(loaded.Files) != 1 {
+		t.Fatalf("Load() = %+v", loaded)
+	}
+	content, err := repo.ReadBackup(loaded, &loaded.Files[0])
+	if err != nil {
+		t.Fatalf("ReadBackup() error = %v", err)
+	}
+	if string(content) != "# original\n" {
+		t.Errorf("ReadBackup() = %q", content)
+	}
+
+	// A discarded backup is gone, discarding it again is not an error
+	for range 2 {
+		if err := repo.Discard(loaded, &loaded.Files[0]); err != nil {
+			t.Fatalf("Discard() error = %v", err)
+		}
+	}
+	if _, err := repo.ReadBackup(loaded, &loaded.Files[0]); err == nil {
+		t.Error("ReadBackup() after Discard() expected error")
+	}
+}
+
+func TestFormatBackupRepository_List(t *testing.T) {
+	core.Cfg = &core.Config{}
+	core.Cfg.Paths.CacheDir = t.TempDir()
+
+	repo, _ := NewFormatBackupRepository(do.New())
+	runs, err := repo.List()
+	if err != nil || len(runs) != 0 {
+		t.Fatalf("List() = %v, %v, want no runs", runs, err)
+	}
+
+	older := model.NewFormatRun(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), "drive", "folder1")
+	newer := model.NewFormatRun(time.Date(2024, 1, 16, 10, 0, 0, 0, time.UTC), "drive", "folder1")
+	for _, run := range []*model.FormatRun{older, newer} {
+		if err := repo.Save(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A run interrupted before its manifest was written
+	interrupted := model.NewFormatRun(time.Date(2024, 1, 17, 10, 0, 0, 0, time.UTC), "drive", "folder1")
+	file := interrupted.NextFile(&drive.File{Id: "file1", Name: "2024-01-17.md"}, nil)
+	if err := repo.Backup(interrupted, &file, []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err = repo.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(runs) != 2 || runs[0].Id != newer.Id || runs[1].Id != older.Id {
+		t.Errorf("List() = %+v, want the newer run first", runs)
+	}
+}
+
+func TestFormatBackupRepository_LoadInvalid(t *testing.T) {
+	core.Cfg = &core.Config{}
+	core.Cfg.Paths.CacheDir = t.TempDir()
+
+	repo, _ := NewFormatBackupRepository(do.New())
+	if _, err := repo.Load("20240115-100000.000"); !errors.Is(err, i.ErrFormatRunNotFound) {
+		t.Errorf("Load() error = %v, want ErrFormatRunNotFound", err)
+	}
+	for _, id := range []string{"", "..", "../secret"} {
+		if _, err := repo.Load(id); err == nil || errors.Is(err, i.ErrFormatRunNotFound) {
+			t.Errorf("Load(%q) error = %v, want an invalid ID", id, err)
+		}
+	}
+}
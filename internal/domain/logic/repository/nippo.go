@@ -1,9 +1,14 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/c18t/nippo-cli/internal/adapter/gateway"
 	"github.com/c18t/nippo-cli/internal/core"
@@ -21,7 +26,15 @@ type localNippoQuery struct {
 	provider gateway.LocalFileProvider `do:""`
 }
 
+// NewRemoteNippoQuery returns the query for the configured nippo source,
+// Google Drive unless source.type is "local" or "git".
 func NewRemoteNippoQuery(injector do.Injector) (i.RemoteNippoQuery, error) {
+	if core.Cfg != nil && core.Cfg.Source.IsLocal() {
+		return NewDirectoryNippoQuery(injector)
+	}
+	if core.Cfg != nil && core.Cfg.Source.IsGit() {
+		return NewGitNippoQuery(injector)
+	}
 	provider, err := do.Invoke[gateway.DriveFileProvider](injector)
 	if err != nil {
 		return nil, err
@@ -32,39 +45,233 @@ func NewRemoteNippoQuery(injector do.Injector) (i.RemoteNippoQuery, error) {
 }
 
 func (r *remoteNippoQuery) List(param *i.QueryListParam, option *i.QueryListOption) ([]model.Nippo, error) {
+	nippoList, _, err := r.ListTree(param, option)
+	return nippoList, err
+}
+
+func (r *remoteNippoQuery) ListTree(param *i.QueryListParam, option *i.QueryListOption) ([]model.Nippo, []string, error) {
+	naming, err := nippoNaming()
+	if err != nil {
+		return nil, nil, err
+	}
+	dirs := make(map[string]string, len(param.Folders))
+	for _, id := range param.Folders {
+		dirs[id] = ""
+	}
+	return r.listTree(param, option, naming, dirs)
+}
+
+// listTree lists the folders of param recursively. dirs maps the ID of each
+// folder walked to its path within the root folders.
+func (r *remoteNippoQuery) listTree(param *i.QueryListParam, option *i.QueryListOption, naming *model.NippoNaming, dirs map[string]string) ([]model.Nippo, []string, error) {
 	tempParam := *param
-	res, nippoList, folderList, err := r.list(&tempParam, option)
+	res, nippoList, folderList, err := r.list(&tempParam, option, naming, dirs)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	for res.NextPageToken != "" {
 		tempParam.PageToken = res.NextPageToken
 		var pageNippoList []model.Nippo
 		var pageFolderList []drive.File
-		res, pageNippoList, pageFolderList, err = r.list(&tempParam, option)
+		res, pageNippoList, pageFolderList, err = r.list(&tempParam, option, naming, dirs)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		nippoList = append(nippoList, pageNippoList...)
 		folderList = append(folderList, pageFolderList...)
 	}
 
+	folderIds := append([]string{}, param.Folders...)
 	if option.Recursive && len(folderList) > 0 {
-		folderIds := make([]string, len(folderList))
+		childFolderIds := make([]string, len(folderList))
 		for i, folder := range folderList {
-			folderIds[i] = folder.Id
+			childFolderIds[i] = folder.Id
+			dirs[folder.Id] = path.Join(parentDir(&folder, dirs), folder.Name)
 		}
-		tempParam.Folders = folderIds
-		childNippoList, err := r.List(&tempParam, option)
+		tempParam.Folders = childFolderIds
+		tempParam.PageToken = ""
+		childNippoList, walkedFolderIds, err := r.listTree(&tempParam, option, naming, dirs)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		nippoList = append(nippoList, childNippoList...)
+		folderIds = append(folderIds, walkedFolderIds...)
 	}
-	return nippoList, nil
+	return nippoList, folderIds, nil
+}
+
+// parentDir returns the path of the first parent of file found in dirs
+func parentDir(file *drive.File, dirs map[string]string) string {
+	for _, parent := range file.Parents {
+		if dir, ok := dirs[parent]; ok {
+			return dir
+		}
+	}
+	return ""
+}
+
+func (r *remoteNippoQuery) StartPageToken() (string, error) {
+	return r.provider.GetStartPageToken()
+}
+
+// ListChanges follows the change log from pageToken and keeps the changes to files
+// inside folderIds. Folders created in the tree join the folder set, while a folder of
+// the set that is removed or moved elsewhere yields ErrFullSyncRequired, as the files
+// it took along are not reported as changes.
+func (r *remoteNippoQuery) ListChanges(pageToken string, folderIds []string, param *i.QueryListParam) (*i.RemoteNippoChanges, error) {
+	var changes []*drive.Change
+	token := pageToken
+	nextToken := ""
+	for {
+		res, err := r.provider.ListChanges(token)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, res.Changes...)
+		if res.NextPageToken == "" {
+			nextToken = res.NewStartPageToken
+			break
+		}
+		token = res.NextPageToken
+	}
+
+	roots := make(map[string]bool, len(param.Folders))
+	for _, id := range param.Folders {
+		roots[id] = true
+	}
+	folders := make(map[string]bool, len(folderIds))
+	for _, id := range folderIds {
+		folders[id] = true
+	}
+
+	// Resolve folders first, repeating until stable, since a new folder and
+	// the folders and files inside it may be listed in any order.
+	for updated := true; updated; {
+		updated = false
+		for _, change := range changes {
+			file := change.File
+			if folders[change.FileId] {
+				if change.Removed || file == nil || file.Trashed {
+					return nil, i.ErrFullSyncRequired
+				}
+				if !roots[change.FileId] && !hasParentIn(file, folders) {
+					return nil, i.ErrFullSyncRequired
+				}
+				continue
+			}
+			if file != nil && file.MimeType == gateway.DriveFolderMimeType && !file.Trashed && hasParentIn(file, folders) {
+				folders[change.FileId] = true
+				updated = true
+			}
+		}
+	}
+
+	changed := map[string]*drive.File{}
+	removed := map[string]bool{}
+	for _, change := range changes {
+		if folders[change.FileId] {
+			continue
+		}
+		file := change.File
+		if file != nil && file.MimeType == gateway.DriveFolderMimeType {
+			continue
+		}
+		if change.Removed || file == nil || file.Trashed || !hasParentIn(file, folders) || !hasExtension(file, param.FileExtensions) {
+			delete(changed, change.FileId)
+			removed[change.FileId] = true
+			continue
+		}
+		delete(removed, change.FileId)
+		changed[change.FileId] = file
+	}
+
+	naming, err := nippoNaming()
+	if err != nil {
+		return nil, err
+	}
+	result := &i.RemoteNippoChanges{PageToken: nextToken}
+	dirs := make(map[string]string, len(roots))
+	for id := range roots {
+		dirs[id] = ""
+	}
+	for _, file := range changed {
+		relPath := file.Name
+		if naming.UsesFolders() {
+			dir, err := r.folderDir(parentIn(file, folders), dirs)
+			if err != nil {
+				return nil, err
+			}
+			relPath = path.Join(dir, file.Name)
+		}
+		date, _ := naming.Parse(relPath)
+		result.Changed = append(result.Changed, model.Nippo{
+			Date:       date,
+			RemoteFile: file,
+		})
+	}
+	sort.Slice(result.Changed, func(a, b int) bool {
+		return result.Changed[a].RemoteFile.Name < result.Changed[b].RemoteFile.Name
+	})
+	for id := range removed {
+		result.Removed = append(result.Removed, id)
+	}
+	sort.Strings(result.Removed)
+	for id := range folders {
+		result.FolderIds = append(result.FolderIds, id)
+	}
+	sort.Strings(result.FolderIds)
+	return result, nil
+}
+
+func hasParentIn(file *drive.File, folders map[string]bool) bool {
+	return parentIn(file, folders) != ""
+}
+
+// parentIn returns the first parent of file in folders, or "" when there is none
+func parentIn(file *drive.File, folders map[string]bool) string {
+	for _, parent := range file.Parents {
+		if folders[parent] {
+			return parent
+		}
+	}
+	return ""
+}
+
+// folderDir returns the path of the folder of id within the root folders in dirs,
+// looking up the names of the folders above it as needed
+func (r *remoteNippoQuery) folderDir(id string, dirs map[string]string) (string, error) {
+	if dir, ok := dirs[id]; ok {
+		return dir, nil
+	}
+	folder, err := r.provider.Get(id)
+	if err != nil {
+		return "", err
+	}
+	if len(folder.Parents) == 0 {
+		// Moved out of the root folders since the change was made
+		return "", i.ErrFullSyncRequired
+	}
+	parent, err := r.folderDir(folder.Parents[0], dirs)
+	if err != nil {
+		return "", err
+	}
+	dirs[id] = path.Join(parent, folder.Name)
+	return dirs[id], nil
 }
 
-func (r *remoteNippoQuery) list(param *i.QueryListParam, option *i.QueryListOption) (*drive.FileList, []model.Nippo, []drive.File, error) {
+func hasExtension(file *drive.File, extensions []string) bool {
+	if len(extensions) == 0 {
+		return true
+	}
+	for _, ext := range extensions {
+		if file.FileExtension == ext {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *remoteNippoQuery) list(param *i.QueryListParam, option *i.QueryListOption, naming *model.NippoNaming, dirs map[string]string) (*drive.FileList, []model.Nippo, []drive.File, error) {
 	res, err := r.provider.List(param)
 	if err != nil {
 		return nil, nil, nil, err
@@ -76,8 +283,9 @@ func (r *remoteNippoQuery) list(param *i.QueryListParam, option *i.QueryListOpti
 		if file.MimeType == gateway.DriveFolderMimeType {
 			folderList = append(folderList, *file)
 		} else {
-			nippo := &model.Nippo{}
-			nippo.Date = model.NewNippoDate(file.Name)
+			nippo := &model.Nippo{RemotePath: path.Join(parentDir(file, dirs), file.Name)}
+			// Files without a date in their path are resolved from their front-matter later
+			nippo.Date, _ = naming.Parse(nippo.RemotePath)
 			nippo.RemoteFile = file
 			if option.WithContent {
 				_ = r.Download(nippo)
@@ -93,10 +301,105 @@ func (r *remoteNippoQuery) Download(nippo *model.Nippo) (err error) {
 	return
 }
 
+// Update checks the file on Drive has not changed since it was listed right
+// before uploading, as the Drive API has no conditional update
 func (r *remoteNippoQuery) Update(nippo *model.Nippo, content []byte) error {
+	current, err := r.provider.Get(nippo.RemoteFile.Id)
+	if err != nil {
+		return err
+	}
+	if current.Trashed || remoteFileChanged(nippo.RemoteFile, current) {
+		return fmt.Errorf("%w: %s", i.ErrConflict, nippo.RemoteFile.Name)
+	}
 	return r.provider.Update(nippo.RemoteFile.Id, content)
 }
 
+// remoteFileChanged reports whether current is another revision than fetched.
+// The checksum is compared when both are known, as only content changes can
+// be lost; the version or the modified time otherwise.
+func remoteFileChanged(fetched, current *drive.File) bool {
+	switch {
+	case fetched.Md5Checksum != "" && current.Md5Checksum != "":
+		return fetched.Md5Checksum != current.Md5Checksum
+	case fetched.Version != 0 && current.Version != 0:
+		return fetched.Version != current.Version
+	default:
+		return fetched.ModifiedTime != current.ModifiedTime
+	}
+}
+
+func (r *remoteNippoQuery) Create(folder, filePath string, nippo *model.Nippo) error {
+	dir, name := path.Split(filePath)
+	dir = strings.TrimSuffix(dir, "/")
+	parent := folder
+	if dir != "" {
+		for _, name := range strings.Split(dir, "/") {
+			child, err := r.findChild(parent, name, true)
+			if err != nil {
+				return err
+			}
+			if child == nil {
+				child, err = r.provider.Create(&drive.File{
+					Name:     name,
+					MimeType: gateway.DriveFolderMimeType,
+					Parents:  []string{parent},
+				}, nil)
+				if err != nil {
+					return err
+				}
+			}
+			parent = child.Id
+		}
+	}
+
+	existing, err := r.findChild(parent, name, false)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("%w: %s", i.ErrNippoExists, name)
+	}
+	file, err := r.provider.Create(&drive.File{
+		Name:     name,
+		MimeType: "text/markdown",
+		Parents:  []string{parent},
+	}, nippo.Content)
+	if err != nil {
+		return err
+	}
+	nippo.RemoteFile = file
+	return nil
+}
+
+// Flush does nothing, as Drive files are uploaded as they change
+func (r *remoteNippoQuery) Flush() error {
+	return nil
+}
+
+func (r *remoteNippoQuery) Naming() (*model.NippoNaming, error) {
+	return nippoNaming()
+}
+
+// findChild returns the folder or file named name directly inside parent, or nil
+func (r *remoteNippoQuery) findChild(parent, name string, folder bool) (*drive.File, error) {
+	param := &i.QueryListParam{Folders: []string{parent}}
+	for {
+		res, err := r.provider.List(param)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range res.Files {
+			if file.Name == name && (file.MimeType == gateway.DriveFolderMimeType) == folder && slices.Contains(file.Parents, parent) {
+				return file, nil
+			}
+		}
+		if res.NextPageToken == "" {
+			return nil, nil
+		}
+		param.PageToken = res.NextPageToken
+	}
+}
+
 func NewLocalNippoQuery(injector do.Injector) (i.LocalNippoQuery, error) {
 	provider, err := do.Invoke[gateway.LocalFileProvider](injector)
 	if err != nil {
@@ -108,7 +411,11 @@ func NewLocalNippoQuery(injector do.Injector) (i.LocalNippoQuery, error) {
 }
 
 func (r *localNippoQuery) Exist(date *model.NippoDate) bool {
-	return true
+	if date == nil || *date == nil {
+		return false
+	}
+	_, err := os.Stat(cachedNippoPath(*date))
+	return err == nil
 }
 
 func (r *localNippoQuery) Find(date *model.NippoDate) (*model.Nippo, error) {
@@ -131,6 +438,10 @@ func (r *localNippoQuery) List(param *i.QueryListParam, option *i.QueryListOptio
 
 		for _, file := range files {
 			nippo, err := model.NewNippo(filepath.Join(folder, file.Name()))
+			if errors.Is(err, model.ErrInvalidNippoName) {
+				// Not a nippo cached by a build
+				continue
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -140,6 +451,8 @@ func (r *localNippoQuery) List(param *i.QueryListParam, option *i.QueryListOptio
 			nippoList = append(nippoList, *nippo)
 		}
 	}
+	// By name, 2024-01-15-evening.md would come before 2024-01-15.md
+	slices.SortStableFunc(nippoList, model.CompareNippo)
 	return nippoList, nil
 }
 
@@ -164,12 +477,11 @@ func NewLocalNippoCommand(injector do.Injector) (i.LocalNippoCommand, error) {
 }
 
 func (r *localNippoCommand) Create(nippo *model.Nippo) (err error) {
-	cacheDir := filepath.Join(core.Cfg.GetCacheDir(), "md")
-	err = os.MkdirAll(cacheDir, 0755)
+	filePath := cachedNippoPath(nippo.Date)
+	err = os.MkdirAll(filepath.Dir(filePath), 0755)
 	if err != nil && !os.IsExist(err) {
 		return err
 	}
-	filePath := filepath.Join(cacheDir, fmt.Sprintf("%v.md", nippo.Date.FileString()))
 	f, err := os.Create(filePath)
 	if err != nil {
 		return err
@@ -187,6 +499,36 @@ func (r *localNippoCommand) Create(nippo *model.Nippo) (err error) {
 	return nil
 }
 
+// Delete removes the cached markdown of nippo. A file that is already gone is not an error.
 func (r *localNippoCommand) Delete(nippo *model.Nippo) error {
+	filePath := nippo.FilePath
+	if filePath == "" {
+		if nippo.Date == nil {
+			return fmt.Errorf("unable to resolve cache path for nippo")
+		}
+		filePath = cachedNippoPath(nippo.Date)
+	}
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	nippo.FilePath = ""
 	return nil
 }
+
+// nippoNaming returns the naming patterns of nippo files configured for the source
+func nippoNaming() (*model.NippoNaming, error) {
+	var patterns []string
+	if core.Cfg != nil {
+		patterns = core.Cfg.Source.NamePatterns
+	}
+	naming, err := model.NewNippoNaming(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid name_patterns in [source] section of nippo.toml: %w", err)
+	}
+	return naming, nil
+}
+
+// cachedNippoPath returns the path a nippo for date is cached to
+func cachedNippoPath(date model.NippoDate) string {
+	return filepath.Join(core.Cfg.GetCacheDir(), "md", fmt.Sprintf("%v.md", date.FileString()))
+}
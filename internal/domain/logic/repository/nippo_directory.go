@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/c18t/nippo-cli/internal/domain/model"
+	i "github.com/c18t/nippo-cli/internal/domain/repository"
+	"github.com/samber/do/v2"
+	"google.golang.org/api/drive/v3"
+)
+
+// directoryNippoQuery reads nippo from a plain directory tree, such as a folder kept
+// in sync by Syncthing or Dropbox. Files are described with drive.File so the rest of
+// the sync works unchanged: the ID is the file path and the parents are its directory.
+type directoryNippoQuery struct{}
+
+func NewDirectoryNippoQuery(_ do.Injector) (i.RemoteNippoQuery, error) {
+	return &directoryNippoQuery{}, nil
+}
+
+func (r *directoryNippoQuery) List(param *i.QueryListParam, option *i.QueryListOption) ([]model.Nippo, error) {
+	nippoList, _, err := r.ListTree(param, option)
+	return nippoList, err
+}
+
+func (r *directoryNippoQuery) ListTree(param *i.QueryListParam, option *i.QueryListOption) ([]model.Nippo, []string, error) {
+	naming, err := nippoNaming()
+	if err != nil {
+		return nil, nil, err
+	}
+	var nippoList []model.Nippo
+	var folderIds []string
+	for _, root := range param.Folders {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path == root {
+					folderIds = append(folderIds, path)
+					return nil
+				}
+				// Skip sync tool metadata such as .stfolder, .stversions or .dropbox.cache
+				if isHiddenName(d.Name()) || !option.Recursive {
+					return filepath.SkipDir
+				}
+				folderIds = append(folderIds, path)
+				return nil
+			}
+			if isHiddenName(d.Name()) || !hasFileExtension(d.Name(), param.FileExtensions) {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if !param.UpdatedAt.IsZero() && !info.ModTime().After(param.UpdatedAt) {
+				return nil
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			relPath, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			// Files without a date in their path are resolved from their front-matter later
+			date, _ := naming.Parse(filepath.ToSlash(relPath))
+			nippo := model.Nippo{
+				Date:       date,
+				RemoteFile: newDirectoryFile(path, info, content),
+				RemotePath: filepath.ToSlash(relPath),
+			}
+			if option.WithContent {
+				nippo.Content = content
+			}
+			nippoList = append(nippoList, nippo)
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if param.OrderBy == "name" {
+		sort.SliceStable(nippoList, func(a, b int) bool {
+			return nippoList[a].RemoteFile.Name < nippoList[b].RemoteFile.Name
+		})
+	}
+	return nippoList, folderIds, nil
+}
+
+// StartPageToken returns no token, as a directory has no change log to follow
+func (r *directoryNippoQuery) StartPageToken() (string, error) {
+	return "", nil
+}
+
+// ListChanges always asks for a full listing, which is cheap for a local directory
+func (r *directoryNippoQuery) ListChanges(pageToken string, folderIds []string, param *i.QueryListParam) (*i.RemoteNippoChanges, error) {
+	return nil, i.ErrFullSyncRequired
+}
+
+func (r *directoryNippoQuery) Download(nippo *model.Nippo) (err error) {
+	nippo.Content, err = os.ReadFile(nippo.RemoteFile.Id)
+	return
+}
+
+// Update rewrites the file in place, keeping its permissions
+func (r *directoryNippoQuery) Update(nippo *model.Nippo, content []byte) error {
+	path := nippo.RemoteFile.Id
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if nippo.RemoteFile.Md5Checksum != "" {
+		current, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		sum := md5.Sum(current)
+		if hex.EncodeToString(sum[:]) != nippo.RemoteFile.Md5Checksum {
+			return fmt.Errorf("%w: %s", i.ErrConflict, path)
+		}
+	}
+	return os.WriteFile(path, content, info.Mode().Perm())
+}
+
+// Create writes the new file with O_EXCL, so an existing nippo is never overwritten
+func (r *directoryNippoQuery) Create(folder, filePath string, nippo *model.Nippo) error {
+	path := filepath.Join(folder, filepath.FromSlash(filePath))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("%w: %s", i.ErrNippoExists, path)
+		}
+		return err
+	}
+	if _, err := f.Write(nippo.Content); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	nippo.RemoteFile = newDirectoryFile(path, info, nippo.Content)
+	return nil
+}
+
+// Flush does nothing, as files are written as they change
+func (r *directoryNippoQuery) Flush() error {
+	return nil
+}
+
+func (r *directoryNippoQuery) Naming() (*model.NippoNaming, error) {
+	return nippoNaming()
+}
+
+// newDirectoryFile describes the file at path. Files have no portable creation
+// time, so the modified time stands in for it.
+func newDirectoryFile(path string, info fs.FileInfo, content []byte) *drive.File {
+	sum := md5.Sum(content)
+	return &drive.File{
+		Id:            path,
+		Name:          info.Name(),
+		FileExtension: strings.TrimPrefix(filepath.Ext(info.Name()), "."),
+		MimeType:      "text/markdown",
+		Md5Checksum:   hex.EncodeToString(sum[:]),
+		CreatedTime:   info.ModTime().UTC().Format(time.RFC3339Nano),
+		ModifiedTime:  info.ModTime().UTC().Format(time.RFC3339Nano),
+		Parents:       []string{filepath.Dir(path)},
+	}
+}
+
+func isHiddenName(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+func hasFileExtension(name string, extensions []string) bool {
+	if len(extensions) == 0 {
+		return true
+	}
+	for _, ext := range extensions {
+		if strings.HasSuffix(name, "."+ext) {
+			return true
+		}
+	}
+	return false
+}
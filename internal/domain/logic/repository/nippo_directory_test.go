@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/domain/model"
+	"github.com/c18t/nippo-cli/internal/domain/repository"
+	"github.com/samber/do/v2"
+)
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestNippoDirectory(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "2024-01-16.md"), "# 16")
+	writeTestFile(t, filepath.Join(root, "2024", "01", "2024-01-15.md"), "# 15")
+	writeTestFile(t, filepath.Join(root, "notes.txt"), "not a nippo")
+	writeTestFile(t, filepath.Join(root, ".stversions", "2024-01-14.md"), "# old version")
+	writeTestFile(t, filepath.Join(root, ".hidden.md"), "# hidden")
+	return root
+}
+
+func TestDirectoryNippoQuery_ListTree(t *testing.T) {
+	root := newTestNippoDirectory(t)
+	query, _ := NewDirectoryNippoQuery(do.New())
+
+	nippoList, folderIds, err := query.ListTree(&repository.QueryListParam{
+		Folders:        []string{root},
+		FileExtensions: []string{"md"},
+		OrderBy:        "name",
+	}, &repository.QueryListOption{Recursive: true, WithContent: true})
+	if err != nil {
+		t.Fatalf("ListTree() error = %v", err)
+	}
+
+	if len(nippoList) != 2 {
+		t.Fatalf("ListTree() returned %d nippo, want 2", len(nippoList))
+	}
+	first := nippoList[0]
+	if first.RemoteFile.Name != "2024-01-15.md" || nippoList[1].RemoteFile.Name != "2024-01-16.md" {
+		t.Errorf("ListTree() names = %s, %s", first.RemoteFile.Name, nippoList[1].RemoteFile.Name)
+	}
+	if first.RemoteFile.Id != filepath.Join(root, "2024", "01", "2024-01-15.md") {
+		t.Errorf("Id = %q, want the file path", first.RemoteFile.Id)
+	}
+	if first.RemoteFile.Md5Checksum == "" || first.RemoteFile.ModifiedTime == "" || first.RemoteFile.CreatedTime != first.RemoteFile.ModifiedTime {
+		t.Errorf("RemoteFile = %+v, want checksum and modified time as the created time", first.RemoteFile)
+	}
+	if string(first.Content) != "# 15" {
+		t.Errorf("Content = %q, want %q", first.Content, "# 15")
+	}
+	if first.Date.FileString() != "2024-01-15" {
+		t.Errorf("Date = %q", first.Date.FileString())
+	}
+
+	wantFolders := []string{root, filepath.Join(root, "2024"), filepath.Join(root, "2024", "01")}
+	if len(folderIds) != len(wantFolders) {
+		t.Fatalf("folderIds = %v, want %v", folderIds, wantFolders)
+	}
+	for i, want := range wantFolders {
+		if folderIds[i] != want {
+			t.Errorf("folderIds[%d] = %q, want %q", i, folderIds[i], want)
+		}
+	}
+}
+
+func TestDirectoryNippoQuery_ListNotRecursive(t *testing.T) {
+	root := newTestNippoDirectory(t)
+	query, _ := NewDirectoryNippoQuery(do.New())
+
+	nippoList, err := query.List(&repository.QueryListParam{
+		Folders:        []string{root},
+		FileExtensions: []string{"md"},
+	}, &repository.QueryListOption{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(nippoList) != 1 || nippoList[0].RemoteFile.Name != "2024-01-16.md" {
+		t.Errorf("List() = %v, want only the top-level nippo", nippoList)
+	}
+	if nippoList[0].Content != nil {
+		t.Error("Content should not be loaded without WithContent")
+	}
+}
+
+func TestDirectoryNippoQuery_ListUpdatedAt(t *testing.T) {
+	root := newTestNippoDirectory(t)
+	old := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(root, "2024-01-16.md"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	query, _ := NewDirectoryNippoQuery(do.New())
+
+	nippoList, err := query.List(&repository.QueryListParam{
+		Folders:        []string{root},
+		FileExtensions: []string{"md"},
+		UpdatedAt:      old.Add(time.Hour),
+	}, &repository.QueryListOption{Recursive: true})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(nippoList) != 1 || nippoList[0].RemoteFile.Name != "2024-01-15.md" {
+		t.Errorf("List() = %v, want only the recently modified nippo", nippoList)
+	}
+}
+
+func TestDirectoryNippoQuery_ListMissingFolder(t *testing.T) {
+	query, _ := NewDirectoryNippoQuery(do.New())
+
+	_, err := query.List(&repository.QueryListParam{
+		Folders: []string{filepath.Join(t.TempDir(), "missing")},
+	}, &repository.QueryListOption{Recursive: true})
+	if err == nil {
+		t.Error("List() expected error for a missing folder")
+	}
+}
+
+func TestDirectoryNippoQuery_DownloadAndUpdate(t *testing.T) {
+	root := newTestNippoDirectory(t)
+	path := filepath.Join(root, "2024-01-16.md")
+	query, _ := NewDirectoryNippoQuery(do.New())
+
+	nippoList, _ := query.List(&repository.QueryListParam{
+		Folders:        []string{root},
+		FileExtensions: []string{"md"},
+	}, &repository.QueryListOption{})
+	nippo := &nippoList[0]
+
+	if err := query.Update(nippo, []byte("# updated")); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := query.Download(nippo); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if string(nippo.Content) != "# updated" {
+		t.Errorf("Content = %q, want %q", nippo.Content, "# updated")
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("mode = %v, want permissions to be kept", info.Mode().Perm())
+	}
+}
+
+func TestDirectoryNippoQuery_UpdateConflict(t *testing.T) {
+	root := newTestNippoDirectory(t)
+	query, _ := NewDirectoryNippoQuery(do.New())
+
+	nippoList, _ := query.List(&repository.QueryListParam{
+		Folders:        []string{root},
+		FileExtensions: []string{"md"},
+	}, &repository.QueryListOption{})
+	nippo := &nippoList[0]
+
+	// Edited by someone else after it was listed
+	if err := os.WriteFile(nippo.RemoteFile.Id, []byte("# edited elsewhere"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := query.Update(nippo, []byte("# updated")); !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("Update() error = %v, want ErrConflict", err)
+	}
+	if b, _ := os.ReadFile(nippo.RemoteFile.Id); string(b) != "# edited elsewhere" {
+		t.Errorf("content = %q, want the other edit to be kept", b)
+	}
+}
+
+func TestDirectoryNippoQuery_Changes(t *testing.T) {
+	query, _ := NewDirectoryNippoQuery(do.New())
+
+	token, err := query.StartPageToken()
+	if err != nil || token != "" {
+		t.Errorf("StartPageToken() = %q, %v, want no token", token, err)
+	}
+	_, err = query.ListChanges("token", nil, &repository.QueryListParam{})
+	if !errors.Is(err, repository.ErrFullSyncRequired) {
+		t.Errorf("ListChanges() error = %v, want ErrFullSyncRequired", err)
+	}
+}
+
+func TestNewRemoteNippoQuery_LocalSource(t *testing.T) {
+	saved := core.Cfg
+	t.Cleanup(func() { core.Cfg = saved })
+	core.Cfg = &core.Config{}
+	core.Cfg.Source.Type = core.SourceTypeLocal
+
+	query, err := NewRemoteNippoQuery(do.New())
+	if err != nil {
+		t.Fatalf("NewRemoteNippoQuery() error = %v", err)
+	}
+	if _, ok := query.(*directoryNippoQuery); !ok {
+		t.Errorf("NewRemoteNippoQuery() = %T, want *directoryNippoQuery", query)
+	}
+}
+
+func TestDirectoryNippoQuery_Create(t *testing.T) {
+	root := t.TempDir()
+	query := &directoryNippoQuery{}
+
+	nippo := &model.Nippo{Date: model.NewNippoDate("2024-01-15.md"), Content: []byte("# nippo")}
+	if err := query.Create(root, "2024/2024-01-15.md", nippo); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	path := filepath.Join(root, "2024", "2024-01-15.md")
+	if content, err := os.ReadFile(path); err != nil || string(content) != "# nippo" {
+		t.Errorf("file = %q, %v", content, err)
+	}
+	if nippo.RemoteFile == nil || nippo.RemoteFile.Id != path {
+		t.Errorf("RemoteFile = %+v, want the created file", nippo.RemoteFile)
+	}
+
+	err := query.Create(root, "2024/2024-01-15.md", &model.Nippo{Date: model.NewNippoDate("2024-01-15.md"), Content: []byte("overwritten")})
+	if !errors.Is(err, repository.ErrNippoExists) {
+		t.Errorf("Create() error = %v, want ErrNippoExists", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "# nippo" {
+		t.Errorf("file = %q, want it unchanged", content)
+	}
+}
+
+func TestDirectoryNippoQuery_ListTreeFolderPattern(t *testing.T) {
+	saved := core.Cfg
+	t.Cleanup(func() { core.Cfg = saved })
+	core.Cfg = &core.Config{}
+	core.Cfg.Source.NamePatterns = []string{"YYYY/MM/DD", "YYYYMMDD"}
+
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "2024", "01", "15.md"), "# 15")
+	writeTestFile(t, filepath.Join(root, "20240116.md"), "# 16")
+	writeTestFile(t, filepath.Join(root, "notes.md"), "# notes")
+	query, _ := NewDirectoryNippoQuery(do.New())
+
+	nippoList, _, err := query.ListTree(&repository.QueryListParam{
+		Folders:        []string{root},
+		FileExtensions: []string{"md"},
+		OrderBy:        "name",
+	}, &repository.QueryListOption{Recursive: true})
+	if err != nil {
+		t.Fatalf("ListTree() error = %v", err)
+	}
+
+	dates := map[string]string{}
+	for _, nippo := range nippoList {
+		dates[nippo.RemoteFile.Name] = "nil"
+		if nippo.Date != nil {
+			dates[nippo.RemoteFile.Name] = nippo.Date.FileString()
+		}
+	}
+	want := map[string]string{"15.md": "2024-01-15", "20240116.md": "2024-01-16", "notes.md": "nil"}
+	for name, date := range want {
+		if dates[name] != date {
+			t.Errorf("Date of %s = %s, want %s", name, dates[name], date)
+		}
+	}
+}
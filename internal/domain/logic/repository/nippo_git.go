@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/c18t/nippo-cli/internal/adapter/gateway"
+	"github.com/c18t/nippo-cli/internal/domain/model"
+	i "github.com/c18t/nippo-cli/internal/domain/repository"
+	"github.com/samber/do/v2"
+	"google.golang.org/api/drive/v3"
+)
+
+// gitNippoQuery reads nippo from a branch of a git repository. Files are described
+// with drive.File like the other sources: the ID is the path in the repository, the
+// checksum is the blob ID, and the created and modified times come from the history.
+type gitNippoQuery struct {
+	provider gateway.GitProvider `do:""`
+}
+
+func NewGitNippoQuery(injector do.Injector) (i.RemoteNippoQuery, error) {
+	provider, err := do.Invoke[gateway.GitProvider](injector)
+	if err != nil {
+		return nil, err
+	}
+	return &gitNippoQuery{
+		provider: provider,
+	}, nil
+}
+
+func (r *gitNippoQuery) List(param *i.QueryListParam, option *i.QueryListOption) ([]model.Nippo, error) {
+	nippoList, _, err := r.ListTree(param, option)
+	return nippoList, err
+}
+
+func (r *gitNippoQuery) ListTree(param *i.QueryListParam, option *i.QueryListOption) ([]model.Nippo, []string, error) {
+	naming, err := nippoNaming()
+	if err != nil {
+		return nil, nil, err
+	}
+	var nippoList []model.Nippo
+	folderIds := append([]string{}, param.Folders...)
+	for _, folder := range param.Folders {
+		files, err := r.provider.ListFiles(folder)
+		if err != nil {
+			return nil, nil, err
+		}
+		history, err := r.provider.History(folder)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, file := range files {
+			dir := path.Dir(file.Path)
+			if !option.Recursive && dir != path.Clean(folder) {
+				continue
+			}
+			if hasHiddenSegment(file.Path) || !hasFileExtension(file.Path, param.FileExtensions) {
+				continue
+			}
+			h := history[file.Path]
+			if !param.UpdatedAt.IsZero() && !h.Modified.After(param.UpdatedAt) {
+				continue
+			}
+
+			// Files without a date in their path are resolved from their front-matter later
+			relPath := gitRelPath(folder, file.Path)
+			date, _ := naming.Parse(relPath)
+			nippo := model.Nippo{
+				Date:       date,
+				RemoteFile: newGitFile(file, h),
+				RemotePath: relPath,
+			}
+			if option.WithContent {
+				if nippo.Content, err = r.provider.Read(file.Path); err != nil {
+					return nil, nil, err
+				}
+			}
+			nippoList = append(nippoList, nippo)
+		}
+	}
+
+	if param.OrderBy == "name" {
+		sort.SliceStable(nippoList, func(a, b int) bool {
+			return nippoList[a].RemoteFile.Name < nippoList[b].RemoteFile.Name
+		})
+	}
+	return nippoList, folderIds, nil
+}
+
+// StartPageToken returns no token, as listing a branch is cheap and unchanged
+// files are told apart by their blob ID
+func (r *gitNippoQuery) StartPageToken() (string, error) {
+	return "", nil
+}
+
+// ListChanges always asks for a full listing
+func (r *gitNippoQuery) ListChanges(pageToken string, folderIds []string, param *i.QueryListParam) (*i.RemoteNippoChanges, error) {
+	return nil, i.ErrFullSyncRequired
+}
+
+// Download reads the nippo from the branch. When the front-matter has no created
+// or updated time, they are filled in from the commit history, so the built site
+// shows the same times as a formatted nippo would.
+func (r *gitNippoQuery) Download(nippo *model.Nippo) error {
+	content, err := r.provider.Read(nippo.RemoteFile.Id)
+	if err != nil {
+		return err
+	}
+	nippo.Content, err = withHistoryTimes(content, nippo.RemoteFile)
+	return err
+}
+
+// Update commits content to the branch, unless the file on the branch is no
+// longer the blob it was listed with
+func (r *gitNippoQuery) Update(nippo *model.Nippo, content []byte) error {
+	if nippo.RemoteFile.Md5Checksum != "" {
+		files, err := r.provider.ListFiles(nippo.RemoteFile.Id)
+		if err != nil {
+			return err
+		}
+		if len(files) != 1 || files[0].Blob != nippo.RemoteFile.Md5Checksum {
+			return fmt.Errorf("%w: %s", i.ErrConflict, nippo.RemoteFile.Id)
+		}
+	}
+	return r.provider.Commit(nippo.RemoteFile.Id, content, fmt.Sprintf("Update %s", nippo.RemoteFile.Name))
+}
+
+// Create is not supported, as new nippo are committed with git itself
+func (r *gitNippoQuery) Create(folder, filePath string, nippo *model.Nippo) error {
+	return fmt.Errorf("creating nippo is not supported for the git source. Add the file to the repository with git instead")
+}
+
+// Flush pushes the commits of the updates
+func (r *gitNippoQuery) Flush() error {
+	return r.provider.Push()
+}
+
+func (r *gitNippoQuery) Naming() (*model.NippoNaming, error) {
+	return nippoNaming()
+}
+
+// gitRelPath returns the path of a file within folder, a directory of the repository
+func gitRelPath(folder, filePath string) string {
+	folder = path.Clean(folder)
+	if folder == "." {
+		return filePath
+	}
+	return strings.TrimPrefix(filePath, folder+"/")
+}
+
+func newGitFile(file gateway.GitFile, h gateway.GitFileHistory) *drive.File {
+	name := path.Base(file.Path)
+	remoteFile := &drive.File{
+		Id:            file.Path,
+		Name:          name,
+		FileExtension: strings.TrimPrefix(path.Ext(name), "."),
+		MimeType:      "text/markdown",
+		Md5Checksum:   file.Blob,
+		Parents:       []string{path.Dir(file.Path)},
+	}
+	if !h.Created.IsZero() {
+		remoteFile.CreatedTime = h.Created.Format(time.RFC3339)
+		remoteFile.ModifiedTime = h.Modified.Format(time.RFC3339)
+	}
+	return remoteFile
+}
+
+// withHistoryTimes adds the created and updated times of file to content
+// when its front-matter is missing them
+func withHistoryTimes(content []byte, file *drive.File) ([]byte, error) {
+	created, err := time.Parse(time.RFC3339, file.CreatedTime)
+	if err != nil {
+		return content, nil
+	}
+	modified, _ := time.Parse(time.RFC3339, file.ModifiedTime)
+
+	fm, _, err := model.ParseFrontMatter(content)
+	if err != nil {
+		// Leave malformed front-matter for the build to report
+		return content, nil
+	}
+	var setCreated, setUpdated time.Time
+	if fm == nil || fm.Raw["created"] == nil {
+		setCreated = created
+	}
+	if modified.After(created) && (fm == nil || fm.Raw["updated"] == nil) {
+		setUpdated = modified
+	}
+	if setCreated.IsZero() && setUpdated.IsZero() {
+		return content, nil
+	}
+	if fm == nil {
+		// UpdateFrontMatter only adds created to content without front-matter
+		if content, err = model.UpdateFrontMatter(content, setCreated, time.Time{}, false); err != nil {
+			return nil, err
+		}
+		setCreated = time.Time{}
+		if setUpdated.IsZero() {
+			return content, nil
+		}
+	}
+	return model.UpdateFrontMatter(content, setCreated, setUpdated, false)
+}
+
+func hasHiddenSegment(filePath string) bool {
+	for _, segment := range strings.Split(filePath, "/") {
+		if isHiddenName(segment) {
+			return true
+		}
+	}
+	return false
+}
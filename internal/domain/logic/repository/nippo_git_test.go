@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c18t/nippo-cli/internal/adapter/gateway"
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/domain/model"
+	"github.com/c18t/nippo-cli/internal/domain/repository"
+	"github.com/samber/do/v2"
+	"google.golang.org/api/drive/v3"
+)
+
+type mockGitProvider struct {
+	files    []gateway.GitFile
+	history  map[string]gateway.GitFileHistory
+	contents map[string]string
+	commits  []string
+	pushes   int
+}
+
+func (m *mockGitProvider) ListFiles(dir string) ([]gateway.GitFile, error) {
+	var files []gateway.GitFile
+	for _, file := range m.files {
+		if file.Path == dir || strings.HasPrefix(file.Path, dir+"/") {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+func (m *mockGitProvider) History(dir string) (map[string]gateway.GitFileHistory, error) {
+	return m.history, nil
+}
+
+func (m *mockGitProvider) Read(filePath string) ([]byte, error) {
+	return []byte(m.contents[filePath]), nil
+}
+
+func (m *mockGitProvider) Commit(filePath string, content []byte, message string) error {
+	m.contents[filePath] = string(content)
+	m.commits = append(m.commits, message)
+	return nil
+}
+
+func (m *mockGitProvider) Push() error {
+	m.pushes++
+	return nil
+}
+
+var (
+	gitTestCreated  = time.Date(2024, 1, 15, 21, 0, 0, 0, time.UTC)
+	gitTestModified = time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)
+)
+
+func newTestGitNippoQuery() (repository.RemoteNippoQuery, *mockGitProvider) {
+	provider := &mockGitProvider{
+		files: []gateway.GitFile{
+			{Path: "nippo/2024-01-16.md", Blob: "b16"},
+			{Path: "nippo/2024/2024-01-15.md", Blob: "b15"},
+			{Path: "nippo/.drafts/2024-01-17.md", Blob: "b17"},
+			{Path: "nippo/notes.txt", Blob: "bnotes"},
+		},
+		history: map[string]gateway.GitFileHistory{
+			"nippo/2024/2024-01-15.md": {Created: gitTestCreated, Modified: gitTestModified},
+			"nippo/2024-01-16.md":      {Created: gitTestModified, Modified: gitTestModified},
+		},
+		contents: map[string]string{
+			"nippo/2024/2024-01-15.md": "# 15",
+			"nippo/2024-01-16.md":      "---\ncreated: 2024-01-16T08:00:00+09:00\n---\n\n# 16",
+		},
+	}
+	injector := do.New()
+	do.ProvideValue[gateway.GitProvider](injector, provider)
+	query, _ := NewGitNippoQuery(injector)
+	return query, provider
+}
+
+func TestGitNippoQuery_ListTree(t *testing.T) {
+	query, _ := newTestGitNippoQuery()
+
+	nippoList, folderIds, err := query.ListTree(&repository.QueryListParam{
+		Folders:        []string{"nippo"},
+		FileExtensions: []string{"md"},
+		OrderBy:        "name",
+	}, &repository.QueryListOption{Recursive: true, WithContent: true})
+	if err != nil {
+		t.Fatalf("ListTree() error = %v", err)
+	}
+	if len(nippoList) != 2 {
+		t.Fatalf("ListTree() returned %d nippo, want 2", len(nippoList))
+	}
+
+	file := nippoList[0].RemoteFile
+	if file.Id != "nippo/2024/2024-01-15.md" || file.Name != "2024-01-15.md" || file.Md5Checksum != "b15" {
+		t.Errorf("RemoteFile = %+v", file)
+	}
+	if file.CreatedTime != gitTestCreated.Format(time.RFC3339) || file.ModifiedTime != gitTestModified.Format(time.RFC3339) {
+		t.Errorf("times = %s, %s, want the commit history", file.CreatedTime, file.ModifiedTime)
+	}
+	if string(nippoList[0].Content) != "# 15" {
+		t.Errorf("Content = %q, want the raw content", nippoList[0].Content)
+	}
+	if len(folderIds) != 1 || folderIds[0] != "nippo" {
+		t.Errorf("folderIds = %v", folderIds)
+	}
+}
+
+func TestGitNippoQuery_ListFilters(t *testing.T) {
+	query, _ := newTestGitNippoQuery()
+
+	nippoList, err := query.List(&repository.QueryListParam{
+		Folders:        []string{"nippo"},
+		FileExtensions: []string{"md"},
+	}, &repository.QueryListOption{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(nippoList) != 1 || nippoList[0].RemoteFile.Name != "2024-01-16.md" {
+		t.Errorf("List() = %v, want only the top-level nippo", nippoList)
+	}
+
+	nippoList, _ = query.List(&repository.QueryListParam{
+		Folders:        []string{"nippo"},
+		FileExtensions: []string{"md"},
+		UpdatedAt:      gitTestModified.Add(-time.Minute),
+	}, &repository.QueryListOption{Recursive: true})
+	if len(nippoList) != 2 {
+		t.Errorf("List() with UpdatedAt = %d nippo, want 2", len(nippoList))
+	}
+	nippoList, _ = query.List(&repository.QueryListParam{
+		Folders:        []string{"nippo"},
+		FileExtensions: []string{"md"},
+		UpdatedAt:      gitTestModified,
+	}, &repository.QueryListOption{Recursive: true})
+	if len(nippoList) != 0 {
+		t.Errorf("List() with UpdatedAt = %d nippo, want 0", len(nippoList))
+	}
+}
+
+func TestGitNippoQuery_DownloadFillsTimesFromHistory(t *testing.T) {
+	query, _ := newTestGitNippoQuery()
+
+	nippo := &model.Nippo{RemoteFile: &drive.File{
+		Id:           "nippo/2024/2024-01-15.md",
+		CreatedTime:  gitTestCreated.Format(time.RFC3339),
+		ModifiedTime: gitTestModified.Format(time.RFC3339),
+	}}
+	if err := query.Download(nippo); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	fm, body, err := model.ParseFrontMatter(nippo.Content)
+	if err != nil || fm == nil {
+		t.Fatalf("ParseFrontMatter() = %v, %v", fm, err)
+	}
+	if !fm.Created.Equal(gitTestCreated) || !fm.Updated.Equal(gitTestModified) {
+		t.Errorf("front-matter = %v, %v, want the commit history", fm.Created, fm.Updated)
+	}
+	if !strings.Contains(string(body), "# 15") {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestGitNippoQuery_DownloadKeepsFrontMatter(t *testing.T) {
+	query, _ := newTestGitNippoQuery()
+
+	nippo := &model.Nippo{RemoteFile: &drive.File{
+		Id:           "nippo/2024-01-16.md",
+		CreatedTime:  gitTestModified.Format(time.RFC3339),
+		ModifiedTime: gitTestModified.Format(time.RFC3339),
+	}}
+	if err := query.Download(nippo); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if string(nippo.Content) != "---\ncreated: 2024-01-16T08:00:00+09:00\n---\n\n# 16" {
+		t.Errorf("Content = %q, want it unchanged", nippo.Content)
+	}
+}
+
+func TestGitNippoQuery_UpdateCommits(t *testing.T) {
+	query, provider := newTestGitNippoQuery()
+
+	nippo := &model.Nippo{RemoteFile: &drive.File{Id: "nippo/2024-01-16.md", Name: "2024-01-16.md", Md5Checksum: "b16"}}
+	if err := query.Update(nippo, []byte("# formatted")); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if provider.contents["nippo/2024-01-16.md"] != "# formatted" {
+		t.Errorf("content = %q", provider.contents["nippo/2024-01-16.md"])
+	}
+	if len(provider.commits) != 1 || provider.commits[0] != "Update 2024-01-16.md" {
+		t.Errorf("commits = %v", provider.commits)
+	}
+	if provider.pushes != 0 {
+		t.Error("Update() should leave the push to Flush()")
+	}
+	if err := query.Flush(); err != nil || provider.pushes != 1 {
+		t.Errorf("Flush() = %v with %d pushes, want one push", err, provider.pushes)
+	}
+}
+
+func TestGitNippoQuery_UpdateConflict(t *testing.T) {
+	query, provider := newTestGitNippoQuery()
+
+	nippo := &model.Nippo{RemoteFile: &drive.File{Id: "nippo/2024-01-16.md", Name: "2024-01-16.md", Md5Checksum: "stale"}}
+	if err := query.Update(nippo, []byte("# formatted")); !errors.Is(err, repository.ErrConflict) {
+		t.Errorf("Update() error = %v, want ErrConflict", err)
+	}
+	if len(provider.commits) != 0 {
+		t.Errorf("commits = %v, want none", provider.commits)
+	}
+}
+
+func TestGitNippoQuery_Changes(t *testing.T) {
+	query, _ := newTestGitNippoQuery()
+
+	if token, err := query.StartPageToken(); err != nil || token != "" {
+		t.Errorf("StartPageToken() = %q, %v, want no token", token, err)
+	}
+	if _, err := query.ListChanges("token", nil, &repository.QueryListParam{}); !errors.Is(err, repository.ErrFullSyncRequired) {
+		t.Errorf("ListChanges() error = %v, want ErrFullSyncRequired", err)
+	}
+}
+
+func TestNewRemoteNippoQuery_GitSource(t *testing.T) {
+	saved := core.Cfg
+	t.Cleanup(func() { core.Cfg = saved })
+	core.Cfg = &core.Config{}
+	core.Cfg.Source.Type = core.SourceTypeGit
+
+	injector := do.New()
+	do.ProvideValue[gateway.GitProvider](injector, &mockGitProvider{})
+	query, err := NewRemoteNippoQuery(injector)
+	if err != nil {
+		t.Fatalf("NewRemoteNippoQuery() error = %v", err)
+	}
+	if _, ok := query.(*gitNippoQuery); !ok {
+		t.Errorf("NewRemoteNippoQuery() = %T, want *gitNippoQuery", query)
+	}
+}
@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/c18t/nippo-cli/internal/adapter/gateway"
@@ -20,6 +23,9 @@ type mockDriveFileProvider struct {
 	content   []byte
 	downloadErr error
 	updateErr error
+	changePages map[string]*drive.ChangeList
+	changesErr  error
+	created     []*drive.File
 }
 
 func (m *mockDriveFileProvider) List(param *repository.QueryListParam) (*drive.FileList, error) {
@@ -29,6 +35,15 @@ func (m *mockDriveFileProvider) List(param *repository.QueryListParam) (*drive.F
 	return &drive.FileList{Files: m.files}, nil
 }
 
+// Create adds the file to files, so later calls can list it
+func (m *mockDriveFileProvider) Create(file *drive.File, content []byte) (*drive.File, error) {
+	created := *file
+	created.Id = fmt.Sprintf("created-%d", len(m.created)+1)
+	m.created = append(m.created, &created)
+	m.files = append(m.files, &created)
+	return &created, nil
+}
+
 func (m *mockDriveFileProvider) Download(id string) ([]byte, error) {
 	if m.downloadErr != nil {
 		return nil, m.downloadErr
@@ -36,10 +51,33 @@ func (m *mockDriveFileProvider) Download(id string) ([]byte, error) {
 	return m.content, nil
 }
 
+// Get returns the listed file of fileId, or a file without any revision
+func (m *mockDriveFileProvider) Get(fileId string) (*drive.File, error) {
+	for _, file := range m.files {
+		if file.Id == fileId {
+			return file, nil
+		}
+	}
+	return &drive.File{Id: fileId}, nil
+}
+
 func (m *mockDriveFileProvider) Update(fileId string, content []byte) error {
 	return m.updateErr
 }
 
+func (m *mockDriveFileProvider) GetStartPageToken() (string, error) { return "start-token", nil }
+
+func (m *mockDriveFileProvider) ListChanges(pageToken string) (*drive.ChangeList, error) {
+	if m.changesErr != nil {
+		return nil, m.changesErr
+	}
+	return m.changePages[pageToken], nil
+}
+
+func (m *mockDriveFileProvider) OnRetry(notify gateway.RetryNotifier) {}
+
+func (m *mockDriveFileProvider) RequireAccess(access gateway.DriveAccess) {}
+
 func (m *mockDriveFileProvider) Shutdown() error { return nil }
 func (m *mockDriveFileProvider) HealthCheck() error { return nil }
 
@@ -159,6 +197,36 @@ func TestRemoteNippoQuery_Update(t *testing.T) {
 	}
 }
 
+func TestRemoteNippoQuery_UpdateConflict(t *testing.T) {
+	tests := []struct {
+		name     string
+		fetched  *drive.File
+		current  *drive.File
+		conflict bool
+	}{
+		{"same checksum", &drive.File{Id: "1", Md5Checksum: "abc", Version: 1}, &drive.File{Id: "1", Md5Checksum: "abc", Version: 2}, false},
+		{"changed checksum", &drive.File{Id: "1", Md5Checksum: "abc"}, &drive.File{Id: "1", Md5Checksum: "def"}, true},
+		{"changed version", &drive.File{Id: "1", Version: 1}, &drive.File{Id: "1", Version: 2}, true},
+		{"changed modified time", &drive.File{Id: "1", ModifiedTime: "2024-01-15T10:00:00Z"}, &drive.File{Id: "1", ModifiedTime: "2024-01-15T11:00:00Z"}, true},
+		{"trashed", &drive.File{Id: "1", Md5Checksum: "abc"}, &drive.File{Id: "1", Md5Checksum: "abc", Trashed: true}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockDriveFileProvider{files: []*drive.File{tt.current}}
+			injector := do.New()
+			do.Provide(injector, func(_ do.Injector) (gateway.DriveFileProvider, error) {
+				return mock, nil
+			})
+			query, _ := NewRemoteNippoQuery(injector)
+
+			err := query.Update(&model.Nippo{RemoteFile: tt.fetched}, []byte("content"))
+			if got := errors.Is(err, repository.ErrConflict); got != tt.conflict {
+				t.Errorf("Update() error = %v, want conflict %v", err, tt.conflict)
+			}
+		})
+	}
+}
+
 func TestNewLocalNippoQuery(t *testing.T) {
 	mock := &mockLocalFileProvider{}
 	injector := do.New()
@@ -176,6 +244,18 @@ func TestNewLocalNippoQuery(t *testing.T) {
 }
 
 func TestLocalNippoQuery_Exist(t *testing.T) {
+	tmpDir := t.TempDir()
+	core.Cfg = &core.Config{}
+	core.Cfg.Paths.CacheDir = tmpDir
+
+	mdDir := filepath.Join(tmpDir, "md")
+	if err := os.MkdirAll(mdDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mdDir, "2024-01-15.md"), []byte("test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
 	mock := &mockLocalFileProvider{}
 	injector := do.New()
 	do.Provide(injector, func(_ do.Injector) (gateway.LocalFileProvider, error) {
@@ -183,11 +263,15 @@ func TestLocalNippoQuery_Exist(t *testing.T) {
 	})
 
 	query, _ := NewLocalNippoQuery(injector)
-	date := model.NewNippoDate("2024-01-15.md")
 
-	result := query.Exist(&date)
-	if !result {
-		t.Error("Exist() should return true")
+	cached := model.NewNippoDate("2024-01-15.md")
+	if !query.Exist(&cached) {
+		t.Error("Exist() should return true for a cached nippo")
+	}
+
+	missing := model.NewNippoDate("2024-01-16.md")
+	if query.Exist(&missing) {
+		t.Error("Exist() should return false for a nippo that is not cached")
 	}
 }
 
@@ -252,6 +336,10 @@ func TestLocalNippoCommand_Create(t *testing.T) {
 }
 
 func TestLocalNippoCommand_Delete(t *testing.T) {
+	tmpDir := t.TempDir()
+	core.Cfg = &core.Config{}
+	core.Cfg.Paths.CacheDir = tmpDir
+
 	mock := &mockLocalFileProvider{}
 	injector := do.New()
 	do.Provide(injector, func(_ do.Injector) (gateway.LocalFileProvider, error) {
@@ -260,11 +348,26 @@ func TestLocalNippoCommand_Delete(t *testing.T) {
 
 	cmd, _ := NewLocalNippoCommand(injector)
 
-	nippo := &model.Nippo{}
-	err := cmd.Delete(nippo)
-	if err != nil {
+	nippo := &model.Nippo{
+		Date:    model.NewNippoDate("2024-01-15.md"),
+		Content: []byte("test content"),
+	}
+	if err := cmd.Create(nippo); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	filePath := nippo.FilePath
+
+	if err := cmd.Delete(nippo); err != nil {
 		t.Errorf("Delete() error = %v", err)
 	}
+	if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+		t.Errorf("cached file should be removed, stat error = %v", err)
+	}
+
+	// Deleting by date when the file is already gone is not an error
+	if err := cmd.Delete(&model.Nippo{Date: model.NewNippoDate("2024-01-15.md")}); err != nil {
+		t.Errorf("Delete() of missing file error = %v", err)
+	}
 }
 
 func TestRemoteNippoQuery_ListWithFolders(t *testing.T) {
@@ -531,3 +634,252 @@ func TestRemoteNippoQuery_ListWithContent(t *testing.T) {
 		t.Errorf("Content = %q, want %q", string(result[0].Content), "downloaded content")
 	}
 }
+
+// treeDriveFileProvider lists the children of the requested folders
+type treeDriveFileProvider struct {
+	mockDriveFileProvider
+	children map[string][]*drive.File
+}
+
+func (m *treeDriveFileProvider) List(param *repository.QueryListParam) (*drive.FileList, error) {
+	var files []*drive.File
+	for _, folder := range param.Folders {
+		files = append(files, m.children[folder]...)
+	}
+	return &drive.FileList{Files: files}, nil
+}
+
+func newRemoteNippoQueryFor(provider gateway.DriveFileProvider) repository.RemoteNippoQuery {
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (gateway.DriveFileProvider, error) {
+		return provider, nil
+	})
+	query, _ := NewRemoteNippoQuery(injector)
+	return query
+}
+
+func TestRemoteNippoQuery_ListTree(t *testing.T) {
+	provider := &treeDriveFileProvider{children: map[string][]*drive.File{
+		"root": {
+			{Id: "2024", Name: "2024", MimeType: gateway.DriveFolderMimeType},
+		},
+		"2024": {
+			{Id: "01", Name: "01", MimeType: gateway.DriveFolderMimeType},
+			{Id: "02", Name: "02", MimeType: gateway.DriveFolderMimeType},
+		},
+		"01": {{Id: "f1", Name: "2024-01-15.md"}},
+		"02": {{Id: "f2", Name: "2024-02-15.md"}},
+	}}
+	query := newRemoteNippoQueryFor(provider)
+
+	nippoList, folderIds, err := query.ListTree(&repository.QueryListParam{
+		Folders: []string{"root"},
+	}, &repository.QueryListOption{Recursive: true})
+	if err != nil {
+		t.Fatalf("ListTree() error = %v", err)
+	}
+	if len(nippoList) != 2 {
+		t.Errorf("ListTree() returned %d nippo, want 2", len(nippoList))
+	}
+	if strings.Join(folderIds, ",") != "root,2024,01,02" {
+		t.Errorf("ListTree() folders = %v, want [root 2024 01 02]", folderIds)
+	}
+}
+
+func TestRemoteNippoQuery_ListChanges(t *testing.T) {
+	provider := &mockDriveFileProvider{changePages: map[string]*drive.ChangeList{
+		"10": {
+			NextPageToken: "11",
+			Changes: []*drive.Change{
+				// A file in a folder that is created later in the log
+				{FileId: "new", File: &drive.File{Id: "new", Name: "2024-03-01.md", FileExtension: "md", Parents: []string{"03"}}},
+				{FileId: "edited", File: &drive.File{Id: "edited", Name: "2024-01-15.md", FileExtension: "md", Parents: []string{"01"}}},
+				{FileId: "other", File: &drive.File{Id: "other", Name: "notes.md", FileExtension: "md", Parents: []string{"elsewhere"}}},
+			},
+		},
+		"11": {
+			NewStartPageToken: "12",
+			Changes: []*drive.Change{
+				{FileId: "03", File: &drive.File{Id: "03", Name: "03", MimeType: gateway.DriveFolderMimeType, Parents: []string{"2024"}}},
+				{FileId: "gone", Removed: true},
+				{FileId: "trashed", File: &drive.File{Id: "trashed", Name: "2024-01-10.md", FileExtension: "md", Parents: []string{"01"}, Trashed: true}},
+				{FileId: "text", File: &drive.File{Id: "text", Name: "2024-01-11.txt", FileExtension: "txt", Parents: []string{"01"}}},
+			},
+		},
+	}}
+	query := newRemoteNippoQueryFor(provider)
+
+	changes, err := query.ListChanges("10", []string{"root", "2024", "01"}, &repository.QueryListParam{
+		Folders:        []string{"root"},
+		FileExtensions: []string{"md"},
+	})
+	if err != nil {
+		t.Fatalf("ListChanges() error = %v", err)
+	}
+
+	var changed []string
+	for _, nippo := range changes.Changed {
+		changed = append(changed, nippo.RemoteFile.Id)
+	}
+	if strings.Join(changed, ",") != "edited,new" {
+		t.Errorf("Changed = %v, want [edited new]", changed)
+	}
+	if strings.Join(changes.Removed, ",") != "gone,other,text,trashed" {
+		t.Errorf("Removed = %v, want [gone other text trashed]", changes.Removed)
+	}
+	if strings.Join(changes.FolderIds, ",") != "01,03,2024,root" {
+		t.Errorf("FolderIds = %v", changes.FolderIds)
+	}
+	if changes.PageToken != "12" {
+		t.Errorf("PageToken = %q, want 12", changes.PageToken)
+	}
+}
+
+func TestRemoteNippoQuery_ListChangesFolderRemoved(t *testing.T) {
+	tests := []struct {
+		name   string
+		change *drive.Change
+	}{
+		{"removed", &drive.Change{FileId: "01", Removed: true}},
+		{"trashed", &drive.Change{FileId: "01", File: &drive.File{Id: "01", MimeType: gateway.DriveFolderMimeType, Parents: []string{"2024"}, Trashed: true}}},
+		{"moved out", &drive.Change{FileId: "01", File: &drive.File{Id: "01", MimeType: gateway.DriveFolderMimeType, Parents: []string{"elsewhere"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &mockDriveFileProvider{changePages: map[string]*drive.ChangeList{
+				"10": {NewStartPageToken: "11", Changes: []*drive.Change{tt.change}},
+			}}
+			query := newRemoteNippoQueryFor(provider)
+
+			_, err := query.ListChanges("10", []string{"root", "2024", "01"}, &repository.QueryListParam{
+				Folders: []string{"root"},
+			})
+			if !errors.Is(err, repository.ErrFullSyncRequired) {
+				t.Errorf("ListChanges() error = %v, want ErrFullSyncRequired", err)
+			}
+		})
+	}
+}
+
+func TestRemoteNippoQuery_Create(t *testing.T) {
+	mock := &mockDriveFileProvider{files: []*drive.File{
+		{Id: "year", Name: "2024", MimeType: gateway.DriveFolderMimeType, Parents: []string{"root"}},
+		// A file of the same name in another folder does not count
+		{Id: "other", Name: "2024-01-15.md", Parents: []string{"elsewhere"}},
+	}}
+	query := &remoteNippoQuery{provider: mock}
+
+	nippo := &model.Nippo{Date: model.NewNippoDate("2024-01-15.md"), Content: []byte("# nippo")}
+	if err := query.Create("root", "2024/01/2024-01-15.md", nippo); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if len(mock.created) != 2 {
+		t.Fatalf("created = %d files, want the month folder and the nippo", len(mock.created))
+	}
+	month, file := mock.created[0], mock.created[1]
+	if month.Name != "01" || month.MimeType != gateway.DriveFolderMimeType || month.Parents[0] != "year" {
+		t.Errorf("folder = %+v, want 01 inside the existing year folder", month)
+	}
+	if file.Name != "2024-01-15.md" || file.Parents[0] != month.Id {
+		t.Errorf("file = %+v, want 2024-01-15.md inside the month folder", file)
+	}
+	if nippo.RemoteFile != file {
+		t.Errorf("RemoteFile = %+v, want the created file", nippo.RemoteFile)
+	}
+
+	err := query.Create("root", "2024/01/2024-01-15.md", &model.Nippo{Date: model.NewNippoDate("2024-01-15.md")})
+	if !errors.Is(err, repository.ErrNippoExists) {
+		t.Errorf("Create() error = %v, want ErrNippoExists", err)
+	}
+	if len(mock.created) != 2 {
+		t.Errorf("created = %d files, want nothing more", len(mock.created))
+	}
+}
+
+func TestRemoteNippoQuery_ListTreeFolderPattern(t *testing.T) {
+	saved := core.Cfg
+	t.Cleanup(func() { core.Cfg = saved })
+	core.Cfg = &core.Config{}
+	core.Cfg.Source.NamePatterns = []string{"YYYY/MM/DD"}
+
+	provider := &treeDriveFileProvider{children: map[string][]*drive.File{
+		"root": {
+			{Id: "2024", Name: "2024", MimeType: gateway.DriveFolderMimeType, Parents: []string{"root"}},
+		},
+		"2024": {
+			{Id: "01", Name: "01", MimeType: gateway.DriveFolderMimeType, Parents: []string{"2024"}},
+		},
+		"01": {
+			{Id: "f1", Name: "15.md", Parents: []string{"01"}},
+			{Id: "f2", Name: "notes.md", Parents: []string{"01"}},
+		},
+	}}
+	query := newRemoteNippoQueryFor(provider)
+
+	nippoList, _, err := query.ListTree(&repository.QueryListParam{
+		Folders: []string{"root"},
+	}, &repository.QueryListOption{Recursive: true})
+	if err != nil {
+		t.Fatalf("ListTree() error = %v", err)
+	}
+	if len(nippoList) != 2 {
+		t.Fatalf("ListTree() returned %d nippo, want 2", len(nippoList))
+	}
+	if nippoList[0].Date == nil || nippoList[0].Date.FileString() != "2024-01-15" {
+		t.Errorf("Date of 2024/01/15.md = %v, want 2024-01-15", nippoList[0].Date)
+	}
+	if nippoList[1].Date != nil {
+		t.Errorf("Date of notes.md = %v, want nil", nippoList[1].Date)
+	}
+}
+
+func TestRemoteNippoQuery_ListChangesFolderPattern(t *testing.T) {
+	saved := core.Cfg
+	t.Cleanup(func() { core.Cfg = saved })
+	core.Cfg = &core.Config{}
+	core.Cfg.Source.NamePatterns = []string{"YYYY/MM/DD"}
+
+	provider := &mockDriveFileProvider{
+		files: []*drive.File{
+			{Id: "2024", Name: "2024", MimeType: gateway.DriveFolderMimeType, Parents: []string{"root"}},
+			{Id: "01", Name: "01", MimeType: gateway.DriveFolderMimeType, Parents: []string{"2024"}},
+		},
+		changePages: map[string]*drive.ChangeList{
+			"10": {
+				NewStartPageToken: "11",
+				Changes: []*drive.Change{
+					{FileId: "f1", File: &drive.File{Id: "f1", Name: "15.md", FileExtension: "md", Parents: []string{"01"}}},
+				},
+			},
+		},
+	}
+	query := newRemoteNippoQueryFor(provider)
+
+	changes, err := query.ListChanges("10", []string{"root", "2024", "01"}, &repository.QueryListParam{
+		Folders:        []string{"root"},
+		FileExtensions: []string{"md"},
+	})
+	if err != nil {
+		t.Fatalf("ListChanges() error = %v", err)
+	}
+	if len(changes.Changed) != 1 {
+		t.Fatalf("Changed = %v, want 1 nippo", changes.Changed)
+	}
+	if date := changes.Changed[0].Date; date == nil || date.FileString() != "2024-01-15" {
+		t.Errorf("Date = %v, want 2024-01-15", date)
+	}
+}
+
+func TestRemoteNippoQuery_ListInvalidNamePatterns(t *testing.T) {
+	saved := core.Cfg
+	t.Cleanup(func() { core.Cfg = saved })
+	core.Cfg = &core.Config{}
+	core.Cfg.Source.NamePatterns = []string{"YYYY-MM"}
+
+	query := newRemoteNippoQueryFor(&mockDriveFileProvider{})
+	_, _, err := query.ListTree(&repository.QueryListParam{Folders: []string{"root"}}, &repository.QueryListOption{})
+	if err == nil || !strings.Contains(err.Error(), "name_patterns") {
+		t.Errorf("ListTree() error = %v, want an invalid name_patterns error", err)
+	}
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/domain/model"
+	i "github.com/c18t/nippo-cli/internal/domain/repository"
+	"github.com/samber/do/v2"
+)
+
+const syncManifestFileName = "sync-manifest.json"
+
+type syncManifestRepository struct{}
+
+func NewSyncManifestRepository(_ do.Injector) (i.SyncManifestRepository, error) {
+	return &syncManifestRepository{}, nil
+}
+
+func syncManifestPath() string {
+	return filepath.Join(core.Cfg.GetCacheDir(), syncManifestFileName)
+}
+
+// Load reads the manifest from the cache dir.
+// A missing manifest is not an error; an empty one is returned instead.
+func (r *syncManifestRepository) Load() (*model.SyncManifest, error) {
+	b, err := os.ReadFile(syncManifestPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return model.NewSyncManifest(), nil
+		}
+		return nil, fmt.Errorf("unable to read sync manifest: %w", err)
+	}
+
+	manifest := model.NewSyncManifest()
+	if err := json.Unmarshal(b, manifest); err != nil {
+		return nil, fmt.Errorf("unable to parse sync manifest: %w", err)
+	}
+	if manifest.Files == nil {
+		manifest.Files = map[string]model.SyncManifestEntry{}
+	}
+	return manifest, nil
+}
+
+// Save writes the manifest to a temporary file and renames it into place,
+// so an interrupted build never leaves a truncated manifest behind.
+func (r *syncManifestRepository) Save(manifest *model.SyncManifest) error {
+	return saveManifest(syncManifestPath(), manifest)
+}
+
+// saveManifest writes v as JSON to path through a temporary file
+func saveManifest(path string, v any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/samber/do/v2"
+	"google.golang.org/api/drive/v3"
+)
+
+func TestSyncManifestRepository_LoadMissing(t *testing.T) {
+	core.Cfg = &core.Config{}
+	core.Cfg.Paths.CacheDir = t.TempDir()
+
+	repo, _ := NewSyncManifestRepository(do.New())
+	manifest, err := repo.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(manifest.Files) != 0 {
+		t.Errorf("Load() returned %d entries, want 0", len(manifest.Files))
+	}
+}
+
+func TestSyncManifestRepository_SaveAndLoad(t *testing.T) {
+	tmpDir := t.TempDir()
+	core.Cfg = &core.Config{}
+	core.Cfg.Paths.CacheDir = tmpDir
+
+	repo, _ := NewSyncManifestRepository(do.New())
+	manifest, _ := repo.Load()
+	manifest.Record(&drive.File{
+		Id:           "file1",
+		Name:         "2024-01-15.md",
+		Md5Checksum:  "abc",
+		ModifiedTime: "2024-01-15T10:00:00Z",
+	}, "2024-01-15.md")
+
+	if err := repo.Save(manifest); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 1 || entries[0].Name() != syncManifestFileName {
+		t.Errorf("cache dir should only contain the manifest, got %v", entries)
+	}
+
+	loaded, err := repo.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	entry, ok := loaded.Files["file1"]
+	if !ok {
+		t.Fatal("Load() lost entry file1")
+	}
+	if entry.Name != "2024-01-15.md" || entry.Md5Checksum != "abc" || entry.CachePath != "2024-01-15.md" {
+		t.Errorf("Load() entry = %+v", entry)
+	}
+}
+
+func TestSyncManifestRepository_LoadCorrupt(t *testing.T) {
+	tmpDir := t.TempDir()
+	core.Cfg = &core.Config{}
+	core.Cfg.Paths.CacheDir = tmpDir
+
+	if err := os.WriteFile(filepath.Join(tmpDir, syncManifestFileName), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, _ := NewSyncManifestRepository(do.New())
+	if _, err := repo.Load(); err == nil {
+		t.Error("Load() should fail on a corrupt manifest")
+	}
+}
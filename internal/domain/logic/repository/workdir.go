@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/domain/model"
+	i "github.com/c18t/nippo-cli/internal/domain/repository"
+	"github.com/samber/do/v2"
+)
+
+const workdirStateFileName = ".nippo-pull.json"
+
+type workdirRepository struct{}
+
+func NewWorkdirRepository(_ do.Injector) (i.WorkdirRepository, error) {
+	return &workdirRepository{}, nil
+}
+
+// LoadState reads the state of dir.
+// A directory that was never pulled into is not an error; an empty state is returned instead.
+func (r *workdirRepository) LoadState(dir string) (*model.WorkdirState, error) {
+	b, err := os.ReadFile(filepath.Join(dir, workdirStateFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return model.NewWorkdirState(), nil
+		}
+		return nil, fmt.Errorf("unable to read working directory state: %w", err)
+	}
+
+	state := model.NewWorkdirState()
+	if err := json.Unmarshal(b, state); err != nil {
+		return nil, fmt.Errorf("unable to parse working directory state: %w", err)
+	}
+	if state.Files == nil {
+		state.Files = map[string]model.WorkdirEntry{}
+	}
+	return state, nil
+}
+
+// SaveState writes the state to a temporary file and renames it into place,
+// so an interrupted pull never leaves a truncated state behind.
+func (r *workdirRepository) SaveState(dir string, state *model.WorkdirState) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, workdirStateFileName+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, workdirStateFileName))
+}
+
+// List returns the Markdown files in the folder tree of dir, named by their
+// slash-separated path within dir. Hidden files and folders are skipped. A
+// missing dir has no files.
+func (r *workdirRepository) List(dir string) ([]model.WorkdirFile, error) {
+	var files []model.WorkdirFile
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && os.IsNotExist(err) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		if isHiddenName(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !strings.HasSuffix(d.Name(), ".md") {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		content, err := readWorkdirFile(dir, rel)
+		if err != nil {
+			return err
+		}
+		files = append(files, model.WorkdirFile{Name: filepath.ToSlash(rel), Content: content})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read working directory: %w", err)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	return files, nil
+}
+
+func readWorkdirFile(dir, name string) ([]byte, error) {
+	f, err := core.SafeOpen(dir, filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return io.ReadAll(f)
+}
+
+// Write writes file, creating the folders of its path
+func (r *workdirRepository) Write(dir string, file *model.WorkdirFile) error {
+	path := filepath.Join(dir, filepath.FromSlash(file.Name))
+	if !core.IsPathSafe(dir, path) {
+		return fmt.Errorf("path traversal detected: %s is outside %s", file.Name, dir)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, file.Content, 0644)
+}
+
+// Remove removes the file name, and the folders of its path it leaves empty
+func (r *workdirRepository) Remove(dir, name string) error {
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if !core.IsPathSafe(dir, path) {
+		return fmt.Errorf("path traversal detected: %s is outside %s", name, dir)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	// Removing a folder that still has files fails, which ends the walk up
+	for folder := filepath.Dir(path); folder != filepath.Clean(dir) && core.IsPathSafe(dir, folder); folder = filepath.Dir(folder) {
+		if os.Remove(folder) != nil {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/c18t/nippo-cli/internal/domain/model"
+	"github.com/samber/do/v2"
+	"google.golang.org/api/drive/v3"
+)
+
+func TestWorkdirRepository_State(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nippo")
+	repo, _ := NewWorkdirRepository(do.New())
+
+	state, err := repo.LoadState(dir)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	if len(state.Files) != 0 {
+		t.Errorf("LoadState() returned %d entries, want 0", len(state.Files))
+	}
+
+	state.Record("2024-01-15.md", &drive.File{Id: "file1", Name: "2024-01-15.md", Md5Checksum: "abc", Version: 2}, "abc")
+	if err := repo.SaveState(dir, state); err != nil {
+		t.Fatalf("SaveState() error = %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != workdirStateFileName {
+		t.Errorf("working directory should only contain the state, got %v", entries)
+	}
+
+	loaded, err := repo.LoadState(dir)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	want := model.WorkdirEntry{Id: "file1", Md5Checksum: "abc", Version: 2, LocalMd5: "abc"}
+	if got := loaded.Files["2024-01-15.md"]; got != want {
+		t.Errorf("LoadState() entry = %+v, want %+v", got, want)
+	}
+}
+
+func TestWorkdirRepository_Files(t *testing.T) {
+	dir := t.TempDir()
+	repo, _ := NewWorkdirRepository(do.New())
+
+	files, err := repo.List(filepath.Join(dir, "missing"))
+	if err != nil || len(files) != 0 {
+		t.Errorf("List() = %v, %v for a missing directory", files, err)
+	}
+
+	for _, name := range []string{"2024/01/16.md", "2024-01-15.md"} {
+		if err := repo.Write(dir, &model.WorkdirFile{Name: name, Content: []byte(name)}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	_ = os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("skip"), 0644)
+	_ = os.Mkdir(filepath.Join(dir, "2024.md"), 0755)
+	_ = os.Mkdir(filepath.Join(dir, ".git"), 0755)
+	_ = os.WriteFile(filepath.Join(dir, ".git", "hidden.md"), []byte("skip"), 0644)
+
+	files, err = repo.List(dir)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(files) != 2 || files[0].Name != "2024-01-15.md" || files[1].Name != "2024/01/16.md" || string(files[1].Content) != "2024/01/16.md" {
+		t.Errorf("List() = %v, want the two nippo sorted by path", files)
+	}
+
+	if err := repo.Write(dir, &model.WorkdirFile{Name: "../escape.md"}); err == nil {
+		t.Error("Write() expected error for a name outside the directory")
+	}
+
+	if err := repo.Remove(dir, "2024-01-15.md"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if err := repo.Remove(dir, "2024-01-15.md"); err != nil {
+		t.Errorf("Remove() error = %v for a missing file", err)
+	}
+	if files, _ := repo.List(dir); len(files) != 1 {
+		t.Errorf("List() after Remove() = %v", files)
+	}
+
+	// The folders a removed file leaves empty go with it
+	if err := repo.Remove(dir, "2024/01/16.md"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2024")); !os.IsNotExist(err) {
+		t.Errorf("folder 2024 should be removed, got %v", err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("the working directory should be kept, got %v", err)
+	}
+}
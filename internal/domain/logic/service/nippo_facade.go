@@ -1,18 +1,26 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
 
+	"github.com/c18t/nippo-cli/internal/core"
 	"github.com/c18t/nippo-cli/internal/domain/model"
 	"github.com/c18t/nippo-cli/internal/domain/repository"
 	ds "github.com/c18t/nippo-cli/internal/domain/service"
 	"github.com/samber/do/v2"
+	"google.golang.org/api/drive/v3"
 )
 
 type nippoFacade struct {
-	remoteQuery  repository.RemoteNippoQuery  `do:""`
-	localQuery   repository.LocalNippoQuery   `do:""`
-	localCommand repository.LocalNippoCommand `do:""`
+	remoteQuery  repository.RemoteNippoQuery       `do:""`
+	localQuery   repository.LocalNippoQuery        `do:""`
+	localCommand repository.LocalNippoCommand      `do:""`
+	manifest     repository.SyncManifestRepository `do:""`
 }
 
 func NewNippoFacade(injector do.Injector) (ds.NippoFacade, error) {
@@ -28,63 +36,391 @@ func NewNippoFacade(injector do.Injector) (ds.NippoFacade, error) {
 	if err != nil {
 		return nil, err
 	}
+	manifest, err := do.Invoke[repository.SyncManifestRepository](injector)
+	if err != nil {
+		return nil, err
+	}
 	return &nippoFacade{
 		remoteQuery:  remoteQuery,
 		localQuery:   localQuery,
 		localCommand: localCommand,
+		manifest:     manifest,
 	}, nil
 }
 
 func (s *nippoFacade) Send(request *ds.NippoFacadeRequest, option *ds.NippoFacadeOption) (*ds.NippoFacadeReponse, error) {
+	syncAction := ds.NippoFacadeAction(ds.NippoFacadeActionDownload | ds.NippoFacadeActionCache)
+	if request.Action&syncAction == syncAction {
+		return s.sync(request, option)
+	}
+
 	remoteFiles, err := s.remoteQuery.List(request.Query, request.Option)
 	if err != nil {
 		return nil, err
 	}
 
-	nippoList := make([]model.Nippo, len(remoteFiles))
-	total := len(remoteFiles)
+	targets := request.Content
+	var steps []func(*model.Nippo) error
+	if request.Action&ds.NippoFacadeActionDownload != 0 {
+		targets = remoteFiles
+		steps = append(steps, s.remoteQuery.Download)
+	}
+	if request.Action&ds.NippoFacadeActionCache != 0 {
+		steps = append(steps, s.localCommand.Create)
+	}
+
+	nippoList := targets
+	if len(steps) > 0 {
+		nippoList, _, err = s.process(targets, steps, option)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	if request.Action&ds.NippoFacadeActionDownload != 0 {
-		for i, nippo := range remoteFiles {
-			// Report progress via callback if provided
-			if option != nil && option.OnProgress != nil {
-				if !option.OnProgress(nippo.RemoteFile.Name, nippo.RemoteFile.Id, i+1, total) {
-					return nil, ds.ErrCancelled
-				}
-			}
-			err = s.remoteQuery.Download(&nippo)
-			if err != nil {
-				// Progress callback can handle error display if needed
-				// For now, continue processing other files
-				_ = err
+		sortByName(nippoList)
+	}
+
+	count := len(nippoList)
+	return &ds.NippoFacadeReponse{
+		Result: &ds.NippoFacadeResponseResult{
+			Action:  request.Action,
+			Count:   count,
+			Message: fmt.Sprintf("%d files downloaded.", len(nippoList)),
+		},
+		Content: nippoList,
+	}, nil
+}
+
+// sync downloads remote files that changed since the last run and removes cached
+// files whose remote counterpart was deleted or renamed, then saves the manifest.
+func (s *nippoFacade) sync(request *ds.NippoFacadeRequest, option *ds.NippoFacadeOption) (*ds.NippoFacadeReponse, error) {
+	manifest, err := s.manifest.Load()
+	if err != nil {
+		return nil, err
+	}
+	untracked := len(manifest.Files) == 0
+
+	remoteFiles, err := s.listRemote(request, option, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*drive.File, len(remoteFiles))
+	var targets []model.Nippo
+	var pending []model.SyncChange
+	for i := range remoteFiles {
+		nippo := remoteFiles[i]
+		files[i] = nippo.RemoteFile
+		changeType, changed := manifest.Compare(nippo.RemoteFile)
+		if entry := manifest.Files[nippo.RemoteFile.Id]; !changed && !entry.IsSkipped() {
+			if date := entry.CachedDate(); !s.localQuery.Exist(&date) {
+				// The cached copy was removed outside of nippo, fetch it again
+				changeType, changed = model.SyncChangeUpdated, true
 			}
-			if len(request.Content) <= i {
-				request.Content = append(request.Content, model.Nippo{})
+		}
+		if !changed {
+			continue
+		}
+		change := model.SyncChange{Type: changeType, FileId: nippo.RemoteFile.Id, Name: nippo.RemoteFile.Name}
+		if changeType == model.SyncChangeRenamed {
+			change.OldName = manifest.Files[nippo.RemoteFile.Id].Name
+		}
+		targets = append(targets, nippo)
+		pending = append(pending, change)
+	}
+
+	var changes, failed []model.SyncChange
+
+	// Remove stale cached files before writing new ones, so a file renamed onto
+	// the date of a deleted file is not removed right after being downloaded.
+	for _, id := range manifest.Missing(files) {
+		entry := manifest.Files[id]
+		if entry.IsSkipped() {
+			manifest.Forget(id)
+			continue
+		}
+		change := model.SyncChange{Type: model.SyncChangeDeleted, FileId: id, Name: entry.Name}
+		if err := s.localCommand.Delete(&model.Nippo{FilePath: entry.CachePath}); err != nil {
+			failed = append(failed, change)
+			continue
+		}
+		manifest.Forget(id)
+		changes = append(changes, change)
+	}
+	// A file whose old cached copy can't be removed is not downloaded under its
+	// new name, and keeps its record to be renamed again on the next sync
+	var keptTargets []model.Nippo
+	var keptPending []model.SyncChange
+	for i, change := range pending {
+		entry := manifest.Files[change.FileId]
+		if change.Type == model.SyncChangeRenamed && !entry.IsSkipped() {
+			if err := s.localCommand.Delete(&model.Nippo{FilePath: entry.CachePath}); err != nil {
+				failed = append(failed, change)
+				continue
 			}
-			request.Content[i] = model.Nippo(nippo)
+			// Until the new name is downloaded the file is unknown to the cache
+			manifest.Forget(change.FileId)
 		}
+		keptTargets = append(keptTargets, targets[i])
+		keptPending = append(keptPending, change)
+	}
+	targets, pending = keptTargets, keptPending
+	if untracked {
+		// Without a manifest, cached files may predate it and can't be matched
+		// to remote files by ID, so drop the ones no remote file maps to.
+		stale, err := s.untrackedCache(request.Content, remoteFiles)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, stale...)
 	}
 
-	if request.Action&ds.NippoFacadeActionCache != 0 {
-		for i, nippo := range request.Content {
-			err = s.localCommand.Create(&nippo)
-			if err != nil {
-				// Continue processing other files on cache error
-				_ = err
+	// The date in the front-matter takes precedence over the one in the file name
+	loc := time.Local
+	if option != nil && option.Location != nil {
+		loc = option.Location
+	}
+	resolveDate := func(nippo *model.Nippo) error { return nippo.ResolveDate(loc) }
+	claims := newCacheClaims(manifest)
+	steps := []func(*model.Nippo) error{s.remoteQuery.Download, resolveDate, claims.claim, s.localCommand.Create}
+	results, errs, err := s.process(targets, steps, option)
+	if err != nil {
+		return nil, err
+	}
+
+	var nippoList []model.Nippo
+	for i := range results {
+		skipped := errors.Is(errs[i], model.ErrInvalidNippoName)
+		if errs[i] != nil && !skipped {
+			change := pending[i]
+			if errors.Is(errs[i], model.ErrDuplicateNippo) {
+				change.Err = errs[i]
+			}
+			failed = append(failed, change)
+			continue
+		}
+		// An edit to the date in the front-matter moves the file to another date
+		if entry, ok := manifest.Files[results[i].RemoteFile.Id]; ok && !entry.IsSkipped() && entry.CachePath != results[i].FilePath {
+			if err := s.localCommand.Delete(&model.Nippo{FilePath: entry.CachePath}); err != nil {
+				return nil, err
 			}
-			nippoList[i] = nippo
 		}
-	} else {
-		nippoList = request.Content
+		if skipped {
+			// Recorded without a cache path, so the file is not fetched again until it changes
+			manifest.Record(results[i].RemoteFile, "")
+			continue
+		}
+		manifest.Record(results[i].RemoteFile, results[i].FilePath)
+		changes = append(changes, pending[i])
+		nippoList = append(nippoList, results[i])
+	}
+	if len(failed) > 0 {
+		// Failed files are not in the manifest, and the change log moves on without
+		// them, so fall back to a full listing next time to pick them up again.
+		manifest.PageToken = ""
+	}
+	if err := s.manifest.Save(manifest); err != nil {
+		return nil, err
 	}
 
-	count := len(nippoList)
+	sortByName(nippoList)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+
 	return &ds.NippoFacadeReponse{
 		Result: &ds.NippoFacadeResponseResult{
 			Action:  request.Action,
-			Count:   count,
+			Count:   len(nippoList),
 			Message: fmt.Sprintf("%d files downloaded.", len(nippoList)),
+			Changes: changes,
+			Failed:  failed,
+			Skipped: manifest.Skipped(),
 		},
 		Content: nippoList,
 	}, nil
 }
+
+// cacheClaims tells which file each cached nippo belongs to, so that two files
+// of the same date and suffix don't overwrite each other's cached copy
+type cacheClaims struct {
+	mu    sync.Mutex
+	files map[string]*drive.File
+}
+
+func newCacheClaims(manifest *model.SyncManifest) *cacheClaims {
+	c := &cacheClaims{files: map[string]*drive.File{}}
+	for id, entry := range manifest.Files {
+		if date := entry.CachedDate(); date != nil {
+			c.files[date.FileString()] = &drive.File{Id: id, Name: entry.Name}
+		}
+	}
+	return c
+}
+
+// claim takes the cached copy of the date of nippo for its file. It fails with
+// ErrDuplicateNippo when another file has it, the one cached first keeping it.
+func (c *cacheClaims) claim(nippo *model.Nippo) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := nippo.Date.FileString()
+	if owner, ok := c.files[key]; ok && owner.Id != nippo.RemoteFile.Id {
+		return fmt.Errorf("%w: %s and %s are both %s.md, rename one of them", model.ErrDuplicateNippo, owner.Name, nippo.RemoteFile.Name, key)
+	}
+	c.files[key] = nippo.RemoteFile
+	return nil
+}
+
+// listRemote returns the remote files, following the change log from the manifest's
+// page token when possible and listing every folder otherwise.
+func (s *nippoFacade) listRemote(request *ds.NippoFacadeRequest, option *ds.NippoFacadeOption, manifest *model.SyncManifest) ([]model.Nippo, error) {
+	fullSync := option != nil && option.FullSync
+	if !fullSync && manifest.PageToken != "" && manifest.HasFolders(request.Query.Folders) {
+		changes, err := s.remoteQuery.ListChanges(manifest.PageToken, manifest.FolderIds, request.Query)
+		if err == nil {
+			manifest.PageToken = changes.PageToken
+			manifest.FolderIds = changes.FolderIds
+			return manifest.Apply(changes.Changed, changes.Removed), nil
+		}
+		if !errors.Is(err, repository.ErrFullSyncRequired) {
+			return nil, err
+		}
+	}
+
+	// Take the token before listing, so changes made during the listing are seen next time
+	token, err := s.remoteQuery.StartPageToken()
+	if err != nil {
+		return nil, err
+	}
+	remoteFiles, folderIds, err := s.remoteQuery.ListTree(request.Query, request.Option)
+	if err != nil {
+		return nil, err
+	}
+	manifest.PageToken = token
+	manifest.FolderIds = folderIds
+	return remoteFiles, nil
+}
+
+// untrackedCache deletes cached files that none of remoteFiles would be cached to
+func (s *nippoFacade) untrackedCache(cached []model.Nippo, remoteFiles []model.Nippo) ([]model.SyncChange, error) {
+	dates := make(map[string]bool, len(remoteFiles))
+	for _, nippo := range remoteFiles {
+		if nippo.Date != nil {
+			dates[nippo.Date.FileString()] = true
+		}
+	}
+	var changes []model.SyncChange
+	for i := range cached {
+		if dates[cached[i].Date.FileString()] {
+			continue
+		}
+		if err := s.localCommand.Delete(&cached[i]); err != nil {
+			return nil, err
+		}
+		changes = append(changes, model.SyncChange{
+			Type: model.SyncChangeDeleted,
+			Name: filepath.Base(cached[i].FilePath),
+		})
+	}
+	return changes, nil
+}
+
+// process runs steps for each nippo through a bounded worker pool.
+// The returned slices keep the order of nippoList regardless of completion order;
+// errs holds the first step error of each nippo.
+func (s *nippoFacade) process(nippoList []model.Nippo, steps []func(*model.Nippo) error, option *ds.NippoFacadeOption) ([]model.Nippo, []error, error) {
+	results := make([]model.Nippo, len(nippoList))
+	errs := make([]error, len(nippoList))
+	copy(results, nippoList)
+	total := len(results)
+
+	var onProgress ds.ProgressCallback
+	concurrency := 0
+	if option != nil {
+		onProgress = option.OnProgress
+		concurrency = option.Concurrency
+	}
+	if concurrency < 1 {
+		concurrency = core.DefaultSyncConcurrency
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		current   int
+		cancelled bool
+	)
+
+	// report serializes progress callbacks and records cancellation
+	report := func(nippo *model.Nippo) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if cancelled {
+			return false
+		}
+		current++
+		if onProgress != nil {
+			filename, fileId := nippoFileInfo(nippo)
+			if !onProgress(filename, fileId, current, total) {
+				cancelled = true
+			}
+		}
+		return !cancelled
+	}
+	isCancelled := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return cancelled
+	}
+
+	jobs := make(chan int)
+	for w := 0; w < min(concurrency, total); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if !report(&results[i]) {
+					continue
+				}
+				for _, step := range steps {
+					if err := step(&results[i]); err != nil {
+						// Skip remaining steps for this file and continue with others
+						errs[i] = err
+						break
+					}
+				}
+			}
+		}()
+	}
+
+	for i := range results {
+		if isCancelled() {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if cancelled {
+		return nil, nil, ds.ErrCancelled
+	}
+	return results, errs, nil
+}
+
+// sortByName restores name order, since recursive listing returns files folder by folder
+func sortByName(nippoList []model.Nippo) {
+	sort.SliceStable(nippoList, func(i, j int) bool {
+		nameI, _ := nippoFileInfo(&nippoList[i])
+		nameJ, _ := nippoFileInfo(&nippoList[j])
+		return nameI < nameJ
+	})
+}
+
+func nippoFileInfo(nippo *model.Nippo) (string, string) {
+	if nippo.RemoteFile != nil {
+		return nippo.RemoteFile.Name, nippo.RemoteFile.Id
+	}
+	return filepath.Base(nippo.FilePath), ""
+}
@@ -1,7 +1,12 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/c18t/nippo-cli/internal/domain/model"
 	"github.com/c18t/nippo-cli/internal/domain/repository"
@@ -16,6 +21,9 @@ type mockRemoteNippoQuery struct {
 	listErr   error
 	downloadErr error
 	updateErr error
+	changes    *repository.RemoteNippoChanges
+	changesErr error
+	listed     bool
 }
 
 func (m *mockRemoteNippoQuery) List(param *repository.QueryListParam, option *repository.QueryListOption) ([]model.Nippo, error) {
@@ -25,6 +33,26 @@ func (m *mockRemoteNippoQuery) List(param *repository.QueryListParam, option *re
 	return m.nippos, nil
 }
 
+func (m *mockRemoteNippoQuery) ListTree(param *repository.QueryListParam, option *repository.QueryListOption) ([]model.Nippo, []string, error) {
+	m.listed = true
+	nippos, err := m.List(param, option)
+	return nippos, append([]string{"sub"}, param.Folders...), err
+}
+
+func (m *mockRemoteNippoQuery) StartPageToken() (string, error) {
+	return "start-token", nil
+}
+
+func (m *mockRemoteNippoQuery) ListChanges(pageToken string, folderIds []string, param *repository.QueryListParam) (*repository.RemoteNippoChanges, error) {
+	if m.changesErr != nil {
+		return nil, m.changesErr
+	}
+	if m.changes == nil {
+		return nil, repository.ErrFullSyncRequired
+	}
+	return m.changes, nil
+}
+
 func (m *mockRemoteNippoQuery) Download(nippo *model.Nippo) error {
 	if m.downloadErr != nil {
 		return m.downloadErr
@@ -37,6 +65,18 @@ func (m *mockRemoteNippoQuery) Update(nippo *model.Nippo, content []byte) error
 	return m.updateErr
 }
 
+func (m *mockRemoteNippoQuery) Create(folder, filePath string, nippo *model.Nippo) error {
+	return nil
+}
+
+func (m *mockRemoteNippoQuery) Flush() error {
+	return nil
+}
+
+func (m *mockRemoteNippoQuery) Naming() (*model.NippoNaming, error) {
+	return model.NewNippoNaming(nil)
+}
+
 type mockLocalNippoQuery struct{}
 
 func (m *mockLocalNippoQuery) Exist(date *model.NippoDate) bool { return true }
@@ -56,6 +96,27 @@ type mockLocalNippoCommand struct {
 func (m *mockLocalNippoCommand) Create(nippo *model.Nippo) error { return m.createErr }
 func (m *mockLocalNippoCommand) Delete(nippo *model.Nippo) error { return m.deleteErr }
 
+type mockSyncManifestRepository struct {
+	manifest *model.SyncManifest
+	saved    *model.SyncManifest
+	loadErr  error
+}
+
+func (m *mockSyncManifestRepository) Load() (*model.SyncManifest, error) {
+	if m.loadErr != nil {
+		return nil, m.loadErr
+	}
+	if m.manifest == nil {
+		return model.NewSyncManifest(), nil
+	}
+	return m.manifest, nil
+}
+
+func (m *mockSyncManifestRepository) Save(manifest *model.SyncManifest) error {
+	m.saved = manifest
+	return nil
+}
+
 func TestNewNippoFacade(t *testing.T) {
 	injector := do.New()
 	do.Provide(injector, func(_ do.Injector) (repository.RemoteNippoQuery, error) {
@@ -67,6 +128,9 @@ func TestNewNippoFacade(t *testing.T) {
 	do.Provide(injector, func(_ do.Injector) (repository.LocalNippoCommand, error) {
 		return &mockLocalNippoCommand{}, nil
 	})
+	do.Provide(injector, func(_ do.Injector) (repository.SyncManifestRepository, error) {
+		return &mockSyncManifestRepository{}, nil
+	})
 
 	facade, err := NewNippoFacade(injector)
 	if err != nil {
@@ -95,6 +159,9 @@ func TestNippoFacade_Send_Search(t *testing.T) {
 	do.Provide(injector, func(_ do.Injector) (repository.LocalNippoCommand, error) {
 		return &mockLocalNippoCommand{}, nil
 	})
+	do.Provide(injector, func(_ do.Injector) (repository.SyncManifestRepository, error) {
+		return &mockSyncManifestRepository{}, nil
+	})
 
 	facade, _ := NewNippoFacade(injector)
 
@@ -134,6 +201,9 @@ func TestNippoFacade_Send_Download(t *testing.T) {
 	do.Provide(injector, func(_ do.Injector) (repository.LocalNippoCommand, error) {
 		return &mockLocalNippoCommand{}, nil
 	})
+	do.Provide(injector, func(_ do.Injector) (repository.SyncManifestRepository, error) {
+		return &mockSyncManifestRepository{}, nil
+	})
 
 	facade, _ := NewNippoFacade(injector)
 
@@ -168,6 +238,9 @@ func TestNippoFacade_Send_Cache(t *testing.T) {
 	do.Provide(injector, func(_ do.Injector) (repository.LocalNippoCommand, error) {
 		return &mockLocalNippoCommand{}, nil
 	})
+	do.Provide(injector, func(_ do.Injector) (repository.SyncManifestRepository, error) {
+		return &mockSyncManifestRepository{}, nil
+	})
 
 	facade, _ := NewNippoFacade(injector)
 
@@ -202,6 +275,9 @@ func TestNippoFacade_Send_WithProgressCallback(t *testing.T) {
 	do.Provide(injector, func(_ do.Injector) (repository.LocalNippoCommand, error) {
 		return &mockLocalNippoCommand{}, nil
 	})
+	do.Provide(injector, func(_ do.Injector) (repository.SyncManifestRepository, error) {
+		return &mockSyncManifestRepository{}, nil
+	})
 
 	facade, _ := NewNippoFacade(injector)
 
@@ -245,6 +321,9 @@ func TestNippoFacade_Send_CancelledByCallback(t *testing.T) {
 	do.Provide(injector, func(_ do.Injector) (repository.LocalNippoCommand, error) {
 		return &mockLocalNippoCommand{}, nil
 	})
+	do.Provide(injector, func(_ do.Injector) (repository.SyncManifestRepository, error) {
+		return &mockSyncManifestRepository{}, nil
+	})
 
 	facade, _ := NewNippoFacade(injector)
 
@@ -262,3 +341,617 @@ func TestNippoFacade_Send_CancelledByCallback(t *testing.T) {
 		t.Errorf("Send() error = %v, want ErrCancelled", err)
 	}
 }
+
+// concurrentRemoteNippoQuery records how many downloads run at the same time
+type concurrentRemoteNippoQuery struct {
+	mockRemoteNippoQuery
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func (m *concurrentRemoteNippoQuery) Download(nippo *model.Nippo) error {
+	m.mu.Lock()
+	m.inFlight++
+	if m.inFlight > m.peak {
+		m.peak = m.inFlight
+	}
+	m.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+	nippo.Content = []byte("content of " + nippo.RemoteFile.Name)
+
+	m.mu.Lock()
+	m.inFlight--
+	m.mu.Unlock()
+	return nil
+}
+
+func newTestNippos(n int) []model.Nippo {
+	nippos := make([]model.Nippo, n)
+	for i := range nippos {
+		// Reverse order to verify the response is sorted by name
+		name := fmt.Sprintf("2024-01-%02d.md", n-i)
+		nippos[i] = model.Nippo{
+			Date:       model.NewNippoDate(name),
+			RemoteFile: &drive.File{Id: fmt.Sprintf("id-%d", n-i), Name: name},
+		}
+	}
+	return nippos
+}
+
+func TestNippoFacade_Send_ConcurrentDownload(t *testing.T) {
+	remoteQuery := &concurrentRemoteNippoQuery{
+		mockRemoteNippoQuery: mockRemoteNippoQuery{nippos: newTestNippos(20)},
+	}
+
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (repository.RemoteNippoQuery, error) {
+		return remoteQuery, nil
+	})
+	do.Provide(injector, func(_ do.Injector) (repository.LocalNippoQuery, error) {
+		return &mockLocalNippoQuery{}, nil
+	})
+	do.Provide(injector, func(_ do.Injector) (repository.LocalNippoCommand, error) {
+		return &mockLocalNippoCommand{}, nil
+	})
+	do.Provide(injector, func(_ do.Injector) (repository.SyncManifestRepository, error) {
+		return &mockSyncManifestRepository{}, nil
+	})
+
+	facade, _ := NewNippoFacade(injector)
+
+	var calls []int
+	resp, err := facade.Send(&ds.NippoFacadeRequest{
+		Action: ds.NippoFacadeActionDownload | ds.NippoFacadeActionCache,
+		Query:  &repository.QueryListParam{},
+		Option: &repository.QueryListOption{},
+	}, &ds.NippoFacadeOption{
+		Concurrency: 4,
+		OnProgress: func(filename string, fileId string, current int, total int) bool {
+			calls = append(calls, current)
+			return true
+		},
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if len(resp.Content) != 20 {
+		t.Fatalf("Send() returned %d items, want 20", len(resp.Content))
+	}
+	for i, nippo := range resp.Content {
+		want := fmt.Sprintf("2024-01-%02d.md", i+1)
+		if nippo.RemoteFile.Name != want {
+			t.Errorf("Content[%d] = %s, want %s", i, nippo.RemoteFile.Name, want)
+		}
+		if string(nippo.Content) != "content of "+want {
+			t.Errorf("Content[%d] has content %q", i, nippo.Content)
+		}
+	}
+
+	// Progress callback is serialized and counts up without gaps
+	for i, current := range calls {
+		if current != i+1 {
+			t.Fatalf("progress call %d reported current=%d", i, current)
+		}
+	}
+	if remoteQuery.peak < 2 {
+		t.Errorf("peak concurrent downloads = %d, want >= 2", remoteQuery.peak)
+	}
+	if remoteQuery.peak > 4 {
+		t.Errorf("peak concurrent downloads = %d, want <= 4", remoteQuery.peak)
+	}
+}
+
+func TestNippoFacade_Send_CancelledMidway(t *testing.T) {
+	remoteQuery := &concurrentRemoteNippoQuery{
+		mockRemoteNippoQuery: mockRemoteNippoQuery{nippos: newTestNippos(20)},
+	}
+
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (repository.RemoteNippoQuery, error) {
+		return remoteQuery, nil
+	})
+	do.Provide(injector, func(_ do.Injector) (repository.LocalNippoQuery, error) {
+		return &mockLocalNippoQuery{}, nil
+	})
+	do.Provide(injector, func(_ do.Injector) (repository.LocalNippoCommand, error) {
+		return &mockLocalNippoCommand{}, nil
+	})
+	do.Provide(injector, func(_ do.Injector) (repository.SyncManifestRepository, error) {
+		return &mockSyncManifestRepository{}, nil
+	})
+
+	facade, _ := NewNippoFacade(injector)
+
+	calls := 0
+	_, err := facade.Send(&ds.NippoFacadeRequest{
+		Action: ds.NippoFacadeActionDownload,
+		Query:  &repository.QueryListParam{},
+		Option: &repository.QueryListOption{},
+	}, &ds.NippoFacadeOption{
+		Concurrency: 3,
+		OnProgress: func(filename string, fileId string, current int, total int) bool {
+			calls++
+			return current < 5
+		},
+	})
+
+	if err != ds.ErrCancelled {
+		t.Errorf("Send() error = %v, want ErrCancelled", err)
+	}
+	if calls != 5 {
+		t.Errorf("progress callback called %d times, want 5", calls)
+	}
+}
+
+// recordingLocalNippoCommand records created and deleted cache paths
+type recordingLocalNippoCommand struct {
+	mu      sync.Mutex
+	created []string
+	deleted []string
+}
+
+func (m *recordingLocalNippoCommand) Create(nippo *model.Nippo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	nippo.FilePath = "/cache/md/" + nippo.Date.FileString() + ".md"
+	m.created = append(m.created, nippo.FilePath)
+	return nil
+}
+
+func (m *recordingLocalNippoCommand) Delete(nippo *model.Nippo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deleted = append(m.deleted, nippo.FilePath)
+	return nil
+}
+
+// failingLocalNippoCommand fails to delete the cached files in failPaths
+type failingLocalNippoCommand struct {
+	recordingLocalNippoCommand
+	failPaths map[string]bool
+}
+
+func (m *failingLocalNippoCommand) Delete(nippo *model.Nippo) error {
+	if m.failPaths[nippo.FilePath] {
+		return errors.New("delete failed")
+	}
+	return m.recordingLocalNippoCommand.Delete(nippo)
+}
+
+// failingRemoteNippoQuery fails to download the files in failIds
+type failingRemoteNippoQuery struct {
+	mockRemoteNippoQuery
+	failIds map[string]bool
+}
+
+func (m *failingRemoteNippoQuery) Download(nippo *model.Nippo) error {
+	if m.failIds[nippo.RemoteFile.Id] {
+		return errors.New("download failed")
+	}
+	nippo.Content = []byte("downloaded content")
+	return nil
+}
+
+func newSyncTestFacade(remoteQuery repository.RemoteNippoQuery, localCommand repository.LocalNippoCommand, manifest repository.SyncManifestRepository) ds.NippoFacade {
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (repository.RemoteNippoQuery, error) {
+		return remoteQuery, nil
+	})
+	do.Provide(injector, func(_ do.Injector) (repository.LocalNippoQuery, error) {
+		return &mockLocalNippoQuery{}, nil
+	})
+	do.Provide(injector, func(_ do.Injector) (repository.LocalNippoCommand, error) {
+		return localCommand, nil
+	})
+	do.Provide(injector, func(_ do.Injector) (repository.SyncManifestRepository, error) {
+		return manifest, nil
+	})
+	facade, _ := NewNippoFacade(injector)
+	return facade
+}
+
+func TestNippoFacade_Send_SyncReconcilesManifest(t *testing.T) {
+	manifest := model.NewSyncManifest()
+	manifest.Files["same"] = model.SyncManifestEntry{Name: "2024-01-10.md", Md5Checksum: "a", CachePath: "/cache/md/2024-01-10.md"}
+	manifest.Files["edited"] = model.SyncManifestEntry{Name: "2024-01-11.md", Md5Checksum: "b", CachePath: "/cache/md/2024-01-11.md"}
+	manifest.Files["moved"] = model.SyncManifestEntry{Name: "2024-01-12.md", Md5Checksum: "c", CachePath: "/cache/md/2024-01-12.md"}
+	manifest.Files["gone"] = model.SyncManifestEntry{Name: "2024-01-13.md", Md5Checksum: "d", CachePath: "/cache/md/2024-01-13.md"}
+
+	remoteQuery := &mockRemoteNippoQuery{nippos: []model.Nippo{
+		{Date: model.NewNippoDate("2024-01-10.md"), RemoteFile: &drive.File{Id: "same", Name: "2024-01-10.md", Md5Checksum: "a"}},
+		{Date: model.NewNippoDate("2024-01-11.md"), RemoteFile: &drive.File{Id: "edited", Name: "2024-01-11.md", Md5Checksum: "B"}},
+		{Date: model.NewNippoDate("2024-01-14.md"), RemoteFile: &drive.File{Id: "moved", Name: "2024-01-14.md", Md5Checksum: "c"}},
+		{Date: model.NewNippoDate("2024-01-15.md"), RemoteFile: &drive.File{Id: "new", Name: "2024-01-15.md", Md5Checksum: "e"}},
+	}}
+	localCommand := &recordingLocalNippoCommand{}
+	manifestRepo := &mockSyncManifestRepository{manifest: manifest}
+
+	facade := newSyncTestFacade(remoteQuery, localCommand, manifestRepo)
+	resp, err := facade.Send(&ds.NippoFacadeRequest{
+		Action: ds.NippoFacadeActionDownload | ds.NippoFacadeActionCache,
+		Query:  &repository.QueryListParam{},
+		Option: &repository.QueryListOption{},
+	}, &ds.NippoFacadeOption{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	want := map[string]model.SyncChangeType{
+		"2024-01-11.md": model.SyncChangeUpdated,
+		"2024-01-13.md": model.SyncChangeDeleted,
+		"2024-01-14.md": model.SyncChangeRenamed,
+		"2024-01-15.md": model.SyncChangeAdded,
+	}
+	if len(resp.Result.Changes) != len(want) {
+		t.Fatalf("Changes = %+v, want %d entries", resp.Result.Changes, len(want))
+	}
+	for _, change := range resp.Result.Changes {
+		if want[change.Name] != change.Type {
+			t.Errorf("change for %s = %v, want %v", change.Name, change.Type, want[change.Name])
+		}
+		if change.Type == model.SyncChangeRenamed && change.OldName != "2024-01-12.md" {
+			t.Errorf("renamed OldName = %q", change.OldName)
+		}
+	}
+
+	// Unchanged files are not downloaded again
+	if len(localCommand.created) != 3 {
+		t.Errorf("created %v, want 3 files", localCommand.created)
+	}
+	deleted := strings.Join(localCommand.deleted, ",")
+	if !strings.Contains(deleted, "2024-01-13.md") || !strings.Contains(deleted, "2024-01-12.md") {
+		t.Errorf("deleted %v, want the deleted and the renamed file's old copy", localCommand.deleted)
+	}
+
+	saved := manifestRepo.saved
+	if saved == nil {
+		t.Fatal("manifest was not saved")
+	}
+	if _, ok := saved.Files["gone"]; ok {
+		t.Error("deleted file should be removed from the manifest")
+	}
+	if saved.Files["moved"].Name != "2024-01-14.md" || saved.Files["moved"].CachePath != "/cache/md/2024-01-14.md" {
+		t.Errorf("renamed entry = %+v", saved.Files["moved"])
+	}
+	if saved.Files["edited"].Md5Checksum != "B" {
+		t.Errorf("updated entry = %+v", saved.Files["edited"])
+	}
+}
+
+func TestNippoFacade_Send_SyncFailedDownloadIsRetried(t *testing.T) {
+	remoteQuery := &failingRemoteNippoQuery{
+		mockRemoteNippoQuery: mockRemoteNippoQuery{nippos: []model.Nippo{
+			{Date: model.NewNippoDate("2024-01-15.md"), RemoteFile: &drive.File{Id: "ok", Name: "2024-01-15.md"}},
+			{Date: model.NewNippoDate("2024-01-16.md"), RemoteFile: &drive.File{Id: "ng", Name: "2024-01-16.md"}},
+		}},
+		failIds: map[string]bool{"ng": true},
+	}
+	manifestRepo := &mockSyncManifestRepository{}
+
+	facade := newSyncTestFacade(remoteQuery, &recordingLocalNippoCommand{}, manifestRepo)
+	resp, err := facade.Send(&ds.NippoFacadeRequest{
+		Action: ds.NippoFacadeActionDownload | ds.NippoFacadeActionCache,
+		Query:  &repository.QueryListParam{},
+		Option: &repository.QueryListOption{},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if len(resp.Result.Failed) != 1 || resp.Result.Failed[0].FileId != "ng" {
+		t.Errorf("Failed = %+v, want ng", resp.Result.Failed)
+	}
+	if _, ok := manifestRepo.saved.Files["ng"]; ok {
+		t.Error("failed file should not be recorded, so it is retried next time")
+	}
+	if _, ok := manifestRepo.saved.Files["ok"]; !ok {
+		t.Error("downloaded file should be recorded")
+	}
+	if manifestRepo.saved.PageToken != "" {
+		t.Error("page token should be dropped so the next sync lists every folder")
+	}
+}
+
+func TestNippoFacade_Send_SyncFailedRenameIsRetried(t *testing.T) {
+	manifest := model.NewSyncManifest()
+	manifest.PageToken = "10"
+	manifest.Files["moved"] = model.SyncManifestEntry{Name: "2024-01-12.md", Md5Checksum: "c", CachePath: "/cache/md/2024-01-12.md"}
+
+	remoteQuery := &mockRemoteNippoQuery{nippos: []model.Nippo{
+		{Date: model.NewNippoDate("2024-01-14.md"), RemoteFile: &drive.File{Id: "moved", Name: "2024-01-14.md", Md5Checksum: "c"}},
+	}}
+	localCommand := &failingLocalNippoCommand{failPaths: map[string]bool{"/cache/md/2024-01-12.md": true}}
+	manifestRepo := &mockSyncManifestRepository{manifest: manifest}
+
+	facade := newSyncTestFacade(remoteQuery, localCommand, manifestRepo)
+	resp, err := facade.Send(&ds.NippoFacadeRequest{
+		Action: ds.NippoFacadeActionDownload | ds.NippoFacadeActionCache,
+		Query:  &repository.QueryListParam{},
+		Option: &repository.QueryListOption{},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if len(resp.Result.Failed) != 1 || resp.Result.Failed[0].Type != model.SyncChangeRenamed {
+		t.Errorf("Failed = %+v, want the renamed file", resp.Result.Failed)
+	}
+	if len(resp.Result.Changes) != 0 {
+		t.Errorf("Changes = %+v, want none", resp.Result.Changes)
+	}
+	if len(localCommand.created) != 0 {
+		t.Errorf("created %v, want nothing downloaded under the new name", localCommand.created)
+	}
+	if entry := manifestRepo.saved.Files["moved"]; entry.Name != "2024-01-12.md" || entry.CachePath != "/cache/md/2024-01-12.md" {
+		t.Errorf("renamed entry = %+v, want the old record kept to retry the rename", entry)
+	}
+	if manifestRepo.saved.PageToken != "" {
+		t.Error("page token should be dropped so the next sync lists every folder")
+	}
+}
+
+func TestNippoFacade_Send_SyncDuplicateDates(t *testing.T) {
+	manifest := model.NewSyncManifest()
+	manifest.Files["cached"] = model.SyncManifestEntry{Name: "2024-01-15_evening.md", Md5Checksum: "a", CachePath: "/cache/md/2024-01-15-evening.md"}
+
+	remoteQuery := &mockRemoteNippoQuery{nippos: []model.Nippo{
+		{Date: model.NewNippoDate("2024-01-15_evening.md"), RemoteFile: &drive.File{Id: "cached", Name: "2024-01-15_evening.md", Md5Checksum: "a"}},
+		{Date: model.NewNippoDate("2024-01-15-evening.md"), RemoteFile: &drive.File{Id: "copy", Name: "2024-01-15-evening.md", Md5Checksum: "b"}},
+		{Date: model.NewNippoDate("2024-01-16.md"), RemoteFile: &drive.File{Id: "new1", Name: "2024-01-16.md", Md5Checksum: "c"}},
+		{Date: model.NewNippoDate("2024-01-16.md"), RemoteFile: &drive.File{Id: "new2", Name: "2024-01-16.md", Md5Checksum: "d"}},
+	}}
+	localCommand := &recordingLocalNippoCommand{}
+	manifestRepo := &mockSyncManifestRepository{manifest: manifest}
+
+	facade := newSyncTestFacade(remoteQuery, localCommand, manifestRepo)
+	resp, err := facade.Send(&ds.NippoFacadeRequest{
+		Action: ds.NippoFacadeActionDownload | ds.NippoFacadeActionCache,
+		Query:  &repository.QueryListParam{},
+		Option: &repository.QueryListOption{},
+	}, &ds.NippoFacadeOption{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	// The cached file keeps its copy, and only one of two new files gets one
+	if len(localCommand.created) != 1 || localCommand.created[0] != "/cache/md/2024-01-16.md" {
+		t.Errorf("created %v, want one copy of 2024-01-16", localCommand.created)
+	}
+	if len(resp.Result.Failed) != 2 {
+		t.Fatalf("Failed = %+v, want the two duplicates", resp.Result.Failed)
+	}
+	for _, change := range resp.Result.Failed {
+		if !errors.Is(change.Err, model.ErrDuplicateNippo) {
+			t.Errorf("Failed %s error = %v, want ErrDuplicateNippo", change.Name, change.Err)
+		}
+		if _, ok := manifestRepo.saved.Files[change.FileId]; ok {
+			t.Errorf("duplicate %s should not be recorded", change.FileId)
+		}
+	}
+	if resp.Result.Failed[0].FileId != "copy" && resp.Result.Failed[1].FileId != "copy" {
+		t.Errorf("Failed = %+v, want the copy of the cached file", resp.Result.Failed)
+	}
+	if manifestRepo.saved.Files["cached"].CachePath != "/cache/md/2024-01-15-evening.md" {
+		t.Errorf("cached entry = %+v, want it kept", manifestRepo.saved.Files["cached"])
+	}
+}
+
+func TestNippoFacade_Send_SyncRemovesUntrackedCache(t *testing.T) {
+	remoteQuery := &mockRemoteNippoQuery{nippos: []model.Nippo{
+		{Date: model.NewNippoDate("2024-01-15.md"), RemoteFile: &drive.File{Id: "1", Name: "2024-01-15.md"}},
+	}}
+	localCommand := &recordingLocalNippoCommand{}
+
+	facade := newSyncTestFacade(remoteQuery, localCommand, &mockSyncManifestRepository{})
+	resp, err := facade.Send(&ds.NippoFacadeRequest{
+		Action: ds.NippoFacadeActionDownload | ds.NippoFacadeActionCache,
+		Query:  &repository.QueryListParam{},
+		Option: &repository.QueryListOption{},
+		Content: []model.Nippo{
+			{Date: model.NewNippoDate("2024-01-14.md"), FilePath: "/cache/md/2024-01-14.md"},
+			{Date: model.NewNippoDate("2024-01-15.md"), FilePath: "/cache/md/2024-01-15.md"},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if len(localCommand.deleted) != 1 || localCommand.deleted[0] != "/cache/md/2024-01-14.md" {
+		t.Errorf("deleted %v, want only the file without a remote counterpart", localCommand.deleted)
+	}
+	if len(resp.Result.Changes) != 2 {
+		t.Errorf("Changes = %+v, want one added and one deleted", resp.Result.Changes)
+	}
+}
+
+func TestNippoFacade_Send_SyncFollowsChanges(t *testing.T) {
+	manifest := model.NewSyncManifest()
+	manifest.PageToken = "10"
+	manifest.FolderIds = []string{"root", "sub"}
+	manifest.Files["kept"] = model.SyncManifestEntry{Name: "2024-01-10.md", Md5Checksum: "a", CachePath: "/cache/md/2024-01-10.md"}
+	manifest.Files["gone"] = model.SyncManifestEntry{Name: "2024-01-11.md", Md5Checksum: "b", CachePath: "/cache/md/2024-01-11.md"}
+
+	remoteQuery := &mockRemoteNippoQuery{changes: &repository.RemoteNippoChanges{
+		Changed: []model.Nippo{
+			{Date: model.NewNippoDate("2024-01-12.md"), RemoteFile: &drive.File{Id: "new", Name: "2024-01-12.md", Md5Checksum: "c"}},
+		},
+		Removed:   []string{"gone"},
+		FolderIds: []string{"root", "sub"},
+		PageToken: "11",
+	}}
+	localCommand := &recordingLocalNippoCommand{}
+	manifestRepo := &mockSyncManifestRepository{manifest: manifest}
+
+	facade := newSyncTestFacade(remoteQuery, localCommand, manifestRepo)
+	resp, err := facade.Send(&ds.NippoFacadeRequest{
+		Action: ds.NippoFacadeActionDownload | ds.NippoFacadeActionCache,
+		Query:  &repository.QueryListParam{Folders: []string{"root"}},
+		Option: &repository.QueryListOption{Recursive: true},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if remoteQuery.listed {
+		t.Error("folders should not be listed when the change log can be followed")
+	}
+	if len(resp.Result.Changes) != 2 {
+		t.Errorf("Changes = %+v, want one added and one deleted", resp.Result.Changes)
+	}
+	if len(localCommand.created) != 1 {
+		t.Errorf("created %v, want only the new file", localCommand.created)
+	}
+	if manifestRepo.saved.PageToken != "11" {
+		t.Errorf("saved PageToken = %q, want 11", manifestRepo.saved.PageToken)
+	}
+	if _, ok := manifestRepo.saved.Files["kept"]; !ok {
+		t.Error("unchanged file should stay in the manifest")
+	}
+}
+
+func TestNippoFacade_Send_SyncFallsBackToFullListing(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		folders  []string
+		fullSync bool
+	}{
+		{"no page token", "", []string{"root"}, false},
+		{"expired page token", "10", []string{"root"}, false},
+		{"root folder changed", "10", []string{"old-root"}, false},
+		{"full sync requested", "10", []string{"root"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest := model.NewSyncManifest()
+			manifest.PageToken = tt.token
+			manifest.FolderIds = tt.folders
+			manifest.Files["1"] = model.SyncManifestEntry{Name: "2024-01-15.md", CachePath: "/cache/md/2024-01-15.md"}
+
+			remoteQuery := &mockRemoteNippoQuery{
+				nippos: []model.Nippo{
+					{Date: model.NewNippoDate("2024-01-15.md"), RemoteFile: &drive.File{Id: "1", Name: "2024-01-15.md"}},
+				},
+				changesErr: repository.ErrFullSyncRequired,
+			}
+			manifestRepo := &mockSyncManifestRepository{manifest: manifest}
+
+			facade := newSyncTestFacade(remoteQuery, &recordingLocalNippoCommand{}, manifestRepo)
+			_, err := facade.Send(&ds.NippoFacadeRequest{
+				Action: ds.NippoFacadeActionDownload | ds.NippoFacadeActionCache,
+				Query:  &repository.QueryListParam{Folders: []string{"root"}},
+				Option: &repository.QueryListOption{Recursive: true},
+			}, &ds.NippoFacadeOption{FullSync: tt.fullSync})
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			if !remoteQuery.listed {
+				t.Error("folders should be listed")
+			}
+			if manifestRepo.saved.PageToken != "start-token" {
+				t.Errorf("saved PageToken = %q, want the token taken before listing", manifestRepo.saved.PageToken)
+			}
+			if strings.Join(manifestRepo.saved.FolderIds, ",") != "sub,root" {
+				t.Errorf("saved FolderIds = %v", manifestRepo.saved.FolderIds)
+			}
+		})
+	}
+}
+
+func TestNippoFacade_Send_SyncChangesError(t *testing.T) {
+	manifest := model.NewSyncManifest()
+	manifest.PageToken = "10"
+	manifest.FolderIds = []string{"root"}
+
+	remoteQuery := &mockRemoteNippoQuery{changesErr: errors.New("network down")}
+	facade := newSyncTestFacade(remoteQuery, &recordingLocalNippoCommand{}, &mockSyncManifestRepository{manifest: manifest})
+	_, err := facade.Send(&ds.NippoFacadeRequest{
+		Action: ds.NippoFacadeActionDownload | ds.NippoFacadeActionCache,
+		Query:  &repository.QueryListParam{Folders: []string{"root"}},
+		Option: &repository.QueryListOption{},
+	}, nil)
+	if err == nil {
+		t.Error("Send() should return errors other than ErrFullSyncRequired")
+	}
+}
+
+// contentRemoteNippoQuery downloads the content in contents by file ID
+type contentRemoteNippoQuery struct {
+	mockRemoteNippoQuery
+	contents map[string]string
+}
+
+func (m *contentRemoteNippoQuery) Download(nippo *model.Nippo) error {
+	nippo.Content = []byte(m.contents[nippo.RemoteFile.Id])
+	return nil
+}
+
+func TestNippoFacade_Send_SyncResolvesDates(t *testing.T) {
+	manifest := model.NewSyncManifest()
+	manifest.Files["redated"] = model.SyncManifestEntry{Name: "2024-01-12.md", Md5Checksum: "a", CachePath: "/cache/md/2024-01-12.md"}
+
+	remoteQuery := &contentRemoteNippoQuery{
+		mockRemoteNippoQuery: mockRemoteNippoQuery{nippos: []model.Nippo{
+			{Date: model.NewNippoDate("2024-01-12.md"), RemoteFile: &drive.File{Id: "redated", Name: "2024-01-12.md", Md5Checksum: "A"}},
+			{RemoteFile: &drive.File{Id: "dated", Name: "diary.md", Md5Checksum: "b"}},
+			{RemoteFile: &drive.File{Id: "notes", Name: "notes.md", Md5Checksum: "c"}},
+		}},
+		contents: map[string]string{
+			"redated": "---\ndate: 2024-01-13\n---\n\n# Moved\n",
+			"dated":   "---\ndate: 2024-01-14\n---\n\n# Diary\n",
+			"notes":   "# Notes\n",
+		},
+	}
+	localCommand := &recordingLocalNippoCommand{}
+	manifestRepo := &mockSyncManifestRepository{manifest: manifest}
+
+	facade := newSyncTestFacade(remoteQuery, localCommand, manifestRepo)
+	resp, err := facade.Send(&ds.NippoFacadeRequest{
+		Action: ds.NippoFacadeActionDownload | ds.NippoFacadeActionCache,
+		Query:  &repository.QueryListParam{},
+		Option: &repository.QueryListOption{},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	created := strings.Join(localCommand.created, ",")
+	if created != "/cache/md/2024-01-13.md,/cache/md/2024-01-14.md" {
+		t.Errorf("created %v, want the files cached under their front-matter dates", localCommand.created)
+	}
+	if len(localCommand.deleted) != 1 || localCommand.deleted[0] != "/cache/md/2024-01-12.md" {
+		t.Errorf("deleted %v, want the copy under the old date", localCommand.deleted)
+	}
+	if len(resp.Result.Failed) != 0 {
+		t.Errorf("Failed = %+v, want none", resp.Result.Failed)
+	}
+	if len(resp.Result.Skipped) != 1 || resp.Result.Skipped[0].Name != "notes.md" {
+		t.Errorf("Skipped = %+v, want notes.md", resp.Result.Skipped)
+	}
+	if entry := manifestRepo.saved.Files["notes"]; !entry.IsSkipped() || entry.Md5Checksum != "c" {
+		t.Errorf("skipped entry = %+v, want it recorded without a cache path", entry)
+	}
+
+	// The skipped file is not fetched again until it changes, but still reported
+	localCommand = &recordingLocalNippoCommand{}
+	facade = newSyncTestFacade(remoteQuery, localCommand, manifestRepo)
+	resp, err = facade.Send(&ds.NippoFacadeRequest{
+		Action: ds.NippoFacadeActionDownload | ds.NippoFacadeActionCache,
+		Query:  &repository.QueryListParam{},
+		Option: &repository.QueryListOption{},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(localCommand.created) != 0 || len(resp.Result.Changes) != 0 {
+		t.Errorf("second sync created %v and changed %+v, want nothing", localCommand.created, resp.Result.Changes)
+	}
+	if len(resp.Result.Skipped) != 1 {
+		t.Errorf("Skipped = %+v, want notes.md again", resp.Result.Skipped)
+	}
+}
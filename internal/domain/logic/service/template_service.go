@@ -1,66 +1,118 @@
 package service
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"html/template"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/c18t/nippo-cli/internal/core"
 	i "github.com/c18t/nippo-cli/internal/domain/service"
 	"github.com/samber/do/v2"
 )
 
+// defaultTemplates are the templates of the pages a theme may lack, and the
+// terms template the nippo template calls with {{template "terms" .Terms}},
+// which the templates of the theme replace
+const defaultTemplates = `{{define "terms"}}{{if .}}<ul class="tags">
+{{range .}}<li><a href="{{.Url}}" rel="tag">{{.Name}}</a></li>
+{{end}}</ul>
+{{end}}{{end}}{{define "taxonomy"}}<h1>{{.Date}}</h1>
+<ul class="terms">
+{{range .Terms}}<li><a href="{{.Url}}">{{.Name}}</a> ({{.Count}})</li>
+{{end}}</ul>
+{{end}}{{define "term"}}<h1>{{.Date}}</h1>
+<p><a href="{{.FeedUrl}}">Feed</a></p>
+<ul class="entries">
+{{range .Entries}}<li><a href="{{.Url}}">{{.Date}}</a></li>
+{{end}}</ul>
+{{end}}`
+
 type templateService struct {
-	t *template.Template
+	// template loads the templates on first use, which pages rendered in
+	// parallel share
+	template func() (*template.Template, error)
 }
 
 func NewTemplateService(_ do.Injector) (i.TemplateService, error) {
-	return &templateService{}, nil
+	return &templateService{template: sync.OnceValues(loadTemplate)}, nil
 }
 
 func (s *templateService) SaveTo(filePath string, templateName string, data any) (err error) {
-	outputDir := filepath.Dir(filePath)
-	err = os.MkdirAll(outputDir, 0755)
-	if err != nil && !os.IsExist(err) {
-		fmt.Println(err)
-		return nil
+	t, err := s.template()
+	if err != nil {
+		return err
+	}
+	layout, content := t.Lookup("layout"), t.Lookup(templateName)
+	if layout == nil {
+		return fmt.Errorf("template layout not found in %s", templatePattern())
+	}
+	if content == nil {
+		return fmt.Errorf("template %s not found in %s", templateName, templatePattern())
+	}
+	tmpl, err := layout.Clone()
+	if err != nil {
+		return err
+	}
+	// Escaping rewrites the tree it executes, so each page gets its own copy
+	tmpl, err = tmpl.AddParseTree("content", content.Tree.Copy())
+	if err != nil {
+		return err
 	}
 
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
 	f, err := os.Create(filePath)
 	if err != nil {
-		fmt.Println(err)
-		return nil
+		return err
 	}
 	defer func() {
 		if cerr := f.Close(); cerr != nil && err == nil {
 			err = cerr
 		}
 	}()
+	return tmpl.ExecuteTemplate(f, "layout", data)
+}
 
-	tmpl, err := s.template().Lookup("layout").Clone()
-	if err != nil {
-		return err
+// Hash fails when the templates don't load, as they couldn't render the pages
+func (s *templateService) Hash() (string, error) {
+	if _, err := s.template(); err != nil {
+		return "", err
 	}
-	tmpl, err = tmpl.AddParseTree("content", s.template().Lookup(templateName).Tree)
+	files, err := filepath.Glob(templatePattern())
 	if err != nil {
-		return err
+		return "", err
 	}
-	return tmpl.ExecuteTemplate(f, "layout", data)
-}
-
-func (s *templateService) template() *template.Template {
-	if s.t == nil {
-		_ = s.lazyLoadTemplate()
+	h := sha256.New()
+	h.Write([]byte(defaultTemplates))
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		// The name goes in too, so renaming a template changes the hash
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.Base(file), len(b))
+		h.Write(b)
 	}
-	return s.t
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
-func (s *templateService) lazyLoadTemplate() error {
-	t, err := template.ParseGlob(filepath.Join(core.Cfg.GetDataDir(), "templates", "*.html"))
+func loadTemplate() (*template.Template, error) {
+	t, err := template.New("defaults").Parse(defaultTemplates)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	s.t = t
-	return nil
+	t, err = t.ParseGlob(templatePattern())
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the templates: %w", err)
+	}
+	return t, nil
+}
+
+func templatePattern() string {
+	return filepath.Join(core.Cfg.GetDataDir(), "templates", "*.html")
 }
@@ -1,8 +1,11 @@
 package service
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/c18t/nippo-cli/internal/core"
@@ -113,3 +116,173 @@ func TestTemplateService_SaveTo_CreateDirectory(t *testing.T) {
 		t.Error("SaveTo() did not create output directory")
 	}
 }
+
+func TestTemplateService_Hash(t *testing.T) {
+	tmpDir := t.TempDir()
+	templateDir := filepath.Join(tmpDir, "templates")
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	layoutPath := filepath.Join(templateDir, "layout.html")
+	if err := os.WriteFile(layoutPath, []byte(`{{define "layout"}}{{end}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	core.Cfg = &core.Config{}
+	core.Cfg.Paths.DataDir = tmpDir
+
+	service, _ := NewTemplateService(do.New())
+	before, err := service.Hash()
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if again, _ := service.Hash(); again != before {
+		t.Errorf("Hash() = %s, then %s for the same templates", before, again)
+	}
+
+	if err := os.WriteFile(layoutPath, []byte(`{{define "layout"}}changed{{end}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := service.Hash(); after == before {
+		t.Error("Hash() didn't change with a template")
+	}
+}
+
+func TestTemplateService_SaveTo_Parallel(t *testing.T) {
+	tmpDir := t.TempDir()
+	templateDir := filepath.Join(tmpDir, "templates")
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	templates := map[string]string{
+		"layout.html": `{{define "layout"}}<html>{{template "content" .}}</html>{{end}}`,
+		"test.html":   `{{define "test"}}<a href="/{{.}}">{{.}}</a>{{end}}`,
+	}
+	for name, content := range templates {
+		if err := os.WriteFile(filepath.Join(templateDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	core.Cfg = &core.Config{}
+	core.Cfg.Paths.DataDir = tmpDir
+
+	service, _ := NewTemplateService(do.New())
+	var wg sync.WaitGroup
+	for n := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := service.SaveTo(filepath.Join(tmpDir, "output", fmt.Sprintf("%d.html", n)), "test", fmt.Sprintf("a&%d", n)); err != nil {
+				t.Errorf("SaveTo() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Every page is escaped once, however many were rendered before it
+	for n := range 16 {
+		got, err := os.ReadFile(filepath.Join(tmpDir, "output", fmt.Sprintf("%d.html", n)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := fmt.Sprintf(`<html><a href="/a&amp;%d">a&amp;%d</a></html>`, n, n)
+		if string(got) != want {
+			t.Errorf("page %d = %s, want %s", n, got, want)
+		}
+	}
+}
+
+func TestTemplateService_SaveTo_DefaultTemplates(t *testing.T) {
+	tmpDir := t.TempDir()
+	templateDir := filepath.Join(tmpDir, "templates")
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	templates := map[string]string{
+		"layout.html": `{{define "layout"}}<html>{{template "content" .}}</html>{{end}}`,
+		"term.html":   `{{define "term"}}<h1>#{{.Date}}</h1>{{end}}`,
+		"nippo.html":  `{{define "nippo"}}{{.Content}}{{template "terms" .Terms}}{{end}}`,
+	}
+	for name, content := range templates {
+		if err := os.WriteFile(filepath.Join(templateDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	core.Cfg = &core.Config{}
+	core.Cfg.Paths.DataDir = tmpDir
+
+	service, _ := NewTemplateService(do.New())
+	type termLink struct {
+		Name, Url string
+		Count     int
+	}
+	taxonomyPath := filepath.Join(tmpDir, "output", "tags.html")
+	if err := service.SaveTo(taxonomyPath, "taxonomy", struct {
+		Date  string
+		Terms []termLink
+	}{"tags", []termLink{{"Go", "/tags/go", 2}}}); err != nil {
+		t.Fatalf("SaveTo() error = %v", err)
+	}
+	nippoPath := filepath.Join(tmpDir, "output", "20240115.html")
+	if err := service.SaveTo(nippoPath, "nippo", struct {
+		Content string
+		Terms   []termLink
+	}{"body", []termLink{{"<b>", "/tags/b", 1}}}); err != nil {
+		t.Fatalf("SaveTo() error = %v", err)
+	}
+	termPath := filepath.Join(tmpDir, "output", "tags", "go.html")
+	if err := service.SaveTo(termPath, "term", struct{ Date string }{"Go"}); err != nil {
+		t.Fatalf("SaveTo() error = %v", err)
+	}
+
+	// The theme lacks the taxonomy template, so the default renders it
+	got, _ := os.ReadFile(taxonomyPath)
+	if want := `<li><a href="/tags/go">Go</a> (2)</li>`; !strings.Contains(string(got), want) {
+		t.Errorf("taxonomy page = %s, want it to contain %s", got, want)
+	}
+	// The nippo template of the theme links to the terms with the default
+	got, _ = os.ReadFile(nippoPath)
+	if want := `<li><a href="/tags/b" rel="tag">&lt;b&gt;</a></li>`; !strings.Contains(string(got), want) {
+		t.Errorf("nippo page = %s, want it to contain %s", got, want)
+	}
+	// The theme's term template replaces the default
+	if got, _ := os.ReadFile(termPath); string(got) != "<html><h1>#Go</h1></html>" {
+		t.Errorf("term page = %s, want the template of the theme", got)
+	}
+}
+
+func TestTemplateService_SaveTo_Errors(t *testing.T) {
+	tmpDir := t.TempDir()
+	core.Cfg = &core.Config{}
+	core.Cfg.Paths.DataDir = tmpDir
+
+	// No templates have been downloaded yet
+	service, _ := NewTemplateService(do.New())
+	if err := service.SaveTo(filepath.Join(tmpDir, "output", "index.html"), "index", nil); err == nil {
+		t.Error("SaveTo() error = nil without templates")
+	}
+	if _, err := service.Hash(); err == nil {
+		t.Error("Hash() error = nil without templates")
+	}
+
+	templateDir := filepath.Join(tmpDir, "templates")
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	layoutContent := `{{define "layout"}}{{template "content" .}}{{end}}`
+	if err := os.WriteFile(filepath.Join(templateDir, "layout.html"), []byte(layoutContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A template the theme doesn't define is an error, not a panic
+	service, _ = NewTemplateService(do.New())
+	outputPath := filepath.Join(tmpDir, "output", "missing.html")
+	if err := service.SaveTo(outputPath, "missing", nil); err == nil {
+		t.Error("SaveTo() error = nil for an unknown template")
+	}
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Error("SaveTo() created a file for an unknown template")
+	}
+}
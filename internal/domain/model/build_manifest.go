@@ -0,0 +1,165 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// BuildManifestVersion is the current version of the build manifest format
+const BuildManifestVersion = 2
+
+// BuildManifest records what the site was last built from, so the next build
+// renders only the pages affected by what changed since
+type BuildManifest struct {
+	Version int `json:"version"`
+	// Templates is the hash of the templates the pages were rendered with
+	Templates string `json:"templates"`
+	// Settings is the hash of the settings every page depends on, such as the site URL
+	Settings string `json:"settings"`
+	// Entries maps the file name of each nippo without extension, such as
+	// 2024-01-15-evening, to the hash of its content
+	Entries map[string]string `json:"entries"`
+	// Terms are the paths of the tag and category pages, such as tags/go
+	Terms []string `json:"terms"`
+}
+
+func NewBuildManifest() *BuildManifest {
+	return &BuildManifest{
+		Version: BuildManifestVersion,
+		Entries: map[string]string{},
+	}
+}
+
+// ContentHash returns the hash the build manifest records for content
+func ContentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// BuildPlan lists the pages a build renders and removes
+type BuildPlan struct {
+	// Full is set when every page is rendered, after a change of the templates
+	// or settings, and the output is cleaned first
+	Full bool
+	// Pages are the paths of the day and nippo pages to render, such as 20240115
+	Pages map[string]bool
+	// Months are the archive months to render, such as 2024-01
+	Months map[string]bool
+	// Removed are the paths of the pages of nippo, months and terms that are
+	// gone, such as 20240115-evening, 202401 or tags/go
+	Removed []string
+}
+
+// HasChanges reports whether any page is rendered or removed. The index, the
+// feed and the sitemap are rendered again only then.
+func (p *BuildPlan) HasChanges() bool {
+	return p.Full || len(p.Pages) > 0 || len(p.Months) > 0 || len(p.Removed) > 0
+}
+
+// Plan compares nippoList, loaded with content, with the last build. A changed
+// nippo renders its day page, its own page, the pages of the days before and
+// after it and its month archive. All pages are rendered when force is set or
+// templates or settings changed. Plan returns the manifest to save once the
+// build succeeds.
+func (m *BuildManifest) Plan(nippoList []Nippo, templates, settings string, force bool) (*BuildPlan, *BuildManifest) {
+	next := NewBuildManifest()
+	next.Templates = templates
+	next.Settings = settings
+	for _, nippo := range nippoList {
+		next.Entries[nippo.Date.FileString()] = ContentHash(nippo.Content)
+	}
+
+	days := GroupNippoByDay(nippoList)
+	plan := &BuildPlan{Pages: map[string]bool{}, Months: map[string]bool{}}
+	if force || m.Version != BuildManifestVersion || m.Templates != templates || m.Settings != settings {
+		plan.Full = true
+		for _, day := range days {
+			plan.Pages[day.Date.PathString()] = true
+			plan.Months[day.Date.FileString()[:7]] = true
+			for _, nippo := range day.Nippo {
+				if nippo.Date.Suffix() != "" {
+					plan.Pages[nippo.Date.PathString()] = true
+				}
+			}
+		}
+		return plan, next
+	}
+
+	var changed []string
+	for name, hash := range next.Entries {
+		if m.Entries[name] != hash {
+			changed = append(changed, name)
+		}
+	}
+	for name := range m.Entries {
+		if _, ok := next.Entries[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	slices.Sort(changed)
+
+	months := map[string]bool{}
+	for name := range next.Entries {
+		months[name[:7]] = true
+	}
+	removed := map[string]bool{}
+	for _, name := range changed {
+		date, err := ParseNippoDate(name)
+		if err != nil {
+			continue
+		}
+		if date.Suffix() != "" {
+			plan.mark(date.PathString(), next.Entries[name] != "", removed)
+		}
+		day := dayOf(date)
+		i, found := slices.BinarySearchFunc(days, day.PathString(), func(d NippoDay, path string) int {
+			return strings.Compare(d.Date.PathString(), path)
+		})
+		plan.mark(day.PathString(), found, removed)
+		// The days around it may link to it, or to each other once it is gone
+		before, after := i-1, i
+		if found {
+			after = i + 1
+		}
+		if before >= 0 {
+			plan.Pages[days[before].Date.PathString()] = true
+		}
+		if after < len(days) {
+			plan.Pages[days[after].Date.PathString()] = true
+		}
+
+		month := name[:7]
+		if months[month] {
+			plan.Months[month] = true
+		} else if ym, err := NewCalenderYearMonth(month); err == nil {
+			removed[ym.PathString()] = true
+		}
+	}
+	plan.Removed = slices.Sorted(maps.Keys(removed))
+	return plan, next
+}
+
+// RemoveTerms adds the pages of the terms of the last build that are gone in
+// next to remove. The output is cleaned anyway on a full build.
+func (p *BuildPlan) RemoveTerms(last, next *BuildManifest) {
+	if p.Full {
+		return
+	}
+	for _, path := range last.Terms {
+		if !slices.Contains(next.Terms, path) {
+			p.Removed = append(p.Removed, path)
+		}
+	}
+}
+
+// mark adds the page of path to render when it still exists, or to remove
+func (p *BuildPlan) mark(path string, exists bool, removed map[string]bool) {
+	if exists {
+		p.Pages[path] = true
+	} else {
+		removed[path] = true
+	}
+}
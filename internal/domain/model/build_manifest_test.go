@@ -0,0 +1,137 @@
+package model
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestBuildManifest_Plan(t *testing.T) {
+	nippo := func(name, content string) Nippo {
+		return Nippo{Date: NewNippoDate(name), Content: []byte(content)}
+	}
+	built := []Nippo{
+		nippo("2024-01-10.md", "a"),
+		nippo("2024-01-15.md", "b"),
+		nippo("2024-01-15-evening.md", "c"),
+		nippo("2024-01-20.md", "d"),
+		nippo("2024-02-01.md", "e"),
+	}
+	_, manifest := NewBuildManifest().Plan(built, "templates", "settings", false)
+
+	tests := []struct {
+		name        string
+		nippoList   []Nippo
+		templates   string
+		force       bool
+		wantFull    bool
+		wantPages   []string
+		wantMonths  []string
+		wantRemoved []string
+	}{
+		{
+			name:      "nothing changed",
+			nippoList: built,
+			templates: "templates",
+		},
+		{
+			name:       "changed nippo renders its day, the days around it and its month",
+			nippoList:  []Nippo{built[0], nippo("2024-01-15.md", "b2"), built[2], built[3], built[4]},
+			templates:  "templates",
+			wantPages:  []string{"20240110", "20240115", "20240120"},
+			wantMonths: []string{"2024-01"},
+		},
+		{
+			name:       "changed nippo with a suffix renders its own page too",
+			nippoList:  []Nippo{built[0], built[1], nippo("2024-01-15-evening.md", "c2"), built[3], built[4]},
+			templates:  "templates",
+			wantPages:  []string{"20240110", "20240115", "20240115-evening", "20240120"},
+			wantMonths: []string{"2024-01"},
+		},
+		{
+			name:        "removed day renders the days that were around it",
+			nippoList:   []Nippo{built[0], built[1], built[2], built[4]},
+			templates:   "templates",
+			wantPages:   []string{"20240115", "20240201"},
+			wantMonths:  []string{"2024-01"},
+			wantRemoved: []string{"20240120"},
+		},
+		{
+			name:        "removed last nippo of a month removes its archive",
+			nippoList:   built[:4],
+			templates:   "templates",
+			wantPages:   []string{"20240120"},
+			wantRemoved: []string{"202402", "20240201"},
+		},
+		{
+			name:       "added nippo on a new day",
+			nippoList:  append(slices.Clone(built), nippo("2024-01-12.md", "f")),
+			templates:  "templates",
+			wantPages:  []string{"20240110", "20240112", "20240115"},
+			wantMonths: []string{"2024-01"},
+		},
+		{
+			name:       "changed templates rebuild everything",
+			nippoList:  built,
+			templates:  "changed",
+			wantFull:   true,
+			wantPages:  []string{"20240110", "20240115", "20240115-evening", "20240120", "20240201"},
+			wantMonths: []string{"2024-01", "2024-02"},
+		},
+		{
+			name:       "force rebuilds everything",
+			nippoList:  built,
+			templates:  "templates",
+			force:      true,
+			wantFull:   true,
+			wantPages:  []string{"20240110", "20240115", "20240115-evening", "20240120", "20240201"},
+			wantMonths: []string{"2024-01", "2024-02"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, next := manifest.Plan(tt.nippoList, tt.templates, "settings", tt.force)
+			if plan.Full != tt.wantFull {
+				t.Errorf("Full = %v, want %v", plan.Full, tt.wantFull)
+			}
+			if got := slices.Sorted(maps.Keys(plan.Pages)); !slices.Equal(got, tt.wantPages) {
+				t.Errorf("Pages = %v, want %v", got, tt.wantPages)
+			}
+			if got := slices.Sorted(maps.Keys(plan.Months)); !slices.Equal(got, tt.wantMonths) {
+				t.Errorf("Months = %v, want %v", got, tt.wantMonths)
+			}
+			if !slices.Equal(plan.Removed, tt.wantRemoved) {
+				t.Errorf("Removed = %v, want %v", plan.Removed, tt.wantRemoved)
+			}
+			if len(next.Entries) != len(tt.nippoList) || next.Templates != tt.templates {
+				t.Errorf("next manifest = %+v", next)
+			}
+		})
+	}
+}
+
+func TestBuildManifest_PlanWithoutManifest(t *testing.T) {
+	// The manifest of a site never built has no version
+	plan, _ := (&BuildManifest{}).Plan([]Nippo{{Date: NewNippoDate("2024-01-15.md")}}, "templates", "settings", false)
+	if !plan.Full || !plan.HasChanges() {
+		t.Errorf("the first build should be a full one, got %+v", plan)
+	}
+}
+
+func TestBuildPlan_RemoveTerms(t *testing.T) {
+	last := &BuildManifest{Terms: []string{"tags", "tags/go", "tags/release"}}
+	next := &BuildManifest{Terms: []string{"tags", "tags/go"}}
+
+	plan := &BuildPlan{Removed: []string{"20240115"}}
+	plan.RemoveTerms(last, next)
+	if want := []string{"20240115", "tags/release"}; !slices.Equal(plan.Removed, want) {
+		t.Errorf("Removed = %v, want %v", plan.Removed, want)
+	}
+
+	// A full build cleans the output anyway
+	full := &BuildPlan{Full: true}
+	full.RemoveTerms(last, &BuildManifest{})
+	if len(full.Removed) != 0 {
+		t.Errorf("Removed = %v on a full build, want none", full.Removed)
+	}
+}
@@ -19,30 +19,34 @@ type CalenderYearMonth struct {
 type CalenderDay struct {
 	HasContent bool
 	Date       NippoDate
+	// Count is the number of nippo written on the day
+	Count int
+	// Title is the title of the day, set by Site.Calender
+	Title string
 }
 
 func NewCalender(ym CalenderYearMonth, nippoList []Nippo) (*Calender, error) {
 	month := ym.Time()
-	monthFirstDay := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
+	monthFirstDay := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
 	monthLastDay := monthFirstDay.AddDate(0, 1, -1)
 	lastWeekNo := (int(monthFirstDay.Weekday()) + monthLastDay.Day() - 1) / 7
 
-	hasContentMap := make([][7]bool, 1+lastWeekNo)
+	countMap := make([][7]int, 1+lastWeekNo)
 	for _, nippo := range nippoList {
 		if nippo.Date.Year() == month.Year() && nippo.Date.Month() == month.Month() {
 			weekNo := (int(monthFirstDay.Weekday()) + nippo.Date.Day() - 1) / 7
 			weekDay := nippo.Date.Weekday()
-			hasContentMap[weekNo][weekDay] = true
+			countMap[weekNo][weekDay]++
 		}
 	}
 
 	weeks := make([][7]CalenderDay, 1+lastWeekNo)
 	for day := 1; day <= monthLastDay.Day(); day++ {
-		date := time.Date(month.Year(), month.Month(), day, 0, 0, 0, 0, time.Local)
+		date := time.Date(month.Year(), month.Month(), day, 0, 0, 0, 0, time.UTC)
 		weekNo := (int(monthFirstDay.Weekday()) + day - 1) / 7
 		weekDay := date.Weekday()
-		hasContent := hasContentMap[weekNo][weekDay]
-		weeks[weekNo][weekDay] = CalenderDay{hasContent, NewNippoDate(date.Format(time.RFC3339))}
+		count := countMap[weekNo][weekDay]
+		weeks[weekNo][weekDay] = CalenderDay{HasContent: count > 0, Date: &nippoDate{time: date}, Count: count}
 	}
 	return &Calender{
 		ym,
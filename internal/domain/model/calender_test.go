@@ -142,6 +142,34 @@ func TestNewCalender(t *testing.T) {
 	}
 }
 
+func TestNewCalender_CountsNippoOfADay(t *testing.T) {
+	ym, _ := NewCalenderYearMonth("2024-01-15.md")
+
+	cal, err := NewCalender(ym, []Nippo{
+		{Date: NewNippoDate("2024-01-15.md")},
+		{Date: NewNippoDate("2024-01-15-evening.md")},
+		{Date: NewNippoDate("2024-01-16.md")},
+	})
+	if err != nil {
+		t.Fatalf("NewCalender() error = %v", err)
+	}
+
+	counts := map[int]int{}
+	for _, week := range cal.Weeks {
+		for _, day := range week {
+			if day.Date != nil {
+				counts[day.Date.Day()] = day.Count
+				if day.HasContent != (day.Count > 0) {
+					t.Errorf("day %d HasContent = %v with count %d", day.Date.Day(), day.HasContent, day.Count)
+				}
+			}
+		}
+	}
+	if counts[15] != 2 || counts[16] != 1 || counts[17] != 0 {
+		t.Errorf("counts = %v, want 2 on the 15th and 1 on the 16th", counts)
+	}
+}
+
 func TestNewCalender_WithoutNippos(t *testing.T) {
 	ym, _ := NewCalenderYearMonth("2024-02-15.md")
 
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change
+const diffContextLines = 3
+
+// diffNoNewline follows a last line that has no newline, as in diff(1)
+const diffNoNewline = "\\ No newline at end of file\n"
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	// text is the line with its newline, which the last line may lack
+	text string
+	// aLine and bLine are the 0-based lines of a and b the op starts at
+	aLine, bLine int
+}
+
+// UnifiedDiff returns the changes from a to b of the file name in unified diff
+// format, or "" when they are equal
+func UnifiedDiff(name string, a, b []byte) string {
+	if string(a) == string(b) {
+		return ""
+	}
+	ops := diffLines(splitLines(string(a)), splitLines(string(b)))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", name, name)
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		// Merge changes whose context would overlap into one hunk
+		last := i
+		for k := i; k < len(ops) && k-last <= 2*diffContextLines; k++ {
+			if ops[k].kind != ' ' {
+				last = k
+			}
+		}
+		start := max(0, i-diffContextLines)
+		stop := min(len(ops), last+diffContextLines+1)
+		writeHunk(&sb, ops[start:stop])
+		i = stop
+	}
+	return sb.String()
+}
+
+func writeHunk(sb *strings.Builder, ops []diffOp) {
+	var aCount, bCount int
+	for _, op := range ops {
+		if op.kind != '+' {
+			aCount++
+		}
+		if op.kind != '-' {
+			bCount++
+		}
+	}
+	// An empty range starts at the line before it
+	aStart, bStart := ops[0].aLine, ops[0].bLine
+	if aCount > 0 {
+		aStart++
+	}
+	if bCount > 0 {
+		bStart++
+	}
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+	for _, op := range ops {
+		sb.WriteByte(op.kind)
+		sb.WriteString(op.text)
+		if !strings.HasSuffix(op.text, "\n") {
+			sb.WriteString("\n" + diffNoNewline)
+		}
+	}
+}
+
+// diffLines returns the edit script from a to b along their longest common subsequence
+func diffLines(a, b []string) []diffOp {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i], i, j})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', a[i], i, j})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j], i, j})
+			j++
+		}
+	}
+	return ops
+}
+
+// splitLines splits s into lines that keep their newline, so a last line
+// without one differs from the same line with it
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
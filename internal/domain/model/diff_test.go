@@ -0,0 +1,70 @@
+package model
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "equal",
+			a:    "# title\n",
+			b:    "# title\n",
+			want: "",
+		},
+		{
+			name: "added front-matter",
+			a:    "# title\n\nbody\n",
+			b:    "---\ncreated: 2024-01-15T10:00:00+09:00\n---\n\n# title\n\nbody\n",
+			want: "--- a/2024-01-15.md\n+++ b/2024-01-15.md\n" +
+				"@@ -1,3 +1,7 @@\n" +
+				"+---\n+created: 2024-01-15T10:00:00+09:00\n+---\n+\n" +
+				" # title\n \n body\n",
+		},
+		{
+			name: "replaced line with context",
+			a:    "---\nupdated: now\n---\n\n1\n2\n3\n4\n5\n",
+			b:    "---\nupdated: 2024-01-15T10:00:00+09:00\n---\n\n1\n2\n3\n4\n5\n",
+			want: "--- a/2024-01-15.md\n+++ b/2024-01-15.md\n" +
+				"@@ -1,5 +1,5 @@\n" +
+				" ---\n-updated: now\n+updated: 2024-01-15T10:00:00+09:00\n ---\n \n 1\n",
+		},
+		{
+			name: "separate hunks",
+			a:    "a\n1\n2\n3\n4\n5\n6\n7\n8\nb\n",
+			b:    "A\n1\n2\n3\n4\n5\n6\n7\n8\nB\n",
+			want: "--- a/2024-01-15.md\n+++ b/2024-01-15.md\n" +
+				"@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n 3\n" +
+				"@@ -7,4 +7,4 @@\n 6\n 7\n 8\n-b\n+B\n",
+		},
+		{
+			name: "added newline at end of file",
+			a:    "# title\nbody",
+			b:    "# title\nbody\n",
+			want: "--- a/2024-01-15.md\n+++ b/2024-01-15.md\n" +
+				"@@ -1,2 +1,2 @@\n # title\n-body\n\\ No newline at end of file\n+body\n",
+		},
+		{
+			name: "removed newline at end of file",
+			a:    "body\n",
+			b:    "body",
+			want: "--- a/2024-01-15.md\n+++ b/2024-01-15.md\n" +
+				"@@ -1,1 +1,1 @@\n-body\n+body\n\\ No newline at end of file\n",
+		},
+		{
+			name: "new file",
+			a:    "",
+			b:    "# title\n",
+			want: "--- a/2024-01-15.md\n+++ b/2024-01-15.md\n@@ -0,0 +1,1 @@\n+# title\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UnifiedDiff("2024-01-15.md", []byte(tt.a), []byte(tt.b)); got != tt.want {
+				t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// FormatRunVersion is the current version of the format run manifest
+const FormatRunVersion = 1
+
+// formatRunIdLayout names runs after the UTC time they started at, so they sort
+// by time. Milliseconds keep runs started within the same second apart.
+const formatRunIdLayout = "20060102-150405.000"
+
+// FormatRun records the files a `nippo format` run changed, whose originals are
+// backed up next to its manifest
+type FormatRun struct {
+	Version   int       `json:"version"`
+	Id        string    `json:"id"`
+	StartedAt time.Time `json:"startedAt"`
+	// SourceType and Folder are the source the files were formatted in, so a
+	// run is never reverted into another one
+	SourceType string `json:"sourceType"`
+	Folder     string `json:"folder"`
+	// RevertedAt is when the run was reverted, nil until then
+	RevertedAt *time.Time      `json:"revertedAt,omitempty"`
+	Files      []FormatRunFile `json:"files"`
+}
+
+// FormatRunFile is a file changed by a format run
+type FormatRunFile struct {
+	Id      string   `json:"id"`
+	Name    string   `json:"name"`
+	Reasons []string `json:"reasons,omitempty"`
+	// Backup is the name of the original content in the run directory
+	Backup string `json:"backup"`
+	// FormattedMd5 is the checksum of the uploaded content, to tell whether the
+	// file has been edited since
+	FormattedMd5 string `json:"formattedMd5"`
+}
+
+// NewFormatRun creates the record of a run started at startedAt in the folder of a source
+func NewFormatRun(startedAt time.Time, sourceType, folder string) *FormatRun {
+	return &FormatRun{
+		Version:    FormatRunVersion,
+		Id:         startedAt.UTC().Format(formatRunIdLayout),
+		StartedAt:  startedAt,
+		SourceType: sourceType,
+		Folder:     folder,
+		Files:      []FormatRunFile{},
+	}
+}
+
+// NextFile returns the record of file as the next file of the run, with a
+// backup name that is unique within the run
+func (r *FormatRun) NextFile(file *drive.File, reasons []string) FormatRunFile {
+	return FormatRunFile{
+		Id:      file.Id,
+		Name:    file.Name,
+		Reasons: reasons,
+		Backup:  fmt.Sprintf("%04d-%s", len(r.Files)+1, strings.ReplaceAll(file.Name, "/", "_")),
+	}
+}
+
+// IsReverted reports whether the run has been reverted
+func (r *FormatRun) IsReverted() bool {
+	return r.RevertedAt != nil
+}
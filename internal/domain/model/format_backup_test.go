@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+func TestNewFormatRun(t *testing.T) {
+	startedAt := time.Date(2024, 1, 15, 19, 4, 5, 120*int(time.Millisecond), time.FixedZone("JST", 9*60*60))
+	run := NewFormatRun(startedAt, "drive", "folder1")
+	if run.Id != "20240115-100405.120" {
+		t.Errorf("Id = %q, want the UTC start time", run.Id)
+	}
+	if next := NewFormatRun(startedAt.Add(time.Millisecond), "drive", "folder1"); next.Id == run.Id {
+		t.Errorf("runs a millisecond apart share the ID %q", run.Id)
+	}
+	if run.IsReverted() {
+		t.Error("a new run should not be reverted")
+	}
+
+	first := run.NextFile(&drive.File{Id: "file1", Name: "2024/2024-01-15.md"}, []string{"Sorted tags"})
+	run.Files = append(run.Files, first)
+	second := run.NextFile(&drive.File{Id: "file2", Name: "2024-01-15.md"}, nil)
+	if first.Backup != "0001-2024_2024-01-15.md" || second.Backup != "0002-2024-01-15.md" {
+		t.Errorf("backup names = %q, %q", first.Backup, second.Backup)
+	}
+}
@@ -0,0 +1,491 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Names of the built-in format rules, as enabled in the [format] section of nippo.toml
+const (
+	FormatRuleFrontMatter        = "front_matter"
+	FormatRuleCreated            = "created"
+	FormatRuleUpdatedNow         = "updated_now"
+	FormatRuleTrailingWhitespace = "trailing_whitespace"
+	FormatRuleFinalNewline       = "final_newline"
+	FormatRuleHeadingLevels      = "heading_levels"
+	FormatRuleSpaces             = "spaces"
+	FormatRuleSortTags           = "sort_tags"
+)
+
+// DefaultFormatRules are applied when no rules are configured
+var DefaultFormatRules = []string{FormatRuleFrontMatter, FormatRuleCreated, FormatRuleUpdatedNow}
+
+// FormatRule detects and fixes one kind of issue in a nippo
+type FormatRule interface {
+	Name() string
+	// Check returns why content of nippo needs fixing, or "" when it doesn't
+	Check(nippo *Nippo, content []byte) (string, error)
+	// Fix returns content with the issue fixed
+	Fix(nippo *Nippo, content []byte) ([]byte, error)
+}
+
+// FormatRuleOptions configures the built-in format rules
+type FormatRuleOptions struct {
+	// HeadingTopLevel is the level heading_levels gives the top headings, 1 when unset
+	HeadingTopLevel int
+	// Location is the time zone of the times written to front-matter, local time when unset
+	Location *time.Location
+	// FrontMatterFormat is FrontMatterYAML to have front_matter convert TOML and
+	// JSON front-matter to YAML, or "" to keep each file's format
+	FrontMatterFormat FrontMatterFormat
+}
+
+var formatRuleFactories = map[string]func(opts FormatRuleOptions) FormatRule{
+	FormatRuleFrontMatter: func(opts FormatRuleOptions) FormatRule {
+		return frontMatterRule{loc: opts.Location, toYAML: opts.FrontMatterFormat == FrontMatterYAML}
+	},
+	FormatRuleCreated:    func(opts FormatRuleOptions) FormatRule { return createdRule{loc: opts.Location} },
+	FormatRuleUpdatedNow: func(opts FormatRuleOptions) FormatRule { return updatedNowRule{loc: opts.Location} },
+	FormatRuleTrailingWhitespace: func(FormatRuleOptions) FormatRule {
+		return &lineRule{name: FormatRuleTrailingWhitespace, reason: "Removed trailing whitespace", fixLine: trimTrailingWhitespace}
+	},
+	FormatRuleFinalNewline: func(FormatRuleOptions) FormatRule { return finalNewlineRule{} },
+	FormatRuleHeadingLevels: func(opts FormatRuleOptions) FormatRule {
+		return headingLevelsRule{top: opts.HeadingTopLevel}
+	},
+	FormatRuleSpaces: func(FormatRuleOptions) FormatRule {
+		return &lineRule{name: FormatRuleSpaces, reason: "Replaced full-width spaces", fixLine: halfWidthSpaces}
+	},
+	FormatRuleSortTags: func(FormatRuleOptions) FormatRule { return sortTagsRule{} },
+}
+
+// FormatRuleNames returns the names of all built-in format rules
+func FormatRuleNames() []string {
+	return slices.Sorted(maps.Keys(formatRuleFactories))
+}
+
+// NewFormatRules creates the rules of names in the given order, or the
+// DefaultFormatRules when names is empty
+func NewFormatRules(names []string, opts FormatRuleOptions) ([]FormatRule, error) {
+	if len(names) == 0 {
+		names = DefaultFormatRules
+	}
+	if opts.HeadingTopLevel == 0 {
+		opts.HeadingTopLevel = 1
+	}
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	if opts.HeadingTopLevel < 1 || opts.HeadingTopLevel > 6 {
+		return nil, fmt.Errorf("heading_top_level must be between 1 and 6: %d", opts.HeadingTopLevel)
+	}
+	if opts.FrontMatterFormat != "" && opts.FrontMatterFormat != FrontMatterYAML {
+		return nil, fmt.Errorf("front_matter_format must be \"keep\" or \"yaml\": %s", opts.FrontMatterFormat)
+	}
+
+	rules := make([]FormatRule, 0, len(names))
+	seen := map[string]bool{}
+	for _, name := range names {
+		factory, ok := formatRuleFactories[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown format rule: %s. Available rules: %s", name, strings.Join(FormatRuleNames(), ", "))
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		rules = append(rules, factory(opts))
+	}
+	return rules, nil
+}
+
+// ApplyFormatRules applies rules to the content of nippo in order. It returns
+// the fixed content and the reason of each rule that changed it.
+func ApplyFormatRules(rules []FormatRule, nippo *Nippo) ([]byte, []string, error) {
+	content := nippo.Content
+	var reasons []string
+	for _, rule := range rules {
+		reason, err := rule.Check(nippo, content)
+		if err != nil {
+			return nil, nil, err
+		}
+		if reason == "" {
+			continue
+		}
+		fixed, err := rule.Fix(nippo, content)
+		if err != nil {
+			return nil, nil, err
+		}
+		if bytes.Equal(fixed, content) {
+			continue
+		}
+		content = fixed
+		reasons = append(reasons, reason)
+	}
+	return content, reasons, nil
+}
+
+func malformedFrontMatter(err error) error {
+	return fmt.Errorf("malformed front-matter: %w", err)
+}
+
+// remoteFileTime returns the RFC 3339 time of the remote file in loc,
+// or the zero time when it is unknown
+func remoteFileTime(value string, loc *time.Location) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.In(loc)
+}
+
+// frontMatterRule adds front-matter with the created time of the remote file,
+// and converts TOML and JSON front-matter to YAML when toYAML is set
+type frontMatterRule struct {
+	loc    *time.Location
+	toYAML bool
+}
+
+func (frontMatterRule) Name() string { return FormatRuleFrontMatter }
+
+func (r frontMatterRule) Check(nippo *Nippo, content []byte) (string, error) {
+	if !HasFrontMatter(content) {
+		return "Added front-matter", nil
+	}
+	if format := FrontMatterFormatOf(content); r.toYAML && format != "" && format != FrontMatterYAML {
+		return fmt.Sprintf("Converted %s front-matter to YAML", strings.ToUpper(string(format))), nil
+	}
+	return "", nil
+}
+
+func (r frontMatterRule) Fix(nippo *Nippo, content []byte) ([]byte, error) {
+	if HasFrontMatter(content) {
+		converted, err := ConvertFrontMatterToYAML(content)
+		if err != nil {
+			return nil, malformedFrontMatter(err)
+		}
+		return converted, nil
+	}
+	created := time.Time{}
+	if nippo.RemoteFile != nil {
+		created = remoteFileTime(nippo.RemoteFile.CreatedTime, r.loc)
+	}
+	return append([]byte(GenerateFrontMatter(created)), content...), nil
+}
+
+// createdRule adds a missing created field with the created time of the remote file
+type createdRule struct {
+	loc *time.Location
+}
+
+func (createdRule) Name() string { return FormatRuleCreated }
+
+func (r createdRule) Check(nippo *Nippo, content []byte) (string, error) {
+	// Without a created time there is nothing to add
+	if nippo.RemoteFile == nil || remoteFileTime(nippo.RemoteFile.CreatedTime, r.loc).IsZero() {
+		return "", nil
+	}
+	fm, _, err := ParseFrontMatter(content)
+	if err != nil {
+		return "", malformedFrontMatter(err)
+	}
+	if fm == nil {
+		return "", nil
+	}
+	if _, hasCreated := fm.Raw["created"]; hasCreated {
+		return "", nil
+	}
+	return "Added created field", nil
+}
+
+func (r createdRule) Fix(nippo *Nippo, content []byte) ([]byte, error) {
+	if nippo.RemoteFile == nil {
+		return content, nil
+	}
+	created := remoteFileTime(nippo.RemoteFile.CreatedTime, r.loc)
+	if created.IsZero() {
+		return content, nil
+	}
+	fixed, err := UpdateFrontMatter(content, created, time.Time{}, false)
+	if err != nil {
+		return nil, malformedFrontMatter(err)
+	}
+	return fixed, nil
+}
+
+// updatedNowRule replaces the `updated: now` placeholder with the modified time of the remote file
+type updatedNowRule struct {
+	loc *time.Location
+}
+
+func (updatedNowRule) Name() string { return FormatRuleUpdatedNow }
+
+func (updatedNowRule) Check(nippo *Nippo, content []byte) (string, error) {
+	if !HasNowPlaceholder(content) {
+		return "", nil
+	}
+	return "Replaced updated: now", nil
+}
+
+func (r updatedNowRule) Fix(nippo *Nippo, content []byte) ([]byte, error) {
+	if nippo.RemoteFile == nil {
+		return content, nil
+	}
+	modified := remoteFileTime(nippo.RemoteFile.ModifiedTime, r.loc)
+	if modified.IsZero() {
+		return content, nil
+	}
+	fixed, err := UpdateFrontMatter(content, time.Time{}, modified, true)
+	if err != nil {
+		return nil, malformedFrontMatter(err)
+	}
+	return fixed, nil
+}
+
+// lineRule fixes each line of the Markdown body outside code blocks
+type lineRule struct {
+	name   string
+	reason string
+	// fixLine fixes a line without its line break
+	fixLine func(line string) string
+}
+
+func (r *lineRule) Name() string { return r.name }
+
+func (r *lineRule) Check(nippo *Nippo, content []byte) (string, error) {
+	if bytes.Equal(mapBodyLines(content, r.fixLine), content) {
+		return "", nil
+	}
+	return r.reason, nil
+}
+
+func (r *lineRule) Fix(nippo *Nippo, content []byte) ([]byte, error) {
+	return mapBodyLines(content, r.fixLine), nil
+}
+
+// trimTrailingWhitespace removes trailing spaces and tabs, but keeps two
+// spaces where they make a Markdown hard line break
+func trimTrailingWhitespace(line string) string {
+	trimmed := strings.TrimRight(line, " \t")
+	if trimmed != "" && strings.TrimRight(line, " ") == trimmed && len(line)-len(trimmed) >= 2 {
+		return trimmed + "  "
+	}
+	return trimmed
+}
+
+// halfWidthSpaces replaces full-width spaces with half-width ones
+func halfWidthSpaces(line string) string {
+	return strings.ReplaceAll(line, "　", " ")
+}
+
+// finalNewlineRule ends the content with exactly one line break
+type finalNewlineRule struct{}
+
+func (finalNewlineRule) Name() string { return FormatRuleFinalNewline }
+
+func (finalNewlineRule) Check(nippo *Nippo, content []byte) (string, error) {
+	switch {
+	case len(bytes.TrimRight(content, "\n")) == 0:
+		return "", nil
+	case !bytes.HasSuffix(content, []byte("\n")):
+		return "Added final newline", nil
+	case bytes.HasSuffix(content, []byte("\n\n")):
+		return "Removed trailing blank lines", nil
+	default:
+		return "", nil
+	}
+}
+
+func (finalNewlineRule) Fix(nippo *Nippo, content []byte) ([]byte, error) {
+	return append(bytes.TrimRight(content, "\n"), '\n'), nil
+}
+
+// headingLevelsRule shifts the headings so the top ones are at the configured
+// level, and pulls up headings that skip a level below the previous heading
+type headingLevelsRule struct {
+	top int
+}
+
+func (headingLevelsRule) Name() string { return FormatRuleHeadingLevels }
+
+func (r headingLevelsRule) Check(nippo *Nippo, content []byte) (string, error) {
+	if bytes.Equal(r.normalize(content), content) {
+		return "", nil
+	}
+	return "Normalized heading levels", nil
+}
+
+func (r headingLevelsRule) Fix(nippo *Nippo, content []byte) ([]byte, error) {
+	return r.normalize(content), nil
+}
+
+func (r headingLevelsRule) normalize(content []byte) []byte {
+	minLevel := 0
+	mapBodyLines(content, func(line string) string {
+		if level, _, _ := atxHeading(line); level > 0 && (minLevel == 0 || level < minLevel) {
+			minLevel = level
+		}
+		return line
+	})
+	if minLevel == 0 {
+		return content
+	}
+
+	prev := r.top - 1
+	return mapBodyLines(content, func(line string) string {
+		level, indent, rest := atxHeading(line)
+		if level == 0 {
+			return line
+		}
+		level = min(level-minLevel+r.top, prev+1, 6)
+		prev = level
+		return indent + strings.Repeat("#", level) + rest
+	})
+}
+
+// atxHeading returns the level of an ATX heading line with its indentation and
+// the text after the '#'s, or level 0 when line is not a heading
+func atxHeading(line string) (int, string, string) {
+	text := strings.TrimLeft(line, " ")
+	indent := line[:len(line)-len(text)]
+	if len(indent) > 3 {
+		return 0, "", ""
+	}
+	rest := strings.TrimLeft(text, "#")
+	level := len(text) - len(rest)
+	if level == 0 || level > 6 || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+		return 0, "", ""
+	}
+	return level, indent, rest
+}
+
+// sortTagsRule sorts the tags list of the front-matter
+type sortTagsRule struct{}
+
+func (sortTagsRule) Name() string { return FormatRuleSortTags }
+
+func (sortTagsRule) Check(nippo *Nippo, content []byte) (string, error) {
+	tags, err := frontMatterTags(content)
+	if err != nil {
+		return "", err
+	}
+	if slices.IsSorted(tags) {
+		return "", nil
+	}
+	return "Sorted tags", nil
+}
+
+func (sortTagsRule) Fix(nippo *Nippo, content []byte) ([]byte, error) {
+	tags, err := frontMatterTags(content)
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		return content, nil
+	}
+	src, err := newFrontMatterEditor(content)
+	if err != nil {
+		return nil, malformedFrontMatter(err)
+	}
+	// Move the items themselves, so their quotes and comments go along
+	list := src.value("tags")
+	sorted := *list
+	sorted.Content = slices.Clone(list.Content)
+	slices.SortStableFunc(sorted.Content, func(a, b *yaml.Node) int {
+		return strings.Compare(a.Value, b.Value)
+	})
+	if err := src.set("tags", &sorted, ""); err != nil {
+		return nil, err
+	}
+	return src.Bytes(), nil
+}
+
+// frontMatterTags returns the tags of the front-matter, or nil unless they are a list of strings
+func frontMatterTags(content []byte) ([]string, error) {
+	fm, _, err := ParseFrontMatter(content)
+	if err != nil {
+		return nil, malformedFrontMatter(err)
+	}
+	if fm == nil {
+		return nil, nil
+	}
+	list, ok := fm.Raw["tags"].([]interface{})
+	if !ok {
+		return nil, nil
+	}
+	tags := make([]string, 0, len(list))
+	for _, v := range list {
+		tag, ok := v.(string)
+		if !ok {
+			return nil, nil
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// mapBodyLines replaces each line of the Markdown body of content with the
+// result of fn, leaving the front-matter and fenced code blocks as they are
+func mapBodyLines(content []byte, fn func(line string) string) []byte {
+	head, body := splitFrontMatterBlock(content)
+
+	var buf bytes.Buffer
+	buf.Write(head)
+	fence := ""
+	for _, line := range strings.SplitAfter(string(body), "\n") {
+		text := strings.TrimSuffix(line, "\n")
+		eol := line[len(text):]
+		marker := codeFence(text)
+		switch {
+		case fence == "" && marker != "":
+			fence = marker
+		case fence != "":
+			// A fence is closed by at least as many of the same character and nothing else
+			if marker != "" && marker[0] == fence[0] && len(marker) >= len(fence) &&
+				strings.TrimSpace(strings.TrimLeft(text, " ")[len(marker):]) == "" {
+				fence = ""
+			}
+		default:
+			text = fn(text)
+		}
+		buf.WriteString(text)
+		buf.WriteString(eol)
+	}
+	return buf.Bytes()
+}
+
+// codeFence returns the opening backticks or tildes of a fenced code block line, or ""
+func codeFence(line string) string {
+	text := strings.TrimLeft(line, " ")
+	if len(line)-len(text) > 3 {
+		return ""
+	}
+	for _, c := range []string{"`", "~"} {
+		if n := len(text) - len(strings.TrimLeft(text, c)); n >= 3 {
+			return text[:n]
+		}
+	}
+	return ""
+}
+
+// splitFrontMatterBlock splits content into the front-matter block, including
+// its closing delimiter line, and the rest
+func splitFrontMatterBlock(content []byte) ([]byte, []byte) {
+	b := splitFrontMatter(content)
+	if b == nil {
+		return nil, content
+	}
+	end := len(content) - len(b.tail) + b.closeLen
+	if next := bytes.IndexByte(content[end:], '\n'); next != -1 {
+		end += next + 1
+	} else {
+		end = len(content)
+	}
+	return content[:end], content[end:]
+}
@@ -0,0 +1,253 @@
+package model
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+func TestNewFormatRules(t *testing.T) {
+	rules, err := NewFormatRules(nil, FormatRuleOptions{})
+	if err != nil {
+		t.Fatalf("NewFormatRules() error = %v", err)
+	}
+	var names []string
+	for _, rule := range rules {
+		names = append(names, rule.Name())
+	}
+	if !slices.Equal(names, DefaultFormatRules) {
+		t.Errorf("default rules = %v, want %v", names, DefaultFormatRules)
+	}
+
+	rules, err = NewFormatRules([]string{FormatRuleFinalNewline, FormatRuleSortTags, FormatRuleFinalNewline}, FormatRuleOptions{})
+	if err != nil {
+		t.Fatalf("NewFormatRules() error = %v", err)
+	}
+	if len(rules) != 2 || rules[0].Name() != FormatRuleFinalNewline || rules[1].Name() != FormatRuleSortTags {
+		t.Errorf("NewFormatRules() should keep the configured order without duplicates, got %d rules", len(rules))
+	}
+
+	if _, err := NewFormatRules([]string{"lint"}, FormatRuleOptions{}); err == nil || !strings.Contains(err.Error(), FormatRuleSortTags) {
+		t.Errorf("NewFormatRules() error = %v, want an unknown rule listing the available ones", err)
+	}
+	if _, err := NewFormatRules(nil, FormatRuleOptions{HeadingTopLevel: 7}); err == nil {
+		t.Error("NewFormatRules() expected error for heading level 7")
+	}
+	if _, err := NewFormatRules(nil, FormatRuleOptions{FrontMatterFormat: FrontMatterTOML}); err == nil {
+		t.Error("NewFormatRules() expected error for converting front-matter to TOML")
+	}
+}
+
+func TestFormatRules_FrontMatterFormat(t *testing.T) {
+	content := "+++\ntitle = \"Day\"\ntags = [\"b\", \"a\"]\n+++\n\nBody\n"
+	tests := []struct {
+		name       string
+		opts       FormatRuleOptions
+		want       string
+		wantReason string
+	}{
+		{
+			name: "keep",
+			want: content,
+		},
+		{
+			name:       "yaml",
+			opts:       FormatRuleOptions{FrontMatterFormat: FrontMatterYAML},
+			want:       "---\ntitle: Day\ntags:\n  - b\n  - a\n---\n\nBody\n",
+			wantReason: "Converted TOML front-matter to YAML",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := NewFormatRules([]string{FormatRuleFrontMatter}, tt.opts)
+			if err != nil {
+				t.Fatalf("NewFormatRules() error = %v", err)
+			}
+			got, reasons, err := ApplyFormatRules(rules, &Nippo{Content: []byte(content)})
+			if err != nil {
+				t.Fatalf("ApplyFormatRules() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ApplyFormatRules() = %q, want %q", got, tt.want)
+			}
+			if reason := strings.Join(reasons, ", "); reason != tt.wantReason {
+				t.Errorf("reasons = %q, want %q", reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestFormatRules_Location(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := NewFormatRules([]string{FormatRuleFrontMatter}, FormatRuleOptions{Location: tokyo})
+	if err != nil {
+		t.Fatalf("NewFormatRules() error = %v", err)
+	}
+
+	// Created just after midnight in Tokyo, which is the day before in UTC
+	nippo := &Nippo{
+		Content:    []byte("# Title\n"),
+		RemoteFile: &drive.File{CreatedTime: "2024-01-15T15:05:00Z"},
+	}
+	got, _, err := ApplyFormatRules(rules, nippo)
+	if err != nil {
+		t.Fatalf("ApplyFormatRules() error = %v", err)
+	}
+	if !strings.Contains(string(got), "created: 2024-01-16T00:05:00+09:00") {
+		t.Errorf("ApplyFormatRules() = %q, want the created time in Tokyo", got)
+	}
+}
+
+func TestFormatRules(t *testing.T) {
+	tests := []struct {
+		rule    string
+		opts    FormatRuleOptions
+		content string
+		want    string
+		reason  string
+	}{
+		{
+			rule:    FormatRuleFrontMatter,
+			content: "# Title\n",
+			want:    "---\ncreated: " + remoteFileTime("2024-01-15T01:00:00Z", time.Local).Format("2006-01-02T15:04:05Z07:00") + "\n---\n\n# Title\n",
+			reason:  "Added front-matter",
+		},
+		{
+			rule:    FormatRuleCreated,
+			content: "---\ntitle: Day\n---\n\n# Title\n",
+			want:    "---\ncreated: " + remoteFileTime("2024-01-15T01:00:00Z", time.Local).Format("2006-01-02T15:04:05Z07:00") + "\ntitle: Day\n---\n\n# Title\n",
+			reason:  "Added created field",
+		},
+		{
+			rule:    FormatRuleUpdatedNow,
+			content: "---\ncreated: 2024-01-15T10:00:00+09:00\nupdated: now\n---\n\n# Title\n",
+			want:    "---\ncreated: 2024-01-15T10:00:00+09:00\nupdated: " + remoteFileTime("2024-01-15T12:00:00Z", time.Local).Format("2006-01-02T15:04:05Z07:00") + "\n---\n\n# Title\n",
+			reason:  "Replaced updated: now",
+		},
+		{
+			rule:    FormatRuleTrailingWhitespace,
+			content: "---\ntitle: Day \n---\n\nline \t\nbreak  \ntab\t  \n```\ncode  \n```\n",
+			want:    "---\ntitle: Day \n---\n\nline\nbreak  \ntab\n```\ncode  \n```\n",
+			reason:  "Removed trailing whitespace",
+		},
+		{
+			rule:    FormatRuleFinalNewline,
+			content: "# Title",
+			want:    "# Title\n",
+			reason:  "Added final newline",
+		},
+		{
+			rule:    FormatRuleFinalNewline,
+			content: "# Title\n\n\n",
+			want:    "# Title\n",
+			reason:  "Removed trailing blank lines",
+		},
+		{
+			rule:    FormatRuleHeadingLevels,
+			content: "## Title\n#### Done\n##### Item\n### Todo\n```sh\n# comment\n```\n",
+			want:    "# Title\n## Done\n### Item\n## Todo\n```sh\n# comment\n```\n",
+			reason:  "Normalized heading levels",
+		},
+		{
+			rule:    FormatRuleHeadingLevels,
+			opts:    FormatRuleOptions{HeadingTopLevel: 2},
+			content: "# Title\n#hashtag\n",
+			want:    "## Title\n#hashtag\n",
+			reason:  "Normalized heading levels",
+		},
+		{
+			rule:    FormatRuleSpaces,
+			content: "---\ntitle: 日報　一\n---\n\n今日は　晴れ\n~~~\n全角　スペース\n~~~\n",
+			want:    "---\ntitle: 日報　一\n---\n\n今日は 晴れ\n~~~\n全角　スペース\n~~~\n",
+			reason:  "Replaced full-width spaces",
+		},
+		{
+			rule:    FormatRuleSortTags,
+			content: "---\ncreated: 2024-01-15T10:00:00+09:00\ntags:\n  - work\n  - go\n---\n\n# Title\n",
+			want:    "---\ncreated: 2024-01-15T10:00:00+09:00\ntags:\n  - go\n  - work\n---\n\n# Title\n",
+			reason:  "Sorted tags",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			rules, err := NewFormatRules([]string{tt.rule}, tt.opts)
+			if err != nil {
+				t.Fatalf("NewFormatRules() error = %v", err)
+			}
+			nippo := &Nippo{
+				Content:    []byte(tt.content),
+				RemoteFile: &drive.File{CreatedTime: "2024-01-15T01:00:00Z", ModifiedTime: "2024-01-15T12:00:00Z"},
+			}
+			got, reasons, err := ApplyFormatRules(rules, nippo)
+			if err != nil {
+				t.Fatalf("ApplyFormatRules() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ApplyFormatRules() =\n%q\nwant\n%q", got, tt.want)
+			}
+			if len(reasons) != 1 || reasons[0] != tt.reason {
+				t.Errorf("reasons = %v, want [%s]", reasons, tt.reason)
+			}
+
+			// Fixed content needs no more fixes
+			nippo.Content = got
+			if _, reasons, _ := ApplyFormatRules(rules, nippo); len(reasons) != 0 {
+				t.Errorf("reasons after fixing = %v, want none", reasons)
+			}
+		})
+	}
+}
+
+func TestFormatRules_NoCreatedTime(t *testing.T) {
+	rules, err := NewFormatRules([]string{FormatRuleFrontMatter, FormatRuleCreated}, FormatRuleOptions{})
+	if err != nil {
+		t.Fatalf("NewFormatRules() error = %v", err)
+	}
+	nippo := &Nippo{Content: []byte("# Title\n"), RemoteFile: &drive.File{}}
+	got, reasons, err := ApplyFormatRules(rules, nippo)
+	if err != nil {
+		t.Fatalf("ApplyFormatRules() error = %v", err)
+	}
+	if string(got) != "---\n---\n\n# Title\n" {
+		t.Errorf("ApplyFormatRules() = %q, want front-matter without created", got)
+	}
+	if !slices.Equal(reasons, []string{"Added front-matter"}) {
+		t.Errorf("reasons = %v, want only the front-matter added", reasons)
+	}
+
+	nippo.Content = got
+	if _, reasons, _ := ApplyFormatRules(rules, nippo); len(reasons) != 0 {
+		t.Errorf("reasons after fixing = %v, want none", reasons)
+	}
+}
+
+func TestApplyFormatRules(t *testing.T) {
+	rules, err := NewFormatRules([]string{FormatRuleFrontMatter, FormatRuleCreated, FormatRuleTrailingWhitespace, FormatRuleFinalNewline}, FormatRuleOptions{})
+	if err != nil {
+		t.Fatalf("NewFormatRules() error = %v", err)
+	}
+	nippo := &Nippo{
+		Content:    []byte("# Title \nbody"),
+		RemoteFile: &drive.File{CreatedTime: "2024-01-15T01:00:00Z"},
+	}
+	_, reasons, err := ApplyFormatRules(rules, nippo)
+	if err != nil {
+		t.Fatalf("ApplyFormatRules() error = %v", err)
+	}
+	want := []string{"Added front-matter", "Removed trailing whitespace", "Added final newline"}
+	if !slices.Equal(reasons, want) {
+		t.Errorf("reasons = %v, want %v", reasons, want)
+	}
+
+	nippo.Content = []byte("---\ncreated: not-a-date\n---\n\n# Title\n")
+	if _, _, err := ApplyFormatRules(rules, nippo); !errors.Is(err, ErrInvalidDateFormat) || !strings.HasPrefix(err.Error(), "malformed front-matter") {
+		t.Errorf("ApplyFormatRules() error = %v, want malformed front-matter", err)
+	}
+}
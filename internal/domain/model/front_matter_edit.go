@@ -0,0 +1,241 @@
+package model
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// defaultFrontMatterIndent indents lists and maps added to front-matter
+const defaultFrontMatterIndent = 2
+
+// frontMatterEditor changes single fields of front-matter and keeps the others
+// as written
+type frontMatterEditor interface {
+	// keys returns the top-level keys in order
+	keys() []string
+	// value returns the value of key, or nil when it is missing
+	value(key string) *yaml.Node
+	// set replaces the value of key, or adds key after the field after, or first
+	// when after is "" or missing
+	set(key string, value *yaml.Node, after string) error
+	// Bytes returns the content with the edited front-matter
+	Bytes() []byte
+}
+
+// newFrontMatterEditor returns an editor of the front-matter of content in its
+// own format, or nil when content has none
+func newFrontMatterEditor(content []byte) (frontMatterEditor, error) {
+	b := splitFrontMatter(content)
+	if b == nil {
+		return nil, nil
+	}
+	switch b.format {
+	case FrontMatterTOML:
+		return newTOMLFrontMatter(b)
+	case FrontMatterJSON:
+		return newJSONFrontMatter(b)
+	default:
+		return newYAMLFrontMatter(b)
+	}
+}
+
+// frontMatterLines are the lines between the delimiters of front-matter
+type frontMatterLines struct {
+	head  []byte
+	lines []string
+	tail  []byte
+}
+
+func splitFrontMatterLines(b *frontMatterBlock) frontMatterLines {
+	l := frontMatterLines{head: b.head, tail: b.tail}
+	if len(b.data) > 0 {
+		l.lines = strings.Split(strings.TrimSuffix(string(b.data), "\n"), "\n")
+	}
+	return l
+}
+
+// Bytes returns the content with the edited lines
+func (l *frontMatterLines) Bytes() []byte {
+	var buf bytes.Buffer
+	buf.Write(l.head)
+	for _, line := range l.lines {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	buf.Write(l.tail)
+	return buf.Bytes()
+}
+
+// splice replaces the lines from start to end with lines
+func (l *frontMatterLines) splice(start, end int, lines ...string) {
+	edited := make([]string, 0, len(l.lines)+len(lines))
+	edited = append(edited, l.lines[:start]...)
+	edited = append(edited, lines...)
+	edited = append(edited, l.lines[end:]...)
+	l.lines = edited
+}
+
+// yamlFrontMatter edits YAML front-matter line by line, so that a field can be
+// replaced without touching the order, comments and style of the others
+type yamlFrontMatter struct {
+	frontMatterLines
+	root *yaml.Node
+}
+
+func newYAMLFrontMatter(b *frontMatterBlock) (*yamlFrontMatter, error) {
+	s := &yamlFrontMatter{frontMatterLines: splitFrontMatterLines(b)}
+	return s, s.parse()
+}
+
+// parse reads the lines into root, an empty mapping for empty front-matter
+func (s *yamlFrontMatter) parse() error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(strings.Join(s.lines, "\n")), &doc); err != nil {
+		return ErrMalformedYAML
+	}
+	if len(doc.Content) == 0 {
+		s.root = &yaml.Node{Kind: yaml.MappingNode}
+		return nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return ErrMalformedYAML
+	}
+	s.root = doc.Content[0]
+	return nil
+}
+
+func (s *yamlFrontMatter) keys() []string {
+	keys := make([]string, 0, len(s.root.Content)/2)
+	for i := 0; i+1 < len(s.root.Content); i += 2 {
+		keys = append(keys, s.root.Content[i].Value)
+	}
+	return keys
+}
+
+func (s *yamlFrontMatter) value(key string) *yaml.Node {
+	for i := 0; i+1 < len(s.root.Content); i += 2 {
+		if s.root.Content[i].Value == key {
+			return s.root.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// span returns the range of lines of the field at index i of the mapping, up to
+// the next field without the blank and comment lines before it
+func (s *yamlFrontMatter) span(i int) (int, int) {
+	start := s.root.Content[i].Line - 1
+	end := len(s.lines)
+	if i+2 < len(s.root.Content) {
+		end = s.root.Content[i+2].Line - 1
+	}
+	for end > start+1 {
+		line := strings.TrimSpace(s.lines[end-1])
+		if line != "" && !strings.HasPrefix(line, "#") {
+			break
+		}
+		end--
+	}
+	return start, end
+}
+
+// lineAfter returns the line after the field key, or the first line when it is missing
+func (s *yamlFrontMatter) lineAfter(key string) int {
+	for i := 0; i+1 < len(s.root.Content); i += 2 {
+		if s.root.Content[i].Value == key {
+			_, end := s.span(i)
+			return end
+		}
+	}
+	return 0
+}
+
+// set replaces the lines of the field key with value, or inserts them after the
+// field after when key is missing. The lines of the other fields are kept as they are.
+func (s *yamlFrontMatter) set(key string, value *yaml.Node, after string) error {
+	if s.root.Style&yaml.FlowStyle != 0 {
+		// All fields share the lines of a flow mapping, so write it again as a whole
+		return s.setFlow(key, value)
+	}
+
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: key}
+	start, end := 0, 0
+	if after != "" {
+		start = s.lineAfter(after)
+		end = start
+	}
+	indent := defaultFrontMatterIndent
+	for i := 0; i+1 < len(s.root.Content); i += 2 {
+		if s.root.Content[i].Value != key {
+			continue
+		}
+		old := s.root.Content[i+1]
+		start, end = s.span(i)
+		// The head comment stays above the replaced lines
+		copied := *s.root.Content[i]
+		copied.HeadComment, copied.FootComment = "", ""
+		keyNode = &copied
+		if value.LineComment == "" {
+			value.LineComment = old.LineComment
+		}
+		if old.Kind == value.Kind && old.Kind != yaml.ScalarNode {
+			// Keep flow lists as flow lists, and block lists at their indentation
+			value.Style = old.Style
+			indent = old.Column - keyNode.Column
+		}
+		break
+	}
+
+	lines, err := encodeFrontMatterField(keyNode, value, indent)
+	if err != nil {
+		return err
+	}
+	s.splice(start, end, lines...)
+	return s.parse()
+}
+
+// setFlow sets key in the flow mapping of the front-matter and writes it again
+func (s *yamlFrontMatter) setFlow(key string, value *yaml.Node) error {
+	if old := s.value(key); old != nil {
+		*old = *value
+	} else {
+		s.root.Content = append(s.root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+	out, err := yaml.Marshal(s.root)
+	if err != nil {
+		return err
+	}
+	s.lines = strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	return s.parse()
+}
+
+// encodeFrontMatterField returns the lines of a field, with lists and maps
+// indented by indent spaces
+func encodeFrontMatterField(key, value *yaml.Node, indent int) ([]string, error) {
+	// The encoder indents by at least two spaces, so less is taken off afterwards
+	encIndent := max(indent, 2)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(encIndent)
+	if err := enc.Encode(&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{key, value}}); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if dedent := encIndent - max(indent, 0); dedent > 0 {
+		for i := 1; i < len(lines); i++ {
+			lines[i] = strings.TrimPrefix(lines[i], strings.Repeat(" ", dedent))
+		}
+	}
+	return lines, nil
+}
+
+// timestampNode returns t as an unquoted RFC 3339 timestamp
+func timestampNode(t time.Time) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!timestamp", Value: t.Format(time.RFC3339)}
+}
@@ -0,0 +1,163 @@
+package model
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// TestFrontMatterGolden formats each testdata/front_matter/*.md with the rules
+// that edit front-matter, and compares the result with its .golden.md file.
+// Run `go test -update` to write the golden files again.
+func TestFrontMatterGolden(t *testing.T) {
+	rules, err := NewFormatRules([]string{FormatRuleFrontMatter, FormatRuleCreated, FormatRuleUpdatedNow, FormatRuleSortTags}, FormatRuleOptions{
+		Location: time.FixedZone("JST", 9*60*60),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inputs, err := filepath.Glob(filepath.Join("testdata", "front_matter", "*.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range inputs {
+		if strings.HasSuffix(input, ".golden.md") {
+			continue
+		}
+		t.Run(strings.TrimSuffix(filepath.Base(input), ".md"), func(t *testing.T) {
+			content, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			nippo := &Nippo{
+				Content: content,
+				RemoteFile: &drive.File{
+					CreatedTime:  "2024-01-15T00:30:00Z",
+					ModifiedTime: "2024-01-16T03:00:00Z",
+				},
+			}
+			got, _, err := ApplyFormatRules(rules, nippo)
+			if err != nil {
+				t.Fatalf("ApplyFormatRules() error = %v", err)
+			}
+
+			golden := strings.TrimSuffix(input, ".md") + ".golden.md"
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("formatted %s =\n%s\nwant\n%s", input, got, want)
+			}
+			if _, _, err := ParseFrontMatter(got); err != nil {
+				t.Errorf("formatted front-matter doesn't parse: %v", err)
+			}
+		})
+	}
+}
+
+func TestSetFrontMatterField(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		val     interface{}
+		want    string
+	}{
+		{
+			name:    "replace keeps the other fields",
+			content: "---\n# note\ntitle: Old # was\nz: 1\na: 2\n---\nBody",
+			val:     "New",
+			want:    "---\n# note\ntitle: New # was\nz: 1\na: 2\n---\nBody",
+		},
+		{
+			name:    "add after the last field",
+			content: "---\nz: 1\na: 2\n\n# trailing comment\n---\nBody",
+			val:     "New",
+			want:    "---\nz: 1\na: 2\ntitle: New\n\n# trailing comment\n---\nBody",
+		},
+		{
+			name:    "list keeps its flow style",
+			content: "---\ntitle: [a, b]\n---\n",
+			val:     []string{"x", "z"},
+			want:    "---\ntitle: [x, z]\n---\n",
+		},
+		{
+			name:    "toml field goes before the tables",
+			content: "+++\nz = 1 # kept\n\n[params]\na = 2\n+++\nBody",
+			val:     "New",
+			want:    "+++\nz = 1 # kept\ntitle = \"New\"\n\n[params]\na = 2\n+++\nBody",
+		},
+		{
+			name:    "toml keeps literal strings",
+			content: "+++\ntitle = 'Old'\nz = \"1\"\n+++\n",
+			val:     `C:\path "quoted"`,
+			want:    "+++\ntitle = 'C:\\path \"quoted\"'\nz = \"1\"\n+++\n",
+		},
+		{
+			name:    "toml new field quoted as the others",
+			content: "+++\nz = ['a', \"b\"]\n+++\n",
+			val:     []string{"x", `C:\path`},
+			want:    "+++\nz = ['a', \"b\"]\ntitle = ['x', 'C:\\path']\n+++\n",
+		},
+		{
+			name:    "toml basic strings are escaped",
+			content: "+++\nz = 1\n+++\n",
+			val:     `C:\path "quoted"`,
+			want:    "+++\nz = 1\ntitle = \"C:\\\\path \\\"quoted\\\"\"\n+++\n",
+		},
+		{
+			name:    "json keeps its indentation",
+			content: "{\n\t\"title\": \"Old\",\n\t\"z\": [1,2]\n}\nBody",
+			val:     "New",
+			want:    "{\n\t\"title\": \"New\",\n\t\"z\": [1,2]\n}\nBody",
+		},
+		{
+			name:    "json keeps the spacing of the other members",
+			content: "{\n  \"z\":1,\n    \"title\" : \"Old\"\n}\nBody",
+			val:     "New",
+			want:    "{\n  \"z\":1,\n    \"title\" : \"New\"\n}\nBody",
+		},
+		{
+			name:    "json member spaced like the last one",
+			content: "{\"z\":1,  \"a\":2}\nBody",
+			val:     "New",
+			want:    "{\"z\":1,  \"a\":2,  \"title\":\"New\"}\nBody",
+		},
+		{
+			name:    "json empty object",
+			content: "{}\nBody",
+			val:     "New",
+			want:    "{\"title\": \"New\"}\nBody",
+		},
+		{
+			name:    "no front-matter",
+			content: "Body",
+			val:     "New",
+			want:    "Body",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SetFrontMatterField([]byte(tt.content), "title", tt.val)
+			if err != nil {
+				t.Fatalf("SetFrontMatterField() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("SetFrontMatterField() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+// FrontMatterFormat is the syntax of the front-matter of a nippo
+type FrontMatterFormat string
+
+const (
+	// FrontMatterYAML is written between --- lines
+	FrontMatterYAML FrontMatterFormat = "yaml"
+	// FrontMatterTOML is written between +++ lines, as in Hugo
+	FrontMatterTOML FrontMatterFormat = "toml"
+	// FrontMatterJSON is a JSON object at the start of the file, as in Hugo
+	FrontMatterJSON FrontMatterFormat = "json"
+)
+
+var (
+	ErrMalformedTOML = errors.New("malformed TOML in front-matter")
+	ErrMalformedJSON = errors.New("malformed JSON in front-matter")
+)
+
+var frontMatterDelimiters = []struct {
+	format    FrontMatterFormat
+	delimiter []byte
+}{
+	{FrontMatterYAML, frontMatterDelimiter},
+	{FrontMatterTOML, []byte("+++")},
+}
+
+// frontMatterBlock is the front-matter of content split from the rest
+type frontMatterBlock struct {
+	format FrontMatterFormat
+	// head is the opening delimiter with its line break, empty for JSON
+	head []byte
+	// data is the front-matter between the delimiters, up to and including the
+	// line break before the closing one
+	data []byte
+	// tail is the closing delimiter and the body after it
+	tail []byte
+	// closeLen is the length of the closing delimiter at the start of tail
+	closeLen int
+}
+
+// splitFrontMatter returns the front-matter of content, or nil when content has none
+func splitFrontMatter(content []byte) *frontMatterBlock {
+	for _, d := range frontMatterDelimiters {
+		if !bytes.HasPrefix(content, d.delimiter) {
+			continue
+		}
+		openLen := len(d.delimiter)
+		if len(content) > openLen && content[openLen] == '\n' {
+			openLen++
+		}
+		b := &frontMatterBlock{format: d.format, head: content[:openLen], closeLen: len(d.delimiter)}
+		rest := content[openLen:]
+		if bytes.HasPrefix(rest, d.delimiter) {
+			// Empty front-matter
+			b.tail = rest
+			return b
+		}
+		end := bytes.Index(rest, append([]byte("\n"), d.delimiter...))
+		if end == -1 {
+			// No closing delimiter, treat as no front-matter
+			return nil
+		}
+		b.data, b.tail = rest[:end+1], rest[end+1:]
+		return b
+	}
+
+	if len(content) > 0 && content[0] == '{' {
+		// The object ends the front-matter, on a line of its own
+		dec := json.NewDecoder(bytes.NewReader(content))
+		var obj map[string]json.RawMessage
+		if err := dec.Decode(&obj); err != nil {
+			return nil
+		}
+		end := int(dec.InputOffset())
+		if end < len(content) && content[end] != '\n' && content[end] != '\r' {
+			return nil
+		}
+		return &frontMatterBlock{format: FrontMatterJSON, data: content[:end], tail: content[end:]}
+	}
+	return nil
+}
+
+// body returns the content after the closing delimiter without leading blank lines
+func (b *frontMatterBlock) body() []byte {
+	return bytes.TrimLeft(b.tail[b.closeLen:], "\n")
+}
+
+// decode parses the fields of the front-matter, an empty map for empty front-matter
+func (b *frontMatterBlock) decode() (map[string]interface{}, error) {
+	var raw map[string]interface{}
+	switch b.format {
+	case FrontMatterTOML:
+		if err := toml.Unmarshal(b.data, &raw); err != nil {
+			return nil, ErrMalformedTOML
+		}
+		for key, val := range raw {
+			raw[key] = fromTOMLValue(val)
+		}
+	case FrontMatterJSON:
+		if err := json.Unmarshal(b.data, &raw); err != nil {
+			return nil, ErrMalformedJSON
+		}
+	default:
+		if err := yaml.Unmarshal(b.data, &raw); err != nil {
+			return nil, ErrMalformedYAML
+		}
+	}
+	if raw == nil {
+		raw = make(map[string]interface{})
+	}
+	return raw, nil
+}
+
+// FrontMatterFormatOf returns the format of the front-matter of content, or ""
+// when content has none
+func FrontMatterFormatOf(content []byte) FrontMatterFormat {
+	if b := splitFrontMatter(content); b != nil {
+		return b.format
+	}
+	return ""
+}
+
+// ConvertFrontMatterToYAML writes TOML and JSON front-matter of content as YAML,
+// keeping the order of its fields. Content with YAML or no front-matter is
+// returned as is.
+func ConvertFrontMatterToYAML(content []byte) ([]byte, error) {
+	b := splitFrontMatter(content)
+	if b == nil || b.format == FrontMatterYAML {
+		return content, nil
+	}
+	editor, err := newFrontMatterEditor(content)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range editor.keys() {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, editor.value(key))
+	}
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	if len(root.Content) > 0 {
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(defaultFrontMatterIndent)
+		if err := enc.Encode(root); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteString("---")
+	buf.Write(b.tail[b.closeLen:])
+	return buf.Bytes(), nil
+}
+
+// fromTOMLValue turns the local dates and times of TOML into the values YAML
+// decodes them to, so front-matter reads the same in either format
+func fromTOMLValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case toml.LocalDate:
+		return v.AsTime(time.UTC)
+	case toml.LocalDateTime:
+		return v.AsTime(time.UTC)
+	case toml.LocalTime:
+		return v.String()
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = fromTOMLValue(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = fromTOMLValue(item)
+		}
+		return v
+	default:
+		return val
+	}
+}
@@ -0,0 +1,181 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseFrontMatter_Formats(t *testing.T) {
+	jst := time.FixedZone("", 9*60*60)
+	tests := []struct {
+		name          string
+		content       string
+		expectFormat  FrontMatterFormat
+		expectCreated time.Time
+		expectTitle   interface{}
+		expectBody    string
+		expectErr     error
+	}{
+		{
+			name:          "toml",
+			content:       "+++\ncreated = 2024-01-15T09:30:00+09:00\ntitle = \"Day\"\n+++\n\n# Content",
+			expectFormat:  FrontMatterTOML,
+			expectCreated: time.Date(2024, 1, 15, 9, 30, 0, 0, jst),
+			expectTitle:   "Day",
+			expectBody:    "# Content",
+		},
+		{
+			name:          "toml quoted time",
+			content:       "+++\ncreated = \"2024-01-15T09:30:00+09:00\"\n+++\n# Content",
+			expectFormat:  FrontMatterTOML,
+			expectCreated: time.Date(2024, 1, 15, 9, 30, 0, 0, jst),
+			expectBody:    "# Content",
+		},
+		{
+			name:         "empty toml",
+			content:      "+++\n+++\n# Content",
+			expectFormat: FrontMatterTOML,
+			expectBody:   "# Content",
+		},
+		{
+			name:          "json",
+			content:       "{\n  \"created\": \"2024-01-15T09:30:00+09:00\",\n  \"title\": \"Day\"\n}\n\n# Content",
+			expectFormat:  FrontMatterJSON,
+			expectCreated: time.Date(2024, 1, 15, 9, 30, 0, 0, jst),
+			expectTitle:   "Day",
+			expectBody:    "# Content",
+		},
+		{
+			name:       "brace in body text",
+			content:    "{not json}\n# Content",
+			expectBody: "{not json}\n# Content",
+		},
+		{
+			name:       "json object not on a line of its own",
+			content:    "{} text\n# Content",
+			expectBody: "{} text\n# Content",
+		},
+		{
+			name:       "unclosed toml",
+			content:    "+++\ntitle = \"Day\"\n# Content",
+			expectBody: "+++\ntitle = \"Day\"\n# Content",
+		},
+		{
+			name:      "malformed toml",
+			content:   "+++\ntitle = Day\n+++\n# Content",
+			expectErr: ErrMalformedTOML,
+		},
+		{
+			name:      "invalid created in json",
+			content:   "{\"created\": \"yesterday\"}\n# Content",
+			expectErr: ErrInvalidDateFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, body, err := ParseFrontMatter([]byte(tt.content))
+			if tt.expectErr != nil {
+				if !errors.Is(err, tt.expectErr) {
+					t.Fatalf("ParseFrontMatter() error = %v, want %v", err, tt.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFrontMatter() error = %v", err)
+			}
+			if string(body) != tt.expectBody {
+				t.Errorf("body = %q, want %q", body, tt.expectBody)
+			}
+			if tt.expectFormat == "" {
+				if fm != nil {
+					t.Errorf("ParseFrontMatter() = %+v, want no front-matter", fm)
+				}
+				return
+			}
+			if fm == nil {
+				t.Fatal("ParseFrontMatter() returned no front-matter")
+			}
+			if fm.Format != tt.expectFormat {
+				t.Errorf("Format = %q, want %q", fm.Format, tt.expectFormat)
+			}
+			if !fm.Created.Equal(tt.expectCreated) {
+				t.Errorf("Created = %v, want %v", fm.Created, tt.expectCreated)
+			}
+			if fm.Raw["title"] != tt.expectTitle {
+				t.Errorf("title = %v, want %v", fm.Raw["title"], tt.expectTitle)
+			}
+		})
+	}
+}
+
+func TestHasNowPlaceholder_Formats(t *testing.T) {
+	tests := map[string]bool{
+		"+++\nupdated = \"now\"\n+++\n":         true,
+		"+++\nupdated = 2024-01-15\n+++\n":      false,
+		"{\"updated\": \"now\"}\n":              true,
+		"{\"title\": \"updated: now\"}\n":       false,
+		"+++\ntitle = \"updated = now\"\n+++\n": false,
+	}
+	for content, want := range tests {
+		if got := HasNowPlaceholder([]byte(content)); got != want {
+			t.Errorf("HasNowPlaceholder(%q) = %v, want %v", content, got, want)
+		}
+	}
+}
+
+func TestNippo_ResolveDateFromTOML(t *testing.T) {
+	nippo := &Nippo{
+		FilePath: "imported.md",
+		Content:  []byte("+++\ndate = 2024-01-15\n+++\n# Content"),
+	}
+	if err := nippo.ResolveDate(time.UTC); err != nil {
+		t.Fatalf("ResolveDate() error = %v", err)
+	}
+	if got := nippo.Date.FileString(); got != "2024-01-15" {
+		t.Errorf("date = %s, want 2024-01-15", got)
+	}
+}
+
+func TestConvertFrontMatterToYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "toml keeps the order of the fields",
+			content: "+++\nz = 1\ncreated = 2024-01-15T09:30:00+09:00\ntags = [\"b\", \"a\"]\n\n[params]\nmood = \"calm\"\n+++\n\nBody\n",
+			want:    "---\nz: 1\ncreated: 2024-01-15T09:30:00+09:00\ntags:\n  - b\n  - a\nparams:\n  mood: calm\n---\n\nBody\n",
+		},
+		{
+			name:    "json",
+			content: "{\"title\": \"Day\", \"a\": [1, 2]}\nBody\n",
+			want:    "---\ntitle: Day\na:\n  - 1\n  - 2\n---\nBody\n",
+		},
+		{
+			name:    "empty toml",
+			content: "+++\n+++\nBody\n",
+			want:    "---\n---\nBody\n",
+		},
+		{
+			name:    "yaml is kept",
+			content: "---\nb: 1\na: 2 # comment\n---\nBody\n",
+			want:    "---\nb: 1\na: 2 # comment\n---\nBody\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConvertFrontMatterToYAML([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("ConvertFrontMatterToYAML() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ConvertFrontMatterToYAML() = %q, want %q", got, tt.want)
+			}
+			if _, _, err := ParseFrontMatter(got); err != nil {
+				t.Errorf("converted front-matter doesn't parse: %v", err)
+			}
+		})
+	}
+}
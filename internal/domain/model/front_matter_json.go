@@ -0,0 +1,146 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// jsonFrontMatter edits the members of JSON front-matter in place, so the
+// others keep their order, spacing and values as written
+type jsonFrontMatter struct {
+	// data is the object as written, from { to }
+	data   []byte
+	fields []jsonField
+	tail   []byte
+}
+
+// jsonField is a member of the object, at offsets of data
+type jsonField struct {
+	key string
+	// start is where the key starts, keyEnd where it ends, and value is the
+	// range of the value
+	start, keyEnd, valueStart, end int
+}
+
+func newJSONFrontMatter(b *frontMatterBlock) (*jsonFrontMatter, error) {
+	f := &jsonFrontMatter{data: b.data, tail: b.tail}
+	return f, f.parse()
+}
+
+// parse finds the offsets of each member of the object
+func (f *jsonFrontMatter) parse() error {
+	f.fields = nil
+	dec := json.NewDecoder(bytes.NewReader(f.data))
+	if _, err := dec.Token(); err != nil {
+		return ErrMalformedJSON
+	}
+	for dec.More() {
+		prev := int(dec.InputOffset())
+		tok, err := dec.Token()
+		if err != nil {
+			return ErrMalformedJSON
+		}
+		key, _ := tok.(string)
+		keyEnd := int(dec.InputOffset())
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return ErrMalformedJSON
+		}
+		end := int(dec.InputOffset())
+		f.fields = append(f.fields, jsonField{
+			key:        key,
+			start:      prev + bytes.IndexByte(f.data[prev:keyEnd], '"'),
+			keyEnd:     keyEnd,
+			valueStart: end - len(value),
+			end:        end,
+		})
+	}
+	return nil
+}
+
+func (f *jsonFrontMatter) keys() []string {
+	keys := make([]string, 0, len(f.fields))
+	for _, field := range f.fields {
+		keys = append(keys, field.key)
+	}
+	return keys
+}
+
+func (f *jsonFrontMatter) field(key string) (jsonField, bool) {
+	for _, field := range f.fields {
+		if field.key == key {
+			return field, true
+		}
+	}
+	return jsonField{}, false
+}
+
+func (f *jsonFrontMatter) value(key string) *yaml.Node {
+	field, ok := f.field(key)
+	if !ok {
+		return nil
+	}
+	var val interface{}
+	if err := json.Unmarshal(f.data[field.valueStart:field.end], &val); err != nil {
+		return nil
+	}
+	var node yaml.Node
+	if err := node.Encode(val); err != nil {
+		return nil
+	}
+	return &node
+}
+
+// set replaces the value of the member key, or adds the member after the
+// member after, spaced like the last member
+func (f *jsonFrontMatter) set(key string, value *yaml.Node, after string) error {
+	var val interface{}
+	if err := value.Decode(&val); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(val); err != nil {
+		return err
+	}
+	encoded := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+
+	if field, ok := f.field(key); ok {
+		f.splice(field.valueStart, field.end, encoded)
+		return f.parse()
+	}
+
+	name, _ := json.Marshal(key)
+	if len(f.fields) == 0 {
+		member := slices.Concat(name, []byte(": "), encoded)
+		if bytes.ContainsRune(f.data, '\n') {
+			member = slices.Concat([]byte("\n"+strings.Repeat(" ", defaultFrontMatterIndent)), member, []byte("\n"))
+		}
+		f.splice(1, len(f.data)-1, member)
+		return f.parse()
+	}
+	last := f.fields[len(f.fields)-1]
+	lead := f.data[len(bytes.TrimRight(f.data[:last.start], " \t\r\n")):last.start]
+	member := slices.Concat(name, f.data[last.keyEnd:last.valueStart], encoded)
+	if field, ok := f.field(after); ok {
+		f.splice(field.end, field.end, slices.Concat([]byte(","), lead, member))
+	} else {
+		f.splice(f.fields[0].start, f.fields[0].start, slices.Concat(member, []byte(","), lead))
+	}
+	return f.parse()
+}
+
+// splice replaces data[start:end] with b
+func (f *jsonFrontMatter) splice(start, end int, b []byte) {
+	f.data = slices.Concat(f.data[:start], b, f.data[end:])
+}
+
+// Bytes returns the content with the object as edited
+func (f *jsonFrontMatter) Bytes() []byte {
+	return slices.Concat(f.data, f.tail)
+}
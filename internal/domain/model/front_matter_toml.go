@@ -0,0 +1,255 @@
+package model
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+// tomlFrontMatter edits the top-level fields of TOML front-matter line by line,
+// keeping the comments and the other fields as written
+type tomlFrontMatter struct {
+	frontMatterLines
+	raw map[string]interface{}
+	// fields are the top-level key/value pairs before the first table, in order
+	fields []tomlField
+}
+
+// tomlField is a key/value pair taking the lines from start to end
+type tomlField struct {
+	key        string
+	start, end int
+}
+
+func newTOMLFrontMatter(b *frontMatterBlock) (*tomlFrontMatter, error) {
+	f := &tomlFrontMatter{frontMatterLines: splitFrontMatterLines(b)}
+	return f, f.parse()
+}
+
+// parse decodes the lines and finds the lines of each top-level field
+func (f *tomlFrontMatter) parse() error {
+	raw, err := decodeTOML(f.lines)
+	if err != nil {
+		return ErrMalformedTOML
+	}
+	f.raw = raw
+	f.fields = nil
+	for i := 0; i < len(f.lines); {
+		line := strings.TrimSpace(f.lines[i])
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			i++
+			continue
+		case strings.HasPrefix(line, "["):
+			// Tables come after all the top-level fields
+			return nil
+		}
+		field, ok := f.parseField(i)
+		if !ok {
+			return ErrMalformedTOML
+		}
+		f.fields = append(f.fields, field)
+		i = field.end
+	}
+	return nil
+}
+
+// parseField returns the field starting at line i, which ends at the first line
+// that completes its value
+func (f *tomlFrontMatter) parseField(i int) (tomlField, bool) {
+	for end := i + 1; end <= len(f.lines); end++ {
+		raw, err := decodeTOML(f.lines[i:end])
+		if err != nil {
+			continue
+		}
+		for key := range raw {
+			return tomlField{key: key, start: i, end: end}, true
+		}
+	}
+	return tomlField{}, false
+}
+
+func (f *tomlFrontMatter) field(key string) (tomlField, bool) {
+	for _, field := range f.fields {
+		if field.key == key {
+			return field, true
+		}
+	}
+	return tomlField{}, false
+}
+
+// keys returns the top-level fields in order, then the tables by name
+func (f *tomlFrontMatter) keys() []string {
+	keys := make([]string, 0, len(f.raw))
+	for _, field := range f.fields {
+		if !slices.Contains(keys, field.key) {
+			keys = append(keys, field.key)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(f.raw)) {
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (f *tomlFrontMatter) value(key string) *yaml.Node {
+	val, ok := f.raw[key]
+	if !ok {
+		return nil
+	}
+	var node yaml.Node
+	if err := node.Encode(fromTOMLValue(val)); err != nil {
+		return nil
+	}
+	return &node
+}
+
+// set replaces the lines of the field key, keeping a comment at the end of a
+// single line, or adds the field after the field after. Strings are quoted as
+// the field was, or as the first string of the front-matter for a new field.
+func (f *tomlFrontMatter) set(key string, value *yaml.Node, after string) error {
+	var val interface{}
+	if err := value.Decode(&val); err != nil {
+		return err
+	}
+	field, exists := f.field(key)
+	quote := byte(0)
+	if exists {
+		quote = tomlQuote(f.lines[field.start:field.end])
+	}
+	if quote == 0 {
+		quote = cmp.Or(tomlQuote(f.lines), '"')
+	}
+	line, err := encodeTOMLField(key, val, quote)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		old := f.lines[field.start]
+		line = old[:len(old)-len(strings.TrimLeft(old, " \t"))] + line
+		if field.end == field.start+1 {
+			if comment := tomlLineComment(old); comment != "" {
+				line += " " + comment
+			}
+		}
+		f.splice(field.start, field.end, line)
+		return f.parse()
+	}
+	if _, ok := f.raw[key]; ok {
+		return fmt.Errorf("%s is a table in the TOML front-matter", key)
+	}
+	at := 0
+	if field, ok := f.field(after); ok {
+		at = field.end
+	} else if _, ok := f.raw[after]; ok && len(f.fields) > 0 {
+		// After a table means after the last field, as the tables come last
+		at = f.fields[len(f.fields)-1].end
+	}
+	f.splice(at, at, line)
+	return f.parse()
+}
+
+// encodeTOMLField returns the line of a top-level field, with tables written
+// inline and strings quoted with quote, ' or "
+func encodeTOMLField(key string, val interface{}, quote byte) (string, error) {
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.SetTablesInline(true)
+	if err := enc.Encode(map[string]interface{}{key: val}); err != nil {
+		return "", err
+	}
+	line := strings.TrimSuffix(buf.String(), "\n")
+	if quote == '"' {
+		line = tomlBasicStrings(line)
+	}
+	return line, nil
+}
+
+// tomlBasicStrings rewrites the literal strings of line, which the encoder
+// prefers, as basic strings in double quotes
+func tomlBasicStrings(line string) string {
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			// Basic strings stay as they are, up to the unescaped closing quote
+			end := i + 1
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end, len(line)-1)
+			b.WriteString(line[i : end+1])
+			i = end
+		case '\'':
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end == -1 {
+				b.WriteString(line[i:])
+				return b.String()
+			}
+			s := line[i+1 : i+1+end]
+			b.WriteByte('"')
+			b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s))
+			b.WriteByte('"')
+			i += end + 1
+		default:
+			b.WriteByte(line[i])
+		}
+	}
+	return b.String()
+}
+
+// tomlQuote returns the quote of the first string in the values of lines, '
+// or ", or 0 when they have no strings
+func tomlQuote(lines []string) byte {
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "[") {
+			continue
+		}
+		// The value starts after the =, or the line continues a value
+		_, value, found := strings.Cut(line, "=")
+		if !found {
+			value = line
+		}
+		if i := strings.IndexAny(value, `'"#`); i != -1 && value[i] != '#' {
+			return value[i]
+		}
+	}
+	return 0
+}
+
+// tomlLineComment returns the comment at the end of a key/value line, or ""
+func tomlLineComment(line string) string {
+	for i := strings.IndexByte(line, '#'); i != -1; {
+		// A # inside a string leaves the string unterminated
+		if _, err := decodeTOML([]string{line[:i]}); err == nil {
+			return line[i:]
+		}
+		next := strings.IndexByte(line[i+1:], '#')
+		if next == -1 {
+			break
+		}
+		i += next + 1
+	}
+	return ""
+}
+
+func decodeTOML(lines []string) (map[string]interface{}, error) {
+	var raw map[string]interface{}
+	if err := toml.Unmarshal([]byte(strings.Join(lines, "\n")), &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
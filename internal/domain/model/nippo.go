@@ -6,22 +6,24 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
 	"github.com/gomarkdown/markdown/html"
 	"github.com/gomarkdown/markdown/parser"
 	"go.yaml.in/yaml/v3"
 	"google.golang.org/api/drive/v3"
 )
 
-// FrontMatter represents the YAML front-matter metadata in a nippo file
+// FrontMatter represents the front-matter metadata in a nippo file
 type FrontMatter struct {
 	Created time.Time `yaml:"created,omitempty"`
 	Updated time.Time `yaml:"updated,omitempty"`
-	Raw     map[string]interface{}
+	// Format is the syntax the front-matter is written in
+	Format FrontMatterFormat `yaml:"-"`
+	Raw    map[string]interface{}
 }
 
 var (
@@ -36,6 +38,9 @@ type Nippo struct {
 	Content     []byte
 	FrontMatter *FrontMatter
 	RemoteFile  *drive.File
+	// RemotePath is the slash-separated path of RemoteFile within the folder it
+	// was listed from, such as 2024/01/15.md
+	RemotePath string
 }
 
 type NippoDate interface {
@@ -47,9 +52,13 @@ type NippoDate interface {
 	Month() time.Month
 	Day() int
 	Weekday() time.Weekday
+	// Suffix tells apart the nippo of a day, such as "evening" for
+	// 2024-01-15_evening.md. It is empty for the nippo named after the day alone.
+	Suffix() string
 }
 type nippoDate struct {
-	time time.Time
+	time   time.Time
+	suffix string
 }
 
 func NewNippo(filePath string) (*Nippo, error) {
@@ -59,28 +68,37 @@ func NewNippo(filePath string) (*Nippo, error) {
 		return nippo, err
 	}
 
-	nippo.Date = NewNippoDate(filePath)
+	date, err := ParseNippoDate(filePath)
+	if err != nil {
+		return nippo, err
+	}
+	nippo.Date = date
 	nippo.FilePath = filePath
 	return nippo, nil
 }
 
+// NewNippoDate is like ParseNippoDate but panics when filePath doesn't start
+// with a date. It is meant for names nippo builds itself from a date.
 func NewNippoDate(filePath string) NippoDate {
-	date, err := time.Parse("2006-01-02", filepath.Base(filePath)[:10])
+	date, err := ParseNippoDate(filePath)
 	if err != nil {
 		panic(err)
 	}
-	return &nippoDate{date}
+	return date
 }
 
 func (n *Nippo) GetMarkdown() ([]byte, error) {
 	if len(n.Content) > 0 {
 		// If content is already loaded, strip front-matter and return body
-		_, body, err := ParseFrontMatter(n.Content)
+		fm, body, err := ParseFrontMatter(n.Content)
 		if err != nil {
 			// On parse error, log warning and return content as-is
 			fmt.Fprintf(os.Stderr, "Warning: malformed front-matter in %s: %v\n", n.FilePath, err)
 			return n.Content, nil
 		}
+		if fm != nil {
+			n.FrontMatter = fm
+		}
 		return body, nil
 	}
 	f, err := os.Open(n.FilePath)
@@ -112,16 +130,26 @@ func (n *Nippo) GetHtml() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	return renderMarkdown(data), nil
+}
+
+// renderMarkdown renders the body of a nippo to HTML
+func renderMarkdown(data []byte) []byte {
+	return renderHtml(parseMarkdown(data))
+}
 
+func parseMarkdown(data []byte) ast.Node {
 	extensions := parser.CommonExtensions | parser.NoEmptyLineBeforeBlock
 	p := parser.NewWithExtensions(extensions)
-	doc := p.Parse(data)
+	return p.Parse(data)
+}
 
+func renderHtml(doc ast.Node) []byte {
 	htmlFlags := html.CommonFlags
 	opts := html.RendererOptions{Flags: htmlFlags}
 	renderer := html.NewRenderer(opts)
 
-	return markdown.Render(doc, renderer), nil
+	return markdown.Render(doc, renderer)
 }
 
 func checkNippoIsExist(filePath string) error {
@@ -136,16 +164,23 @@ func (date *nippoDate) String() string {
 }
 
 func (date *nippoDate) FileString() string {
-	return fmt.Sprintf("%04d-%02d-%02d", date.time.Year(), date.time.Month(), date.time.Day())
+	return fmt.Sprintf("%04d-%02d-%02d", date.time.Year(), date.time.Month(), date.time.Day()) + date.suffixString("-")
 }
 
 func (date *nippoDate) PathString() string {
-	return fmt.Sprintf("%04d%02d%02d", date.time.Year(), date.time.Month(), date.time.Day())
+	return fmt.Sprintf("%04d%02d%02d", date.time.Year(), date.time.Month(), date.time.Day()) + date.suffixString("-")
 }
 
 func (date *nippoDate) TitleString() string {
 	return fmt.Sprintf("%02d/%02d %s", date.time.Month(), date.time.Day(),
-		strings.ToLower(date.time.Weekday().String()[:3]))
+		strings.ToLower(date.time.Weekday().String()[:3])) + date.suffixString(" ")
+}
+
+func (date *nippoDate) suffixString(sep string) string {
+	if date.suffix == "" {
+		return ""
+	}
+	return sep + date.suffix
 }
 
 func (date *nippoDate) Year() int {
@@ -164,29 +199,34 @@ func (date *nippoDate) Weekday() time.Weekday {
 	return date.time.Weekday()
 }
 
-// HasFrontMatter checks if content starts with front-matter delimiters
+func (date *nippoDate) Suffix() string {
+	return date.suffix
+}
+
+// HasFrontMatter checks if content starts with YAML or TOML front-matter
+// delimiters, or with a JSON object
 func HasFrontMatter(content []byte) bool {
-	return bytes.HasPrefix(content, frontMatterDelimiter)
+	for _, d := range frontMatterDelimiters {
+		if bytes.HasPrefix(content, d.delimiter) {
+			return true
+		}
+	}
+	return splitFrontMatter(content) != nil
 }
 
-// HasNowPlaceholder checks if content contains "updated: now" in front-matter
+// HasNowPlaceholder checks if content contains "updated: now" in front-matter,
+// or updated = "now" in TOML and JSON front-matter
 func HasNowPlaceholder(content []byte) bool {
-	if !HasFrontMatter(content) {
+	b := splitFrontMatter(content)
+	if b == nil {
 		return false
 	}
-
-	// Find the end of front-matter
-	rest := content[3:]
-	if len(rest) > 0 && rest[0] == '\n' {
-		rest = rest[1:]
-	}
-
-	endIndex := bytes.Index(rest, []byte("\n---"))
-	if endIndex == -1 {
-		return false
+	if b.format != FrontMatterYAML {
+		raw, err := b.decode()
+		return err == nil && raw["updated"] == "now"
 	}
 
-	yamlContent := rest[:endIndex]
+	yamlContent := b.data
 	// Check for "updated: now" pattern (with variations)
 	return bytes.Contains(yamlContent, []byte("updated: now")) ||
 		bytes.Contains(yamlContent, []byte("updated:now")) ||
@@ -194,51 +234,22 @@ func HasNowPlaceholder(content []byte) bool {
 		bytes.Contains(yamlContent, []byte("updated: \"now\""))
 }
 
-// ParseFrontMatter extracts YAML front-matter from content and returns
-// the parsed FrontMatter and the remaining body content.
+// ParseFrontMatter extracts YAML, TOML or JSON front-matter from content and
+// returns the parsed FrontMatter and the remaining body content.
 // Returns nil FrontMatter if no front-matter is present.
 func ParseFrontMatter(content []byte) (*FrontMatter, []byte, error) {
-	if !HasFrontMatter(content) {
+	b := splitFrontMatter(content)
+	if b == nil {
 		return nil, content, nil
 	}
 
-	// Skip the first "---"
-	rest := content[3:]
-	if len(rest) > 0 && rest[0] == '\n' {
-		rest = rest[1:]
-	}
-
-	// Find the closing "---"
-	// Handle empty front-matter case (---\n---) where rest starts with "---"
-	var endIndex int
-	var yamlContent []byte
-	if bytes.HasPrefix(rest, frontMatterDelimiter) {
-		// Empty front-matter
-		endIndex = 0
-		yamlContent = []byte{}
-	} else {
-		endIndex = bytes.Index(rest, []byte("\n---"))
-		if endIndex == -1 {
-			// No closing delimiter, treat as no front-matter
-			return nil, content, nil
-		}
-		yamlContent = rest[:endIndex]
-	}
-
 	// Parse into map to preserve unknown fields
-	var raw map[string]interface{}
-	if len(yamlContent) > 0 {
-		if err := yaml.Unmarshal(yamlContent, &raw); err != nil {
-			return nil, content, ErrMalformedYAML
-		}
-	}
-
-	// Handle empty front-matter (---\n---)
-	if raw == nil {
-		raw = make(map[string]interface{})
+	raw, err := b.decode()
+	if err != nil {
+		return nil, content, err
 	}
 
-	fm := &FrontMatter{Raw: raw}
+	fm := &FrontMatter{Format: b.format, Raw: raw}
 
 	// Extract and validate created field
 	if createdVal, ok := raw["created"]; ok {
@@ -262,20 +273,8 @@ func ParseFrontMatter(content []byte) (*FrontMatter, []byte, error) {
 		}
 	}
 
-	// Extract body (after the closing "---" and newline)
-	var body []byte
-	if endIndex == 0 {
-		// Empty front-matter: skip "---" and newline
-		body = rest[3:]
-	} else {
-		body = rest[endIndex+4:] // Skip "\n---"
-	}
 	// Strip all leading newlines from body (we'll add exactly one blank line when reconstructing)
-	for len(body) > 0 && body[0] == '\n' {
-		body = body[1:]
-	}
-
-	return fm, body, nil
+	return fm, b.body(), nil
 }
 
 // parseDateTime parses a datetime value from front-matter
@@ -295,23 +294,13 @@ func parseDateTime(val interface{}) (time.Time, error) {
 	}
 }
 
-// formatTimeValue converts a value to RFC3339 string format.
-// Handles time.Time, string, and other types.
-func formatTimeValue(val interface{}) string {
-	switch v := val.(type) {
-	case time.Time:
-		return v.Format(time.RFC3339)
-	case string:
-		return v
-	default:
-		return fmt.Sprintf("%v", v)
-	}
-}
-
 // GenerateFrontMatter creates a YAML front-matter string with the given created time.
-// The updated field is omitted by default.
+// The updated field is omitted by default, and the created field when created is zero.
 // A blank line is added after the closing delimiter for readability.
 func GenerateFrontMatter(created time.Time) string {
+	if created.IsZero() {
+		return "---\n---\n\n"
+	}
 	return fmt.Sprintf("---\ncreated: %s\n---\n\n", created.Format(time.RFC3339))
 }
 
@@ -319,14 +308,19 @@ func GenerateFrontMatter(created time.Time) string {
 // If created is non-zero, it sets/updates the created field.
 // If updated is non-zero, it sets/updates the updated field.
 // If replaceNow is true and updated is non-zero, it replaces "now" placeholder.
+// Only the lines of the changed fields are rewritten, so the order, comments and
+// style of the other fields and the body are kept as written.
 func UpdateFrontMatter(content []byte, created, updated time.Time, replaceNow bool) ([]byte, error) {
-	fm, body, err := ParseFrontMatter(content)
+	if _, _, err := ParseFrontMatter(content); err != nil {
+		return nil, err
+	}
+	src, err := newFrontMatterEditor(content)
 	if err != nil {
 		return nil, err
 	}
 
 	// If no front-matter exists, create new one
-	if fm == nil {
+	if src == nil {
 		if created.IsZero() {
 			return content, nil
 		}
@@ -334,72 +328,71 @@ func UpdateFrontMatter(content []byte, created, updated time.Time, replaceNow bo
 		return append([]byte(newFM), content...), nil
 	}
 
-	// Update the raw map
 	if !created.IsZero() {
-		fm.Raw["created"] = created.Format(time.RFC3339)
+		if err := src.set("created", timestampNode(created), ""); err != nil {
+			return nil, err
+		}
 	}
 
 	if !updated.IsZero() {
-		if replaceNow {
-			// Only replace if current value is "now"
-			if val, ok := fm.Raw["updated"]; ok {
-				if str, ok := val.(string); ok && str == "now" {
-					fm.Raw["updated"] = updated.Format(time.RFC3339)
-				}
+		current := src.value("updated")
+		// Only replace if current value is "now"
+		if !replaceNow || (current != nil && current.Kind == yaml.ScalarNode && current.Value == "now") {
+			// A new updated field goes right after created
+			if err := src.set("updated", timestampNode(updated), "created"); err != nil {
+				return nil, err
 			}
-		} else {
-			fm.Raw["updated"] = updated.Format(time.RFC3339)
 		}
 	}
 
-	// Serialize back to YAML manually to avoid quoting timestamps
-	var buf bytes.Buffer
-	buf.WriteString("---\n")
+	return src.Bytes(), nil
+}
 
-	// Write created field first (if present)
-	if val, ok := fm.Raw["created"]; ok {
-		buf.WriteString(fmt.Sprintf("created: %s\n", formatTimeValue(val)))
+// SetFrontMatterField sets key of the front-matter in content to val while
+// preserving the other fields as written. A new key is added after the others.
+// Content without front-matter is returned as is.
+func SetFrontMatterField(content []byte, key string, val interface{}) ([]byte, error) {
+	var node yaml.Node
+	if err := node.Encode(val); err != nil {
+		return nil, err
 	}
+	return setFrontMatterNode(content, key, &node)
+}
 
-	// Write updated field second (if present)
-	if val, ok := fm.Raw["updated"]; ok {
-		buf.WriteString(fmt.Sprintf("updated: %s\n", formatTimeValue(val)))
+// setFrontMatterNode sets key of the front-matter in content to node, like SetFrontMatterField
+func setFrontMatterNode(content []byte, key string, node *yaml.Node) ([]byte, error) {
+	src, err := newFrontMatterEditor(content)
+	if err != nil {
+		return nil, err
 	}
-
-	// Write other fields using yaml.Marshal
-	for key, val := range fm.Raw {
-		if key == "created" || key == "updated" {
-			continue
-		}
-		// Marshal single field
-		fieldBytes, err := yaml.Marshal(map[string]interface{}{key: val})
-		if err != nil {
-			return nil, err
-		}
-		buf.Write(fieldBytes)
+	if src == nil {
+		return content, nil
 	}
-
-	buf.WriteString("---\n\n")
-	buf.Write(body)
-
-	return buf.Bytes(), nil
+	after := ""
+	if keys := src.keys(); len(keys) > 0 {
+		after = keys[len(keys)-1]
+	}
+	if err := src.set(key, node, after); err != nil {
+		return nil, err
+	}
+	return src.Bytes(), nil
 }
 
-// GetCreatedTime returns the created time from front-matter if available,
-// otherwise returns the time derived from the nippo date (filename).
-func (n *Nippo) GetCreatedTime() time.Time {
+// GetCreatedTime returns the created time from front-matter in loc if available,
+// otherwise returns the start of the nippo date (filename) in loc.
+func (n *Nippo) GetCreatedTime(loc *time.Location) time.Time {
 	if n.FrontMatter != nil && !n.FrontMatter.Created.IsZero() {
-		return n.FrontMatter.Created
+		return n.FrontMatter.Created.In(loc)
 	}
 	// Fallback to filename-derived date
-	return time.Date(n.Date.Year(), n.Date.Month(), n.Date.Day(), 0, 0, 0, 0, time.Local)
+	return time.Date(n.Date.Year(), n.Date.Month(), n.Date.Day(), 0, 0, 0, 0, loc)
 }
 
-// GetUpdatedTime returns the updated time from front-matter if available,
+// GetUpdatedTime returns the updated time from front-matter in loc if available,
 // otherwise returns zero time.
-func (n *Nippo) GetUpdatedTime() time.Time {
-	if n.FrontMatter != nil {
-		return n.FrontMatter.Updated
+func (n *Nippo) GetUpdatedTime(loc *time.Location) time.Time {
+	if n.FrontMatter != nil && !n.FrontMatter.Updated.IsZero() {
+		return n.FrontMatter.Updated.In(loc)
 	}
 	return time.Time{}
 }
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// NippoDay is the nippo written on a day, which share the page of the day
+type NippoDay struct {
+	// Date is the day, without a suffix
+	Date  NippoDate
+	Nippo []Nippo
+}
+
+// CompareNippo orders nippo by date, the nippo named after the day alone first
+// and the others by suffix, so 2024-01-15-2 comes before 2024-01-15-10
+func CompareNippo(a, b Nippo) int {
+	if c := cmp.Compare(dayOf(a.Date).PathString(), dayOf(b.Date).PathString()); c != 0 {
+		return c
+	}
+	return compareSuffix(a.Date.Suffix(), b.Date.Suffix())
+}
+
+func compareSuffix(a, b string) int {
+	if a == "" || b == "" {
+		return cmp.Compare(a, b)
+	}
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil {
+		return cmp.Compare(na, nb)
+	}
+	return cmp.Compare(a, b)
+}
+
+// GroupNippoByDay returns the days of nippoList in order, each with its nippo
+// ordered by CompareNippo
+func GroupNippoByDay(nippoList []Nippo) []NippoDay {
+	sorted := slices.Clone(nippoList)
+	slices.SortStableFunc(sorted, CompareNippo)
+
+	var days []NippoDay
+	for _, nippo := range sorted {
+		date := dayOf(nippo.Date)
+		if len(days) > 0 && days[len(days)-1].Date.PathString() == date.PathString() {
+			days[len(days)-1].Nippo = append(days[len(days)-1].Nippo, nippo)
+			continue
+		}
+		days = append(days, NippoDay{Date: date, Nippo: []Nippo{nippo}})
+	}
+	return days
+}
+
+// dayOf returns date without its suffix
+func dayOf(date NippoDate) NippoDate {
+	return &nippoDate{time: time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)}
+}
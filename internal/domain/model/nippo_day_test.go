@@ -0,0 +1,47 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGroupNippoByDay(t *testing.T) {
+	nippoList := []Nippo{
+		{Date: NewNippoDate("2024-01-16-evening.md")},
+		{Date: NewNippoDate("2024-01-15-10.md")},
+		{Date: NewNippoDate("2024-01-15.md")},
+		{Date: NewNippoDate("2024-01-15-2.md")},
+	}
+
+	days := GroupNippoByDay(nippoList)
+	if len(days) != 2 {
+		t.Fatalf("GroupNippoByDay() returned %d days, want 2", len(days))
+	}
+
+	var first []string
+	for _, nippo := range days[0].Nippo {
+		first = append(first, nippo.Date.PathString())
+	}
+	if strings.Join(first, ",") != "20240115,20240115-2,20240115-10" {
+		t.Errorf("nippo of the 15th = %v", first)
+	}
+	if days[1].Date.PathString() != "20240116" || days[1].Date.Suffix() != "" {
+		t.Errorf("second day = %v, want 20240116 without a suffix", days[1].Date)
+	}
+	if len(days[1].Nippo) != 1 || days[1].Nippo[0].Date.Suffix() != "evening" {
+		t.Errorf("nippo of the 16th = %v", days[1].Nippo)
+	}
+}
+
+func TestNippoDate_Suffix(t *testing.T) {
+	date := NewNippoDate("2024-01-15-evening.md")
+	if date.FileString() != "2024-01-15-evening" {
+		t.Errorf("FileString() = %q", date.FileString())
+	}
+	if date.PathString() != "20240115-evening" {
+		t.Errorf("PathString() = %q", date.PathString())
+	}
+	if date.TitleString() != "01/15 mon evening" {
+		t.Errorf("TitleString() = %q", date.TitleString())
+	}
+}
@@ -0,0 +1,229 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrInvalidNippoName is returned when neither the name of a file nor its
+// front-matter has the date of a nippo
+var ErrInvalidNippoName = errors.New("no date in the file name or front-matter")
+
+// ErrDuplicateNippo is returned when two files resolve to the same date and
+// suffix, such as 2024-01-15_evening.md and 2024-01-15-evening.md
+var ErrDuplicateNippo = errors.New("another file has the same date and suffix")
+
+// DefaultNippoNamePatterns names nippo after their date, such as 2024-01-15.md
+var DefaultNippoNamePatterns = []string{"YYYY-MM-DD"}
+
+// NippoNaming finds the date of a nippo in its path within the source folder,
+// trying each of its patterns in order
+type NippoNaming struct {
+	patterns []nippoNamePattern
+}
+
+type nippoNamePattern struct {
+	pattern string
+	// segments is the number of trailing path segments the pattern matches
+	segments int
+	re       *regexp.Regexp
+}
+
+// NewNippoNaming compiles patterns, where YYYY, MM and DD stand for the year,
+// month and day, and a slash separates folders, such as "YYYY/MM/DD". The name
+// may go on after the date, which makes the rest the suffix of the date.
+// Empty patterns mean DefaultNippoNamePatterns.
+func NewNippoNaming(patterns []string) (*NippoNaming, error) {
+	if len(patterns) == 0 {
+		patterns = DefaultNippoNamePatterns
+	}
+	naming := &NippoNaming{}
+	for _, pattern := range patterns {
+		p, err := compileNippoNamePattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		naming.patterns = append(naming.patterns, p)
+	}
+	return naming, nil
+}
+
+func compileNippoNamePattern(pattern string) (nippoNamePattern, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+	seen := map[string]bool{}
+	for rest := pattern; rest != ""; {
+		token := ""
+		for _, t := range []string{"YYYY", "MM", "DD"} {
+			if strings.HasPrefix(rest, t) {
+				token = t
+				break
+			}
+		}
+		if token == "" {
+			expr.WriteString(regexp.QuoteMeta(rest[:1]))
+			rest = rest[1:]
+			continue
+		}
+		if seen[token] {
+			return nippoNamePattern{}, fmt.Errorf("invalid name pattern %q: %s appears twice", pattern, token)
+		}
+		seen[token] = true
+		fmt.Fprintf(&expr, `(?P<%s>\d{%d})`, token, len(token))
+		rest = rest[len(token):]
+	}
+	if len(seen) != 3 || strings.HasPrefix(pattern, "/") || strings.HasSuffix(pattern, "/") {
+		return nippoNamePattern{}, fmt.Errorf("invalid name pattern %q: expected YYYY, MM and DD, such as YYYY-MM-DD or YYYY/MM/DD", pattern)
+	}
+	return nippoNamePattern{
+		pattern:  pattern,
+		segments: strings.Count(pattern, "/") + 1,
+		re:       regexp.MustCompile(expr.String()),
+	}, nil
+}
+
+// String returns the patterns in the order they are tried, such as
+// "YYYY-MM-DD, YYYYMMDD"
+func (n *NippoNaming) String() string {
+	patterns := make([]string, 0, len(n.patterns))
+	for _, p := range n.patterns {
+		patterns = append(patterns, p.pattern)
+	}
+	return strings.Join(patterns, ", ")
+}
+
+// UsesFolders reports whether any pattern takes the date from folder names
+func (n *NippoNaming) UsesFolders() bool {
+	for _, p := range n.patterns {
+		if p.segments > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// FileName returns the slash-separated path a new nippo for date is named with
+// by the first pattern, such as 2024-01-15.md or 2024/01/15.md
+func (n *NippoNaming) FileName(date time.Time) string {
+	r := strings.NewReplacer("YYYY", date.Format("2006"), "MM", date.Format("01"), "DD", date.Format("02"))
+	return r.Replace(n.patterns[0].pattern) + ".md"
+}
+
+// Parse returns the date in relPath, the slash-separated path of a file within
+// the source folder. It fails with ErrInvalidNippoName when no pattern matches.
+func (n *NippoNaming) Parse(relPath string) (NippoDate, error) {
+	segments := strings.Split(strings.TrimSuffix(relPath, path.Ext(relPath)), "/")
+	for _, p := range n.patterns {
+		if len(segments) < p.segments {
+			continue
+		}
+		name := strings.Join(segments[len(segments)-p.segments:], "/")
+		m := p.re.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		year := m[p.re.SubexpIndex("YYYY")]
+		month := m[p.re.SubexpIndex("MM")]
+		day := m[p.re.SubexpIndex("DD")]
+		if date, err := time.Parse("2006-01-02", year+"-"+month+"-"+day); err == nil {
+			return &nippoDate{time: date, suffix: nippoSuffix(name[len(m[0]):])}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrInvalidNippoName, relPath)
+}
+
+// ParseNippoDate returns the date a file name starts with, such as the names
+// of the cached nippo, with the rest of the name as its suffix. It fails with
+// ErrInvalidNippoName otherwise.
+func ParseNippoDate(filePath string) (NippoDate, error) {
+	name := filepath.Base(filePath)
+	if len(name) >= 10 {
+		if date, err := time.Parse("2006-01-02", name[:10]); err == nil {
+			return &nippoDate{time: date, suffix: nippoSuffix(strings.TrimSuffix(name[10:], filepath.Ext(name)))}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrInvalidNippoName, name)
+}
+
+// nippoSuffix turns the rest of a name after the date into a suffix that is
+// safe in file names and URLs, so "_Evening" and "-evening" both give "evening"
+func nippoSuffix(rest string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(rest) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+// ResolveDate sets the date of the nippo from the `date` field of its
+// front-matter, which takes precedence over the date in its file name while
+// keeping the suffix of the name. A time in the field falls on its date in loc.
+// It fails with ErrInvalidNippoName when neither has a date.
+func (n *Nippo) ResolveDate(loc *time.Location) error {
+	name := n.FilePath
+	if n.RemoteFile != nil {
+		name = n.RemoteFile.Name
+	}
+	if fm, _, err := ParseFrontMatter(n.Content); err == nil && fm != nil {
+		if val, ok := fm.Raw["date"]; ok {
+			date, err := parseNippoDateField(val, loc)
+			if err != nil {
+				return fmt.Errorf("%w: %s has an invalid date field: %v", ErrInvalidNippoName, name, err)
+			}
+			if n.Date != nil {
+				date.suffix = n.Date.Suffix()
+			}
+			n.Date = date
+			return nil
+		}
+	}
+	if n.Date == nil {
+		return fmt.Errorf("%w: %s", ErrInvalidNippoName, name)
+	}
+	return nil
+}
+
+// parseNippoDateField parses the date field of front-matter, a YYYY-MM-DD date
+// or an RFC 3339 time whose date in loc is taken
+func parseNippoDateField(val interface{}, loc *time.Location) (*nippoDate, error) {
+	switch v := val.(type) {
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 && v.Nanosecond() == 0 && v.Location() == time.UTC {
+			// An unquoted YYYY-MM-DD date, which YAML decodes as midnight in UTC
+			return &nippoDate{time: v}, nil
+		}
+		return dateIn(v, loc), nil
+	case string:
+		if date, err := time.Parse("2006-01-02", v); err == nil {
+			return &nippoDate{time: date}, nil
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, err
+		}
+		return dateIn(t, loc), nil
+	default:
+		return nil, fmt.Errorf("unsupported type: %T", val)
+	}
+}
+
+// dateIn returns the date t falls on in loc
+func dateIn(t time.Time, loc *time.Location) *nippoDate {
+	t = t.In(loc)
+	return &nippoDate{time: time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
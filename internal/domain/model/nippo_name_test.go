@@ -0,0 +1,175 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+func TestNippoNaming_Parse(t *testing.T) {
+	naming, err := NewNippoNaming([]string{"YYYY-MM-DD", "YYYYMMDD", "YYYY/MM/DD"})
+	if err != nil {
+		t.Fatalf("NewNippoNaming() error = %v", err)
+	}
+	if !naming.UsesFolders() {
+		t.Error("UsesFolders() = false, want true for YYYY/MM/DD")
+	}
+
+	tests := []struct {
+		relPath string
+		want    string
+	}{
+		{"2024-01-15.md", "2024-01-15"},
+		{"2024/01/2024-01-15-morning.md", "2024-01-15-morning"},
+		{"2024-01-15_Evening Run.md", "2024-01-15-evening-run"},
+		{"20240115.md", "2024-01-15"},
+		{"20240115-2.md", "2024-01-15-2"},
+		{"2024/01/15.md", "2024-01-15"},
+		{"2024/01/15_evening.md", "2024-01-15-evening"},
+		{"archive/2024/01/15.md", "2024-01-15"},
+		{"notes.md", ""},
+		{"2024-13-45.md", ""},
+		{"01/15.md", ""},
+	}
+	for _, tt := range tests {
+		date, err := naming.Parse(tt.relPath)
+		if tt.want == "" {
+			if !errors.Is(err, ErrInvalidNippoName) {
+				t.Errorf("Parse(%q) error = %v, want ErrInvalidNippoName", tt.relPath, err)
+			}
+			continue
+		}
+		if err != nil || date.FileString() != tt.want {
+			t.Errorf("Parse(%q) = %v, %v, want %s", tt.relPath, date, err, tt.want)
+		}
+	}
+}
+
+func TestNewNippoNaming(t *testing.T) {
+	naming, err := NewNippoNaming(nil)
+	if err != nil {
+		t.Fatalf("NewNippoNaming() error = %v", err)
+	}
+	if naming.UsesFolders() {
+		t.Error("the default pattern should not use folders")
+	}
+	if naming.String() != "YYYY-MM-DD" {
+		t.Errorf("String() = %q, want the default pattern", naming.String())
+	}
+	if _, err := naming.Parse("20240115.md"); err == nil {
+		t.Error("the default pattern should not match YYYYMMDD")
+	}
+
+	for _, pattern := range []string{"YYYY-MM", "YYYY-MM-DD-DD", "/YYYY/MM/DD", "date"} {
+		if _, err := NewNippoNaming([]string{pattern}); err == nil {
+			t.Errorf("NewNippoNaming(%q) expected error", pattern)
+		}
+	}
+}
+
+func TestNippoNaming_FileName(t *testing.T) {
+	date := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		patterns []string
+		want     string
+	}{
+		{patterns: nil, want: "2024-01-05.md"},
+		{patterns: []string{"YYYYMMDD", "YYYY-MM-DD"}, want: "20240105.md"},
+		{patterns: []string{"YYYY/MM/DD"}, want: "2024/01/05.md"},
+		{patterns: []string{"nippo_DD.MM.YYYY"}, want: "nippo_05.01.2024.md"},
+	}
+	for _, tt := range tests {
+		naming, err := NewNippoNaming(tt.patterns)
+		if err != nil {
+			t.Fatalf("NewNippoNaming(%v) error = %v", tt.patterns, err)
+		}
+		if got := naming.FileName(date); got != tt.want {
+			t.Errorf("FileName() with %v = %q, want %q", tt.patterns, got, tt.want)
+		}
+		if parsed, err := naming.Parse(tt.want); err != nil || parsed.FileString() != "2024-01-05" {
+			t.Errorf("Parse(%q) = %v, %v, want the date back", tt.want, parsed, err)
+		}
+	}
+}
+
+func TestParseNippoDate(t *testing.T) {
+	date, err := ParseNippoDate("/cache/md/2024-01-15.md")
+	if err != nil || date.FileString() != "2024-01-15" {
+		t.Errorf("ParseNippoDate() = %v, %v", date, err)
+	}
+	date, err = ParseNippoDate("/cache/md/2024-01-15-evening.md")
+	if err != nil || date.Suffix() != "evening" || date.PathString() != "20240115-evening" {
+		t.Errorf("ParseNippoDate() = %v, %v, want the suffix evening", date, err)
+	}
+	for _, name := range []string{"notes.md", "a.md", "2024-02-30.md"} {
+		if _, err := ParseNippoDate(name); !errors.Is(err, ErrInvalidNippoName) {
+			t.Errorf("ParseNippoDate(%q) error = %v, want ErrInvalidNippoName", name, err)
+		}
+	}
+}
+
+func TestNippo_ResolveDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		date    NippoDate
+		content string
+		want    string
+	}{
+		{"front-matter takes precedence", NewNippoDate("2024-01-15.md"), "---\ndate: 2024-01-16\n---\n", "2024-01-16"},
+		{"suffix of the name is kept", NewNippoDate("2024-01-15-evening.md"), "---\ndate: 2024-01-16\n---\n", "2024-01-16-evening"},
+		{"quoted date", nil, "---\ndate: '2024-01-16'\n---\n", "2024-01-16"},
+		{"time keeps its own date", nil, "---\ndate: 2024-01-16T23:30:00+09:00\n---\n", "2024-01-16"},
+		{"name without date field", NewNippoDate("2024-01-15.md"), "---\ncreated: 2024-01-15T10:00:00+09:00\n---\n", "2024-01-15"},
+		{"no date", nil, "# Notes\n", ""},
+		{"invalid date field", NewNippoDate("2024-01-15.md"), "---\ndate: tomorrow\n---\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nippo := &Nippo{Date: tt.date, Content: []byte(tt.content), RemoteFile: &drive.File{Name: "notes.md"}}
+			err := nippo.ResolveDate(time.UTC)
+			if tt.want == "" {
+				if !errors.Is(err, ErrInvalidNippoName) {
+					t.Errorf("ResolveDate() error = %v, want ErrInvalidNippoName", err)
+				}
+				return
+			}
+			if err != nil || nippo.Date.FileString() != tt.want {
+				t.Errorf("ResolveDate() = %v, %v, want %s", nippo.Date, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNippo_ResolveDateJustAfterMidnight(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		loc     *time.Location
+		want    string
+	}{
+		// 00:30 on the 16th in Tokyo is still the 15th in UTC
+		{"time in UTC, Tokyo", "---\ndate: 2024-01-15T15:30:00Z\n---\n", tokyo, "2024-01-16"},
+		{"time in UTC, UTC", "---\ndate: 2024-01-15T15:30:00Z\n---\n", time.UTC, "2024-01-15"},
+		{"time with offset, UTC", "---\ndate: '2024-01-16T00:30:00+09:00'\n---\n", time.UTC, "2024-01-15"},
+		{"date only, New York", "---\ndate: 2024-01-16\n---\n", newYork, "2024-01-16"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nippo := &Nippo{Content: []byte(tt.content)}
+			if err := nippo.ResolveDate(tt.loc); err != nil || nippo.Date.FileString() != tt.want {
+				t.Errorf("ResolveDate() = %v, %v, want %s", nippo.Date, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+)
+
+// DefaultNippoTemplate is used by `nippo new` when the data dir has no template
+const DefaultNippoTemplate = "# {{.Title}}\n\n"
+
+// NippoTemplateData holds the placeholders available in a nippo template
+type NippoTemplateData struct {
+	// Date is the date of the nippo, such as 2024-01-15
+	Date string
+	// Year, Month and Day are zero padded, such as 2024, 01 and 15
+	Year  string
+	Month string
+	Day   string
+	// Weekday is the English name of the day, such as Monday
+	Weekday string
+	// Title is the title build gives the nippo, such as 01/15 mon
+	Title string
+}
+
+// NewNippoTemplateData returns the placeholders for a nippo of date
+func NewNippoTemplateData(date time.Time) NippoTemplateData {
+	nippoDate := &nippoDate{time: date}
+	return NippoTemplateData{
+		Date:    nippoDate.FileString(),
+		Year:    date.Format("2006"),
+		Month:   date.Format("01"),
+		Day:     date.Format("02"),
+		Weekday: date.Weekday().String(),
+		Title:   nippoDate.TitleString(),
+	}
+}
+
+// RenderNippoTemplate renders the markdown template tmpl for a nippo of date,
+// and sets its front-matter created field to created. Other front-matter fields
+// of the template are kept.
+func RenderNippoTemplate(tmpl string, date, created time.Time) ([]byte, error) {
+	t, err := template.New("nippo").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse nippo template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, NewNippoTemplateData(date)); err != nil {
+		return nil, fmt.Errorf("unable to render nippo template: %w", err)
+	}
+
+	content := buf.Bytes()
+	if !HasFrontMatter(content) {
+		return append([]byte(GenerateFrontMatter(created)), content...), nil
+	}
+	return UpdateFrontMatter(content, created, time.Time{}, false)
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewNippoTemplateData(t *testing.T) {
+	data := NewNippoTemplateData(time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC))
+	want := NippoTemplateData{
+		Date:    "2024-01-05",
+		Year:    "2024",
+		Month:   "01",
+		Day:     "05",
+		Weekday: "Friday",
+		Title:   "01/05 fri",
+	}
+	if data != want {
+		t.Errorf("NewNippoTemplateData() = %+v, want %+v", data, want)
+	}
+}
+
+func TestRenderNippoTemplate(t *testing.T) {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	created := time.Date(2024, 1, 15, 9, 30, 0, 0, time.FixedZone("JST", 9*60*60))
+
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{
+			name: "without front-matter",
+			tmpl: "# {{.Date}} ({{.Weekday}})\n",
+			want: "---\ncreated: 2024-01-15T09:30:00+09:00\n---\n\n# 2024-01-15 (Monday)\n",
+		},
+		{
+			name: "default template",
+			tmpl: DefaultNippoTemplate,
+			want: "---\ncreated: 2024-01-15T09:30:00+09:00\n---\n\n# 01/15 mon\n\n",
+		},
+		{
+			name: "with front-matter",
+			tmpl: "---\ntags: [daily]\n---\n\n## {{.Year}}/{{.Month}}/{{.Day}}\n",
+			want: "---\ncreated: 2024-01-15T09:30:00+09:00\ntags: [daily]\n---\n\n## 2024/01/15\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderNippoTemplate(tt.tmpl, date, created)
+			if err != nil {
+				t.Fatalf("RenderNippoTemplate() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("RenderNippoTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderNippoTemplate_Errors(t *testing.T) {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	for _, tmpl := range []string{"{{.Date", "{{.Unknown}}"} {
+		_, err := RenderNippoTemplate(tmpl, date, date)
+		if err == nil || !strings.Contains(err.Error(), "nippo template") {
+			t.Errorf("RenderNippoTemplate(%q) error = %v, want a template error", tmpl, err)
+		}
+	}
+}
@@ -212,6 +212,10 @@ func TestGenerateFrontMatter(t *testing.T) {
 	if result != expected {
 		t.Errorf("GenerateFrontMatter() = %q, want %q", result, expected)
 	}
+
+	if result := GenerateFrontMatter(time.Time{}); result != "---\n---\n\n" {
+		t.Errorf("GenerateFrontMatter(zero) = %q, want the created field left out", result)
+	}
 }
 
 func TestUpdateFrontMatter(t *testing.T) {
@@ -530,7 +534,7 @@ func TestNippo_GetCreatedTime(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := tt.nippo.GetCreatedTime()
+			result := tt.nippo.GetCreatedTime(time.Local)
 			if !tt.expectEqual.IsZero() {
 				if !result.Equal(tt.expectEqual) {
 					t.Errorf("GetCreatedTime() = %v, want %v", result, tt.expectEqual)
@@ -542,6 +546,31 @@ func TestNippo_GetCreatedTime(t *testing.T) {
 	}
 }
 
+func TestNippo_GetCreatedTimeInLocation(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without front-matter, the nippo starts at midnight of its date in the time zone
+	nippo := &Nippo{Date: NewNippoDate("2024-01-16.md")}
+	if got := nippo.GetCreatedTime(tokyo); got.Format(time.RFC3339) != "2024-01-16T00:00:00+09:00" {
+		t.Errorf("GetCreatedTime() = %v, want midnight in Tokyo", got)
+	}
+
+	// A time written just after midnight in Tokyo is shown in Tokyo time
+	nippo.FrontMatter = &FrontMatter{
+		Created: time.Date(2024, 1, 15, 15, 10, 0, 0, time.UTC),
+		Updated: time.Date(2024, 1, 15, 15, 20, 0, 0, time.UTC),
+	}
+	if got := nippo.GetCreatedTime(tokyo); got.Format(time.RFC3339) != "2024-01-16T00:10:00+09:00" {
+		t.Errorf("GetCreatedTime() = %v, want 2024-01-16T00:10:00+09:00", got)
+	}
+	if got := nippo.GetUpdatedTime(tokyo); got.Format(time.RFC3339) != "2024-01-16T00:20:00+09:00" {
+		t.Errorf("GetUpdatedTime() = %v, want 2024-01-16T00:20:00+09:00", got)
+	}
+}
+
 func TestNippo_GetUpdatedTime(t *testing.T) {
 	date := NewNippoDate("2024-01-15.md")
 	updated := time.Date(2024, 1, 16, 10, 0, 0, 0, time.FixedZone("JST", 9*60*60))
@@ -573,7 +602,7 @@ func TestNippo_GetUpdatedTime(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := tt.nippo.GetUpdatedTime()
+			result := tt.nippo.GetUpdatedTime(time.Local)
 			if !tt.expectEqual.IsZero() {
 				if !result.Equal(tt.expectEqual) {
 					t.Errorf("GetUpdatedTime() = %v, want %v", result, tt.expectEqual)
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Site is the nippo of a build in memory. Each nippo is parsed once and
+// rendered at most once, and shared by the pages, the feed and the sitemap.
+type Site struct {
+	// Entries are the nippo in the order of CompareNippo
+	Entries []*SiteEntry
+	// Days are the days of Entries in order
+	Days []*SiteDay
+	// Tags and Categories group Entries by their tags and category
+	Tags       *Taxonomy
+	Categories *Taxonomy
+}
+
+// SiteEntry is a nippo with its front-matter parsed
+type SiteEntry struct {
+	Nippo
+	// Terms are the category and then the tags of the entry
+	Terms []*Term
+	text  func() *entryText
+	html  func() []byte
+}
+
+// SiteDay is the entries written on a day, which share the page of the day
+type SiteDay struct {
+	// Date is the day, without a suffix
+	Date    NippoDate
+	Entries []*SiteEntry
+	html    func() []byte
+}
+
+// NewSite parses the front-matter of nippoList, loaded with content. The HTML
+// of the entries and days is rendered when first asked for, and safe to ask
+// for from several goroutines.
+func NewSite(nippoList []Nippo) (*Site, error) {
+	site := &Site{}
+	for _, day := range GroupNippoByDay(nippoList) {
+		siteDay := &SiteDay{Date: day.Date}
+		for _, nippo := range day.Nippo {
+			body, err := nippo.GetMarkdown()
+			if err != nil {
+				return nil, err
+			}
+			entry := &SiteEntry{Nippo: nippo}
+			entry.text = sync.OnceValue(func() *entryText {
+				return newEntryText(parseMarkdown(body))
+			})
+			entry.html = sync.OnceValue(func() []byte {
+				return renderHtml(entry.text().doc)
+			})
+			siteDay.Entries = append(siteDay.Entries, entry)
+			site.Entries = append(site.Entries, entry)
+		}
+		siteDay.html = sync.OnceValue(siteDay.render)
+		site.Days = append(site.Days, siteDay)
+	}
+	site.Categories = newTaxonomy(TaxonomyCategories, site.Entries, func(e *SiteEntry) []string {
+		return []string{e.Category()}
+	})
+	site.Tags = newTaxonomy(TaxonomyTags, site.Entries, func(e *SiteEntry) []string {
+		return e.Tags()
+	})
+	return site, nil
+}
+
+// Html returns the body of the entry rendered to HTML, as on its page and in
+// the feed
+func (e *SiteEntry) Html() []byte {
+	return e.html()
+}
+
+// Html returns the entries of the day one after another, each in a section
+// linked by its path when the day has more than one
+func (d *SiteDay) Html() []byte {
+	return d.html()
+}
+
+func (d *SiteDay) render() []byte {
+	if len(d.Entries) == 1 {
+		return d.Entries[0].Html()
+	}
+	var buf bytes.Buffer
+	for _, entry := range d.Entries {
+		fmt.Fprintf(&buf, "<section id=\"%s\">\n", entry.Date.PathString())
+		buf.Write(entry.Html())
+		buf.WriteString("</section>\n")
+	}
+	return buf.Bytes()
+}
+
+// Terms returns the terms of the entries of the day in order, each once
+func (d *SiteDay) Terms() []*Term {
+	var terms []*Term
+	for _, entry := range d.Entries {
+		for _, term := range entry.Terms {
+			if !slices.Contains(terms, term) {
+				terms = append(terms, term)
+			}
+		}
+	}
+	return terms
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewSite(t *testing.T) {
+	nippoList := []Nippo{
+		{Date: NewNippoDate("2024-01-16.md"), Content: []byte("# 16th\n")},
+		{Date: NewNippoDate("2024-01-15-evening.md"), Content: []byte("# Evening\n")},
+		{Date: NewNippoDate("2024-01-15.md"), Content: []byte("---\ncreated: 2024-01-15T09:00:00+09:00\n---\n# Morning\n")},
+	}
+
+	site, err := NewSite(nippoList)
+	if err != nil {
+		t.Fatalf("NewSite() error = %v", err)
+	}
+	if len(site.Entries) != 3 || len(site.Days) != 2 {
+		t.Fatalf("NewSite() has %d entries and %d days, want 3 and 2", len(site.Entries), len(site.Days))
+	}
+	if site.Entries[0].Date.PathString() != "20240115" || site.Entries[1].Date.PathString() != "20240115-evening" {
+		t.Errorf("entries = %v, %v, want in the order of CompareNippo", site.Entries[0].Date, site.Entries[1].Date)
+	}
+	if site.Days[0].Entries[1] != site.Entries[1] {
+		t.Error("days don't share the entries of the site")
+	}
+
+	created := site.Entries[0].GetCreatedTime(time.UTC)
+	if want := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC); !created.Equal(want) {
+		t.Errorf("created = %v, want %v from the front-matter", created, want)
+	}
+	if html := string(site.Entries[0].Html()); html != "<h1>Morning</h1>\n" {
+		t.Errorf("Html() = %q, want the body without front-matter", html)
+	}
+
+	dayHtml := string(site.Days[0].Html())
+	for _, want := range []string{`<section id="20240115">`, `<section id="20240115-evening">`, "Evening</h1>"} {
+		if !strings.Contains(dayHtml, want) {
+			t.Errorf("Html() of the 15th = %q, want it to contain %q", dayHtml, want)
+		}
+	}
+	if html := string(site.Days[1].Html()); strings.Contains(html, "<section") {
+		t.Errorf("Html() of a day with one nippo = %q, want no sections", html)
+	}
+}
+
+func TestSiteEntry_HtmlParallel(t *testing.T) {
+	site, err := NewSite([]Nippo{{Date: NewNippoDate("2024-01-15.md"), Content: []byte("# Title\n")}})
+	if err != nil {
+		t.Fatalf("NewSite() error = %v", err)
+	}
+
+	results := make([][]byte, 8)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = site.Days[0].Html()
+		}()
+	}
+	wg.Wait()
+
+	// The HTML is rendered once and shared
+	for _, html := range results[1:] {
+		if &html[0] != &results[0][0] {
+			t.Fatal("Html() rendered the entry more than once")
+		}
+	}
+}
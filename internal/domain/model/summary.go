@@ -0,0 +1,167 @@
+package model
+
+import (
+	"html"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"golang.org/x/text/width"
+)
+
+// entryText is the text of a nippo, for its title and excerpt
+type entryText struct {
+	doc ast.Node
+	// heading is the text of the first heading, or ""
+	heading string
+	// body is the text of the nippo without the headings and code blocks,
+	// with the spaces collapsed
+	body string
+}
+
+func newEntryText(doc ast.Node) *entryText {
+	t := &entryText{doc: doc}
+	var body strings.Builder
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		switch node := node.(type) {
+		case *ast.Heading:
+			if entering && t.heading == "" {
+				t.heading = nodeText(node)
+			}
+			return ast.SkipChildren
+		case *ast.CodeBlock, *ast.HTMLBlock, *ast.HTMLSpan:
+			return ast.SkipChildren
+		case *ast.Text:
+			body.Write(node.Literal)
+		case *ast.Code:
+			body.Write(node.Literal)
+		case *ast.Softbreak, *ast.Hardbreak:
+			body.WriteByte(' ')
+		case *ast.Paragraph, *ast.ListItem:
+			if !entering {
+				body.WriteByte(' ')
+			}
+		}
+		return ast.GoToNext
+	})
+	t.body = strings.Join(strings.Fields(html.UnescapeString(body.String())), " ")
+	return t
+}
+
+// nodeText returns the text of the leaves under node
+func nodeText(node ast.Node) string {
+	var text strings.Builder
+	ast.WalkFunc(node, func(node ast.Node, entering bool) ast.WalkStatus {
+		if leaf := node.AsLeaf(); leaf != nil && entering {
+			text.Write(leaf.Literal)
+		}
+		return ast.GoToNext
+	})
+	return strings.Join(strings.Fields(html.UnescapeString(text.String())), " ")
+}
+
+// Title returns the title field of the front-matter, or the text of the first
+// heading. It is empty when there are neither.
+func (e *SiteEntry) Title() string {
+	if title := e.frontMatterString("title"); title != "" {
+		return title
+	}
+	return e.text().heading
+}
+
+// Summary returns the summary field of the front-matter, or the excerpt of the
+// text of the entry of up to length Japanese characters
+func (e *SiteEntry) Summary(length int) string {
+	if summary := e.frontMatterString("summary"); summary != "" {
+		return summary
+	}
+	return Excerpt(e.text().body, length)
+}
+
+// frontMatterString returns the string field key of the front-matter, or ""
+func (n *Nippo) frontMatterString(key string) string {
+	if n.FrontMatter == nil {
+		return ""
+	}
+	value, _ := n.FrontMatter.Raw[key].(string)
+	return strings.TrimSpace(value)
+}
+
+// Title returns the title of the first entry of the day that has one
+func (d *SiteDay) Title() string {
+	for _, entry := range d.Entries {
+		if title := entry.Title(); title != "" {
+			return title
+		}
+	}
+	return ""
+}
+
+// Summary returns the summary of the first entry of the day that has one
+func (d *SiteDay) Summary(length int) string {
+	for _, entry := range d.Entries {
+		if summary := entry.Summary(length); summary != "" {
+			return summary
+		}
+	}
+	return ""
+}
+
+// Calender returns the calender of the month ym with the title of each day
+func (s *Site) Calender(ym CalenderYearMonth) (*Calender, error) {
+	var nippoList []Nippo
+	titles := map[string]string{}
+	for _, day := range s.Days {
+		if day.Date.Year() != ym.Year || day.Date.Month() != ym.Month {
+			continue
+		}
+		for _, entry := range day.Entries {
+			nippoList = append(nippoList, entry.Nippo)
+		}
+		titles[day.Date.PathString()] = day.Title()
+	}
+	calender, err := NewCalender(ym, nippoList)
+	if err != nil {
+		return nil, err
+	}
+	for w := range calender.Weeks {
+		for i, day := range calender.Weeks[w] {
+			if day.HasContent {
+				calender.Weeks[w][i].Title = titles[day.Date.PathString()]
+			}
+		}
+	}
+	return calender, nil
+}
+
+// Excerpt returns the beginning of text of up to length Japanese characters, a
+// half-width character counting as half of one, ending with … when cut
+func Excerpt(text string, length int) string {
+	limit := length * 2
+	total := 0
+	for _, r := range text {
+		total += runeWidth(r)
+	}
+	if total <= limit {
+		return text
+	}
+
+	// Leave room for the ellipsis, full-width in Japanese text
+	limit -= 2
+	used := 0
+	for i, r := range text {
+		used += runeWidth(r)
+		if used > limit {
+			return strings.TrimRight(text[:i], " ") + "…"
+		}
+	}
+	return text
+}
+
+// runeWidth returns 2 for a full-width character and 1 for the others
+func runeWidth(r rune) int {
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
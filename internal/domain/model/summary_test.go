@@ -0,0 +1,96 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSiteEntry_TitleAndSummary(t *testing.T) {
+	site, err := NewSite([]Nippo{
+		{Date: NewNippoDate("2024-01-14.md"), Content: []byte("---\ntitle: \" Release day \"\nsummary: Shipped v1.\n---\n# 14th\n\nNotes.\n")},
+		{Date: NewNippoDate("2024-01-15.md"), Content: []byte("Intro with `code` &amp; *emphasis*\non two lines.\n\n## Done **today**\n\n- one\n- two\n\n```go\nfmt.Println()\n```\n")},
+		{Date: NewNippoDate("2024-01-16.md"), Content: []byte("\n")},
+	})
+	if err != nil {
+		t.Fatalf("NewSite() error = %v", err)
+	}
+	tests := []struct {
+		name        string
+		entry       *SiteEntry
+		wantTitle   string
+		wantSummary string
+	}{
+		{"front-matter", site.Entries[0], "Release day", "Shipped v1."},
+		{"first heading and text", site.Entries[1], "Done today", "Intro with code & emphasis on two lines. one two"},
+		{"empty", site.Entries[2], "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.Title(); got != tt.wantTitle {
+				t.Errorf("Title() = %q, want %q", got, tt.wantTitle)
+			}
+			if got := tt.entry.Summary(120); got != tt.wantSummary {
+				t.Errorf("Summary() = %q, want %q", got, tt.wantSummary)
+			}
+		})
+	}
+	if got := site.Entries[1].Summary(5); got != "Intro wi…" {
+		t.Errorf("Summary(5) = %q, want the excerpt cut", got)
+	}
+}
+
+func TestSiteDay_Title(t *testing.T) {
+	site, err := NewSite([]Nippo{
+		{Date: NewNippoDate("2024-01-15.md"), Content: []byte("Morning.\n")},
+		{Date: NewNippoDate("2024-01-15-evening.md"), Content: []byte("# Evening\n\nDinner.\n")},
+	})
+	if err != nil {
+		t.Fatalf("NewSite() error = %v", err)
+	}
+	day := site.Days[0]
+	if got := day.Title(); got != "Evening" {
+		t.Errorf("Title() = %q, want the title of the first entry with one", got)
+	}
+	if got := day.Summary(120); got != "Morning." {
+		t.Errorf("Summary() = %q, want the summary of the first entry", got)
+	}
+
+	ym, _ := NewCalenderYearMonth("2024-01")
+	calender, err := site.Calender(ym)
+	if err != nil {
+		t.Fatalf("Calender() error = %v", err)
+	}
+	for _, week := range calender.Weeks {
+		for _, d := range week {
+			if d.HasContent && (d.Title != "Evening" || d.Count != 2) {
+				t.Errorf("day %v = %+v, want the title of the day", d.Date, d)
+			}
+		}
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		length int
+		want   string
+	}{
+		{"fits", "今日は晴れ", 5, "今日は晴れ"},
+		{"full-width cut", "今日は晴れでした", 5, "今日は晴…"},
+		{"half-width counts as half", "abcdefghij", 5, "abcdefghij"},
+		{"half-width cut", "abcdefghijk", 5, "abcdefgh…"},
+		{"mixed", "Go言語のテスト", 4, "Go言語…"},
+		{"no space before the ellipsis", "ab cd efgh", 4, "ab cd…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Excerpt(tt.text, tt.length); got != tt.want {
+				t.Errorf("Excerpt(%q, %d) = %q, want %q", tt.text, tt.length, got, tt.want)
+			}
+			if !strings.HasPrefix(tt.text, strings.TrimSuffix(tt.want, "…")) {
+				t.Errorf("Excerpt(%q, %d) = %q, want a prefix of the text", tt.text, tt.length, tt.want)
+			}
+		})
+	}
+}
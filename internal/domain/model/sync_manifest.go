@@ -0,0 +1,207 @@
+package model
+
+import (
+	"sort"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// SyncManifestVersion is the current version of the sync manifest format
+const SyncManifestVersion = 1
+
+// SyncManifest records which Drive files are mirrored into the local cache.
+// Entries are keyed by Drive file ID so renames can be told apart from additions.
+type SyncManifest struct {
+	Version int                          `json:"version"`
+	Files   map[string]SyncManifestEntry `json:"files"`
+	// PageToken is the Drive Changes API token to continue from on the next sync
+	PageToken string `json:"pageToken,omitempty"`
+	// FolderIds lists the folders of the synced tree, to tell which changes belong to it
+	FolderIds []string `json:"folderIds,omitempty"`
+}
+
+// SyncManifestEntry is the last synced state of a single Drive file
+type SyncManifestEntry struct {
+	Name         string `json:"name"`
+	Md5Checksum  string `json:"md5Checksum,omitempty"`
+	ModifiedTime string `json:"modifiedTime,omitempty"`
+	// CachePath is empty for files skipped for having no date, until they change
+	CachePath string `json:"cachePath"`
+}
+
+// IsSkipped reports whether the file was skipped rather than cached
+func (e SyncManifestEntry) IsSkipped() bool {
+	return e.CachePath == ""
+}
+
+// CachedDate returns the date the file is cached under, nil for a skipped file
+func (e SyncManifestEntry) CachedDate() NippoDate {
+	if e.IsSkipped() {
+		return nil
+	}
+	date, err := ParseNippoDate(e.CachePath)
+	if err != nil {
+		return nil
+	}
+	return date
+}
+
+type SyncChangeType int
+
+const (
+	SyncChangeAdded SyncChangeType = iota + 1
+	SyncChangeUpdated
+	SyncChangeRenamed
+	SyncChangeDeleted
+	// SyncChangeSkipped is a file that has no date to be cached under
+	SyncChangeSkipped
+)
+
+func (t SyncChangeType) String() string {
+	switch t {
+	case SyncChangeAdded:
+		return "added"
+	case SyncChangeUpdated:
+		return "updated"
+	case SyncChangeRenamed:
+		return "renamed"
+	case SyncChangeDeleted:
+		return "deleted"
+	case SyncChangeSkipped:
+		return "skipped"
+	default:
+		return "unknown"
+	}
+}
+
+// SyncChange describes a difference between the manifest and the remote files
+type SyncChange struct {
+	Type    SyncChangeType
+	FileId  string
+	Name    string
+	OldName string
+	// Err is why a file could not be synced, when known
+	Err error
+}
+
+func NewSyncManifest() *SyncManifest {
+	return &SyncManifest{
+		Version: SyncManifestVersion,
+		Files:   map[string]SyncManifestEntry{},
+	}
+}
+
+// Compare returns how file differs from the manifest entry with the same ID.
+// It returns false when the file is unchanged.
+func (m *SyncManifest) Compare(file *drive.File) (SyncChangeType, bool) {
+	entry, ok := m.Files[file.Id]
+	switch {
+	case !ok:
+		return SyncChangeAdded, true
+	case entry.Name != file.Name:
+		return SyncChangeRenamed, true
+	case entry.Md5Checksum != "" && file.Md5Checksum != "":
+		if entry.Md5Checksum != file.Md5Checksum {
+			return SyncChangeUpdated, true
+		}
+		return 0, false
+	case entry.ModifiedTime != file.ModifiedTime:
+		return SyncChangeUpdated, true
+	default:
+		return 0, false
+	}
+}
+
+// Missing returns the IDs of manifest entries that are not in files, sorted by name
+func (m *SyncManifest) Missing(files []*drive.File) []string {
+	seen := make(map[string]bool, len(files))
+	for _, file := range files {
+		seen[file.Id] = true
+	}
+	var ids []string
+	for id := range m.Files {
+		if !seen[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return m.Files[ids[i]].Name < m.Files[ids[j]].Name
+	})
+	return ids
+}
+
+// HasFolders reports whether all of ids are part of the synced folder tree
+func (m *SyncManifest) HasFolders(ids []string) bool {
+	known := make(map[string]bool, len(m.FolderIds))
+	for _, id := range m.FolderIds {
+		known[id] = true
+	}
+	for _, id := range ids {
+		if !known[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns the remote files as of the manifest with changed and removed applied,
+// so they can be reconciled the same way as a full listing.
+func (m *SyncManifest) Apply(changed []Nippo, removed []string) []Nippo {
+	skip := make(map[string]bool, len(changed)+len(removed))
+	for _, id := range removed {
+		skip[id] = true
+	}
+	for _, nippo := range changed {
+		skip[nippo.RemoteFile.Id] = true
+	}
+
+	nippoList := make([]Nippo, 0, len(m.Files)+len(changed))
+	for id, entry := range m.Files {
+		if skip[id] {
+			continue
+		}
+		nippoList = append(nippoList, Nippo{
+			Date: entry.CachedDate(),
+			RemoteFile: &drive.File{
+				Id:           id,
+				Name:         entry.Name,
+				Md5Checksum:  entry.Md5Checksum,
+				ModifiedTime: entry.ModifiedTime,
+			},
+		})
+	}
+	nippoList = append(nippoList, changed...)
+	sort.Slice(nippoList, func(i, j int) bool {
+		return nippoList[i].RemoteFile.Name < nippoList[j].RemoteFile.Name
+	})
+	return nippoList
+}
+
+// Record stores the synced state of file and the path it was cached to
+func (m *SyncManifest) Record(file *drive.File, cachePath string) {
+	m.Files[file.Id] = SyncManifestEntry{
+		Name:         file.Name,
+		Md5Checksum:  file.Md5Checksum,
+		ModifiedTime: file.ModifiedTime,
+		CachePath:    cachePath,
+	}
+}
+
+// Skipped returns the files skipped for having no date, sorted by name
+func (m *SyncManifest) Skipped() []SyncChange {
+	var skipped []SyncChange
+	for id, entry := range m.Files {
+		if entry.IsSkipped() {
+			skipped = append(skipped, SyncChange{Type: SyncChangeSkipped, FileId: id, Name: entry.Name})
+		}
+	}
+	sort.Slice(skipped, func(i, j int) bool {
+		return skipped[i].Name < skipped[j].Name
+	})
+	return skipped
+}
+
+// Forget removes the entry for the given file ID
+func (m *SyncManifest) Forget(fileId string) {
+	delete(m.Files, fileId)
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+)
+
+func TestSyncManifest_Compare(t *testing.T) {
+	manifest := NewSyncManifest()
+	manifest.Record(&drive.File{Id: "1", Name: "2024-01-15.md", Md5Checksum: "abc", ModifiedTime: "2024-01-15T10:00:00Z"}, "/cache/md/2024-01-15.md")
+	manifest.Record(&drive.File{Id: "2", Name: "2024-01-16.md", ModifiedTime: "2024-01-16T10:00:00Z"}, "/cache/md/2024-01-16.md")
+
+	tests := []struct {
+		name        string
+		file        *drive.File
+		wantType    SyncChangeType
+		wantChanged bool
+	}{
+		{"unknown file is added", &drive.File{Id: "3", Name: "2024-01-17.md"}, SyncChangeAdded, true},
+		{"same checksum is unchanged", &drive.File{Id: "1", Name: "2024-01-15.md", Md5Checksum: "abc", ModifiedTime: "2024-02-01T00:00:00Z"}, 0, false},
+		{"different checksum is updated", &drive.File{Id: "1", Name: "2024-01-15.md", Md5Checksum: "def"}, SyncChangeUpdated, true},
+		{"different name is renamed", &drive.File{Id: "1", Name: "2024-01-18.md", Md5Checksum: "abc"}, SyncChangeRenamed, true},
+		{"falls back to modified time", &drive.File{Id: "2", Name: "2024-01-16.md", ModifiedTime: "2024-01-17T10:00:00Z"}, SyncChangeUpdated, true},
+		{"same modified time is unchanged", &drive.File{Id: "2", Name: "2024-01-16.md", ModifiedTime: "2024-01-16T10:00:00Z"}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotType, gotChanged := manifest.Compare(tt.file)
+			if gotType != tt.wantType || gotChanged != tt.wantChanged {
+				t.Errorf("Compare() = (%v, %v), want (%v, %v)", gotType, gotChanged, tt.wantType, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestSyncManifest_Missing(t *testing.T) {
+	manifest := NewSyncManifest()
+	manifest.Record(&drive.File{Id: "b", Name: "2024-01-16.md"}, "")
+	manifest.Record(&drive.File{Id: "a", Name: "2024-01-15.md"}, "")
+	manifest.Record(&drive.File{Id: "c", Name: "2024-01-17.md"}, "")
+
+	missing := manifest.Missing([]*drive.File{{Id: "c"}})
+	if len(missing) != 2 || missing[0] != "a" || missing[1] != "b" {
+		t.Errorf("Missing() = %v, want [a b]", missing)
+	}
+
+	manifest.Forget("a")
+	if _, ok := manifest.Files["a"]; ok {
+		t.Error("Forget() did not remove the entry")
+	}
+}
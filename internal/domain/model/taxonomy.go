@@ -0,0 +1,145 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Taxonomies group the entries of a site by a front-matter field. The name is
+// the folder of their pages.
+const (
+	TaxonomyTags       = "tags"
+	TaxonomyCategories = "categories"
+)
+
+// Taxonomy is the tags or the categories of a site
+type Taxonomy struct {
+	Name string
+	// Terms are ordered by slug
+	Terms []*Term
+}
+
+// Term is a tag or a category with the entries that have it, in order
+type Term struct {
+	Name string
+	// Path is the page of the term without extension, such as tags/go
+	Path    string
+	Entries []*SiteEntry
+}
+
+// Tags returns the tags field of the front-matter, a list or a single tag,
+// without blanks and duplicates
+func (n *Nippo) Tags() []string {
+	if n.FrontMatter == nil {
+		return nil
+	}
+	var names []string
+	switch v := n.FrontMatter.Raw["tags"].(type) {
+	case []interface{}:
+		for _, item := range v {
+			names = append(names, fmt.Sprint(item))
+		}
+	case string:
+		names = append(names, v)
+	}
+	var tags []string
+	for _, name := range names {
+		tag := strings.TrimSpace(name)
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// Category returns the category field of the front-matter, or ""
+func (n *Nippo) Category() string {
+	return n.frontMatterString("category")
+}
+
+// slugSymbols are the words of the symbols that tell terms apart, such as
+// the # of C# and the + of C++
+var slugSymbols = map[rune]string{'#': "sharp", '+': "plus", '&': "and", '@': "at"}
+
+// Slug returns the name of the page of a term: the name in lower case, with
+// the symbols spelt out as words, such as c-sharp for C#, and runs of other
+// characters than letters, digits and _ replaced by a hyphen. It is empty when
+// the name has none of them.
+func Slug(name string) string {
+	var b strings.Builder
+	hyphen := false
+	word := func(w string) {
+		if hyphen && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		hyphen = false
+		b.WriteString(w)
+	}
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			word(string(r))
+		case slugSymbols[r] != "":
+			hyphen = true
+			word(slugSymbols[r])
+			hyphen = true
+		default:
+			hyphen = true
+		}
+	}
+	return b.String()
+}
+
+// newTaxonomy groups entries by the terms termsOf returns. Names with the same
+// slug share a term, named as first written.
+func newTaxonomy(name string, entries []*SiteEntry, termsOf func(*SiteEntry) []string) *Taxonomy {
+	taxonomy := &Taxonomy{Name: name}
+	terms := map[string]*Term{}
+	for _, entry := range entries {
+		for _, termName := range termsOf(entry) {
+			slug := Slug(termName)
+			if slug == "" {
+				continue
+			}
+			term, ok := terms[slug]
+			if !ok {
+				term = &Term{Name: termName, Path: name + "/" + slug}
+				terms[slug] = term
+				taxonomy.Terms = append(taxonomy.Terms, term)
+			}
+			if !slices.Contains(term.Entries, entry) {
+				term.Entries = append(term.Entries, entry)
+				entry.Terms = append(entry.Terms, term)
+			}
+		}
+	}
+	slices.SortFunc(taxonomy.Terms, func(a, b *Term) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return taxonomy
+}
+
+// TermPaths returns the paths of the pages of the taxonomies that have terms
+// and of their terms, such as tags and tags/go
+func (s *Site) TermPaths() []string {
+	var paths []string
+	for _, taxonomy := range []*Taxonomy{s.Categories, s.Tags} {
+		if len(taxonomy.Terms) == 0 {
+			continue
+		}
+		paths = append(paths, taxonomy.Name)
+		for _, term := range taxonomy.Terms {
+			paths = append(paths, term.Path)
+		}
+	}
+	return paths
+}
+
+// Url returns the path of the page of the term on the site, such as /tags/go
+func (t *Term) Url() string {
+	name, slug, _ := strings.Cut(t.Path, "/")
+	return "/" + name + "/" + url.PathEscape(slug)
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNippo_Tags(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  map[string]interface{}
+		want []string
+	}{
+		{"list", map[string]interface{}{"tags": []interface{}{"go", " cli ", "", "go"}}, []string{"go", "cli"}},
+		{"single", map[string]interface{}{"tags": "go"}, []string{"go"}},
+		{"numbers", map[string]interface{}{"tags": []interface{}{2024}}, []string{"2024"}},
+		{"none", map[string]interface{}{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &Nippo{FrontMatter: &FrontMatter{Raw: tt.raw}}
+			if got := n.Tags(); !slices.Equal(got, tt.want) {
+				t.Errorf("Tags() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := (&Nippo{}).Tags(); got != nil {
+		t.Errorf("Tags() without front-matter = %q, want nil", got)
+	}
+}
+
+func TestNippo_Category(t *testing.T) {
+	n := &Nippo{FrontMatter: &FrontMatter{Raw: map[string]interface{}{"category": " work "}}}
+	if got := n.Category(); got != "work" {
+		t.Errorf("Category() = %q, want work", got)
+	}
+	if got := (&Nippo{}).Category(); got != "" {
+		t.Errorf("Category() without front-matter = %q, want empty", got)
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"Go":            "go",
+		"Release Notes": "release-notes",
+		"c++ / rust":    "c-plus-plus-rust",
+		"C#":            "c-sharp",
+		"Q&A":           "q-and-a",
+		"snake_case":    "snake_case",
+		"--trim--":      "trim",
+		"日報":            "日報",
+		"!!!":           "",
+	}
+	for name, want := range tests {
+		if got := Slug(name); got != want {
+			t.Errorf("Slug(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestNewSite_Taxonomies(t *testing.T) {
+	site, err := NewSite([]Nippo{
+		{Date: NewNippoDate("2024-01-14.md"), Content: []byte("---\ncategory: work\ntags: [Go, release]\n---\n# 14th\n")},
+		{Date: NewNippoDate("2024-01-15.md"), Content: []byte("---\ntags: [go, \"<b>\"]\n---\n# 15th\n")},
+		{Date: NewNippoDate("2024-01-15-evening.md"), Content: []byte("---\ntags: [release]\n---\n# Evening\n")},
+	})
+	if err != nil {
+		t.Fatalf("NewSite() error = %v", err)
+	}
+
+	var paths []string
+	for _, term := range site.Tags.Terms {
+		paths = append(paths, term.Path)
+	}
+	if want := []string{"tags/b", "tags/go", "tags/release"}; !slices.Equal(paths, want) {
+		t.Fatalf("tags = %v, want %v", paths, want)
+	}
+	// Go and go share a term, named as first written
+	goTerm := site.Tags.Terms[1]
+	if goTerm.Name != "Go" || len(goTerm.Entries) != 2 || goTerm.Entries[0] != site.Entries[0] {
+		t.Errorf("term go = %+v", goTerm)
+	}
+	if len(site.Categories.Terms) != 1 || site.Entries[0].Terms[0] != site.Categories.Terms[0] {
+		t.Errorf("the category should be the first term of the entry, got %+v", site.Entries[0].Terms)
+	}
+	if want := []string{"categories", "categories/work", "tags", "tags/b", "tags/go", "tags/release"}; !slices.Equal(site.TermPaths(), want) {
+		t.Errorf("TermPaths() = %v, want %v", site.TermPaths(), want)
+	}
+
+	// The day lists the terms of its entries once each, the body has none
+	var dayTerms []string
+	for _, term := range site.Days[1].Terms() {
+		dayTerms = append(dayTerms, term.Path)
+	}
+	if want := []string{"tags/go", "tags/b", "tags/release"}; !slices.Equal(dayTerms, want) {
+		t.Errorf("Terms() of the 15th = %v, want %v", dayTerms, want)
+	}
+	if html := string(site.Days[1].Html()); strings.Contains(html, "rel=\"tag\"") {
+		t.Errorf("Html() = %q, want no links to the terms", html)
+	}
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// WorkdirStateVersion is the current version of the working directory state format
+const WorkdirStateVersion = 1
+
+// WorkdirState records which Drive file each file of a working directory was
+// pulled from. Entries are keyed by the slash-separated path of the file, which
+// is the same within the working directory and the source folder.
+type WorkdirState struct {
+	Version int                     `json:"version"`
+	Files   map[string]WorkdirEntry `json:"files"`
+}
+
+// WorkdirEntry is the state of a single file as of the last pull or push
+type WorkdirEntry struct {
+	Id string `json:"id"`
+	// Md5Checksum and Version are those of the Drive file, to tell whether it
+	// has been changed remotely since
+	Md5Checksum string `json:"md5Checksum,omitempty"`
+	Version     int64  `json:"version,omitempty"`
+	// LocalMd5 is the checksum of the local file, to tell whether it has been edited since
+	LocalMd5 string `json:"localMd5"`
+}
+
+// WorkdirFile is a nippo file in the working directory
+type WorkdirFile struct {
+	// Name is the slash-separated path of the file within the working directory
+	Name    string
+	Content []byte
+}
+
+// Md5 returns the checksum of the file content, as Drive computes md5Checksum
+func (f *WorkdirFile) Md5() string {
+	return ContentMd5(f.Content)
+}
+
+// ContentMd5 returns the hex encoded MD5 checksum of content
+func ContentMd5(content []byte) string {
+	sum := md5.Sum(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// WorkdirConflictSuffix is appended to the name of the remote copy written next
+// to a conflicting file
+const WorkdirConflictSuffix = ".remote.md"
+
+// WorkdirConflictName returns the name of the remote copy of the file name
+func WorkdirConflictName(name string) string {
+	return strings.TrimSuffix(name, ".md") + WorkdirConflictSuffix
+}
+
+// IsWorkdirConflictName reports whether name is a remote copy written on conflict
+func IsWorkdirConflictName(name string) bool {
+	return strings.HasSuffix(name, WorkdirConflictSuffix)
+}
+
+func NewWorkdirState() *WorkdirState {
+	return &WorkdirState{
+		Version: WorkdirStateVersion,
+		Files:   map[string]WorkdirEntry{},
+	}
+}
+
+// RemoteChanged reports whether file, at name in the source folder, differs
+// from the revision recorded for name. The checksum is compared when both are
+// known, the version otherwise.
+func (s *WorkdirState) RemoteChanged(name string, file *drive.File) bool {
+	entry, ok := s.Files[name]
+	switch {
+	case !ok || entry.Id != file.Id:
+		return true
+	case entry.Md5Checksum != "" && file.Md5Checksum != "":
+		return entry.Md5Checksum != file.Md5Checksum
+	default:
+		return entry.Version != file.Version
+	}
+}
+
+// LocalChanged reports whether the local file differs from the one recorded for
+// its name. Files that were never pulled are changed.
+func (s *WorkdirState) LocalChanged(file *WorkdirFile) bool {
+	entry, ok := s.Files[file.Name]
+	return !ok || entry.LocalMd5 != file.Md5()
+}
+
+// Record stores the revision of the Drive file at name and the checksum of its
+// local copy
+func (s *WorkdirState) Record(name string, file *drive.File, localMd5 string) {
+	s.Files[name] = WorkdirEntry{
+		Id:          file.Id,
+		Md5Checksum: file.Md5Checksum,
+		Version:     file.Version,
+		LocalMd5:    localMd5,
+	}
+}
+
+// Forget removes the entry for the file name
+func (s *WorkdirState) Forget(name string) {
+	delete(s.Files, name)
+}
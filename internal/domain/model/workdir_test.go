@@ -0,0 +1,67 @@
+package model
+
+import (
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+)
+
+func TestWorkdirState_RemoteChanged(t *testing.T) {
+	state := NewWorkdirState()
+	state.Record("2024-01-15.md", &drive.File{Id: "1", Name: "2024-01-15.md", Md5Checksum: "abc", Version: 3}, "abc")
+	state.Record("2024/01/16.md", &drive.File{Id: "2", Name: "16.md", Version: 5}, "def")
+
+	tests := []struct {
+		name string
+		path string
+		file *drive.File
+		want bool
+	}{
+		{"unknown file", "2024-01-17.md", &drive.File{Id: "3", Name: "2024-01-17.md"}, true},
+		{"same checksum", "2024-01-15.md", &drive.File{Id: "1", Name: "2024-01-15.md", Md5Checksum: "abc", Version: 4}, false},
+		{"different checksum", "2024-01-15.md", &drive.File{Id: "1", Name: "2024-01-15.md", Md5Checksum: "xyz", Version: 3}, true},
+		{"another file of the same name", "2024-01-15.md", &drive.File{Id: "9", Name: "2024-01-15.md", Md5Checksum: "abc"}, true},
+		{"falls back to version", "2024/01/16.md", &drive.File{Id: "2", Name: "16.md", Version: 6}, true},
+		{"same version", "2024/01/16.md", &drive.File{Id: "2", Name: "16.md", Version: 5}, false},
+		{"same name in another folder", "2024/02/16.md", &drive.File{Id: "2", Name: "16.md", Version: 5}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := state.RemoteChanged(tt.path, tt.file); got != tt.want {
+				t.Errorf("RemoteChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkdirState_LocalChanged(t *testing.T) {
+	file := &WorkdirFile{Name: "2024-01-15.md", Content: []byte("# 01/15 mon\n")}
+	state := NewWorkdirState()
+	if !state.LocalChanged(file) {
+		t.Error("LocalChanged() = false for a file that was never pulled")
+	}
+
+	state.Record(file.Name, &drive.File{Id: "1", Name: file.Name}, file.Md5())
+	if state.LocalChanged(file) {
+		t.Error("LocalChanged() = true for an unedited file")
+	}
+	file.Content = append(file.Content, "edited\n"...)
+	if !state.LocalChanged(file) {
+		t.Error("LocalChanged() = false for an edited file")
+	}
+
+	state.Forget(file.Name)
+	if _, ok := state.Files[file.Name]; ok {
+		t.Error("Forget() did not remove the entry")
+	}
+}
+
+func TestWorkdirConflictName(t *testing.T) {
+	name := WorkdirConflictName("2024-01-15.md")
+	if name != "2024-01-15.remote.md" {
+		t.Errorf("WorkdirConflictName() = %q", name)
+	}
+	if !IsWorkdirConflictName(name) || IsWorkdirConflictName("2024-01-15.md") {
+		t.Error("IsWorkdirConflictName() did not tell remote copies apart")
+	}
+}
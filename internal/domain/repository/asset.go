@@ -3,4 +3,5 @@ package repository
 type AssetRepository interface {
 	CleanNippoCache() error
 	CleanBuildCache() error
+	RemoveBuildPages(paths []string) error
 }
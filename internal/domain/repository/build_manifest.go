@@ -0,0 +1,8 @@
+package repository
+
+import "github.com/c18t/nippo-cli/internal/domain/model"
+
+type BuildManifestRepository interface {
+	Load() (*model.BuildManifest, error)
+	Save(manifest *model.BuildManifest) error
+}
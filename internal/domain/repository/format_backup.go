@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/c18t/nippo-cli/internal/domain/model"
+)
+
+// ErrFormatRunNotFound is returned when loading a format run that has no backup
+var ErrFormatRunNotFound = errors.New("format run not found")
+
+// FormatBackupRepository keeps the originals of the files changed by each
+// `nippo format` run, with a manifest per run
+type FormatBackupRepository interface {
+	// Backup saves the original content of file into the directory of run
+	Backup(run *model.FormatRun, file *model.FormatRunFile, content []byte) error
+	// ReadBackup returns the original content of file saved by Backup
+	ReadBackup(run *model.FormatRun, file *model.FormatRunFile) ([]byte, error)
+	// Discard removes the backup of file, for a file whose update failed
+	Discard(run *model.FormatRun, file *model.FormatRunFile) error
+	// Save writes the manifest of run
+	Save(run *model.FormatRun) error
+	// Load reads the manifest of the run of id. It fails with ErrFormatRunNotFound
+	// when there is no such run.
+	Load(id string) (*model.FormatRun, error)
+	// List returns the manifests of all runs, the newest first
+	List() ([]model.FormatRun, error)
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"errors"
 	"time"
 
 	"github.com/c18t/nippo-cli/internal/domain/model"
@@ -19,10 +20,51 @@ type QueryListOption struct {
 	Recursive   bool
 }
 
+// ErrFullSyncRequired is returned when changes can't be tracked from a page token,
+// because the token expired or the folder tree was restructured, and the remote
+// folders have to be listed again.
+var ErrFullSyncRequired = errors.New("full sync required")
+
+// ErrNippoExists is returned when creating a nippo for a date that already has one
+var ErrNippoExists = errors.New("nippo already exists")
+
+// ErrConflict is returned when updating a nippo that was changed remotely after it was fetched
+var ErrConflict = errors.New("changed remotely since it was fetched")
+
+// RemoteNippoChanges is the set of changes since a page token
+type RemoteNippoChanges struct {
+	// Changed holds nippo files that were added or modified within the folders
+	Changed []model.Nippo
+	// Removed holds IDs of files that were deleted, trashed or moved out of the folders
+	Removed []string
+	// FolderIds is the folder set after applying the changes
+	FolderIds []string
+	// PageToken is the token to pass to the next ListChanges call
+	PageToken string
+}
+
 type RemoteNippoQuery interface {
 	List(param *QueryListParam, option *QueryListOption) ([]model.Nippo, error)
+	// ListTree lists recursively like List and also returns the IDs of every folder walked
+	ListTree(param *QueryListParam, option *QueryListOption) ([]model.Nippo, []string, error)
+	StartPageToken() (string, error)
+	// ListChanges returns the changes within folderIds since pageToken
+	ListChanges(pageToken string, folderIds []string, param *QueryListParam) (*RemoteNippoChanges, error)
 	Download(nippo *model.Nippo) error
+	// Update replaces the content of nippo. It fails with ErrConflict when the
+	// file was changed remotely since nippo.RemoteFile was fetched.
 	Update(nippo *model.Nippo, content []byte) error
+	// Create adds nippo.Content as a new file at filePath, a slash-separated path
+	// below folder whose missing folders are created. It fails with ErrNippoExists
+	// when that folder already has the file.
+	Create(folder, filePath string, nippo *model.Nippo) error
+	// Flush publishes the updates and creations a source holds back to send at
+	// once, such as the commits to a cloned git repository. Commands call it
+	// after their last change.
+	Flush() error
+	// Naming returns the patterns the files of the source are named by, which
+	// List reads the dates of nippo from
+	Naming() (*model.NippoNaming, error)
 }
 
 type LocalNippoQuery interface {
@@ -0,0 +1,8 @@
+package repository
+
+import "github.com/c18t/nippo-cli/internal/domain/model"
+
+type SyncManifestRepository interface {
+	Load() (*model.SyncManifest, error)
+	Save(manifest *model.SyncManifest) error
+}
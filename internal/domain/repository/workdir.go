@@ -0,0 +1,15 @@
+package repository
+
+import "github.com/c18t/nippo-cli/internal/domain/model"
+
+// WorkdirRepository reads and writes the nippo files of a working directory
+// and the state recorded by the last pull or push
+type WorkdirRepository interface {
+	LoadState(dir string) (*model.WorkdirState, error)
+	SaveState(dir string, state *model.WorkdirState) error
+	// List returns the Markdown files in the folder tree of dir, sorted by their
+	// slash-separated path
+	List(dir string) ([]model.WorkdirFile, error)
+	Write(dir string, file *model.WorkdirFile) error
+	Remove(dir, name string) error
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	"time"
 
 	"github.com/c18t/nippo-cli/internal/domain/model"
 	"github.com/c18t/nippo-cli/internal/domain/repository"
@@ -16,6 +17,8 @@ type NippoFacade interface {
 
 type NippoFacadeAction int
 
+// Requesting both Download and Cache syncs the local cache with the remote files,
+// adding, updating, renaming and deleting cached files as recorded in the sync manifest.
 const (
 	NippoFacadeActionSearch = 1 << iota
 	NippoFacadeActionClean
@@ -24,17 +27,29 @@ const (
 )
 
 type NippoFacadeRequest struct {
-	Action  NippoFacadeAction
-	Query   *repository.QueryListParam
-	Option  *repository.QueryListOption
+	Action NippoFacadeAction
+	Query  *repository.QueryListParam
+	Option *repository.QueryListOption
+	// Content is the nippo to cache. When syncing, it is the currently cached nippo,
+	// which is used to remove cached files that predate the sync manifest.
 	Content []model.Nippo
 }
+
 // ProgressCallback is called for each file processed during download/cache operations.
+// Calls are serialized, so the callback does not need to be safe for concurrent use.
 // Returns true to continue, false to cancel the operation.
 type ProgressCallback func(filename string, fileId string, current int, total int) bool
 
 type NippoFacadeOption struct {
 	OnProgress ProgressCallback
+	// Concurrency is the number of workers used for download/cache operations.
+	// Values less than 1 use core.DefaultSyncConcurrency.
+	Concurrency int
+	// FullSync lists every remote folder instead of following the change log
+	FullSync bool
+	// Location is the time zone a time in the date field of front-matter falls
+	// on a date in. Nil uses local time.
+	Location *time.Location
 }
 type NippoFacadeReponse struct {
 	Result  *NippoFacadeResponseResult
@@ -44,4 +59,10 @@ type NippoFacadeResponseResult struct {
 	Action  NippoFacadeAction
 	Count   int
 	Message string
+	// Changes lists the files added, updated, renamed or deleted in the local cache
+	Changes []model.SyncChange
+	// Failed lists the files that could not be synced; they are retried on the next run
+	Failed []model.SyncChange
+	// Skipped lists the files that have no date in their name or front-matter
+	Skipped []model.SyncChange
 }
@@ -2,4 +2,6 @@ package service
 
 type TemplateService interface {
 	SaveTo(filePath string, templateName string, data any) error
+	// Hash returns a hash of the templates, which changes with any of them
+	Hash() (string, error)
 }
@@ -8,20 +8,30 @@ import (
 	"github.com/samber/do/v2"
 )
 
-// AuthPackage groups all services specific to the auth command.
-// Services are lazily initialized when first requested.
+// AuthPackage groups all services specific to the auth command and its
+// status and logout subcommands. Services are lazily initialized when first requested.
 var AuthPackage = do.Package(
 	// adapter/controller
 	do.Lazy(controller.NewAuthController),
+	do.Lazy(controller.NewAuthStatusController),
+	do.Lazy(controller.NewAuthLogoutController),
 
 	// usecase/port
 	do.Lazy(port.NewAuthUseCaseBus),
+	do.Lazy(port.NewAuthStatusUseCaseBus),
+	do.Lazy(port.NewAuthLogoutUseCaseBus),
 
 	// usecase/interactor
 	do.Lazy(interactor.NewAuthInteractor),
+	do.Lazy(interactor.NewAuthStatusInteractor),
+	do.Lazy(interactor.NewAuthLogoutInteractor),
 
 	// adapter/presenter
 	do.Lazy(presenter.NewAuthPresenter),
+	do.Lazy(presenter.NewAuthStatusPresenter),
+	do.LazyNamed(presenter.AuthStatusJSONPresenterName, presenter.NewAuthStatusJSONPresenter),
+	do.Lazy(presenter.NewAuthLogoutPresenter),
+	do.LazyNamed(presenter.AuthLogoutJSONPresenterName, presenter.NewAuthLogoutJSONPresenter),
 )
 
 // InjectorAuth provides a DI container with both base and auth-specific services.
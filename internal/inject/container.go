@@ -42,7 +42,7 @@ var (
 // initializations until actually needed.
 //
 // The package includes:
-//   - adapter/gateway: File providers (Drive API, local filesystem)
+//   - adapter/gateway: File providers (Drive API, local filesystem, git) and Google auth
 //   - domain/repository: Data access (nippo queries, commands, assets)
 //   - domain/service: Business logic (nippo facade, template service)
 //
@@ -55,6 +55,8 @@ var BasePackage = do.Package(
 	// adapter/gateway
 	do.Lazy(gateway.NewDriveFileProvider),
 	do.Lazy(gateway.NewLocalFileProvider),
+	do.Lazy(gateway.NewGitProvider),
+	do.Lazy(gateway.NewGoogleAuthProvider),
 
 	// adapter/presenter
 	do.Lazy(presenter.NewConsolePresenter),
@@ -64,6 +66,10 @@ var BasePackage = do.Package(
 	do.Lazy(repository.NewLocalNippoQuery),
 	do.Lazy(repository.NewLocalNippoCommand),
 	do.Lazy(repository.NewAssetRepository),
+	do.Lazy(repository.NewSyncManifestRepository),
+	do.Lazy(repository.NewBuildManifestRepository),
+	do.Lazy(repository.NewWorkdirRepository),
+	do.Lazy(repository.NewFormatBackupRepository),
 
 	// domain/service
 	do.Lazy(service.NewNippoFacade),
@@ -8,20 +8,30 @@ import (
 	"github.com/samber/do/v2"
 )
 
-// FormatPackage groups all services specific to the format command.
-// Services are lazily initialized when first requested.
+// FormatPackage groups all services specific to the format command and its
+// revert and history subcommands. Services are lazily initialized when first requested.
 var FormatPackage = do.Package(
 	// adapter/controller
 	do.Lazy(controller.NewFormatController),
+	do.Lazy(controller.NewFormatRevertController),
+	do.Lazy(controller.NewFormatHistoryController),
 
 	// usecase/port
 	do.Lazy(port.NewFormatUseCaseBus),
+	do.Lazy(port.NewFormatRevertUseCaseBus),
+	do.Lazy(port.NewFormatHistoryUseCaseBus),
 
 	// usecase/interactor
 	do.Lazy(interactor.NewFormatCommandInteractor),
+	do.Lazy(interactor.NewFormatRevertInteractor),
+	do.Lazy(interactor.NewFormatHistoryInteractor),
 
 	// adapter/presenter
 	do.Lazy(presenter.NewFormatCommandPresenter),
+	do.Lazy(presenter.NewFormatPlanPresenter),
+	do.LazyNamed(presenter.FormatPlanJSONPresenterName, presenter.NewFormatPlanJSONPresenter),
+	do.Lazy(presenter.NewFormatRevertPresenter),
+	do.Lazy(presenter.NewFormatHistoryPresenter),
 )
 
 // InjectorFormat provides a DI container with both base and format-specific services.
@@ -0,0 +1,28 @@
+package inject
+
+import (
+	"github.com/c18t/nippo-cli/internal/adapter/controller"
+	"github.com/c18t/nippo-cli/internal/adapter/presenter"
+	"github.com/c18t/nippo-cli/internal/usecase/interactor"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+)
+
+// CreatePackage groups all services specific to the new command.
+// Services are lazily initialized when first requested.
+var CreatePackage = do.Package(
+	// adapter/controller
+	do.Lazy(controller.NewCreateController),
+
+	// usecase/port
+	do.Lazy(port.NewCreateUseCaseBus),
+
+	// usecase/interactor
+	do.Lazy(interactor.NewCreateCommandInteractor),
+
+	// adapter/presenter
+	do.Lazy(presenter.NewCreateCommandPresenter),
+)
+
+// InjectorCreate provides a DI container with both base and new-specific services.
+var InjectorCreate = do.New(BasePackage, CreatePackage)
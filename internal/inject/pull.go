@@ -0,0 +1,28 @@
+package inject
+
+import (
+	"github.com/c18t/nippo-cli/internal/adapter/controller"
+	"github.com/c18t/nippo-cli/internal/adapter/presenter"
+	"github.com/c18t/nippo-cli/internal/usecase/interactor"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+)
+
+// PullPackage groups all services specific to the pull command.
+// Services are lazily initialized when first requested.
+var PullPackage = do.Package(
+	// adapter/controller
+	do.Lazy(controller.NewPullController),
+
+	// usecase/port
+	do.Lazy(port.NewPullUseCaseBus),
+
+	// usecase/interactor
+	do.Lazy(interactor.NewPullCommandInteractor),
+
+	// adapter/presenter
+	do.Lazy(presenter.NewPullCommandPresenter),
+)
+
+// InjectorPull provides a DI container with both base and pull-specific services.
+var InjectorPull = do.New(BasePackage, PullPackage)
@@ -0,0 +1,28 @@
+package inject
+
+import (
+	"github.com/c18t/nippo-cli/internal/adapter/controller"
+	"github.com/c18t/nippo-cli/internal/adapter/presenter"
+	"github.com/c18t/nippo-cli/internal/usecase/interactor"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+)
+
+// PushPackage groups all services specific to the push command.
+// Services are lazily initialized when first requested.
+var PushPackage = do.Package(
+	// adapter/controller
+	do.Lazy(controller.NewPushController),
+
+	// usecase/port
+	do.Lazy(port.NewPushUseCaseBus),
+
+	// usecase/interactor
+	do.Lazy(interactor.NewPushCommandInteractor),
+
+	// adapter/presenter
+	do.Lazy(presenter.NewPushCommandPresenter),
+)
+
+// InjectorPush provides a DI container with both base and push-specific services.
+var InjectorPush = do.New(BasePackage, PushPackage)
@@ -16,26 +16,41 @@ type TestBasePackageOptions struct {
 	Config *core.Config
 
 	// adapter/gateway
-	DriveFileProvider gateway.DriveFileProvider
-	LocalFileProvider gateway.LocalFileProvider
+	DriveFileProvider  gateway.DriveFileProvider
+	LocalFileProvider  gateway.LocalFileProvider
+	GitProvider        gateway.GitProvider
+	GoogleAuthProvider gateway.GoogleAuthProvider
 
 	// adapter/presenter
 	ConsolePresenter       presenter.ConsolePresenter
 	RootCommandPresenter   presenter.RootCommandPresenter
 	CleanCommandPresenter  presenter.CleanCommandPresenter
+	CreateCommandPresenter presenter.CreateCommandPresenter
 	DeployCommandPresenter presenter.DeployCommandPresenter
+	PullCommandPresenter   presenter.PullCommandPresenter
+	PushCommandPresenter   presenter.PushCommandPresenter
 	UpdateCommandPresenter presenter.UpdateCommandPresenter
 	AuthPresenter          presenter.AuthPresenter
+	// AuthStatusPresenter, AuthLogoutPresenter and FormatPlanPresenter also replace the JSON variants
+	AuthStatusPresenter    presenter.AuthStatusPresenter
+	AuthLogoutPresenter    presenter.AuthLogoutPresenter
 	DoctorPresenter        presenter.DoctorPresenter
 	BuildCommandPresenter  presenter.BuildCommandPresenter
 	FormatCommandPresenter presenter.FormatCommandPresenter
+	FormatPlanPresenter    presenter.FormatPlanPresenter
+	FormatRevertPresenter  presenter.FormatRevertPresenter
+	FormatHistoryPresenter presenter.FormatHistoryPresenter
 	InitSettingPresenter   presenter.InitSettingPresenter
 
 	// domain/repository
-	RemoteNippoQuery  repository.RemoteNippoQuery
-	LocalNippoQuery   repository.LocalNippoQuery
-	LocalNippoCommand repository.LocalNippoCommand
-	AssetRepository   repository.AssetRepository
+	RemoteNippoQuery        repository.RemoteNippoQuery
+	LocalNippoQuery         repository.LocalNippoQuery
+	LocalNippoCommand       repository.LocalNippoCommand
+	AssetRepository         repository.AssetRepository
+	SyncManifestRepository  repository.SyncManifestRepository
+	BuildManifestRepository repository.BuildManifestRepository
+	WorkdirRepository       repository.WorkdirRepository
+	FormatBackupRepository  repository.FormatBackupRepository
 
 	// domain/service
 	NippoFacade     service.NippoFacade
@@ -78,6 +93,18 @@ func NewTestInjector(opts *TestBasePackageOptions) *do.RootScope {
 		})
 	}
 
+	if opts.GitProvider != nil {
+		do.Override(injector, func(do.Injector) (gateway.GitProvider, error) {
+			return opts.GitProvider, nil
+		})
+	}
+
+	if opts.GoogleAuthProvider != nil {
+		do.Override(injector, func(do.Injector) (gateway.GoogleAuthProvider, error) {
+			return opts.GoogleAuthProvider, nil
+		})
+	}
+
 	if opts.RemoteNippoQuery != nil {
 		do.Override(injector, func(do.Injector) (repository.RemoteNippoQuery, error) {
 			return opts.RemoteNippoQuery, nil
@@ -102,6 +129,30 @@ func NewTestInjector(opts *TestBasePackageOptions) *do.RootScope {
 		})
 	}
 
+	if opts.SyncManifestRepository != nil {
+		do.Override(injector, func(do.Injector) (repository.SyncManifestRepository, error) {
+			return opts.SyncManifestRepository, nil
+		})
+	}
+
+	if opts.BuildManifestRepository != nil {
+		do.Override(injector, func(do.Injector) (repository.BuildManifestRepository, error) {
+			return opts.BuildManifestRepository, nil
+		})
+	}
+
+	if opts.WorkdirRepository != nil {
+		do.Override(injector, func(do.Injector) (repository.WorkdirRepository, error) {
+			return opts.WorkdirRepository, nil
+		})
+	}
+
+	if opts.FormatBackupRepository != nil {
+		do.Override(injector, func(do.Injector) (repository.FormatBackupRepository, error) {
+			return opts.FormatBackupRepository, nil
+		})
+	}
+
 	if opts.NippoFacade != nil {
 		do.Override(injector, func(do.Injector) (service.NippoFacade, error) {
 			return opts.NippoFacade, nil
@@ -127,6 +178,24 @@ func NewTestInjector(opts *TestBasePackageOptions) *do.RootScope {
 		})
 	}
 
+	if opts.CreateCommandPresenter != nil {
+		do.Override(injector, func(do.Injector) (presenter.CreateCommandPresenter, error) {
+			return opts.CreateCommandPresenter, nil
+		})
+	}
+
+	if opts.PullCommandPresenter != nil {
+		do.Override(injector, func(do.Injector) (presenter.PullCommandPresenter, error) {
+			return opts.PullCommandPresenter, nil
+		})
+	}
+
+	if opts.PushCommandPresenter != nil {
+		do.Override(injector, func(do.Injector) (presenter.PushCommandPresenter, error) {
+			return opts.PushCommandPresenter, nil
+		})
+	}
+
 	if opts.CleanCommandPresenter != nil {
 		do.Override(injector, func(do.Injector) (presenter.CleanCommandPresenter, error) {
 			return opts.CleanCommandPresenter, nil
@@ -151,6 +220,24 @@ func NewTestInjector(opts *TestBasePackageOptions) *do.RootScope {
 		})
 	}
 
+	if opts.AuthStatusPresenter != nil {
+		do.Override(injector, func(do.Injector) (presenter.AuthStatusPresenter, error) {
+			return opts.AuthStatusPresenter, nil
+		})
+		do.OverrideNamed(injector, presenter.AuthStatusJSONPresenterName, func(do.Injector) (presenter.AuthStatusPresenter, error) {
+			return opts.AuthStatusPresenter, nil
+		})
+	}
+
+	if opts.AuthLogoutPresenter != nil {
+		do.Override(injector, func(do.Injector) (presenter.AuthLogoutPresenter, error) {
+			return opts.AuthLogoutPresenter, nil
+		})
+		do.OverrideNamed(injector, presenter.AuthLogoutJSONPresenterName, func(do.Injector) (presenter.AuthLogoutPresenter, error) {
+			return opts.AuthLogoutPresenter, nil
+		})
+	}
+
 	if opts.DoctorPresenter != nil {
 		do.Override(injector, func(do.Injector) (presenter.DoctorPresenter, error) {
 			return opts.DoctorPresenter, nil
@@ -169,6 +256,27 @@ func NewTestInjector(opts *TestBasePackageOptions) *do.RootScope {
 		})
 	}
 
+	if opts.FormatPlanPresenter != nil {
+		do.Override(injector, func(do.Injector) (presenter.FormatPlanPresenter, error) {
+			return opts.FormatPlanPresenter, nil
+		})
+		do.OverrideNamed(injector, presenter.FormatPlanJSONPresenterName, func(do.Injector) (presenter.FormatPlanPresenter, error) {
+			return opts.FormatPlanPresenter, nil
+		})
+	}
+
+	if opts.FormatRevertPresenter != nil {
+		do.Override(injector, func(do.Injector) (presenter.FormatRevertPresenter, error) {
+			return opts.FormatRevertPresenter, nil
+		})
+	}
+
+	if opts.FormatHistoryPresenter != nil {
+		do.Override(injector, func(do.Injector) (presenter.FormatHistoryPresenter, error) {
+			return opts.FormatHistoryPresenter, nil
+		})
+	}
+
 	if opts.InitSettingPresenter != nil {
 		do.Override(injector, func(do.Injector) (presenter.InitSettingPresenter, error) {
 			return opts.InitSettingPresenter, nil
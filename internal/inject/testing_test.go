@@ -33,10 +33,22 @@ type mockDriveFileProvider struct{}
 func (m *mockDriveFileProvider) List(param *repository.QueryListParam) (*drive.FileList, error) {
 	return nil, nil
 }
-func (m *mockDriveFileProvider) Download(id string) ([]byte, error)    { return nil, nil }
+func (m *mockDriveFileProvider) Download(id string) ([]byte, error)         { return nil, nil }
+func (m *mockDriveFileProvider) Get(fileId string) (*drive.File, error)     { return nil, nil }
 func (m *mockDriveFileProvider) Update(fileId string, content []byte) error { return nil }
-func (m *mockDriveFileProvider) Shutdown() error                       { return nil }
-func (m *mockDriveFileProvider) HealthCheck() error                    { return nil }
+func (m *mockDriveFileProvider) Create(file *drive.File, content []byte) (*drive.File, error) {
+	return file, nil
+}
+func (m *mockDriveFileProvider) GetStartPageToken() (string, error)         { return "", nil }
+func (m *mockDriveFileProvider) ListChanges(pageToken string) (*drive.ChangeList, error) {
+	return nil, nil
+}
+func (m *mockDriveFileProvider) OnRetry(notify gateway.RetryNotifier) {}
+
+func (m *mockDriveFileProvider) RequireAccess(access gateway.DriveAccess) {}
+
+func (m *mockDriveFileProvider) Shutdown() error    { return nil }
+func (m *mockDriveFileProvider) HealthCheck() error { return nil }
 
 type mockLocalFileProvider struct{}
 
@@ -52,8 +64,26 @@ type mockRemoteNippoQuery struct{}
 func (m *mockRemoteNippoQuery) List(param *repository.QueryListParam, option *repository.QueryListOption) ([]model.Nippo, error) {
 	return nil, nil
 }
+func (m *mockRemoteNippoQuery) ListTree(param *repository.QueryListParam, option *repository.QueryListOption) ([]model.Nippo, []string, error) {
+	return nil, nil, nil
+}
+func (m *mockRemoteNippoQuery) StartPageToken() (string, error) { return "", nil }
+func (m *mockRemoteNippoQuery) ListChanges(pageToken string, folderIds []string, param *repository.QueryListParam) (*repository.RemoteNippoChanges, error) {
+	return nil, nil
+}
 func (m *mockRemoteNippoQuery) Download(nippo *model.Nippo) error               { return nil }
 func (m *mockRemoteNippoQuery) Update(nippo *model.Nippo, content []byte) error { return nil }
+func (m *mockRemoteNippoQuery) Create(folder, filePath string, nippo *model.Nippo) error {
+	return nil
+}
+
+func (m *mockRemoteNippoQuery) Flush() error {
+	return nil
+}
+
+func (m *mockRemoteNippoQuery) Naming() (*model.NippoNaming, error) {
+	return model.NewNippoNaming(nil)
+}
 
 type mockLocalNippoQuery struct{}
 
@@ -75,6 +105,7 @@ type mockAssetRepository struct{}
 
 func (m *mockAssetRepository) CleanNippoCache() error { return nil }
 func (m *mockAssetRepository) CleanBuildCache() error { return nil }
+func (m *mockAssetRepository) RemoveBuildPages(paths []string) error { return nil }
 
 type mockNippoFacade struct{}
 
@@ -84,6 +115,8 @@ func (m *mockNippoFacade) Send(request *service.NippoFacadeRequest, option *serv
 
 type mockTemplateService struct{}
 
+func (m *mockTemplateService) Hash() (string, error) { return "", nil }
+
 func (m *mockTemplateService) SaveTo(filePath string, templateName string, data any) error {
 	return nil
 }
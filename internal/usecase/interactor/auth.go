@@ -4,7 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -13,13 +13,12 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/c18t/nippo-cli/internal/adapter/gateway"
 	"github.com/c18t/nippo-cli/internal/adapter/presenter"
 	"github.com/c18t/nippo-cli/internal/core"
 	"github.com/c18t/nippo-cli/internal/usecase/port"
 	"github.com/samber/do/v2"
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
-	"google.golang.org/api/drive/v3"
 )
 
 type authInteractor struct {
@@ -39,6 +38,16 @@ func NewAuthInteractor(i do.Injector) (port.AuthUseCase, error) {
 func (u *authInteractor) Handle(input *port.AuthUseCaseInputData) {
 	output := &port.AuthUseCaseOutputData{}
 
+	access := gateway.DriveReadOnly
+	if input.Write {
+		access = gateway.DriveReadWrite
+	}
+
+	if gateway.CurrentAuthMode() == gateway.AuthModeServiceAccount {
+		u.verifyServiceAccount(access, output)
+		return
+	}
+
 	dataDir := core.Cfg.GetDataDir()
 
 	// Ensure data directory exists
@@ -60,6 +69,9 @@ Please download the OAuth 2.0 Client ID credentials from Google Cloud Console:
 3. Download the credentials JSON file
 4. Save it to: %s
 
+To run without a browser, use 'nippo auth --device' or set
+service_account_key in the [auth] section of nippo.toml.
+
 See: https://github.com/c18t/nippo-cli#setup`, credPath))
 		} else {
 			u.presenter.Suspend(fmt.Errorf("unable to read credentials file: %w", err))
@@ -67,45 +79,63 @@ See: https://github.com/c18t/nippo-cli#setup`, credPath))
 		return
 	}
 
-	oauthConfig, err := google.ConfigFromJSON(b, drive.DriveScope)
+	oauthConfig, err := gateway.OAuthConfigFromJSON(b, access)
 	if err != nil {
-		u.presenter.Suspend(fmt.Errorf("unable to parse client secret file to config: %v", err))
+		u.presenter.Suspend(err)
 		return
 	}
 
-	tok, err := u.getTokenFromWeb(oauthConfig, output)
-	if err != nil {
-		u.presenter.Suspend(fmt.Errorf("unable to get token from web: %w", err))
+	var tok *oauth2.Token
+	if input.Device {
+		tok, err = u.getTokenFromDevice(oauthConfig, output)
+		if err != nil {
+			u.presenter.Suspend(fmt.Errorf("unable to get token with device authorization: %w", err))
+			return
+		}
+	} else {
+		tok, err = u.getTokenFromWeb(oauthConfig, output)
+		if err != nil {
+			u.presenter.Suspend(fmt.Errorf("unable to get token from web: %w", err))
+			return
+		}
+	}
+
+	// The consent screen lets the user leave scopes unchecked
+	if scopes := gateway.TokenScopes(tok); scopes != nil && !access.GrantedBy(scopes) {
+		u.presenter.Suspend(fmt.Errorf("%s access to Google Drive was not granted", access))
 		return
 	}
 
 	// Save token (spinner continues during save)
 	output.Message = "Saving credentials..."
 	u.presenter.Progress(output)
-	if err := u.saveToken(dataDir, filepath.Join(dataDir, "token.json"), tok); err != nil {
+	if err := gateway.SaveToken(dataDir, gateway.TokenPath(dataDir), tok); err != nil {
 		u.presenter.Suspend(fmt.Errorf("unable to save token: %w", err))
 		return
 	}
 
-	output.Message = "Successfully authenticated with Google Drive"
+	output.Message = fmt.Sprintf("Successfully authenticated with Google Drive (%s access)", access)
 	u.presenter.Complete(output)
 }
 
-// saveToken saves a token to a file path.
-func (u *authInteractor) saveToken(baseDir, path string, token *oauth2.Token) (err error) {
-	f, err := core.SafeOpenFile(baseDir, path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+// verifyServiceAccount checks that a token can be issued for the configured
+// service account key. Nothing is saved, as the key itself is the credential.
+func (u *authInteractor) verifyServiceAccount(access gateway.DriveAccess, output *port.AuthUseCaseOutputData) {
+	output.Message = "Verifying service account key..."
+	u.presenter.Progress(output)
+
+	config, err := gateway.ReadServiceAccountKey(core.Cfg.GetServiceAccountKeyPath(), access)
 	if err != nil {
-		return fmt.Errorf("unable to cache oauth token: %w", err)
+		u.presenter.Suspend(err)
+		return
 	}
-	defer func() {
-		if cerr := f.Close(); cerr != nil && err == nil {
-			err = fmt.Errorf("unable to close token file: %w", cerr)
-		}
-	}()
-	if err := json.NewEncoder(f).Encode(token); err != nil {
-		return fmt.Errorf("unable to encode token: %w", err)
+	if _, err := config.TokenSource(context.Background()).Token(); err != nil {
+		u.presenter.Suspend(fmt.Errorf("unable to get token for service account %s: %w", config.Email, err))
+		return
 	}
-	return nil
+
+	output.Message = fmt.Sprintf("Using service account %s (share the Drive folder with this address)", config.Email)
+	u.presenter.Complete(output)
 }
 
 // generateRandomState generates a random state parameter for CSRF protection.
@@ -252,7 +282,8 @@ func (u *authInteractor) getTokenFromWeb(config *oauth2.Config, output *port.Aut
 
 	// Configure redirect URI with the actual port
 	config.RedirectURL = fmt.Sprintf("http://localhost:%d/callback", actualPort)
-	authURL := config.AuthCodeURL(state, oauth2.AccessTypeOffline)
+	// Ask for incremental consent, so the token keeps the scopes granted before
+	authURL := config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.SetAuthURLParam("include_granted_scopes", "true"))
 
 	// Update spinner message with port number
 	output.Message = fmt.Sprintf("Opening browser for authorization (using port %d)...", actualPort)
@@ -289,3 +320,57 @@ func (u *authInteractor) getTokenFromWeb(config *oauth2.Config, output *port.Aut
 		}
 	}
 }
+
+// getTokenFromDevice requests a token with the OAuth 2.0 device authorization
+// flow: the user opens the verification URL on any device and enters the code,
+// while this machine polls for the token.
+func (u *authInteractor) getTokenFromDevice(config *oauth2.Config, output *port.AuthUseCaseOutputData) (*oauth2.Token, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	da, err := config.DeviceAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to start device authorization: %w", deviceScopeError(err))
+	}
+
+	output.Message = fmt.Sprintf("Visit %s and enter the code %s", da.VerificationURI, da.UserCode)
+	u.presenter.Progress(output)
+
+	// Stop polling when the user cancels
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if u.presenter.IsCancelled() {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	tok, err := config.DeviceAccessToken(ctx, da)
+	if err != nil {
+		if u.presenter.IsCancelled() {
+			return nil, fmt.Errorf("authorization cancelled by user")
+		}
+		return nil, deviceScopeError(err)
+	}
+	return tok, nil
+}
+
+// deviceScopeError points to the service account when Google refuses the
+// Drive scopes for the device flow, which it allows only a few of
+func deviceScopeError(err error) error {
+	var rerr *oauth2.RetrieveError
+	if errors.As(err, &rerr) && rerr.ErrorCode == "invalid_scope" {
+		return fmt.Errorf("%w\n\nGoogle doesn't allow this Drive access with the device flow. Set\nservice_account_key in the [auth] section of nippo.toml instead.", err)
+	}
+	return err
+}
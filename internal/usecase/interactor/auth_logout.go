@@ -0,0 +1,52 @@
+package interactor
+
+import (
+	"github.com/c18t/nippo-cli/internal/adapter/gateway"
+	"github.com/c18t/nippo-cli/internal/adapter/presenter"
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+)
+
+type authLogoutInteractor struct {
+	provider      gateway.GoogleAuthProvider
+	presenter     presenter.AuthLogoutPresenter
+	jsonPresenter presenter.AuthLogoutPresenter
+}
+
+func NewAuthLogoutInteractor(i do.Injector) (port.AuthLogoutUseCase, error) {
+	provider, err := do.Invoke[gateway.GoogleAuthProvider](i)
+	if err != nil {
+		return nil, err
+	}
+	p, err := do.Invoke[presenter.AuthLogoutPresenter](i)
+	if err != nil {
+		return nil, err
+	}
+	jp, err := do.InvokeNamed[presenter.AuthLogoutPresenter](i, presenter.AuthLogoutJSONPresenterName)
+	if err != nil {
+		return nil, err
+	}
+	return &authLogoutInteractor{
+		provider:      provider,
+		presenter:     p,
+		jsonPresenter: jp,
+	}, nil
+}
+
+func (u *authLogoutInteractor) Handle(input *port.AuthLogoutUseCaseInputData) {
+	p := u.presenter
+	if input.JSON {
+		p = u.jsonPresenter
+	}
+
+	loggedOut, err := u.provider.Logout()
+	if err != nil {
+		p.Suspend(err)
+		return
+	}
+	p.Complete(&port.AuthLogoutUseCaseOutputData{
+		LoggedOut: loggedOut,
+		Path:      gateway.TokenPath(core.Cfg.GetDataDir()),
+	})
+}
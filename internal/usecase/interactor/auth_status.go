@@ -0,0 +1,57 @@
+package interactor
+
+import (
+	"github.com/c18t/nippo-cli/internal/adapter/gateway"
+	"github.com/c18t/nippo-cli/internal/adapter/presenter"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+)
+
+type authStatusInteractor struct {
+	provider      gateway.GoogleAuthProvider
+	presenter     presenter.AuthStatusPresenter
+	jsonPresenter presenter.AuthStatusPresenter
+}
+
+func NewAuthStatusInteractor(i do.Injector) (port.AuthStatusUseCase, error) {
+	provider, err := do.Invoke[gateway.GoogleAuthProvider](i)
+	if err != nil {
+		return nil, err
+	}
+	p, err := do.Invoke[presenter.AuthStatusPresenter](i)
+	if err != nil {
+		return nil, err
+	}
+	jp, err := do.InvokeNamed[presenter.AuthStatusPresenter](i, presenter.AuthStatusJSONPresenterName)
+	if err != nil {
+		return nil, err
+	}
+	return &authStatusInteractor{
+		provider:      provider,
+		presenter:     p,
+		jsonPresenter: jp,
+	}, nil
+}
+
+func (u *authStatusInteractor) Handle(input *port.AuthStatusUseCaseInputData) {
+	p := u.presenter
+	if input.JSON {
+		p = u.jsonPresenter
+	}
+
+	status, err := u.provider.Status()
+	if err != nil {
+		p.Suspend(err)
+		return
+	}
+	p.Show(&port.AuthStatusUseCaseOutputData{
+		Mode:            string(status.Mode),
+		Path:            status.Path,
+		Authenticated:   status.Authenticated,
+		Account:         status.Account,
+		Scopes:          status.Scopes,
+		Expiry:          status.Expiry,
+		HasRefreshToken: status.HasRefreshToken,
+		Problem:         status.Problem,
+	})
+}
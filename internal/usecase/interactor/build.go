@@ -1,10 +1,17 @@
 package interactor
 
 import (
+	"cmp"
 	"fmt"
 	"html/template"
+	"io"
+	"net/url"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	texttemplate "text/template"
 	"time"
 
 	"github.com/c18t/nippo-cli/internal/adapter/gateway"
@@ -20,12 +27,13 @@ import (
 )
 
 type buildCommandInteractor struct {
-	assetRepository repository.AssetRepository      `do:""`
-	localNippoQuery repository.LocalNippoQuery      `do:""`
-	nippoService    service.NippoFacade             `do:""`
-	templateService service.TemplateService         `do:""`
-	fileProvider    gateway.LocalFileProvider       `do:""`
-	presenter       presenter.BuildCommandPresenter `do:""`
+	assetRepository         repository.AssetRepository         `do:""`
+	buildManifestRepository repository.BuildManifestRepository `do:""`
+	localNippoQuery         repository.LocalNippoQuery         `do:""`
+	nippoService            service.NippoFacade                `do:""`
+	templateService         service.TemplateService            `do:""`
+	fileProvider            gateway.LocalFileProvider          `do:""`
+	presenter               presenter.BuildCommandPresenter    `do:""`
 }
 
 func NewBuildCommandInteractor(i do.Injector) (port.BuildCommandUseCase, error) {
@@ -33,6 +41,10 @@ func NewBuildCommandInteractor(i do.Injector) (port.BuildCommandUseCase, error)
 	if err != nil {
 		return nil, err
 	}
+	buildManifestRepository, err := do.Invoke[repository.BuildManifestRepository](i)
+	if err != nil {
+		return nil, err
+	}
 	localNippoQuery, err := do.Invoke[repository.LocalNippoQuery](i)
 	if err != nil {
 		return nil, err
@@ -53,92 +65,179 @@ func NewBuildCommandInteractor(i do.Injector) (port.BuildCommandUseCase, error)
 	if err != nil {
 		return nil, err
 	}
+	driveFileProvider, err := do.Invoke[gateway.DriveFileProvider](i)
+	if err != nil {
+		return nil, err
+	}
+	// Build only reads from Drive
+	driveFileProvider.RequireAccess(gateway.DriveReadOnly)
+	driveFileProvider.OnRetry(func(event gateway.RetryEvent) {
+		p.Warn(event.String())
+	})
 	return &buildCommandInteractor{
-		assetRepository: assetRepository,
-		localNippoQuery: localNippoQuery,
-		nippoService:    nippoService,
-		templateService: templateService,
-		fileProvider:    fileProvider,
-		presenter:       p,
+		assetRepository:         assetRepository,
+		buildManifestRepository: buildManifestRepository,
+		localNippoQuery:         localNippoQuery,
+		nippoService:            nippoService,
+		templateService:         templateService,
+		fileProvider:            fileProvider,
+		presenter:               p,
 	}, nil
 }
 
 func (u *buildCommandInteractor) Handle(input *port.BuildCommandUseCaseInputData) {
-	downloadedFiles, err := u.downloadNippo()
+	changedFiles, failedFiles, skippedFiles, err := u.downloadNippo()
 	if err != nil {
 		u.presenter.Suspend(err)
 		return
 	}
 
-	var buildError error
+	buildError := u.buildSite(input.Force)
 
-	if err := u.assetRepository.CleanBuildCache(); err != nil {
-		buildError = err
-	}
+	// Show summary (synced files and any build errors)
+	u.presenter.Summary(changedFiles, failedFiles, skippedFiles, buildError)
+}
 
-	if buildError == nil {
-		if err := u.buildIndexPage(); err != nil {
-			buildError = err
-		}
+// buildSite renders the pages affected by the nippo changed since the last
+// build, or every page when force is set or the templates or settings changed
+func (u *buildCommandInteractor) buildSite(force bool) error {
+	siteUrl, err := getSiteUrl()
+	if err != nil {
+		return err
 	}
-
-	if buildError == nil {
-		if err := u.buildNippoPage(); err != nil {
-			buildError = err
-		}
+	meta, err := newSiteMeta(siteUrl, core.Cfg.Site)
+	if err != nil {
+		return err
 	}
-
-	if buildError == nil {
-		if err := u.buildArchivePage(); err != nil {
-			buildError = err
-		}
+	cacheDir := filepath.Join(core.Cfg.GetCacheDir(), "md")
+	nippoList, err := u.localNippoQuery.List(&repository.QueryListParam{
+		Folders: []string{cacheDir},
+	}, &repository.QueryListOption{
+		WithContent: true,
+	})
+	if err != nil {
+		return err
 	}
-
-	if buildError == nil {
-		if err := u.buildFeed(); err != nil {
-			buildError = err
-		}
+	templates, err := u.templateService.Hash()
+	if err != nil {
+		return err
+	}
+	manifest, err := u.buildManifestRepository.Load()
+	if err != nil {
+		return err
+	}
+	// Every page has the site URL and settings, and its dates in the time zone
+	settings := model.ContentHash(fmt.Appendf(nil, "%s\n%s\n%+v", core.Cfg.Project.SiteUrl, core.Cfg.Project.Timezone, core.Cfg.Site))
+	plan, next := manifest.Plan(nippoList, templates, settings, force)
+	if !plan.HasChanges() {
+		return nil
 	}
 
-	if buildError == nil {
-		if err := u.buildSiteMap(); err != nil {
-			buildError = err
-		}
+	site, err := model.NewSite(nippoList)
+	if err != nil {
+		return err
 	}
+	next.Terms = site.TermPaths()
+	plan.RemoveTerms(manifest, next)
 
-	// Show summary (downloaded files and any build errors)
-	u.presenter.Summary(downloadedFiles, nil, buildError)
+	if plan.Full {
+		err = u.assetRepository.CleanBuildCache()
+	} else {
+		err = u.assetRepository.RemoveBuildPages(plan.Removed)
+	}
+	if err != nil {
+		return err
+	}
+	pages := u.nippoPages(site, plan, meta)
+	pages = append(pages, func() error { return u.buildIndexPage(site, meta) })
+	pages = append(pages, u.taxonomyPages(site, meta)...)
+	for month := range plan.Months {
+		pages = append(pages, func() error { return u.buildArchivePage(site, month, meta) })
+	}
+	if err := renderPages(pages, core.Cfg.Build.GetConcurrency()); err != nil {
+		return err
+	}
+	if err := u.buildFeed(site, meta); err != nil {
+		return err
+	}
+	if err := u.buildSiteMap(site); err != nil {
+		return err
+	}
+	return u.buildManifestRepository.Save(next)
+}
 
-	if buildError != nil {
-		return
+// renderPages runs the page renders on concurrency workers and returns the
+// first error
+func renderPages(pages []func() error, concurrency int) error {
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	jobs := make(chan func() error)
+	for w := 0; w < min(concurrency, len(pages)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for render := range jobs {
+				if err := render(); err != nil {
+					once.Do(func() { firstErr = err })
+				}
+			}
+		}()
+	}
+	for _, render := range pages {
+		jobs <- render
 	}
+	close(jobs)
+	wg.Wait()
+	return firstErr
 }
 
-func (u *buildCommandInteractor) downloadNippo() ([]presenter.FileInfo, error) {
+// downloadNippo syncs the cache and returns the files changed, failed and
+// skipped for having no date
+func (u *buildCommandInteractor) downloadNippo() ([]presenter.FileChange, []presenter.FileInfo, []presenter.FileInfo, error) {
 	// Show spinner while fetching file list
-	u.presenter.Progress(&port.BuildCommandUseCaseOutputData{Message: "Fetching file list from Google Drive..."})
+	u.presenter.Progress(&port.BuildCommandUseCaseOutputData{Message: "Fetching file list from " + core.Cfg.Source.DisplayName() + "..."})
 
 	started := false
-	var downloadedFiles []presenter.FileInfo
 
-	// Use configured drive folder ID
-	driveFolderId := core.Cfg.Project.DriveFolderId
-	if driveFolderId == "" {
-		return nil, fmt.Errorf("drive folder ID is not configured. Run `nippo init` to configure")
+	// Use the configured drive folder ID or local source directory
+	sourceFolder, err := core.Cfg.GetSourceFolder()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	loc, err := core.Cfg.Project.GetLocation()
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
-	_, err := u.nippoService.Send(&service.NippoFacadeRequest{
+	cachedNippo, err := u.localNippoQuery.List(&repository.QueryListParam{
+		Folders:        []string{filepath.Join(core.Cfg.GetCacheDir(), "md")},
+		FileExtensions: []string{"md"},
+	}, &repository.QueryListOption{})
+	if err != nil && !os.IsNotExist(err) {
+		u.presenter.StopProgress()
+		return nil, nil, nil, err
+	}
+
+	// List every file rather than only recently modified ones,
+	// so deletions and renames can be reconciled against the sync manifest.
+	res, err := u.nippoService.Send(&service.NippoFacadeRequest{
 		Action: service.NippoFacadeActionSearch | service.NippoFacadeActionDownload | service.NippoFacadeActionCache,
 		Query: &repository.QueryListParam{
-			Folders:        []string{driveFolderId},
+			Folders:        []string{sourceFolder},
 			FileExtensions: []string{"md"},
-			UpdatedAt:      core.Cfg.LastUpdateCheckTimestamp,
 			OrderBy:        "name",
 		},
 		Option: &repository.QueryListOption{
 			Recursive: true,
 		},
+		Content: cachedNippo,
 	}, &service.NippoFacadeOption{
+		Concurrency: core.Cfg.Sync.GetConcurrency(),
+		FullSync:    core.Cfg.Sync.IsFullSync(),
+		Location:    loc,
 		OnProgress: func(filename string, fileId string, current int, total int) bool {
 			if !started {
 				// Stop the "fetching" spinner and start build progress
@@ -147,7 +246,6 @@ func (u *buildCommandInteractor) downloadNippo() ([]presenter.FileInfo, error) {
 				started = true
 			}
 			u.presenter.UpdateBuildProgress(filename, fileId)
-			downloadedFiles = append(downloadedFiles, presenter.FileInfo{Name: filename, Id: fileId})
 			// Return false if user cancelled
 			return !u.presenter.IsBuildCancelled()
 		},
@@ -159,14 +257,32 @@ func (u *buildCommandInteractor) downloadNippo() ([]presenter.FileInfo, error) {
 		u.presenter.StopProgress()
 	}
 	if err != nil {
-		if err == service.ErrCancelled {
-			// User cancelled, exit silently
-			return downloadedFiles, err
+		return nil, nil, nil, err
+	}
+
+	var changedFiles []presenter.FileChange
+	var failedFiles, skippedFiles []presenter.FileInfo
+	if res != nil && res.Result != nil {
+		for _, change := range res.Result.Changes {
+			changedFiles = append(changedFiles, presenter.FileChange{
+				FileInfo: presenter.FileInfo{Name: change.Name, Id: change.FileId},
+				Kind:     change.Type.String(),
+				OldName:  change.OldName,
+			})
+		}
+		for _, change := range res.Result.Failed {
+			if change.Err != nil {
+				u.presenter.Warn(change.Err.Error())
+			}
+			failedFiles = append(failedFiles, presenter.FileInfo{Name: change.Name, Id: change.FileId})
+		}
+		for _, change := range res.Result.Skipped {
+			skippedFiles = append(skippedFiles, presenter.FileInfo{Name: change.Name, Id: change.FileId})
 		}
-		return downloadedFiles, err
 	}
+
 	core.Cfg.LastUpdateCheckTimestamp = time.Now()
-	return downloadedFiles, core.Cfg.SaveConfig()
+	return changedFiles, failedFiles, skippedFiles, core.Cfg.SaveConfig()
 }
 
 type OpenGraph struct {
@@ -184,6 +300,95 @@ func getSiteUrl() (string, error) {
 	return strings.TrimSuffix(core.Cfg.Project.SiteUrl, "/"), nil
 }
 
+// SiteMeta is the [site] settings, which every page has as .Site
+type SiteMeta struct {
+	Url         string
+	Title       string
+	Author      string
+	Language    string
+	Description string
+	// ImageUrl is the OGP image of the site, empty when there is none
+	ImageUrl string
+}
+
+// siteMeta renders the titles and descriptions of the pages and the feed from
+// the [site] settings
+type siteMeta struct {
+	SiteMeta
+	feedEntries      int
+	feedFormat       string
+	excerptLength    int
+	dayDescription   *texttemplate.Template
+	monthDescription *texttemplate.Template
+	pageTitle        *texttemplate.Template
+}
+
+func newSiteMeta(siteUrl string, cfg core.ConfigSite) (*siteMeta, error) {
+	cfg = cfg.WithDefaults()
+	m := &siteMeta{
+		SiteMeta: SiteMeta{
+			Url:      siteUrl,
+			Title:    cfg.Title,
+			Author:   cfg.Author,
+			Language: cfg.Language,
+		},
+		feedEntries:   cfg.FeedEntries,
+		feedFormat:    cfg.FeedFormat,
+		excerptLength: cfg.ExcerptLength,
+	}
+	if m.feedFormat != core.SiteFeedAtom && m.feedFormat != core.SiteFeedRSS {
+		return nil, fmt.Errorf("unknown feed format: %s. Set `feed_format` in the [site] section to %q or %q", m.feedFormat, core.SiteFeedAtom, core.SiteFeedRSS)
+	}
+	if cfg.OgpImage != "" {
+		if parsed, err := url.Parse(cfg.OgpImage); err == nil && parsed.IsAbs() {
+			m.ImageUrl = cfg.OgpImage
+		} else {
+			m.ImageUrl = siteUrl + "/" + strings.TrimPrefix(cfg.OgpImage, "/")
+		}
+	}
+
+	var description *texttemplate.Template
+	for _, t := range []struct {
+		name string
+		text string
+		dest **texttemplate.Template
+	}{
+		{"description", cfg.Description, &description},
+		{"day_description", cfg.DayDescription, &m.dayDescription},
+		{"month_description", cfg.MonthDescription, &m.monthDescription},
+		{"page_title", cfg.PageTitle, &m.pageTitle},
+	} {
+		parsed, err := texttemplate.New(t.name).Parse(t.text)
+		if err == nil {
+			// Catch unknown fields before rendering the pages
+			err = parsed.Execute(io.Discard, m.data("", ""))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in [site] section of nippo.toml: %w", t.name, err)
+		}
+		*t.dest = parsed
+	}
+	m.Description = m.render(description, "")
+	return m, nil
+}
+
+func (m *siteMeta) data(date, subject string) any {
+	return struct{ Title, Author, Date, Subject string }{m.Title, m.Author, date, subject}
+}
+
+// render executes t for the page of date, a day or month such as 2024-01-15
+func (m *siteMeta) render(t *texttemplate.Template, date string) string {
+	return m.renderSubject(t, date, date)
+}
+
+// renderSubject executes t for the page of date about subject, the title of
+// its nippo
+func (m *siteMeta) renderSubject(t *texttemplate.Template, date, subject string) string {
+	var buf strings.Builder
+	_ = t.Execute(&buf, m.data(date, subject))
+	return buf.String()
+}
+
 // page content
 type Content struct {
 	Url         string
@@ -191,7 +396,15 @@ type Content struct {
 	Description string
 	Date        string
 	Og          OpenGraph
-	Content     template.HTML
+	Site        SiteMeta
+	// Title and Summary are those of the nippo of the page, Title empty when
+	// it has none
+	Title   string
+	Summary string
+	Content template.HTML
+	// Terms are the category and tags of the nippo of the page, which the
+	// terms template links to
+	Terms []TermLink
 }
 
 type Archive struct {
@@ -200,197 +413,183 @@ type Archive struct {
 	Description string
 	Date        string
 	Og          OpenGraph
+	Site        SiteMeta
 	Calender    *model.Calender
 }
 
-func (u *buildCommandInteractor) buildIndexPage() error {
-	cacheDir := filepath.Join(core.Cfg.GetCacheDir(), "md")
+func (u *buildCommandInteractor) buildIndexPage(site *model.Site, meta *siteMeta) error {
 	outputDir := filepath.Join(core.Cfg.GetCacheDir(), "output")
 
-	nippoList, err := u.localNippoQuery.List(&repository.QueryListParam{
-		Folders: []string{cacheDir},
-	}, &repository.QueryListOption{})
-	if err != nil {
-		return err
-	}
-	nippo := nippoList[len(nippoList)-1]
-	nippoHtml, err := nippo.GetHtml()
-	if err != nil {
-		return err
+	if len(site.Days) == 0 {
+		return nil
 	}
+	day := site.Days[len(site.Days)-1]
 
-	siteUrl, err := getSiteUrl()
-	if err != nil {
-		return err
-	}
-	err = u.templateService.SaveTo(filepath.Join(outputDir, "index.html"), "index", Content{
-		Url:         siteUrl + "/",
-		Date:        nippo.Date.TitleString(),
-		Description: "ɯ̹t͡ɕʲi's daily reports.",
+	return u.templateService.SaveTo(filepath.Join(outputDir, "index.html"), "index", Content{
+		Url:         meta.Url + "/",
+		Date:        day.Date.TitleString(),
+		Description: meta.Description,
 		Og: OpenGraph{
-			Url:         siteUrl + "/",
-			Title:       "日報 - nippo.c18t.me",
-			Description: "ɯ̹t͡ɕʲi's daily reports.",
-			ImageUrl:    siteUrl + "/nippo_ogp.png",
+			Url:         meta.Url + "/",
+			Title:       meta.Title,
+			Description: meta.Description,
+			ImageUrl:    meta.ImageUrl,
 		},
-		Content: template.HTML(nippoHtml),
+		Site:    meta.SiteMeta,
+		Title:   day.Title(),
+		Summary: day.Summary(meta.excerptLength),
+		Content: template.HTML(day.Html()),
 	})
-	return err
 }
 
-func (u *buildCommandInteractor) buildNippoPage() error {
-	cacheDir := filepath.Join(core.Cfg.GetCacheDir(), "md")
+// nippoPages returns the renders of the pages in plan. Each day has a page with
+// all of its nippo, and the nippo with a suffix have their own page as well.
+func (u *buildCommandInteractor) nippoPages(site *model.Site, plan *model.BuildPlan, meta *siteMeta) []func() error {
 	outputDir := filepath.Join(core.Cfg.GetCacheDir(), "output")
 
-	nippoList, err := u.localNippoQuery.List(&repository.QueryListParam{
-		Folders: []string{cacheDir},
-	}, &repository.QueryListOption{
-		WithContent: true,
-	})
-	if err != nil {
-		return err
-	}
-	siteUrl, err := getSiteUrl()
-	if err != nil {
-		return err
-	}
-	for _, nippo := range nippoList {
-		nippoHtml, err := nippo.GetHtml()
-		if err != nil {
-			return err
+	var pages []func() error
+	for _, day := range site.Days {
+		if plan.Pages[day.Date.PathString()] {
+			pages = append(pages, func() error {
+				return u.saveNippoPage(outputDir, meta, day.Date, day.Html(), day.Terms(), day.Title(), day.Summary(meta.excerptLength))
+			})
 		}
-
-		nippoFile := fmt.Sprintf("%v.html", nippo.Date.PathString())
-		err = u.templateService.SaveTo(filepath.Join(outputDir, nippoFile), "nippo", Content{
-			Url:         siteUrl + "/" + nippo.Date.PathString(),
-			PageTitle:   nippo.Date.FileString(),
-			Description: "ɯ̹t͡ɕʲi's daily report for " + nippo.Date.FileString() + ".",
-			Date:        nippo.Date.TitleString(),
-			Og: OpenGraph{
-				Url:         siteUrl + "/" + nippo.Date.PathString(),
-				Title:       nippo.Date.FileString() + " / 日報 - nippo.c18t.me",
-				Description: "ɯ̹t͡ɕʲi's daily report for " + nippo.Date.FileString() + ".",
-				ImageUrl:    siteUrl + "/nippo_ogp.png",
-			},
-			Content: template.HTML(nippoHtml),
-		})
-		if err != nil {
-			return err
+		for _, entry := range day.Entries {
+			if entry.Date.Suffix() == "" || !plan.Pages[entry.Date.PathString()] {
+				continue
+			}
+			pages = append(pages, func() error {
+				return u.saveNippoPage(outputDir, meta, entry.Date, entry.Html(), entry.Terms, entry.Title(), entry.Summary(meta.excerptLength))
+			})
 		}
 	}
-	return nil
+	return pages
 }
 
-func (u *buildCommandInteractor) buildArchivePage() error {
-	cacheDir := filepath.Join(core.Cfg.GetCacheDir(), "md")
+// saveNippoPage renders the page of date, a day or a nippo with a suffix, with
+// links to terms. The page is titled title and described by summary when they
+// are set, and by the date and day_description otherwise.
+func (u *buildCommandInteractor) saveNippoPage(outputDir string, meta *siteMeta, date model.NippoDate, nippoHtml []byte, terms []*model.Term, title, summary string) error {
+	nippoFile := fmt.Sprintf("%v.html", date.PathString())
+	subject := cmp.Or(title, date.FileString())
+	description := cmp.Or(summary, meta.render(meta.dayDescription, date.FileString()))
+	return u.templateService.SaveTo(filepath.Join(outputDir, nippoFile), "nippo", Content{
+		Url:         meta.Url + "/" + date.PathString(),
+		PageTitle:   subject,
+		Description: description,
+		Date:        date.TitleString(),
+		Og: OpenGraph{
+			Url:         meta.Url + "/" + date.PathString(),
+			Title:       meta.renderSubject(meta.pageTitle, date.FileString(), subject),
+			Description: description,
+			ImageUrl:    meta.ImageUrl,
+		},
+		Site:    meta.SiteMeta,
+		Title:   title,
+		Summary: summary,
+		Content: template.HTML(nippoHtml),
+		Terms:   termLinks(terms),
+	})
+}
+
+// buildArchivePage renders the archive of key, a month such as 2024-01
+func (u *buildCommandInteractor) buildArchivePage(site *model.Site, key string, meta *siteMeta) error {
 	outputDir := filepath.Join(core.Cfg.GetCacheDir(), "output")
 
-	nippoList, err := u.localNippoQuery.List(&repository.QueryListParam{
-		Folders: []string{cacheDir},
-	}, &repository.QueryListOption{
-		WithContent: true,
-	})
+	month, err := model.NewCalenderYearMonth(key)
 	if err != nil {
 		return err
 	}
-	var monthMap = map[string]bool{}
-	for _, nippo := range nippoList {
-		month := nippo.Date.FileString()[:7]
-		monthMap[month] = true
-	}
 
-	siteUrl, err := getSiteUrl()
+	calender, err := site.Calender(month)
 	if err != nil {
 		return err
 	}
-	for key := range monthMap {
-		month, err := model.NewCalenderYearMonth(key)
-		if err != nil {
-			return err
-		}
 
-		calender, err := model.NewCalender(month, nippoList)
-		if err != nil {
-			return err
-		}
+	archiveFile := fmt.Sprintf("%04d%02d.html", calender.YearMonth.Year, calender.YearMonth.Month)
+	description := meta.render(meta.monthDescription, calender.YearMonth.FileString())
 
-		archiveFile := fmt.Sprintf("%04d%02d.html", calender.YearMonth.Year, calender.YearMonth.Month)
-
-		err = u.templateService.SaveTo(filepath.Join(outputDir, archiveFile), "calender", Archive{
-			Url:         siteUrl + "/" + calender.YearMonth.PathString(),
-			PageTitle:   calender.YearMonth.FileString(),
-			Description: "ɯ̹t͡ɕʲi's daily reports for " + calender.YearMonth.FileString() + ".",
-			Date:        calender.YearMonth.TitleString(),
-			Og: OpenGraph{
-				Url:         siteUrl + "/" + calender.YearMonth.PathString(),
-				Title:       calender.YearMonth.FileString() + " / 日報 - nippo.c18t.me",
-				Description: "ɯ̹t͡ɕʲi's daily reports for " + calender.YearMonth.FileString() + ".",
-				ImageUrl:    siteUrl + "/nippo_ogp.png",
-			},
-			Calender: calender,
-		})
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	return u.templateService.SaveTo(filepath.Join(outputDir, archiveFile), "calender", Archive{
+		Url:         meta.Url + "/" + calender.YearMonth.PathString(),
+		PageTitle:   calender.YearMonth.FileString(),
+		Description: description,
+		Date:        calender.YearMonth.TitleString(),
+		Og: OpenGraph{
+			Url:         meta.Url + "/" + calender.YearMonth.PathString(),
+			Title:       meta.render(meta.pageTitle, calender.YearMonth.FileString()),
+			Description: description,
+			ImageUrl:    meta.ImageUrl,
+		},
+		Site:     meta.SiteMeta,
+		Calender: calender,
+	})
 }
 
-func (u *buildCommandInteractor) buildFeed() error {
-	cacheDir := filepath.Join(core.Cfg.GetCacheDir(), "md")
+func (u *buildCommandInteractor) buildFeed(site *model.Site, meta *siteMeta) error {
 	outputDir := filepath.Join(core.Cfg.GetCacheDir(), "output")
+	return u.writeFeed(filepath.Join(outputDir, "feed.xml"), meta, feedChannel{
+		Title: meta.Title,
+		Link:  meta.Url,
+	}, site.Entries)
+}
 
-	siteUrl, err := getSiteUrl()
+// feedChannel is what a feed is about, the site or a term
+type feedChannel struct {
+	Title string
+	Link  string
+}
+
+// writeFeed writes the feed of the last feed_entries of entries to path, with
+// the category and tags of each entry as its categories
+func (u *buildCommandInteractor) writeFeed(path string, meta *siteMeta, channel feedChannel, entries []*model.SiteEntry) error {
+	loc, err := core.Cfg.Project.GetLocation()
 	if err != nil {
 		return err
 	}
-	author := &feeds.Author{Name: "ɯ̹t͡ɕʲi"}
+	var author *feeds.Author
+	if meta.Author != "" {
+		author = &feeds.Author{Name: meta.Author}
+	}
 
 	feed := &feeds.Feed{
-		Title:       "日報 - nippo.c18t.me",
-		Link:        &feeds.Link{Href: siteUrl},
-		Description: "ɯ̹t͡ɕʲi's daily reports.",
+		Title:       channel.Title,
+		Link:        &feeds.Link{Href: channel.Link},
+		Description: meta.Description,
 		Author:      author,
-		Created:     time.Now(),
-	}
-
-	nippoList, err := u.localNippoQuery.List(&repository.QueryListParam{
-		Folders: []string{cacheDir},
-	}, &repository.QueryListOption{})
-	if err != nil {
-		return err
+		Created:     time.Now().In(loc),
 	}
 
-	// Get the last 20 nippo entries (or all if less than 20)
-	startIdx := len(nippoList) - 20
+	// Get the last feed_entries nippo entries (or all if there are fewer)
+	startIdx := len(entries) - meta.feedEntries
 	if startIdx < 0 {
 		startIdx = 0
 	}
-	for _, nippo := range nippoList[startIdx:] {
-		nippoHtml, err := nippo.GetHtml()
-		if err != nil {
-			return err
-		}
-
+	// Items are sorted below, so their categories are found by ID
+	categories := map[string][]string{}
+	for _, nippo := range entries[startIdx:] {
 		// Use front-matter created time if available, fallback to filename-derived date
-		createdTime := nippo.GetCreatedTime()
+		createdTime := nippo.GetCreatedTime(loc)
 
+		date := nippo.Date.FileString()
 		item := &feeds.Item{
-			Title:       nippo.Date.FileString() + " / 日報 - nippo.c18t.me",
-			Link:        &feeds.Link{Href: siteUrl + "/" + nippo.Date.PathString()},
-			Id:          siteUrl + "/" + nippo.Date.PathString(),
-			Description: "ɯ̹t͡ɕʲi's daily report for " + nippo.Date.FileString() + ".",
+			Title:       meta.renderSubject(meta.pageTitle, date, cmp.Or(nippo.Title(), date)),
+			Link:        &feeds.Link{Href: meta.Url + "/" + nippo.Date.PathString()},
+			Id:          meta.Url + "/" + nippo.Date.PathString(),
+			Description: cmp.Or(nippo.Summary(meta.excerptLength), meta.render(meta.dayDescription, date)),
 			Author:      author,
 			Created:     createdTime,
-			Content:     string(nippoHtml),
+			Content:     string(nippo.Html()),
 		}
 
 		// Set updated time if available from front-matter
-		updatedTime := nippo.GetUpdatedTime()
+		updatedTime := nippo.GetUpdatedTime(loc)
 		if !updatedTime.IsZero() {
 			item.Updated = updatedTime
 		}
 
+		for _, term := range nippo.Terms {
+			categories[item.Id] = append(categories[item.Id], term.Name)
+		}
 		feed.Items = append(feed.Items, item)
 	}
 
@@ -398,39 +597,41 @@ func (u *buildCommandInteractor) buildFeed() error {
 		return i.Created.After(j.Created)
 	})
 
-	rss, err := feed.ToAtom()
+	var x feeds.XmlFeed
+	if meta.feedFormat == core.SiteFeedRSS {
+		x = newRssFeedXml(feed, meta.Language, categories)
+	} else {
+		x = newAtomFeedXml(feed, categories)
+	}
+	out, err := feeds.ToXML(x)
 	if err != nil {
 		return err
 	}
-	return u.fileProvider.Write(filepath.Join(outputDir, "feed.xml"), []byte(rss))
+	return u.fileProvider.Write(path, []byte(out))
 }
 
-func (u *buildCommandInteractor) buildSiteMap() error {
-	cacheDir := filepath.Join(core.Cfg.GetCacheDir(), "md")
+func (u *buildCommandInteractor) buildSiteMap(site *model.Site) error {
 	outputDir := filepath.Join(core.Cfg.GetCacheDir(), "output")
 
-	// Get nippo list to extract last modified times from front-matter
-	nippoList, err := u.localNippoQuery.List(&repository.QueryListParam{
-		Folders: []string{cacheDir},
-	}, &repository.QueryListOption{
-		WithContent: true,
-	})
+	loc, err := core.Cfg.Project.GetLocation()
 	if err != nil {
 		return err
 	}
 
 	// Build a map of pathString -> last modified time
 	lastModifiedMap := make(map[string]time.Time)
-	for _, nippo := range nippoList {
-		// GetMarkdown() parses front-matter, so call it to populate FrontMatter
-		_, _ = nippo.GetMarkdown()
-		pathStr := nippo.Date.PathString()
-		updatedTime := nippo.GetUpdatedTime()
-		if !updatedTime.IsZero() {
-			lastModifiedMap[pathStr] = updatedTime
-		} else {
-			// Fallback to created time
-			lastModifiedMap[pathStr] = nippo.GetCreatedTime()
+	for _, day := range site.Days {
+		for _, nippo := range day.Entries {
+			lastModified := nippo.GetUpdatedTime(loc)
+			if lastModified.IsZero() {
+				// Fallback to created time
+				lastModified = nippo.GetCreatedTime(loc)
+			}
+			lastModifiedMap[nippo.Date.PathString()] = lastModified
+			// The page of the day changes with any of its nippo
+			if dayPath := day.Date.PathString(); lastModified.After(lastModifiedMap[dayPath]) {
+				lastModifiedMap[dayPath] = lastModified
+			}
 		}
 	}
 
@@ -446,12 +647,23 @@ func (u *buildCommandInteractor) buildSiteMap() error {
 	if err != nil {
 		return err
 	}
-	now := time.Now()
+	now := time.Now().In(loc)
 	sitemaps := []sitemap.Sitemap{}
 
+	pagePaths := make([]string, 0, len(files))
+	for _, file := range files {
+		pagePaths = append(pagePaths, strings.TrimSuffix(file.Name(), ".html"))
+	}
+	// The pages of the terms are in folders, which files doesn't list
+	for _, path := range site.TermPaths() {
+		if !slices.Contains(pagePaths, path) {
+			pagePaths = append(pagePaths, path)
+		}
+	}
+
 	count := 0
 	data := sitemap.NewSitemap([]*sitemap.SitemapItem{}, nil)
-	for _, file := range files {
+	for _, fileName := range pagePaths {
 		count++
 		if count >= 10000 {
 			sitemaps = append(sitemaps, *data)
@@ -459,7 +671,6 @@ func (u *buildCommandInteractor) buildSiteMap() error {
 			count = 0
 		}
 
-		fileName := strings.TrimSuffix(file.Name(), ".html")
 		if fileName == "index" {
 			data.AddItem(siteUrl+"/", now, "daily", 0.5)
 		} else {
@@ -0,0 +1,217 @@
+package interactor
+
+import (
+	"encoding/xml"
+	"path/filepath"
+	"slices"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/domain/model"
+	"github.com/gorilla/feeds"
+)
+
+// TermLink is a tag or category on the page of its taxonomy or of a nippo
+type TermLink struct {
+	Name  string
+	Url   string
+	Count int
+}
+
+// EntryLink is a nippo on the page of a term
+type EntryLink struct {
+	Url     string
+	Date    string
+	Title   string
+	Summary string
+}
+
+// Taxonomy is the page listing the terms of a taxonomy, such as /tags
+type Taxonomy struct {
+	Url         string
+	PageTitle   string
+	Description string
+	// Date is the name of the taxonomy, the heading of the page like the
+	// date of the other pages
+	Date     string
+	Og       OpenGraph
+	Site     SiteMeta
+	Taxonomy string
+	Terms    []TermLink
+}
+
+// Term is the page listing the nippo of a term, such as /tags/go
+type Term struct {
+	Url         string
+	PageTitle   string
+	Description string
+	// Date is the name of the term
+	Date     string
+	Og       OpenGraph
+	Site     SiteMeta
+	Taxonomy string
+	Name     string
+	FeedUrl  string
+	// Entries are the nippo with the term, newest first
+	Entries []EntryLink
+}
+
+// taxonomyPages returns the renders of the pages and feeds of the tags and
+// categories, which are all rendered again on any change
+func (u *buildCommandInteractor) taxonomyPages(site *model.Site, meta *siteMeta) []func() error {
+	var pages []func() error
+	for _, taxonomy := range []*model.Taxonomy{site.Categories, site.Tags} {
+		if len(taxonomy.Terms) == 0 {
+			continue
+		}
+		pages = append(pages, func() error { return u.buildTaxonomyPage(taxonomy, meta) })
+		for _, term := range taxonomy.Terms {
+			pages = append(pages,
+				func() error { return u.buildTermPage(taxonomy, term, meta) },
+				func() error { return u.buildTermFeed(term, meta) },
+			)
+		}
+	}
+	return pages
+}
+
+// termLinks returns the links to the pages of terms
+func termLinks(terms []*model.Term) []TermLink {
+	links := make([]TermLink, 0, len(terms))
+	for _, term := range terms {
+		links = append(links, TermLink{Name: term.Name, Url: term.Url(), Count: len(term.Entries)})
+	}
+	return links
+}
+
+func (u *buildCommandInteractor) buildTaxonomyPage(taxonomy *model.Taxonomy, meta *siteMeta) error {
+	outputDir := filepath.Join(core.Cfg.GetCacheDir(), "output")
+
+	return u.templateService.SaveTo(filepath.Join(outputDir, taxonomy.Name+".html"), "taxonomy", Taxonomy{
+		Url:         meta.Url + "/" + taxonomy.Name,
+		PageTitle:   taxonomy.Name,
+		Description: meta.Description,
+		Date:        taxonomy.Name,
+		Og: OpenGraph{
+			Url:         meta.Url + "/" + taxonomy.Name,
+			Title:       meta.render(meta.pageTitle, taxonomy.Name),
+			Description: meta.Description,
+			ImageUrl:    meta.ImageUrl,
+		},
+		Site:     meta.SiteMeta,
+		Taxonomy: taxonomy.Name,
+		Terms:    termLinks(taxonomy.Terms),
+	})
+}
+
+func (u *buildCommandInteractor) buildTermPage(taxonomy *model.Taxonomy, term *model.Term, meta *siteMeta) error {
+	outputDir := filepath.Join(core.Cfg.GetCacheDir(), "output")
+
+	entries := make([]EntryLink, 0, len(term.Entries))
+	for _, entry := range slices.Backward(term.Entries) {
+		entries = append(entries, EntryLink{
+			Url:     "/" + entry.Date.PathString(),
+			Date:    entry.Date.FileString(),
+			Title:   entry.Title(),
+			Summary: entry.Summary(meta.excerptLength),
+		})
+	}
+	return u.templateService.SaveTo(filepath.Join(outputDir, filepath.FromSlash(term.Path)+".html"), "term", Term{
+		Url:         meta.Url + term.Url(),
+		PageTitle:   term.Name,
+		Description: meta.Description,
+		Date:        term.Name,
+		Og: OpenGraph{
+			Url:         meta.Url + term.Url(),
+			Title:       meta.render(meta.pageTitle, term.Name),
+			Description: meta.Description,
+			ImageUrl:    meta.ImageUrl,
+		},
+		Site:     meta.SiteMeta,
+		Taxonomy: taxonomy.Name,
+		Name:     term.Name,
+		FeedUrl:  term.Url() + ".xml",
+		Entries:  entries,
+	})
+}
+
+// buildTermFeed writes the feed of the latest nippo of term next to its page
+func (u *buildCommandInteractor) buildTermFeed(term *model.Term, meta *siteMeta) error {
+	outputDir := filepath.Join(core.Cfg.GetCacheDir(), "output")
+	return u.writeFeed(filepath.Join(outputDir, filepath.FromSlash(term.Path)+".xml"), meta, feedChannel{
+		Title: meta.render(meta.pageTitle, term.Name),
+		Link:  meta.Url + term.Url(),
+	}, term.Entries)
+}
+
+// atomFeedXml is an Atom feed whose entries have any number of categories,
+// which feeds writes one of at most
+type atomFeedXml struct {
+	*feeds.AtomFeed
+	Entries []*atomEntryXml `xml:"entry"`
+}
+
+type atomEntryXml struct {
+	*feeds.AtomEntry
+	Categories []atomCategoryXml `xml:"category"`
+}
+
+type atomCategoryXml struct {
+	Term string `xml:"term,attr"`
+}
+
+func newAtomFeedXml(feed *feeds.Feed, categories map[string][]string) *atomFeedXml {
+	atom := (&feeds.Atom{Feed: feed}).AtomFeed()
+	x := &atomFeedXml{AtomFeed: atom}
+	for _, entry := range atom.Entries {
+		e := &atomEntryXml{AtomEntry: entry}
+		for _, name := range categories[entry.Id] {
+			e.Categories = append(e.Categories, atomCategoryXml{Term: name})
+		}
+		x.Entries = append(x.Entries, e)
+	}
+	return x
+}
+
+func (f *atomFeedXml) FeedXml() interface{} {
+	return f
+}
+
+// rssFeedXml is an RSS feed whose items have any number of categories
+type rssFeedXml struct {
+	XMLName          xml.Name `xml:"rss"`
+	Version          string   `xml:"version,attr"`
+	ContentNamespace string   `xml:"xmlns:content,attr"`
+	Channel          *rssChannelXml
+}
+
+type rssChannelXml struct {
+	*feeds.RssFeed
+	Items []*rssItemXml `xml:"item"`
+}
+
+type rssItemXml struct {
+	*feeds.RssItem
+	Categories []string `xml:"category"`
+}
+
+func newRssFeedXml(feed *feeds.Feed, language string, categories map[string][]string) *rssFeedXml {
+	rss := (&feeds.Rss{Feed: feed}).RssFeed()
+	rss.Language = language
+	channel := &rssChannelXml{RssFeed: rss}
+	for _, item := range rss.Items {
+		i := &rssItemXml{RssItem: item}
+		if item.Guid != nil {
+			i.Categories = categories[item.Guid.Id]
+		}
+		channel.Items = append(channel.Items, i)
+	}
+	return &rssFeedXml{
+		Version:          "2.0",
+		ContentNamespace: "http://purl.org/rss/1.0/modules/content/",
+		Channel:          channel,
+	}
+}
+
+func (f *rssFeedXml) FeedXml() interface{} {
+	return f
+}
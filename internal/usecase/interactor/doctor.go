@@ -3,8 +3,11 @@ package interactor
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 
+	"github.com/c18t/nippo-cli/internal/adapter/gateway"
 	"github.com/c18t/nippo-cli/internal/adapter/presenter"
 	"github.com/c18t/nippo-cli/internal/core"
 	"github.com/c18t/nippo-cli/internal/usecase/port"
@@ -70,23 +73,8 @@ func (u *doctorInteractor) checkConfiguration(output *port.DoctorUseCaseOutputDa
 		Message:  configPath,
 	})
 
-	// Check drive folder
-	if core.Cfg.Project.DriveFolderId == "" {
-		output.Checks = append(output.Checks, port.DoctorCheck{
-			Category:   "Configuration",
-			Item:       "Drive folder",
-			Status:     port.DoctorCheckStatusWarn,
-			Message:    "Not configured",
-			Suggestion: "Run `nippo init` to configure",
-		})
-	} else {
-		output.Checks = append(output.Checks, port.DoctorCheck{
-			Category: "Configuration",
-			Item:     "Drive folder",
-			Status:   port.DoctorCheckStatusPass,
-			Message:  core.Cfg.Project.DriveFolderId,
-		})
-	}
+	// Check nippo source
+	u.checkSource(output)
 
 	// Check site URL
 	if core.Cfg.Project.SiteUrl == "" {
@@ -192,45 +180,120 @@ func (u *doctorInteractor) checkDirectories(output *port.DoctorUseCaseOutputData
 	}
 }
 
-func (u *doctorInteractor) checkRequiredFiles(output *port.DoctorUseCaseOutputData) {
-	dataDir := core.Cfg.GetDataDir()
+func (u *doctorInteractor) checkSource(output *port.DoctorUseCaseOutputData) {
+	if core.Cfg.Source.IsGit() {
+		u.checkGitSource(output)
+		return
+	}
+	if core.Cfg.Source.IsDrive() {
+		if core.Cfg.Project.DriveFolderId == "" {
+			output.Checks = append(output.Checks, port.DoctorCheck{
+				Category:   "Configuration",
+				Item:       "Drive folder",
+				Status:     port.DoctorCheckStatusWarn,
+				Message:    "Not configured",
+				Suggestion: "Run `nippo init` to configure",
+			})
+		} else {
+			output.Checks = append(output.Checks, port.DoctorCheck{
+				Category: "Configuration",
+				Item:     "Drive folder",
+				Status:   port.DoctorCheckStatusPass,
+				Message:  core.Cfg.Project.DriveFolderId,
+			})
+		}
+		return
+	}
 
-	// Check credentials.json
-	credPath := filepath.Join(dataDir, "credentials.json")
-	if _, err := os.Stat(credPath); os.IsNotExist(err) {
+	sourceFolder, err := core.Cfg.GetSourceFolder()
+	if err != nil {
 		output.Checks = append(output.Checks, port.DoctorCheck{
-			Category:   "Required Files",
-			Item:       "credentials.json",
+			Category:   "Configuration",
+			Item:       "Source folder",
 			Status:     port.DoctorCheckStatusFail,
-			Message:    "Not found: " + credPath,
-			Suggestion: "Download from Google Cloud Console: https://console.cloud.google.com/apis/credentials",
+			Message:    "Not configured",
+			Suggestion: "Set `path` in the [source] section of nippo.toml",
 		})
-	} else {
+		return
+	}
+	if info, err := os.Stat(sourceFolder); err != nil || !info.IsDir() {
 		output.Checks = append(output.Checks, port.DoctorCheck{
-			Category: "Required Files",
-			Item:     "credentials.json",
-			Status:   port.DoctorCheckStatusPass,
-			Message:  credPath,
+			Category:   "Configuration",
+			Item:       "Source folder",
+			Status:     port.DoctorCheckStatusFail,
+			Message:    "Not found: " + sourceFolder,
+			Suggestion: "Create the directory or fix `path` in the [source] section of nippo.toml",
 		})
+		return
 	}
+	output.Checks = append(output.Checks, port.DoctorCheck{
+		Category: "Configuration",
+		Item:     "Source folder",
+		Status:   port.DoctorCheckStatusPass,
+		Message:  sourceFolder,
+	})
+}
 
-	// Check token.json
-	tokenPath := filepath.Join(dataDir, "token.json")
-	if _, err := os.Stat(tokenPath); os.IsNotExist(err) {
+func (u *doctorInteractor) checkGitSource(output *port.DoctorUseCaseOutputData) {
+	if _, err := core.Cfg.GetSourceFolder(); err != nil {
 		output.Checks = append(output.Checks, port.DoctorCheck{
-			Category:   "Required Files",
-			Item:       "token.json",
+			Category:   "Configuration",
+			Item:       "Source repository",
 			Status:     port.DoctorCheckStatusFail,
-			Message:    "Not found: " + tokenPath,
-			Suggestion: "Run `nippo auth` to authenticate with Google Drive",
+			Message:    err.Error(),
+			Suggestion: "Set `path` or `url`, and `dir`, in the [source] section of nippo.toml",
 		})
-	} else {
+		return
+	}
+	if _, err := exec.LookPath("git"); err != nil {
 		output.Checks = append(output.Checks, port.DoctorCheck{
-			Category: "Required Files",
-			Item:     "token.json",
+			Category:   "Configuration",
+			Item:       "Source repository",
+			Status:     port.DoctorCheckStatusFail,
+			Message:    "git command not found",
+			Suggestion: "Install git to read nippo from a git repository",
+		})
+		return
+	}
+
+	repository := core.Cfg.GetSourceRepository()
+	if core.Cfg.Source.Url != "" {
+		message := core.Cfg.Source.Url
+		if _, err := os.Stat(filepath.Join(repository, ".git")); os.IsNotExist(err) {
+			message += " (will be cloned on build)"
+		}
+		output.Checks = append(output.Checks, port.DoctorCheck{
+			Category: "Configuration",
+			Item:     "Source repository",
 			Status:   port.DoctorCheckStatusPass,
-			Message:  tokenPath,
+			Message:  message,
+		})
+		return
+	}
+	if !core.IsUnderGitRepo(repository) {
+		output.Checks = append(output.Checks, port.DoctorCheck{
+			Category:   "Configuration",
+			Item:       "Source repository",
+			Status:     port.DoctorCheckStatusFail,
+			Message:    "Not a git repository: " + repository,
+			Suggestion: "Fix `path` in the [source] section of nippo.toml",
 		})
+		return
+	}
+	output.Checks = append(output.Checks, port.DoctorCheck{
+		Category: "Configuration",
+		Item:     "Source repository",
+		Status:   port.DoctorCheckStatusPass,
+		Message:  repository,
+	})
+}
+
+func (u *doctorInteractor) checkRequiredFiles(output *port.DoctorUseCaseOutputData) {
+	dataDir := core.Cfg.GetDataDir()
+
+	// Google credentials are only needed to read nippo from Google Drive
+	if core.Cfg.Source.IsDrive() {
+		u.checkAuth(output, dataDir)
 	}
 
 	// Check templates directory
@@ -355,6 +418,137 @@ func (u *doctorInteractor) checkSecurity(output *port.DoctorUseCaseOutputData) {
 	}
 }
 
+// checkAuth reports the active auth mode and checks the credentials it needs
+func (u *doctorInteractor) checkAuth(output *port.DoctorUseCaseOutputData, dataDir string) {
+	mode := gateway.CurrentAuthMode()
+	output.Checks = append(output.Checks, port.DoctorCheck{
+		Category: "Required Files",
+		Item:     "Auth mode",
+		Status:   port.DoctorCheckStatusPass,
+		Message:  string(mode),
+	})
+
+	if mode != gateway.AuthModeServiceAccount {
+		u.checkCredentialFiles(output, dataDir)
+		u.checkTokenScopes(output, dataDir)
+		return
+	}
+
+	keyPath := core.Cfg.GetServiceAccountKeyPath()
+	config, err := gateway.ReadServiceAccountKey(keyPath, gateway.DriveReadOnly)
+	if err != nil {
+		output.Checks = append(output.Checks, port.DoctorCheck{
+			Category:   "Required Files",
+			Item:       "Service account key",
+			Status:     port.DoctorCheckStatusFail,
+			Message:    "Invalid: " + keyPath,
+			Suggestion: "Download a JSON key of the service account from Google Cloud Console and set `service_account_key` in the [auth] section",
+		})
+		return
+	}
+	output.Checks = append(output.Checks, port.DoctorCheck{
+		Category: "Required Files",
+		Item:     "Service account key",
+		Status:   port.DoctorCheckStatusPass,
+		Message:  keyPath + " (" + config.Email + ")",
+	})
+}
+
+// checkTokenScopes warns about a saved token that grants more than nippo needs:
+// read-only access to Drive, and write access for format only
+func (u *doctorInteractor) checkTokenScopes(output *port.DoctorUseCaseOutputData, dataDir string) {
+	tok, err := gateway.TokenFromFile(dataDir, gateway.TokenPath(dataDir))
+	if err != nil {
+		// A missing or unreadable token is reported by checkCredentialFiles
+		return
+	}
+	const reauth = "Run `nippo auth logout` and then `nippo auth` to grant read-only access"
+
+	scopes := gateway.TokenScopes(tok)
+	if scopes == nil {
+		output.Checks = append(output.Checks, port.DoctorCheck{
+			Category:   "Required Files",
+			Item:       "Token scopes",
+			Status:     port.DoctorCheckStatusWarn,
+			Message:    "Unknown, the token was saved by an older version with full access to Google Drive",
+			Suggestion: reauth,
+		})
+		return
+	}
+
+	var extra []string
+	for _, scope := range scopes {
+		if scope != gateway.DriveReadOnly.Scope() && scope != gateway.DriveReadWrite.Scope() {
+			extra = append(extra, scope)
+		}
+	}
+	switch {
+	case len(extra) > 0:
+		output.Checks = append(output.Checks, port.DoctorCheck{
+			Category:   "Required Files",
+			Item:       "Token scopes",
+			Status:     port.DoctorCheckStatusWarn,
+			Message:    "Scopes nippo does not use: " + strings.Join(extra, " "),
+			Suggestion: reauth,
+		})
+	case gateway.DriveReadWrite.GrantedBy(scopes):
+		output.Checks = append(output.Checks, port.DoctorCheck{
+			Category:   "Required Files",
+			Item:       "Token scopes",
+			Status:     port.DoctorCheckStatusWarn,
+			Message:    "Full access to Google Drive",
+			Suggestion: "Only `nippo format` needs write access. If you don't use it, run `nippo auth logout` and then `nippo auth`",
+		})
+	default:
+		output.Checks = append(output.Checks, port.DoctorCheck{
+			Category: "Required Files",
+			Item:     "Token scopes",
+			Status:   port.DoctorCheckStatusPass,
+			Message:  "Read-only access to Google Drive",
+		})
+	}
+}
+
+func (u *doctorInteractor) checkCredentialFiles(output *port.DoctorUseCaseOutputData, dataDir string) {
+	// Check credentials.json
+	credPath := filepath.Join(dataDir, "credentials.json")
+	if _, err := os.Stat(credPath); os.IsNotExist(err) {
+		output.Checks = append(output.Checks, port.DoctorCheck{
+			Category:   "Required Files",
+			Item:       "credentials.json",
+			Status:     port.DoctorCheckStatusFail,
+			Message:    "Not found: " + credPath,
+			Suggestion: "Download from Google Cloud Console: https://console.cloud.google.com/apis/credentials",
+		})
+	} else {
+		output.Checks = append(output.Checks, port.DoctorCheck{
+			Category: "Required Files",
+			Item:     "credentials.json",
+			Status:   port.DoctorCheckStatusPass,
+			Message:  credPath,
+		})
+	}
+
+	// Check token.json
+	tokenPath := filepath.Join(dataDir, "token.json")
+	if _, err := os.Stat(tokenPath); os.IsNotExist(err) {
+		output.Checks = append(output.Checks, port.DoctorCheck{
+			Category:   "Required Files",
+			Item:       "token.json",
+			Status:     port.DoctorCheckStatusFail,
+			Message:    "Not found: " + tokenPath,
+			Suggestion: "Run `nippo auth` to authenticate with Google Drive",
+		})
+	} else {
+		output.Checks = append(output.Checks, port.DoctorCheck{
+			Category: "Required Files",
+			Item:     "token.json",
+			Status:   port.DoctorCheckStatusPass,
+			Message:  tokenPath,
+		})
+	}
+}
+
 func itoa(i int) string {
 	return fmt.Sprintf("%d", i)
 }
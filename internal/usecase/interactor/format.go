@@ -1,10 +1,12 @@
 package interactor
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/c18t/nippo-cli/internal/adapter/gateway"
 	"github.com/c18t/nippo-cli/internal/adapter/presenter"
 	"github.com/c18t/nippo-cli/internal/core"
 	"github.com/c18t/nippo-cli/internal/domain/model"
@@ -14,8 +16,11 @@ import (
 )
 
 type formatCommandInteractor struct {
-	remoteNippoQuery repository.RemoteNippoQuery     `do:""`
-	presenter        presenter.FormatCommandPresenter `do:""`
+	remoteNippoQuery  repository.RemoteNippoQuery       `do:""`
+	backupRepository  repository.FormatBackupRepository `do:""`
+	presenter         presenter.FormatCommandPresenter  `do:""`
+	planPresenter     presenter.FormatPlanPresenter
+	planJSONPresenter presenter.FormatPlanPresenter
 }
 
 func NewFormatCommandInteractor(i do.Injector) (port.FormatCommandUseCase, error) {
@@ -23,19 +28,54 @@ func NewFormatCommandInteractor(i do.Injector) (port.FormatCommandUseCase, error
 	if err != nil {
 		return nil, err
 	}
+	backupRepository, err := do.Invoke[repository.FormatBackupRepository](i)
+	if err != nil {
+		return nil, err
+	}
 	p, err := do.Invoke[presenter.FormatCommandPresenter](i)
 	if err != nil {
 		return nil, err
 	}
+	pp, err := do.Invoke[presenter.FormatPlanPresenter](i)
+	if err != nil {
+		return nil, err
+	}
+	jp, err := do.InvokeNamed[presenter.FormatPlanPresenter](i, presenter.FormatPlanJSONPresenterName)
+	if err != nil {
+		return nil, err
+	}
+	driveFileProvider, err := do.Invoke[gateway.DriveFileProvider](i)
+	if err != nil {
+		return nil, err
+	}
+	// Format writes the fixed front-matter back to Drive
+	driveFileProvider.RequireAccess(gateway.DriveReadWrite)
+	driveFileProvider.OnRetry(func(event gateway.RetryEvent) {
+		p.Warn(event.String())
+	})
 	return &formatCommandInteractor{
-		remoteNippoQuery: remoteNippoQuery,
-		presenter:        p,
+		remoteNippoQuery:  remoteNippoQuery,
+		backupRepository:  backupRepository,
+		presenter:         p,
+		planPresenter:     pp,
+		planJSONPresenter: jp,
 	}, nil
 }
 
 func (u *formatCommandInteractor) Handle(input *port.FormatCommandUseCaseInputData) {
+	if input.DryRun {
+		u.plan(input)
+		return
+	}
+
+	rules, err := formatRules()
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+
 	// Show progress while fetching file list
-	u.presenter.Progress(&port.FormatCommandUseCaseOutputData{Message: "Fetching file list from Google Drive..."})
+	u.presenter.Progress(&port.FormatCommandUseCaseOutputData{Message: "Fetching file list from " + core.Cfg.Source.DisplayName() + "..."})
 
 	// Fetch files updated since last format timestamp
 	nippoList, err := u.fetchFiles()
@@ -51,9 +91,21 @@ func (u *formatCommandInteractor) Handle(input *port.FormatCommandUseCaseInputDa
 		return
 	}
 
+	// The originals of the files changed by this run are backed up under its ID.
+	// The git source keeps them in its history instead.
+	var run *model.FormatRun
+	if !core.Cfg.Source.IsGit() {
+		sourceFolder, err := core.Cfg.GetSourceFolder()
+		if err != nil {
+			u.presenter.Suspend(err)
+			return
+		}
+		run = model.NewFormatRun(time.Now(), core.Cfg.Source.GetType(), sourceFolder)
+	}
+
 	// Track results
-	var successCount, noChangeCount, failedCount int
-	var updatedFiles, failedFiles []presenter.FileInfo
+	var successCount, noChangeCount, failedCount, conflictCount int
+	var updatedFiles, failedFiles, conflictFiles []presenter.FileInfo
 	hasFailure := false
 
 	// Start format progress TUI
@@ -66,7 +118,7 @@ func (u *formatCommandInteractor) Handle(input *port.FormatCommandUseCaseInputDa
 			break
 		}
 
-		result := u.processFile(&nippoList[i])
+		result := u.processFile(&nippoList[i], rules, run)
 		u.presenter.UpdateFormatProgress(result)
 
 		switch result.Status {
@@ -79,6 +131,11 @@ func (u *formatCommandInteractor) Handle(input *port.FormatCommandUseCaseInputDa
 			failedCount++
 			failedFiles = append(failedFiles, presenter.FileInfo{Name: result.Filename, Id: result.FileId})
 			hasFailure = true
+		case port.FormatFileStatusConflict:
+			// The file is formatted on the next run, as it is modified after the timestamp
+			conflictCount++
+			conflictFiles = append(conflictFiles, presenter.FileInfo{Name: result.Filename, Id: result.FileId})
+			hasFailure = true
 		}
 	}
 
@@ -86,9 +143,21 @@ func (u *formatCommandInteractor) Handle(input *port.FormatCommandUseCaseInputDa
 	u.presenter.StopFormatProgress()
 
 	// Show summary
-	u.presenter.Summary(successCount, noChangeCount, failedCount, updatedFiles, failedFiles)
+	u.presenter.Summary(successCount, noChangeCount, failedCount, conflictCount, updatedFiles, failedFiles, conflictFiles)
+	if run != nil && len(run.Files) > 0 {
+		u.presenter.Complete(&port.FormatCommandUseCaseOutputData{
+			Message: fmt.Sprintf("Backed up the original files as run %s. Run `nippo format revert %s` to undo.", run.Id, run.Id),
+		})
+	}
+
+	if successCount > 0 {
+		if err := u.remoteNippoQuery.Flush(); err != nil {
+			u.presenter.Suspend(fmt.Errorf("unable to publish the formatted files: %w", err))
+			return
+		}
+	}
 
-	// Only update timestamp if no failures
+	// Only update timestamp if no failures or conflicts
 	if !hasFailure {
 		core.Cfg.LastFormatTimestamp = time.Now()
 		if err := core.Cfg.SaveConfig(); err != nil {
@@ -98,16 +167,50 @@ func (u *formatCommandInteractor) Handle(input *port.FormatCommandUseCaseInputDa
 	}
 }
 
+// plan shows what a format run would change without uploading anything.
+// LastFormatTimestamp is left as is, so the real run processes the same files.
+func (u *formatCommandInteractor) plan(input *port.FormatCommandUseCaseInputData) {
+	p := u.planPresenter
+	if input.JSON {
+		p = u.planJSONPresenter
+	}
+
+	rules, err := formatRules()
+	if err != nil {
+		p.Suspend(err)
+		return
+	}
+
+	p.Progress(&port.FormatCommandUseCaseOutputData{Message: "Fetching file list from " + core.Cfg.Source.DisplayName() + "..."})
+	nippoList, err := u.fetchFiles()
+	if err != nil {
+		p.Suspend(err)
+		return
+	}
+	p.StopProgress()
+
+	results := make([]*port.FormatCommandUseCaseOutputData, 0, len(nippoList))
+	for i := range nippoList {
+		result, newContent := u.planFile(&nippoList[i], rules)
+		if result.Status == port.FormatFileStatusSuccess {
+			result.Diff = model.UnifiedDiff(result.Filename, nippoList[i].Content, newContent)
+		}
+		p.Plan(result)
+		results = append(results, result)
+	}
+	p.Summary(results)
+}
+
 func (u *formatCommandInteractor) fetchFiles() ([]model.Nippo, error) {
-	// Use configured drive folder ID
-	driveFolderId := core.Cfg.Project.DriveFolderId
-	if driveFolderId == "" {
-		return nil, fmt.Errorf("drive folder ID is not configured. Run `nippo init` to configure")
+	// Use the configured drive folder ID or local source directory
+	sourceFolder, err := core.Cfg.GetSourceFolder()
+	if err != nil {
+		return nil, err
 	}
 
 	// Build query parameters
 	param := &repository.QueryListParam{
-		Folders:        []string{driveFolderId},
+		Folders:        []string{sourceFolder},
 		FileExtensions: []string{"md"},
 		OrderBy:        "name",
 	}
@@ -129,125 +232,97 @@ func (u *formatCommandInteractor) fetchFiles() ([]model.Nippo, error) {
 	return nippoList, nil
 }
 
-func (u *formatCommandInteractor) processFile(nippo *model.Nippo) *port.FormatCommandUseCaseOutputData {
-	result := &port.FormatCommandUseCaseOutputData{
-		Filename: nippo.RemoteFile.Name,
-		FileId:   nippo.RemoteFile.Id,
-	}
-
-	// Check if file needs processing
-	needsUpdate, reason, parseErr := u.needsUpdate(nippo.Content)
-	if parseErr != nil {
-		// Malformed front-matter - log error and skip
-		result.Status = port.FormatFileStatusFailed
-		result.Error = parseErr
-		result.Message = "malformed front-matter: " + parseErr.Error()
+func (u *formatCommandInteractor) processFile(nippo *model.Nippo, rules []model.FormatRule, run *model.FormatRun) *port.FormatCommandUseCaseOutputData {
+	result, newContent := u.planFile(nippo, rules)
+	if result.Status != port.FormatFileStatusSuccess {
 		return result
 	}
-	if !needsUpdate {
-		result.Status = port.FormatFileStatusNoChange
-		result.Message = "No changes needed"
-		return result
+
+	// Back up the original before it is overwritten, so the run can be reverted
+	var file model.FormatRunFile
+	if run != nil {
+		file = run.NextFile(nippo.RemoteFile, result.Reasons)
+		if err := u.backupRepository.Backup(run, &file, nippo.Content); err != nil {
+			result.Status = port.FormatFileStatusFailed
+			result.Error = err
+			result.Message = err.Error()
+			return result
+		}
 	}
 
-	// Process the file based on what's needed
-	newContent, err := u.updateContent(nippo)
-	if err != nil {
+	// Upload to Drive, unless someone edited the file since it was downloaded
+	if err := u.remoteNippoQuery.Update(nippo, newContent); err != nil {
+		if run != nil {
+			err = errors.Join(err, u.backupRepository.Discard(run, &file))
+		}
 		result.Status = port.FormatFileStatusFailed
+		if errors.Is(err, repository.ErrConflict) {
+			result.Status = port.FormatFileStatusConflict
+		}
 		result.Error = err
 		result.Message = err.Error()
 		return result
 	}
 
-	// Check if content actually changed
-	if bytes.Equal(nippo.Content, newContent) {
-		result.Status = port.FormatFileStatusNoChange
-		result.Message = "No changes needed"
+	if run == nil {
 		return result
 	}
-
-	// Upload to Drive
-	if err := u.remoteNippoQuery.Update(nippo, newContent); err != nil {
+	file.FormattedMd5 = model.ContentMd5(newContent)
+	run.Files = append(run.Files, file)
+	if err := u.backupRepository.Save(run); err != nil {
+		err = fmt.Errorf("updated, but unable to record the backup: %w", err)
 		result.Status = port.FormatFileStatusFailed
 		result.Error = err
 		result.Message = err.Error()
-		return result
 	}
-
-	result.Status = port.FormatFileStatusSuccess
-	result.Message = reason
 	return result
 }
 
-func (u *formatCommandInteractor) needsUpdate(content []byte) (bool, string, error) {
-	// Check if file has front-matter
-	if !model.HasFrontMatter(content) {
-		return true, "Added front-matter", nil
+// planFile applies the format rules to the content of nippo without uploading it.
+// A successful result means the file needs the returned content.
+func (u *formatCommandInteractor) planFile(nippo *model.Nippo, rules []model.FormatRule) (*port.FormatCommandUseCaseOutputData, []byte) {
+	result := &port.FormatCommandUseCaseOutputData{
+		Filename: nippo.RemoteFile.Name,
+		FileId:   nippo.RemoteFile.Id,
 	}
 
-	// Parse front-matter to check for missing created or now placeholder
-	fm, _, err := model.ParseFrontMatter(content)
+	newContent, reasons, err := model.ApplyFormatRules(rules, nippo)
 	if err != nil {
-		// Malformed front-matter - return error to log it
-		return false, "", err
-	}
-
-	// Check if created field is missing
-	if fm != nil && fm.Created.IsZero() {
-		if _, hasCreated := fm.Raw["created"]; !hasCreated {
-			return true, "Added created field", nil
-		}
+		// Malformed front-matter - log error and skip
+		result.Status = port.FormatFileStatusFailed
+		result.Error = err
+		result.Message = err.Error()
+		return result, nil
 	}
-
-	// Check for "now" placeholder
-	if model.HasNowPlaceholder(content) {
-		return true, "Replaced updated: now", nil
+	if len(reasons) == 0 {
+		result.Status = port.FormatFileStatusNoChange
+		result.Message = "No changes needed"
+		return result, nil
 	}
 
-	return false, "", nil
+	result.Status = port.FormatFileStatusSuccess
+	result.Reasons = reasons
+	result.Message = strings.Join(reasons, ", ")
+	return result, newContent
 }
 
-func (u *formatCommandInteractor) updateContent(nippo *model.Nippo) ([]byte, error) {
-	content := nippo.Content
-
-	// Get timestamps from RemoteFile
-	createdTime, _ := time.Parse(time.RFC3339, nippo.RemoteFile.CreatedTime)
-	modifiedTime, _ := time.Parse(time.RFC3339, nippo.RemoteFile.ModifiedTime)
-
-	// If no front-matter, generate new one
-	if !model.HasFrontMatter(content) {
-		created := createdTime.Local()
-		newFM := model.GenerateFrontMatter(created)
-		return append([]byte(newFM), content...), nil
-	}
-
-	// Parse existing front-matter
-	fm, _, err := model.ParseFrontMatter(content)
+// formatRules creates the format rules configured in nippo.toml
+func formatRules() ([]model.FormatRule, error) {
+	loc, err := core.Cfg.Project.GetLocation()
 	if err != nil {
 		return nil, err
 	}
-
-	// Determine what to update
-	var created, updated time.Time
-	var replaceNow bool
-
-	// Add created if missing
-	if fm != nil {
-		if _, hasCreated := fm.Raw["created"]; !hasCreated {
-			created = createdTime.Local()
-		}
-	}
-
-	// Replace "now" placeholder with modifiedTime
-	if model.HasNowPlaceholder(content) {
-		updated = modifiedTime.Local()
-		replaceNow = true
+	frontMatterFormat := model.FrontMatterFormat(core.Cfg.Format.FrontMatterFormat)
+	if frontMatterFormat == "keep" {
+		frontMatterFormat = ""
 	}
-
-	// If nothing to update, return original content
-	if created.IsZero() && !replaceNow {
-		return content, nil
+	rules, err := model.NewFormatRules(core.Cfg.Format.Rules, model.FormatRuleOptions{
+		HeadingTopLevel:   core.Cfg.Format.HeadingTopLevel,
+		Location:          loc,
+		FrontMatterFormat: frontMatterFormat,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid [format] section of nippo.toml: %w", err)
 	}
-
-	return model.UpdateFrontMatter(content, created, updated, replaceNow)
+	return rules, nil
 }
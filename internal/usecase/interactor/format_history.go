@@ -0,0 +1,49 @@
+package interactor
+
+import (
+	"github.com/c18t/nippo-cli/internal/adapter/presenter"
+	"github.com/c18t/nippo-cli/internal/domain/repository"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+)
+
+type formatHistoryInteractor struct {
+	backupRepository repository.FormatBackupRepository
+	presenter        presenter.FormatHistoryPresenter
+}
+
+func NewFormatHistoryInteractor(i do.Injector) (port.FormatHistoryUseCase, error) {
+	backupRepository, err := do.Invoke[repository.FormatBackupRepository](i)
+	if err != nil {
+		return nil, err
+	}
+	p, err := do.Invoke[presenter.FormatHistoryPresenter](i)
+	if err != nil {
+		return nil, err
+	}
+	return &formatHistoryInteractor{
+		backupRepository: backupRepository,
+		presenter:        p,
+	}, nil
+}
+
+func (u *formatHistoryInteractor) Handle(input *port.FormatHistoryUseCaseInputData) {
+	runs, err := u.backupRepository.List()
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+
+	output := &port.FormatHistoryUseCaseOutputData{}
+	for _, run := range runs {
+		r := port.FormatHistoryRun{Id: run.Id, StartedAt: run.StartedAt}
+		if run.IsReverted() {
+			r.RevertedAt = *run.RevertedAt
+		}
+		for _, file := range run.Files {
+			r.Files = append(r.Files, port.FormatHistoryFile{Name: file.Name, Id: file.Id, Reasons: file.Reasons})
+		}
+		output.Runs = append(output.Runs, r)
+	}
+	u.presenter.Show(output)
+}
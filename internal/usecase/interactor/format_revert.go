@@ -0,0 +1,173 @@
+package interactor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/c18t/nippo-cli/internal/adapter/gateway"
+	"github.com/c18t/nippo-cli/internal/adapter/presenter"
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/domain/model"
+	"github.com/c18t/nippo-cli/internal/domain/repository"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+)
+
+type formatRevertInteractor struct {
+	remoteNippoQuery repository.RemoteNippoQuery
+	backupRepository repository.FormatBackupRepository
+	presenter        presenter.FormatRevertPresenter
+}
+
+func NewFormatRevertInteractor(i do.Injector) (port.FormatRevertUseCase, error) {
+	remoteNippoQuery, err := do.Invoke[repository.RemoteNippoQuery](i)
+	if err != nil {
+		return nil, err
+	}
+	backupRepository, err := do.Invoke[repository.FormatBackupRepository](i)
+	if err != nil {
+		return nil, err
+	}
+	p, err := do.Invoke[presenter.FormatRevertPresenter](i)
+	if err != nil {
+		return nil, err
+	}
+	driveFileProvider, err := do.Invoke[gateway.DriveFileProvider](i)
+	if err != nil {
+		return nil, err
+	}
+	// Revert writes the backed up files back to Drive
+	driveFileProvider.RequireAccess(gateway.DriveReadWrite)
+	return &formatRevertInteractor{
+		remoteNippoQuery: remoteNippoQuery,
+		backupRepository: backupRepository,
+		presenter:        p,
+	}, nil
+}
+
+func (u *formatRevertInteractor) Handle(input *port.FormatRevertUseCaseInputData) {
+	if core.Cfg.Source.IsGit() {
+		u.presenter.Suspend(fmt.Errorf("format commits each file to the git source. Undo them with `git revert` instead"))
+		return
+	}
+
+	run, err := u.loadRun(input.RunId)
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+	if run.SourceType != core.Cfg.Source.GetType() {
+		u.presenter.Suspend(fmt.Errorf("run %s formatted the %s source, but the source is now %s", run.Id, run.SourceType, core.Cfg.Source.GetType()))
+		return
+	}
+
+	output := &port.FormatRevertUseCaseOutputData{RunId: run.Id}
+	output.Message = "Listing nippo on " + core.Cfg.Source.DisplayName() + "..."
+	u.presenter.Progress(output)
+	nippoList, err := u.remoteNippoQuery.List(&repository.QueryListParam{
+		Folders:        []string{run.Folder},
+		FileExtensions: []string{"md"},
+	}, &repository.QueryListOption{Recursive: true})
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+	current := make(map[string]model.Nippo, len(nippoList))
+	for _, nippo := range nippoList {
+		if nippo.RemoteFile != nil {
+			current[nippo.RemoteFile.Id] = nippo
+		}
+	}
+
+	output.Message = "Reverting format run " + run.Id + "..."
+	u.presenter.Progress(output)
+	restored, failed := 0, 0
+	for i := range run.Files {
+		result := u.revertFile(run, &run.Files[i], current)
+		switch result.Action {
+		case port.FormatRevertRestored:
+			restored++
+		case port.FormatRevertFailed:
+			failed++
+		}
+		output.Files = append(output.Files, result)
+	}
+
+	// A run with files that failed to revert can be reverted again
+	if failed == 0 {
+		now := time.Now()
+		run.RevertedAt = &now
+		if err := u.backupRepository.Save(run); err != nil {
+			u.presenter.Suspend(err)
+			return
+		}
+	}
+
+	output.Message = fmt.Sprintf("Reverted format run %s: %d restored, %d skipped, %d failed", run.Id, restored, len(run.Files)-restored-failed, failed)
+	u.presenter.Complete(output)
+}
+
+// loadRun loads the run of id, or the latest run not reverted yet when id is empty
+func (u *formatRevertInteractor) loadRun(id string) (*model.FormatRun, error) {
+	if id != "" {
+		return u.backupRepository.Load(id)
+	}
+	runs, err := u.backupRepository.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range runs {
+		if !runs[i].IsReverted() {
+			return &runs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no format run to revert. Run `nippo format history` to see past runs")
+}
+
+// revertFile restores the original content of file, unless it has been edited
+// since the format run
+func (u *formatRevertInteractor) revertFile(run *model.FormatRun, file *model.FormatRunFile, current map[string]model.Nippo) port.FormatRevertFileResult {
+	result := port.FormatRevertFileResult{Name: file.Name, Id: file.Id}
+	failed := func(err error) port.FormatRevertFileResult {
+		result.Action = port.FormatRevertFailed
+		result.Detail = err.Error()
+		return result
+	}
+
+	nippo, ok := current[file.Id]
+	if !ok {
+		result.Action = port.FormatRevertSkipped
+		result.Detail = "no longer exists"
+		return result
+	}
+	if err := u.remoteNippoQuery.Download(&nippo); err != nil {
+		return failed(err)
+	}
+	original, err := u.backupRepository.ReadBackup(run, file)
+	if err != nil {
+		return failed(err)
+	}
+	switch model.ContentMd5(nippo.Content) {
+	case model.ContentMd5(original):
+		result.Action = port.FormatRevertRestored
+		result.Detail = "already restored"
+		return result
+	case file.FormattedMd5:
+	default:
+		result.Action = port.FormatRevertSkipped
+		result.Detail = "edited since the format run"
+		return result
+	}
+
+	if err := u.remoteNippoQuery.Update(&nippo, original); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			result.Action = port.FormatRevertSkipped
+			result.Detail = "edited while reverting"
+			return result
+		}
+		return failed(err)
+	}
+	result.Action = port.FormatRevertRestored
+	return result
+}
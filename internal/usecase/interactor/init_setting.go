@@ -120,6 +120,7 @@ func (u *initSettingInteractor) Handle(input *port.InitSettingUseCaseInputData)
 func (u *initSettingInteractor) configureProject(input *port.InitSettingUseCaseInputData, output *port.InitSettingUseCaseOutputData, configExists bool) error {
 	// Load existing config values for defaults if config exists
 	var existingDriveFolder, existingSiteUrl, existingUrl, existingBranch, existingTemplatePath, existingAssetPath string
+	var existingSite core.ConfigSite
 	if configExists {
 		existingDriveFolder = core.Cfg.Project.DriveFolderId
 		existingSiteUrl = core.Cfg.Project.SiteUrl
@@ -127,6 +128,7 @@ func (u *initSettingInteractor) configureProject(input *port.InitSettingUseCaseI
 		existingBranch = core.Cfg.Project.Branch
 		existingTemplatePath = core.Cfg.Project.TemplatePath
 		existingAssetPath = core.Cfg.Project.AssetPath
+		existingSite = core.Cfg.Site
 	}
 
 	// Drive folder input
@@ -238,6 +240,74 @@ func (u *initSettingInteractor) configureProject(input *port.InitSettingUseCaseI
 		output.Project.AssetPath = port.InitSettingProjectAssetPath(ret)
 	}
 
+	// Site title input
+	output.Input = port.InitSettingSiteTitle("")
+	siteTitleCh := make(chan interface{})
+	go u.presenter.Prompt(siteTitleCh, output)
+	switch ret := (<-siteTitleCh).(type) {
+	case error:
+		return ret
+	case string:
+		if ret == "" {
+			if existingSite.Title != "" {
+				ret = existingSite.Title
+			} else {
+				ret = core.DefaultSiteTitle
+			}
+		}
+		output.Site.Title = port.InitSettingSiteTitle(ret)
+	}
+
+	// Author input, left out of the site when empty
+	output.Input = port.InitSettingSiteAuthor("")
+	siteAuthorCh := make(chan interface{})
+	go u.presenter.Prompt(siteAuthorCh, output)
+	switch ret := (<-siteAuthorCh).(type) {
+	case error:
+		return ret
+	case string:
+		if ret == "" {
+			ret = existingSite.Author
+		}
+		output.Site.Author = port.InitSettingSiteAuthor(ret)
+	}
+
+	// Site description input
+	output.Input = port.InitSettingSiteDescription("")
+	siteDescriptionCh := make(chan interface{})
+	go u.presenter.Prompt(siteDescriptionCh, output)
+	switch ret := (<-siteDescriptionCh).(type) {
+	case error:
+		return ret
+	case string:
+		if ret == "" {
+			if existingSite.Description != "" {
+				ret = existingSite.Description
+			} else {
+				ret = core.DefaultSiteDescription
+			}
+		}
+		output.Site.Description = port.InitSettingSiteDescription(ret)
+	}
+
+	// Site language input
+	output.Input = port.InitSettingSiteLanguage("")
+	siteLanguageCh := make(chan interface{})
+	go u.presenter.Prompt(siteLanguageCh, output)
+	switch ret := (<-siteLanguageCh).(type) {
+	case error:
+		return ret
+	case string:
+		if ret == "" {
+			if existingSite.Language != "" {
+				ret = existingSite.Language
+			} else {
+				ret = core.DefaultSiteLanguage
+			}
+		}
+		output.Site.Language = port.InitSettingSiteLanguage(ret)
+	}
+
 	// Save configuration
 	output.Message = "Saving project config..."
 	u.presenter.Progress(output)
@@ -248,6 +318,15 @@ func (u *initSettingInteractor) configureProject(input *port.InitSettingUseCaseI
 	core.Cfg.Project.Branch = string(output.Project.Branch)
 	core.Cfg.Project.TemplatePath = string(output.Project.TemplatePath)
 	core.Cfg.Project.AssetPath = string(output.Project.AssetPath)
+	core.Cfg.Site.Title = string(output.Site.Title)
+	core.Cfg.Site.Author = string(output.Site.Author)
+	core.Cfg.Site.Description = string(output.Site.Description)
+	core.Cfg.Site.Language = string(output.Site.Language)
+	// Write the other site settings too, so they can be found in nippo.toml
+	core.Cfg.Site = core.Cfg.Site.WithDefaults()
+	if !configExists {
+		core.Cfg.Site.OgpImage = core.DefaultSiteOgpImage
+	}
 
 	if err := core.Cfg.SaveConfig(); err != nil {
 		return err
@@ -1,13 +1,21 @@
 package interactor_test
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/c18t/nippo-cli/internal/adapter/gateway"
 	"github.com/c18t/nippo-cli/internal/adapter/presenter"
 	"github.com/c18t/nippo-cli/internal/core"
 	"github.com/c18t/nippo-cli/internal/domain/model"
@@ -17,6 +25,7 @@ import (
 	"github.com/c18t/nippo-cli/internal/usecase/interactor"
 	"github.com/c18t/nippo-cli/internal/usecase/port"
 	"github.com/samber/do/v2"
+	"golang.org/x/oauth2"
 	"google.golang.org/api/drive/v3"
 )
 
@@ -60,6 +69,61 @@ func (m *mockCleanCommandPresenter) Suspend(err error) {
 	m.suspendCalled = true
 }
 
+type mockCreateCommandPresenter struct {
+	output *port.CreateCommandUseCaseOutputData
+	err    error
+}
+
+func (m *mockCreateCommandPresenter) Progress(output *port.CreateCommandUseCaseOutputData) {}
+func (m *mockCreateCommandPresenter) StopProgress()                                        {}
+
+func (m *mockCreateCommandPresenter) Complete(output *port.CreateCommandUseCaseOutputData) {
+	m.output = output
+}
+
+func (m *mockCreateCommandPresenter) Suspend(err error) {
+	m.err = err
+}
+
+type mockPullCommandPresenter struct {
+	output *port.PullCommandUseCaseOutputData
+	err    error
+}
+
+func (m *mockPullCommandPresenter) Progress(output *port.PullCommandUseCaseOutputData) {}
+func (m *mockPullCommandPresenter) StopProgress()                                      {}
+
+func (m *mockPullCommandPresenter) Complete(output *port.PullCommandUseCaseOutputData) {
+	m.output = output
+}
+
+func (m *mockPullCommandPresenter) Suspend(err error) {
+	m.err = err
+}
+
+type mockPushCommandPresenter struct {
+	output     *port.PushCommandUseCaseOutputData
+	err        error
+	resolution port.ConflictResolution
+	asked      []string
+}
+
+func (m *mockPushCommandPresenter) Progress(output *port.PushCommandUseCaseOutputData) {}
+func (m *mockPushCommandPresenter) StopProgress()                                      {}
+
+func (m *mockPushCommandPresenter) Complete(output *port.PushCommandUseCaseOutputData) {
+	m.output = output
+}
+
+func (m *mockPushCommandPresenter) Suspend(err error) {
+	m.err = err
+}
+
+func (m *mockPushCommandPresenter) ResolveConflict(name string) (port.ConflictResolution, error) {
+	m.asked = append(m.asked, name)
+	return m.resolution, nil
+}
+
 type mockDeployCommandPresenter struct {
 	progressCalled     bool
 	stopProgressCalled bool
@@ -131,6 +195,46 @@ func (m *mockAuthPresenter) IsCancelled() bool {
 	return m.cancelled
 }
 
+type mockAuthStatusPresenter struct {
+	output     *port.AuthStatusUseCaseOutputData
+	suspendErr error
+}
+
+func (m *mockAuthStatusPresenter) Show(output *port.AuthStatusUseCaseOutputData) {
+	m.output = output
+}
+
+func (m *mockAuthStatusPresenter) Suspend(err error) {
+	m.suspendErr = err
+}
+
+type mockAuthLogoutPresenter struct {
+	output     *port.AuthLogoutUseCaseOutputData
+	suspendErr error
+}
+
+func (m *mockAuthLogoutPresenter) Complete(output *port.AuthLogoutUseCaseOutputData) {
+	m.output = output
+}
+
+func (m *mockAuthLogoutPresenter) Suspend(err error) {
+	m.suspendErr = err
+}
+
+type mockGoogleAuthProvider struct {
+	status    *gateway.GoogleAuthStatus
+	loggedOut bool
+	err       error
+}
+
+func (m *mockGoogleAuthProvider) Status() (*gateway.GoogleAuthStatus, error) {
+	return m.status, m.err
+}
+
+func (m *mockGoogleAuthProvider) Logout() (bool, error) {
+	return m.loggedOut, m.err
+}
+
 type mockDoctorPresenter struct {
 	showCalled bool
 	output     *port.DoctorUseCaseOutputData
@@ -152,6 +256,10 @@ type mockBuildCommandPresenter struct {
 	suspendCalled         bool
 	buildCancelledReturns bool
 	summaryError          error
+	summaryChanged        []presenter.FileChange
+	summaryFailed         []presenter.FileInfo
+	summarySkipped        []presenter.FileInfo
+	warnings              []string
 }
 
 func (m *mockBuildCommandPresenter) Progress(output *port.BuildCommandUseCaseOutputData) {
@@ -178,12 +286,19 @@ func (m *mockBuildCommandPresenter) StopBuildProgress() {
 	m.stopBuildCalled = true
 }
 
+func (m *mockBuildCommandPresenter) Warn(message string) {
+	m.warnings = append(m.warnings, message)
+}
+
 func (m *mockBuildCommandPresenter) IsBuildCancelled() bool {
 	return m.buildCancelledReturns
 }
 
-func (m *mockBuildCommandPresenter) Summary(downloaded []presenter.FileInfo, failed []presenter.FileInfo, err error) {
+func (m *mockBuildCommandPresenter) Summary(changed []presenter.FileChange, failed []presenter.FileInfo, skipped []presenter.FileInfo, err error) {
 	m.summaryCalled = true
+	m.summaryChanged = changed
+	m.summaryFailed = failed
+	m.summarySkipped = skipped
 	m.summaryError = err
 }
 
@@ -201,6 +316,9 @@ type mockFormatCommandPresenter struct {
 	completeCalled         bool
 	suspendCalled          bool
 	formatCancelledReturns bool
+	warnings               []string
+	statuses               []port.FormatFileStatus
+	conflictFiles          []presenter.FileInfo
 }
 
 func (m *mockFormatCommandPresenter) Progress(output *port.FormatCommandUseCaseOutputData) {
@@ -217,18 +335,24 @@ func (m *mockFormatCommandPresenter) StartFormatProgress(total int) {
 
 func (m *mockFormatCommandPresenter) UpdateFormatProgress(result *port.FormatCommandUseCaseOutputData) {
 	m.updateFormatCalled = true
+	m.statuses = append(m.statuses, result.Status)
 }
 
 func (m *mockFormatCommandPresenter) StopFormatProgress() {
 	m.stopFormatCalled = true
 }
 
+func (m *mockFormatCommandPresenter) Warn(message string) {
+	m.warnings = append(m.warnings, message)
+}
+
 func (m *mockFormatCommandPresenter) IsFormatCancelled() bool {
 	return m.formatCancelledReturns
 }
 
-func (m *mockFormatCommandPresenter) Summary(successCount, noChangeCount, failedCount int, updated, failed []presenter.FileInfo) {
+func (m *mockFormatCommandPresenter) Summary(successCount, noChangeCount, failedCount, conflictCount int, updated, failed, conflicts []presenter.FileInfo) {
 	m.summaryCalled = true
+	m.conflictFiles = conflicts
 }
 
 func (m *mockFormatCommandPresenter) Complete(output *port.FormatCommandUseCaseOutputData) {
@@ -239,6 +363,60 @@ func (m *mockFormatCommandPresenter) Suspend(err error) {
 	m.suspendCalled = true
 }
 
+type mockFormatPlanPresenter struct {
+	plans         []*port.FormatCommandUseCaseOutputData
+	summaryCalled bool
+	suspendCalled bool
+}
+
+func (m *mockFormatPlanPresenter) Progress(output *port.FormatCommandUseCaseOutputData) {}
+
+func (m *mockFormatPlanPresenter) StopProgress() {}
+
+func (m *mockFormatPlanPresenter) Plan(output *port.FormatCommandUseCaseOutputData) {
+	m.plans = append(m.plans, output)
+}
+
+func (m *mockFormatPlanPresenter) Summary(outputs []*port.FormatCommandUseCaseOutputData) {
+	m.summaryCalled = true
+}
+
+func (m *mockFormatPlanPresenter) Suspend(err error) {
+	m.suspendCalled = true
+}
+
+type mockFormatRevertPresenter struct {
+	output        *port.FormatRevertUseCaseOutputData
+	suspendErr    error
+	suspendCalled bool
+}
+
+func (m *mockFormatRevertPresenter) Progress(output *port.FormatRevertUseCaseOutputData) {}
+
+func (m *mockFormatRevertPresenter) StopProgress() {}
+
+func (m *mockFormatRevertPresenter) Complete(output *port.FormatRevertUseCaseOutputData) {
+	m.output = output
+}
+
+func (m *mockFormatRevertPresenter) Suspend(err error) {
+	m.suspendCalled = true
+	m.suspendErr = err
+}
+
+type mockFormatHistoryPresenter struct {
+	output        *port.FormatHistoryUseCaseOutputData
+	suspendCalled bool
+}
+
+func (m *mockFormatHistoryPresenter) Show(output *port.FormatHistoryUseCaseOutputData) {
+	m.output = output
+}
+
+func (m *mockFormatHistoryPresenter) Suspend(err error) {
+	m.suspendCalled = true
+}
+
 type mockInitSettingPresenter struct {
 	progressCalled     bool
 	stopProgressCalled bool
@@ -287,6 +465,8 @@ func (m *mockInitSettingPresenter) IsCancelled() bool {
 type mockAssetRepository struct {
 	cleanNippoCacheErr error
 	cleanBuildCacheErr error
+	cleanedBuildCache  bool
+	removedPages       []string
 }
 
 func (m *mockAssetRepository) CleanNippoCache() error {
@@ -294,9 +474,15 @@ func (m *mockAssetRepository) CleanNippoCache() error {
 }
 
 func (m *mockAssetRepository) CleanBuildCache() error {
+	m.cleanedBuildCache = true
 	return m.cleanBuildCacheErr
 }
 
+func (m *mockAssetRepository) RemoveBuildPages(paths []string) error {
+	m.removedPages = append(m.removedPages, paths...)
+	return nil
+}
+
 type mockLocalNippoQuery struct {
 	nippos   []model.Nippo
 	listErr  error
@@ -328,24 +514,63 @@ func (m *mockLocalNippoQuery) Load(nippo *model.Nippo) error {
 }
 
 type mockRemoteNippoQuery struct {
-	nippos      []model.Nippo
-	listErr     error
-	downloadErr error
-	updateErr   error
+	nippos       []model.Nippo
+	listErr      error
+	downloadErr  error
+	updateErr    error
+	createErr    error
+	created      []model.Nippo
+	createdPaths []string
+	updated      []string
+	uploads      [][]byte
+	flushes      int
 }
 
 func (m *mockRemoteNippoQuery) List(param *repository.QueryListParam, option *repository.QueryListOption) ([]model.Nippo, error) {
 	return m.nippos, m.listErr
 }
 
+func (m *mockRemoteNippoQuery) ListTree(param *repository.QueryListParam, option *repository.QueryListOption) ([]model.Nippo, []string, error) {
+	return m.nippos, param.Folders, m.listErr
+}
+
+func (m *mockRemoteNippoQuery) StartPageToken() (string, error) {
+	return "", nil
+}
+
+func (m *mockRemoteNippoQuery) ListChanges(pageToken string, folderIds []string, param *repository.QueryListParam) (*repository.RemoteNippoChanges, error) {
+	return nil, repository.ErrFullSyncRequired
+}
+
 func (m *mockRemoteNippoQuery) Download(nippo *model.Nippo) error {
 	return m.downloadErr
 }
 
 func (m *mockRemoteNippoQuery) Update(nippo *model.Nippo, content []byte) error {
+	m.updated = append(m.updated, nippo.RemoteFile.Id)
+	m.uploads = append(m.uploads, content)
 	return m.updateErr
 }
 
+func (m *mockRemoteNippoQuery) Create(folder, filePath string, nippo *model.Nippo) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	nippo.RemoteFile = &drive.File{Id: "new-id", Name: path.Base(filePath)}
+	m.created = append(m.created, *nippo)
+	m.createdPaths = append(m.createdPaths, filePath)
+	return nil
+}
+
+func (m *mockRemoteNippoQuery) Flush() error {
+	m.flushes++
+	return nil
+}
+
+func (m *mockRemoteNippoQuery) Naming() (*model.NippoNaming, error) {
+	return model.NewNippoNaming(core.Cfg.Source.NamePatterns)
+}
+
 type mockNippoFacade struct {
 	response *service.NippoFacadeReponse
 	sendErr  error
@@ -357,9 +582,22 @@ func (m *mockNippoFacade) Send(request *service.NippoFacadeRequest, option *serv
 
 type mockTemplateService struct {
 	saveErr error
+	// mu guards saved, as pages are rendered in parallel
+	mu    sync.Mutex
+	saved map[string]interface{}
+}
+
+func (m *mockTemplateService) Hash() (string, error) {
+	return "templates", nil
 }
 
 func (m *mockTemplateService) SaveTo(path, templateName string, data interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.saved == nil {
+		m.saved = map[string]interface{}{}
+	}
+	m.saved[filepath.Base(path)] = data
 	return m.saveErr
 }
 
@@ -370,6 +608,7 @@ type mockLocalFileProvider struct {
 	copyErr  error
 	content  []byte
 	readErr  error
+	written  map[string][]byte
 }
 
 func (m *mockLocalFileProvider) List(param *repository.QueryListParam) ([]os.DirEntry, error) {
@@ -377,6 +616,10 @@ func (m *mockLocalFileProvider) List(param *repository.QueryListParam) ([]os.Dir
 }
 
 func (m *mockLocalFileProvider) Write(path string, content []byte) error {
+	if m.written == nil {
+		m.written = map[string][]byte{}
+	}
+	m.written[filepath.Base(path)] = content
 	return m.writeErr
 }
 
@@ -573,6 +816,7 @@ func TestNewFormatCommandInteractor(t *testing.T) {
 	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
 		RemoteNippoQuery:       mockRemoteQuery,
 		FormatCommandPresenter: mockPres,
+		FormatPlanPresenter:    &mockFormatPlanPresenter{},
 	})
 
 	i, err := interactor.NewFormatCommandInteractor(injector)
@@ -584,6 +828,104 @@ func TestNewFormatCommandInteractor(t *testing.T) {
 	}
 }
 
+// retryDriveFileProvider only captures the retry notifier and the access registered by an interactor
+type retryDriveFileProvider struct {
+	gateway.DriveFileProvider
+	notify gateway.RetryNotifier
+	access gateway.DriveAccess
+}
+
+func (m *retryDriveFileProvider) OnRetry(notify gateway.RetryNotifier) {
+	m.notify = notify
+}
+
+func (m *retryDriveFileProvider) RequireAccess(access gateway.DriveAccess) {
+	m.access = access
+}
+
+func TestNewBuildCommandInteractor_WarnsOnRetry(t *testing.T) {
+	mockDrive := &retryDriveFileProvider{}
+	mockPres := &mockBuildCommandPresenter{}
+
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		AssetRepository:       &mockAssetRepository{},
+		LocalNippoQuery:       &mockLocalNippoQuery{},
+		NippoFacade:           &mockNippoFacade{},
+		TemplateService:       &mockTemplateService{},
+		LocalFileProvider:     &mockLocalFileProvider{},
+		DriveFileProvider:     mockDrive,
+		BuildCommandPresenter: mockPres,
+	})
+
+	if _, err := interactor.NewBuildCommandInteractor(injector); err != nil {
+		t.Fatalf("NewBuildCommandInteractor() error = %v", err)
+	}
+	if mockDrive.notify == nil {
+		t.Fatal("retry notifier should be registered")
+	}
+
+	mockDrive.notify(gateway.RetryEvent{Operation: "list files", Attempt: 1, MaxAttempts: 5, Wait: 2 * time.Second})
+	if len(mockPres.warnings) != 1 {
+		t.Fatalf("warnings = %v, want 1 warning", mockPres.warnings)
+	}
+	if !strings.Contains(mockPres.warnings[0], "list files") {
+		t.Errorf("warning = %q, want it to mention the operation", mockPres.warnings[0])
+	}
+}
+
+func TestNewFormatCommandInteractor_WarnsOnRetry(t *testing.T) {
+	mockDrive := &retryDriveFileProvider{}
+	mockPres := &mockFormatCommandPresenter{}
+
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		RemoteNippoQuery:       &mockRemoteNippoQuery{},
+		DriveFileProvider:      mockDrive,
+		FormatCommandPresenter: mockPres,
+		FormatPlanPresenter:    &mockFormatPlanPresenter{},
+	})
+
+	if _, err := interactor.NewFormatCommandInteractor(injector); err != nil {
+		t.Fatalf("NewFormatCommandInteractor() error = %v", err)
+	}
+	if mockDrive.notify == nil {
+		t.Fatal("retry notifier should be registered")
+	}
+
+	mockDrive.notify(gateway.RetryEvent{Operation: "update abc", Attempt: 2, MaxAttempts: 5, Wait: time.Second})
+	if len(mockPres.warnings) != 1 {
+		t.Fatalf("warnings = %v, want 1 warning", mockPres.warnings)
+	}
+}
+
+func TestNewDriveCommandInteractors_RequireAccess(t *testing.T) {
+	buildDrive := &retryDriveFileProvider{access: gateway.DriveReadWrite}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		AssetRepository:       &mockAssetRepository{},
+		DriveFileProvider:     buildDrive,
+		BuildCommandPresenter: &mockBuildCommandPresenter{},
+	})
+	if _, err := interactor.NewBuildCommandInteractor(injector); err != nil {
+		t.Fatalf("NewBuildCommandInteractor() error = %v", err)
+	}
+	if buildDrive.access != gateway.DriveReadOnly {
+		t.Errorf("build access = %v, want read-only", buildDrive.access)
+	}
+
+	formatDrive := &retryDriveFileProvider{}
+	injector = inject.NewTestInjector(&inject.TestBasePackageOptions{
+		RemoteNippoQuery:       &mockRemoteNippoQuery{},
+		DriveFileProvider:      formatDrive,
+		FormatCommandPresenter: &mockFormatCommandPresenter{},
+		FormatPlanPresenter:    &mockFormatPlanPresenter{},
+	})
+	if _, err := interactor.NewFormatCommandInteractor(injector); err != nil {
+		t.Fatalf("NewFormatCommandInteractor() error = %v", err)
+	}
+	if formatDrive.access != gateway.DriveReadWrite {
+		t.Errorf("format access = %v, want read-write", formatDrive.access)
+	}
+}
+
 func TestNewInitSettingInteractor(t *testing.T) {
 	mockProv := &mockLocalFileProvider{}
 	mockPres := &mockInitSettingPresenter{}
@@ -678,6 +1020,7 @@ func TestFormatCommandInteractor_Handle_NoDriveFolderId(t *testing.T) {
 	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
 		RemoteNippoQuery:       mockRemoteQuery,
 		FormatCommandPresenter: mockPres,
+		FormatPlanPresenter:    &mockFormatPlanPresenter{},
 	})
 
 	i, _ := interactor.NewFormatCommandInteractor(injector)
@@ -700,6 +1043,7 @@ func TestFormatCommandInteractor_Handle_NoFiles(t *testing.T) {
 	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
 		RemoteNippoQuery:       mockRemoteQuery,
 		FormatCommandPresenter: mockPres,
+		FormatPlanPresenter:    &mockFormatPlanPresenter{},
 	})
 
 	i, _ := interactor.NewFormatCommandInteractor(injector)
@@ -722,6 +1066,7 @@ func TestFormatCommandInteractor_Handle_ListError(t *testing.T) {
 	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
 		RemoteNippoQuery:       mockRemoteQuery,
 		FormatCommandPresenter: mockPres,
+		FormatPlanPresenter:    &mockFormatPlanPresenter{},
 	})
 
 	i, _ := interactor.NewFormatCommandInteractor(injector)
@@ -752,6 +1097,7 @@ func TestFormatCommandInteractor_Handle_WithFiles(t *testing.T) {
 	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
 		RemoteNippoQuery:       mockRemoteQuery,
 		FormatCommandPresenter: mockPres,
+		FormatPlanPresenter:    &mockFormatPlanPresenter{},
 	})
 
 	i, _ := interactor.NewFormatCommandInteractor(injector)
@@ -787,6 +1133,7 @@ func TestFormatCommandInteractor_Handle_Cancelled(t *testing.T) {
 	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
 		RemoteNippoQuery:       mockRemoteQuery,
 		FormatCommandPresenter: mockPres,
+		FormatPlanPresenter:    &mockFormatPlanPresenter{},
 	})
 
 	i, _ := interactor.NewFormatCommandInteractor(injector)
@@ -1103,6 +1450,7 @@ func TestFormatCommandInteractor_Handle_WithLastFormatTimestamp(t *testing.T) {
 	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
 		RemoteNippoQuery:       mockRemoteQuery,
 		FormatCommandPresenter: mockPres,
+		FormatPlanPresenter:    &mockFormatPlanPresenter{},
 	})
 
 	i, _ := interactor.NewFormatCommandInteractor(injector)
@@ -1190,6 +1538,7 @@ func TestFormatCommandInteractor_Handle_FileWithoutFrontMatter(t *testing.T) {
 	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
 		RemoteNippoQuery:       mockRemoteQuery,
 		FormatCommandPresenter: mockPres,
+		FormatPlanPresenter:    &mockFormatPlanPresenter{},
 	})
 
 	i, _ := interactor.NewFormatCommandInteractor(injector)
@@ -1198,6 +1547,103 @@ func TestFormatCommandInteractor_Handle_FileWithoutFrontMatter(t *testing.T) {
 	if !mockPres.summaryCalled {
 		t.Error("Summary() was not called")
 	}
+	if len(mockRemoteQuery.updated) != 1 || mockRemoteQuery.flushes != 1 {
+		t.Errorf("updated %v with %d flushes, want the updates flushed once", mockRemoteQuery.updated, mockRemoteQuery.flushes)
+	}
+}
+
+// Test FormatCommandInteractor committing front-matter fixes to a git source
+func TestFormatCommandInteractor_Handle_GitSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	repo := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE=2024-01-15T21:00:00Z", "GIT_COMMITTER_DATE=2024-01-15T21:00:00Z")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+		return string(out)
+	}
+	git("init", "--quiet", "--initial-branch=main")
+	git("config", "user.name", "nippo")
+	git("config", "user.email", "nippo@example.com")
+	if err := os.WriteFile(filepath.Join(repo, "2024-01-15.md"), []byte("# Test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "--quiet", "-m", "Add 2024-01-15.md")
+
+	core.Cfg.Source.Type = core.SourceTypeGit
+	core.Cfg.Source.Path = repo
+
+	mockPres := &mockFormatCommandPresenter{}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		FormatCommandPresenter: mockPres,
+		FormatPlanPresenter:    &mockFormatPlanPresenter{},
+	})
+
+	i, _ := interactor.NewFormatCommandInteractor(injector)
+	i.Handle(&port.FormatCommandUseCaseInputData{})
+
+	if mockPres.suspendCalled {
+		t.Fatal("Suspend() should not be called")
+	}
+	if got := strings.TrimSpace(git("log", "-1", "--format=%s")); got != "Update 2024-01-15.md" {
+		t.Errorf("last commit = %q, want the front-matter fix", got)
+	}
+	content := git("show", "HEAD:2024-01-15.md")
+	fm, _, err := model.ParseFrontMatter([]byte(content))
+	if err != nil || fm == nil || !fm.Created.Equal(time.Date(2024, 1, 15, 21, 0, 0, 0, time.UTC)) {
+		t.Errorf("content = %q, want created from the commit history", content)
+	}
+}
+
+func TestFormatCommandInteractor_Handle_LocalSource(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "2024-01-15.md")
+	if err := os.WriteFile(path, []byte("# Test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modified := time.Date(2024, 1, 15, 21, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+
+	core.Cfg.Source.Type = core.SourceTypeLocal
+	core.Cfg.Source.Path = dir
+
+	mockPres := &mockFormatCommandPresenter{}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		FormatCommandPresenter: mockPres,
+		FormatPlanPresenter:    &mockFormatPlanPresenter{},
+	})
+
+	i, _ := interactor.NewFormatCommandInteractor(injector)
+	i.Handle(&port.FormatCommandUseCaseInputData{})
+
+	if mockPres.suspendCalled {
+		t.Fatal("Suspend() should not be called")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm, _, err := model.ParseFrontMatter(content)
+	if err != nil || fm == nil || !fm.Created.Equal(modified) {
+		t.Errorf("content = %q, want created from the modified time of the file", content)
+	}
 }
 
 func TestFormatCommandInteractor_Handle_FileWithNowPlaceholder(t *testing.T) {
@@ -1220,6 +1666,7 @@ func TestFormatCommandInteractor_Handle_FileWithNowPlaceholder(t *testing.T) {
 	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
 		RemoteNippoQuery:       mockRemoteQuery,
 		FormatCommandPresenter: mockPres,
+		FormatPlanPresenter:    &mockFormatPlanPresenter{},
 	})
 
 	i, _ := interactor.NewFormatCommandInteractor(injector)
@@ -1253,6 +1700,7 @@ func TestFormatCommandInteractor_Handle_UpdateError(t *testing.T) {
 	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
 		RemoteNippoQuery:       mockRemoteQuery,
 		FormatCommandPresenter: mockPres,
+		FormatPlanPresenter:    &mockFormatPlanPresenter{},
 	})
 
 	i, _ := interactor.NewFormatCommandInteractor(injector)
@@ -1263,84 +1711,338 @@ func TestFormatCommandInteractor_Handle_UpdateError(t *testing.T) {
 	}
 }
 
-// Helper test for extractDriveFolderId via init interactor
-func TestExtractDriveFolderId_ViaInitInteractor(t *testing.T) {
+func TestFormatCommandInteractor_Handle_Conflict(t *testing.T) {
 	env := core.SetupTestEnv(t)
 	defer env.Cleanup()
 
-	// We can test that the init interactor can be created successfully
-	mockProv := &mockLocalFileProvider{}
-	mockPres := &mockInitSettingPresenter{}
-
-	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
-		LocalFileProvider:    mockProv,
-		InitSettingPresenter: mockPres,
-	})
+	core.Cfg.Project.DriveFolderId = "test-folder-id"
+	lastFormat := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	core.Cfg.LastFormatTimestamp = lastFormat
 
-	i, err := interactor.NewInitSettingInteractor(injector)
-	if err != nil {
-		t.Errorf("NewInitSettingInteractor() error = %v", err)
+	nippo := model.Nippo{
+		Date:       model.NewNippoDate("2024-01-15.md"),
+		Content:    []byte("# Test\n\nNo front-matter"),
+		RemoteFile: &drive.File{Id: "file1", Name: "2024-01-15.md", CreatedTime: "2024-01-15T10:00:00Z", ModifiedTime: "2024-01-15T10:00:00Z"},
 	}
-	if i == nil {
-		t.Error("NewInitSettingInteractor() returned nil")
+	mockRemoteQuery := &mockRemoteNippoQuery{
+		nippos:    []model.Nippo{nippo},
+		updateErr: fmt.Errorf("%w: 2024-01-15.md", repository.ErrConflict),
 	}
-}
+	mockPres := &mockFormatCommandPresenter{}
 
-// Test generateRandomState indirectly via auth tests
-func TestAuthInteractor_CanBeCreated(t *testing.T) {
-	mockPres := &mockAuthPresenter{}
 	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
-		AuthPresenter: mockPres,
+		RemoteNippoQuery:       mockRemoteQuery,
+		FormatCommandPresenter: mockPres,
+		FormatPlanPresenter:    &mockFormatPlanPresenter{},
 	})
 
-	i, err := interactor.NewAuthInteractor(injector)
-	if err != nil {
-		t.Errorf("NewAuthInteractor() error = %v", err)
-	}
-	if i == nil {
-		t.Error("NewAuthInteractor() returned nil")
-	}
-}
-
-// Test successHTML indirectly - if auth interactor can be created, the HTML is valid
-func TestSuccessHTML_Exists(t *testing.T) {
-	// We can verify successHTML exists by creating an auth interactor
-	// and ensuring no panics occur during creation
-	mockPres := &mockAuthPresenter{}
-	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
-		AuthPresenter: mockPres,
-	})
+	i, _ := interactor.NewFormatCommandInteractor(injector)
+	i.Handle(&port.FormatCommandUseCaseInputData{})
 
-	i, err := interactor.NewAuthInteractor(injector)
-	if err != nil {
-		t.Errorf("NewAuthInteractor() error = %v", err)
+	if len(mockPres.statuses) != 1 || mockPres.statuses[0] != port.FormatFileStatusConflict {
+		t.Errorf("statuses = %v, want a conflict", mockPres.statuses)
 	}
-	if i == nil {
-		t.Error("NewAuthInteractor() returned nil")
+	if len(mockPres.conflictFiles) != 1 || mockPres.conflictFiles[0].Id != "file1" {
+		t.Errorf("Summary() conflict files = %+v", mockPres.conflictFiles)
+	}
+	if !core.Cfg.LastFormatTimestamp.Equal(lastFormat) {
+		t.Errorf("LastFormatTimestamp = %v, want it not to advance", core.Cfg.LastFormatTimestamp)
 	}
 }
 
-// Additional test for types that use html/template.HTML
-func TestFormatCommandInteractor_WithMalformedFrontMatter(t *testing.T) {
+func TestFormatCommandInteractor_Handle_DryRun(t *testing.T) {
 	env := core.SetupTestEnv(t)
 	defer env.Cleanup()
 
 	core.Cfg.Project.DriveFolderId = "test-folder-id"
+	lastFormat := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	core.Cfg.LastFormatTimestamp = lastFormat
 
-	// Create test nippo with malformed front-matter
-	date := model.NewNippoDate("2024-01-15.md")
-	nippo := model.Nippo{
-		Date:       date,
-		Content:    []byte("---\ncreated: not-a-valid-date\n---\n# Test"),
-		RemoteFile: &drive.File{Id: "file1", Name: "2024-01-15.md"},
-	}
-
-	mockRemoteQuery := &mockRemoteNippoQuery{nippos: []model.Nippo{nippo}}
-	mockPres := &mockFormatCommandPresenter{}
-
-	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+	mockRemoteQuery := &mockRemoteNippoQuery{
+		nippos: []model.Nippo{
+			{
+				Date:       model.NewNippoDate("2024-01-15.md"),
+				Content:    []byte("---\ncreated: 2024-01-15T10:00:00+09:00\nupdated: now\n---\n\n# Test\n"),
+				RemoteFile: &drive.File{Id: "file1", Name: "2024-01-15.md", CreatedTime: "2024-01-15T01:00:00Z", ModifiedTime: "2024-01-15T01:00:00Z"},
+			},
+			{
+				Date:       model.NewNippoDate("2024-01-16.md"),
+				Content:    []byte("---\ncreated: 2024-01-16T10:00:00+09:00\n---\n\n# Test\n"),
+				RemoteFile: &drive.File{Id: "file2", Name: "2024-01-16.md", CreatedTime: "2024-01-16T01:00:00Z", ModifiedTime: "2024-01-16T01:00:00Z"},
+			},
+		},
+	}
+	mockPres := &mockFormatCommandPresenter{}
+	mockPlan := &mockFormatPlanPresenter{}
+
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		RemoteNippoQuery:       mockRemoteQuery,
+		FormatCommandPresenter: mockPres,
+		FormatPlanPresenter:    mockPlan,
+	})
+
+	i, _ := interactor.NewFormatCommandInteractor(injector)
+	i.Handle(&port.FormatCommandUseCaseInputData{DryRun: true})
+
+	if len(mockRemoteQuery.updated) != 0 {
+		t.Errorf("Update() called for %v, want no uploads", mockRemoteQuery.updated)
+	}
+	if mockPres.startFormatCalled || mockPres.summaryCalled {
+		t.Error("dry run should not use the format presenter")
+	}
+	if len(mockPlan.plans) != 2 || !mockPlan.summaryCalled {
+		t.Fatalf("plans = %d, summary = %v", len(mockPlan.plans), mockPlan.summaryCalled)
+	}
+	if got := mockPlan.plans[0]; got.Status != port.FormatFileStatusSuccess || !strings.Contains(got.Diff, "-updated: now\n") {
+		t.Errorf("plan of 2024-01-15.md = %+v, want a diff replacing updated: now", got)
+	}
+	if got := mockPlan.plans[1]; got.Status != port.FormatFileStatusNoChange || got.Diff != "" {
+		t.Errorf("plan of 2024-01-16.md = %+v, want no change", got)
+	}
+	if !core.Cfg.LastFormatTimestamp.Equal(lastFormat) {
+		t.Errorf("LastFormatTimestamp = %v, want it unchanged by a dry run", core.Cfg.LastFormatTimestamp)
+	}
+}
+
+func TestFormatCommandInteractor_Handle_ConfiguredRules(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	core.Cfg.Project.DriveFolderId = "test-folder-id"
+	core.Cfg.Format.Rules = []string{model.FormatRuleUpdatedNow, model.FormatRuleTrailingWhitespace, model.FormatRuleFinalNewline}
+
+	mockRemoteQuery := &mockRemoteNippoQuery{
+		nippos: []model.Nippo{{
+			Date:       model.NewNippoDate("2024-01-15.md"),
+			Content:    []byte("# Test  \t\nbody"),
+			RemoteFile: &drive.File{Id: "file1", Name: "2024-01-15.md"},
+		}},
+	}
+	mockPlan := &mockFormatPlanPresenter{}
+
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		RemoteNippoQuery:       mockRemoteQuery,
+		FormatCommandPresenter: &mockFormatCommandPresenter{},
+		FormatPlanPresenter:    mockPlan,
+	})
+
+	i, _ := interactor.NewFormatCommandInteractor(injector)
+	i.Handle(&port.FormatCommandUseCaseInputData{DryRun: true})
+
+	if len(mockPlan.plans) != 1 {
+		t.Fatalf("plans = %d, want 1", len(mockPlan.plans))
+	}
+	want := []string{"Removed trailing whitespace", "Added final newline"}
+	if got := mockPlan.plans[0]; !slices.Equal(got.Reasons, want) || got.Message != strings.Join(want, ", ") {
+		t.Errorf("plan = %+v, want a reason per rule %v", got, want)
+	}
+
+	core.Cfg.Format.Rules = []string{"lint"}
+	mockPlan = &mockFormatPlanPresenter{}
+	injector = inject.NewTestInjector(&inject.TestBasePackageOptions{
+		RemoteNippoQuery:       mockRemoteQuery,
+		FormatCommandPresenter: &mockFormatCommandPresenter{},
+		FormatPlanPresenter:    mockPlan,
+	})
+	i, _ = interactor.NewFormatCommandInteractor(injector)
+	i.Handle(&port.FormatCommandUseCaseInputData{DryRun: true})
+	if !mockPlan.suspendCalled {
+		t.Error("an unknown rule should suspend the command")
+	}
+}
+
+func TestFormatInteractors_BackupAndRevert(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	core.Cfg.Project.DriveFolderId = "test-folder-id"
+	original1 := "---\ncreated: 2024-01-15T10:00:00+09:00\nupdated: now\n---\n\n# Test\n"
+	original2 := "---\ncreated: 2024-01-16T10:00:00+09:00\nupdated: now\n---\n\n# Test\n"
+	mockRemoteQuery := &mockRemoteNippoQuery{
+		nippos: []model.Nippo{
+			{
+				Date:       model.NewNippoDate("2024-01-15.md"),
+				Content:    []byte(original1),
+				RemoteFile: &drive.File{Id: "file1", Name: "2024-01-15.md", CreatedTime: "2024-01-15T01:00:00Z", ModifiedTime: "2024-01-15T01:00:00Z"},
+			},
+			{
+				Date:       model.NewNippoDate("2024-01-16.md"),
+				Content:    []byte(original2),
+				RemoteFile: &drive.File{Id: "file2", Name: "2024-01-16.md", CreatedTime: "2024-01-16T01:00:00Z", ModifiedTime: "2024-01-16T01:00:00Z"},
+			},
+		},
+	}
+	mockRevert := &mockFormatRevertPresenter{}
+	mockHistory := &mockFormatHistoryPresenter{}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		RemoteNippoQuery:       mockRemoteQuery,
+		FormatCommandPresenter: &mockFormatCommandPresenter{},
+		FormatPlanPresenter:    &mockFormatPlanPresenter{},
+		FormatRevertPresenter:  mockRevert,
+		FormatHistoryPresenter: mockHistory,
+	})
+
+	format, _ := interactor.NewFormatCommandInteractor(injector)
+	format.Handle(&port.FormatCommandUseCaseInputData{})
+	if len(mockRemoteQuery.uploads) != 2 {
+		t.Fatalf("format uploaded %d files, want 2", len(mockRemoteQuery.uploads))
+	}
+
+	// file1 is left as formatted, file2 is edited after the format run
+	mockRemoteQuery.nippos[0].Content = mockRemoteQuery.uploads[0]
+	mockRemoteQuery.nippos[1].Content = append(mockRemoteQuery.uploads[1], []byte("edited\n")...)
+	mockRemoteQuery.updated, mockRemoteQuery.uploads = nil, nil
+
+	revert, _ := interactor.NewFormatRevertInteractor(injector)
+	revert.Handle(&port.FormatRevertUseCaseInputData{})
+	if mockRevert.suspendCalled {
+		t.Fatalf("revert suspended: %v", mockRevert.suspendErr)
+	}
+	if !slices.Equal(mockRemoteQuery.updated, []string{"file1"}) || string(mockRemoteQuery.uploads[0]) != original1 {
+		t.Errorf("revert uploaded %v, want the original of file1 only", mockRemoteQuery.updated)
+	}
+	files := mockRevert.output.Files
+	if len(files) != 2 || files[0].Action != port.FormatRevertRestored || files[1].Action != port.FormatRevertSkipped {
+		t.Errorf("revert results = %+v, want file1 restored and file2 skipped", files)
+	}
+
+	history, _ := interactor.NewFormatHistoryInteractor(injector)
+	history.Handle(&port.FormatHistoryUseCaseInputData{})
+	runs := mockHistory.output.Runs
+	if len(runs) != 1 || len(runs[0].Files) != 2 || runs[0].RevertedAt.IsZero() {
+		t.Errorf("history = %+v, want one reverted run of 2 files", runs)
+	}
+
+	// Every run has been reverted
+	mockRevert.suspendCalled = false
+	revert.Handle(&port.FormatRevertUseCaseInputData{})
+	if !mockRevert.suspendCalled {
+		t.Error("revert should fail without a run to revert")
+	}
+	revert.Handle(&port.FormatRevertUseCaseInputData{RunId: "19700101-000000"})
+	if !errors.Is(mockRevert.suspendErr, repository.ErrFormatRunNotFound) {
+		t.Errorf("revert error = %v, want ErrFormatRunNotFound", mockRevert.suspendErr)
+	}
+}
+
+func TestFormatCommandInteractor_Handle_FailedUpdateKeepsNoBackup(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	core.Cfg.Project.DriveFolderId = "test-folder-id"
+	mockRemoteQuery := &mockRemoteNippoQuery{
+		nippos: []model.Nippo{{
+			Date:       model.NewNippoDate("2024-01-15.md"),
+			Content:    []byte("---\ncreated: 2024-01-15T10:00:00+09:00\nupdated: now\n---\n\n# Test\n"),
+			RemoteFile: &drive.File{Id: "file1", Name: "2024-01-15.md", CreatedTime: "2024-01-15T01:00:00Z", ModifiedTime: "2024-01-15T01:00:00Z"},
+		}},
+		updateErr: repository.ErrConflict,
+	}
+	mockPres := &mockFormatCommandPresenter{}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		RemoteNippoQuery:       mockRemoteQuery,
+		FormatCommandPresenter: mockPres,
+		FormatPlanPresenter:    &mockFormatPlanPresenter{},
+	})
+
+	format, _ := interactor.NewFormatCommandInteractor(injector)
+	format.Handle(&port.FormatCommandUseCaseInputData{})
+	if len(mockRemoteQuery.updated) != 1 {
+		t.Fatalf("updated = %v, want the upload to be tried", mockRemoteQuery.updated)
+	}
+
+	// The update never happened, so nothing is left to restore
+	var backups []string
+	_ = filepath.WalkDir(filepath.Join(core.Cfg.GetCacheDir(), "format-backup"), func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			backups = append(backups, path)
+		}
+		return nil
+	})
+	if len(backups) != 0 {
+		t.Errorf("backups = %v, want none for a conflicting update", backups)
+	}
+}
+
+// Helper test for extractDriveFolderId via init interactor
+func TestExtractDriveFolderId_ViaInitInteractor(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	// We can test that the init interactor can be created successfully
+	mockProv := &mockLocalFileProvider{}
+	mockPres := &mockInitSettingPresenter{}
+
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		LocalFileProvider:    mockProv,
+		InitSettingPresenter: mockPres,
+	})
+
+	i, err := interactor.NewInitSettingInteractor(injector)
+	if err != nil {
+		t.Errorf("NewInitSettingInteractor() error = %v", err)
+	}
+	if i == nil {
+		t.Error("NewInitSettingInteractor() returned nil")
+	}
+}
+
+// Test generateRandomState indirectly via auth tests
+func TestAuthInteractor_CanBeCreated(t *testing.T) {
+	mockPres := &mockAuthPresenter{}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		AuthPresenter: mockPres,
+	})
+
+	i, err := interactor.NewAuthInteractor(injector)
+	if err != nil {
+		t.Errorf("NewAuthInteractor() error = %v", err)
+	}
+	if i == nil {
+		t.Error("NewAuthInteractor() returned nil")
+	}
+}
+
+// Test successHTML indirectly - if auth interactor can be created, the HTML is valid
+func TestSuccessHTML_Exists(t *testing.T) {
+	// We can verify successHTML exists by creating an auth interactor
+	// and ensuring no panics occur during creation
+	mockPres := &mockAuthPresenter{}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		AuthPresenter: mockPres,
+	})
+
+	i, err := interactor.NewAuthInteractor(injector)
+	if err != nil {
+		t.Errorf("NewAuthInteractor() error = %v", err)
+	}
+	if i == nil {
+		t.Error("NewAuthInteractor() returned nil")
+	}
+}
+
+// Additional test for types that use html/template.HTML
+func TestFormatCommandInteractor_WithMalformedFrontMatter(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	core.Cfg.Project.DriveFolderId = "test-folder-id"
+
+	// Create test nippo with malformed front-matter
+	date := model.NewNippoDate("2024-01-15.md")
+	nippo := model.Nippo{
+		Date:       date,
+		Content:    []byte("---\ncreated: not-a-valid-date\n---\n# Test"),
+		RemoteFile: &drive.File{Id: "file1", Name: "2024-01-15.md"},
+	}
+
+	mockRemoteQuery := &mockRemoteNippoQuery{nippos: []model.Nippo{nippo}}
+	mockPres := &mockFormatCommandPresenter{}
+
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
 		RemoteNippoQuery:       mockRemoteQuery,
 		FormatCommandPresenter: mockPres,
+		FormatPlanPresenter:    &mockFormatPlanPresenter{},
 	})
 
 	i, _ := interactor.NewFormatCommandInteractor(injector)
@@ -1418,6 +2120,548 @@ func TestBuildCommandInteractor_Handle_SuccessfulBuild(t *testing.T) {
 	}
 }
 
+// Test BuildCommandInteractor gives a day with several nippo one page for the day
+// and one for each nippo with a suffix
+func TestBuildCommandInteractor_Handle_EntriesOfADay(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	core.Cfg.Project.DriveFolderId = "test-folder-id"
+	core.Cfg.Project.SiteUrl = "https://example.com"
+
+	mockLocalQuery := &mockLocalNippoQuery{
+		nippos: []model.Nippo{
+			{Date: model.NewNippoDate("2024-01-15.md"), Content: []byte("# Morning")},
+			{Date: model.NewNippoDate("2024-01-15-evening.md"), Content: []byte("# Evening")},
+		},
+	}
+	mockTemplate := &mockTemplateService{}
+	mockFileProvider := &mockLocalFileProvider{}
+	mockPres := &mockBuildCommandPresenter{}
+
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		AssetRepository:       &mockAssetRepository{},
+		LocalNippoQuery:       mockLocalQuery,
+		NippoFacade:           &mockNippoFacade{response: &service.NippoFacadeReponse{}},
+		TemplateService:       mockTemplate,
+		LocalFileProvider:     mockFileProvider,
+		BuildCommandPresenter: mockPres,
+	})
+
+	i, _ := interactor.NewBuildCommandInteractor(injector)
+	i.Handle(&port.BuildCommandUseCaseInputData{})
+
+	if mockPres.summaryError != nil {
+		t.Fatalf("Summary() error = %v", mockPres.summaryError)
+	}
+	day, ok := mockTemplate.saved["20240115.html"].(interactor.Content)
+	if !ok {
+		t.Fatalf("the page of the day was not saved: %v", mockTemplate.saved)
+	}
+	if !strings.Contains(string(day.Content), "Morning") || !strings.Contains(string(day.Content), `<section id="20240115-evening">`) {
+		t.Errorf("day page content = %s, want both nippo", day.Content)
+	}
+	evening, ok := mockTemplate.saved["20240115-evening.html"].(interactor.Content)
+	if !ok {
+		t.Fatalf("the page of the evening nippo was not saved: %v", mockTemplate.saved)
+	}
+	if evening.Url != "https://example.com/20240115-evening" || strings.Contains(string(evening.Content), "Morning") {
+		t.Errorf("evening page = %+v", evening)
+	}
+
+	archive, ok := mockTemplate.saved["202401.html"].(interactor.Archive)
+	if !ok {
+		t.Fatal("the archive page was not saved")
+	}
+	var count int
+	for _, week := range archive.Calender.Weeks {
+		for _, d := range week {
+			if d.Date != nil && d.Date.Day() == 15 {
+				count = d.Count
+			}
+		}
+	}
+	if count != 2 {
+		t.Errorf("calendar count of the 15th = %d, want 2", count)
+	}
+
+	feed := string(mockFileProvider.written["feed.xml"])
+	if !strings.Contains(feed, "https://example.com/20240115</id>") || !strings.Contains(feed, "https://example.com/20240115-evening</id>") {
+		t.Errorf("feed = %s, want an item for each nippo", feed)
+	}
+}
+
+// Test BuildCommandInteractor reports sync changes from the facade in the summary
+func TestBuildCommandInteractor_Handle_Incremental(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	core.Cfg.Project.DriveFolderId = "test-folder-id"
+	core.Cfg.Project.SiteUrl = "https://example.com"
+
+	mockAssetRepo := &mockAssetRepository{}
+	mockLocalQuery := &mockLocalNippoQuery{
+		nippos: []model.Nippo{
+			{Date: model.NewNippoDate("2024-01-10.md"), Content: []byte("# 10th")},
+			{Date: model.NewNippoDate("2024-01-15.md"), Content: []byte("# 15th")},
+			{Date: model.NewNippoDate("2024-01-20.md"), Content: []byte("# 20th")},
+			{Date: model.NewNippoDate("2024-02-01.md"), Content: []byte("# 1st")},
+		},
+	}
+	mockTemplate := &mockTemplateService{}
+	mockPres := &mockBuildCommandPresenter{}
+
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		AssetRepository:       mockAssetRepo,
+		LocalNippoQuery:       mockLocalQuery,
+		NippoFacade:           &mockNippoFacade{response: &service.NippoFacadeReponse{}},
+		TemplateService:       mockTemplate,
+		LocalFileProvider:     &mockLocalFileProvider{},
+		BuildCommandPresenter: mockPres,
+	})
+	i, _ := interactor.NewBuildCommandInteractor(injector)
+	build := func(force bool) []string {
+		t.Helper()
+		mockTemplate.saved = nil
+		mockAssetRepo.cleanedBuildCache = false
+		i.Handle(&port.BuildCommandUseCaseInputData{Force: force})
+		if mockPres.summaryError != nil {
+			t.Fatalf("Summary() error = %v", mockPres.summaryError)
+		}
+		return slices.Sorted(maps.Keys(mockTemplate.saved))
+	}
+
+	all := []string{"202401.html", "20240110.html", "20240115.html", "20240120.html", "202402.html", "20240201.html", "index.html"}
+	if got := build(false); !slices.Equal(got, all) || !mockAssetRepo.cleanedBuildCache {
+		t.Errorf("first build saved %v, want a full build of %v", got, all)
+	}
+	if got := build(false); len(got) != 0 {
+		t.Errorf("build without changes saved %v", got)
+	}
+
+	mockLocalQuery.nippos[1].Content = []byte("# 15th, edited")
+	want := []string{"202401.html", "20240110.html", "20240115.html", "20240120.html", "index.html"}
+	if got := build(false); !slices.Equal(got, want) || mockAssetRepo.cleanedBuildCache {
+		t.Errorf("build after an edit saved %v, want %v", got, want)
+	}
+
+	mockLocalQuery.nippos = mockLocalQuery.nippos[:3]
+	want = []string{"20240120.html", "index.html"}
+	if got := build(false); !slices.Equal(got, want) {
+		t.Errorf("build after a removal saved %v, want %v", got, want)
+	}
+	if removed := []string{"202402", "20240201"}; !slices.Equal(mockAssetRepo.removedPages, removed) {
+		t.Errorf("removed pages = %v, want %v", mockAssetRepo.removedPages, removed)
+	}
+
+	if got := build(true); len(got) != 5 || !mockAssetRepo.cleanedBuildCache {
+		t.Errorf("forced build saved %v, want a full build", got)
+	}
+}
+
+func TestBuildCommandInteractor_Handle_SiteSettings(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	core.Cfg.Project.DriveFolderId = "test-folder-id"
+	core.Cfg.Project.SiteUrl = "https://example.com/"
+	core.Cfg.Site = core.ConfigSite{
+		Title:          "My nippo",
+		Author:         "alice",
+		Language:       "en",
+		DayDescription: "What {{.Author}} did on {{.Date}}.",
+		OgpImage:       "images/og.png",
+		FeedEntries:    1,
+		FeedFormat:     core.SiteFeedRSS,
+	}
+
+	mockTemplate := &mockTemplateService{}
+	mockFileProvider := &mockLocalFileProvider{}
+	mockPres := &mockBuildCommandPresenter{}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		AssetRepository: &mockAssetRepository{},
+		LocalNippoQuery: &mockLocalNippoQuery{nippos: []model.Nippo{
+			{Date: model.NewNippoDate("2024-01-14.md"), Content: []byte("# 14th")},
+			{Date: model.NewNippoDate("2024-01-15.md"), Content: []byte("# 15th")},
+		}},
+		NippoFacade:           &mockNippoFacade{response: &service.NippoFacadeReponse{}},
+		TemplateService:       mockTemplate,
+		LocalFileProvider:     mockFileProvider,
+		BuildCommandPresenter: mockPres,
+	})
+	i, _ := interactor.NewBuildCommandInteractor(injector)
+	i.Handle(&port.BuildCommandUseCaseInputData{})
+	if mockPres.summaryError != nil {
+		t.Fatalf("Summary() error = %v", mockPres.summaryError)
+	}
+
+	page := mockTemplate.saved["20240115.html"].(interactor.Content)
+	if page.Description != "What alice did on 2024-01-15." {
+		t.Errorf("Description = %q", page.Description)
+	}
+	if page.Og.Title != "15th / My nippo" || page.Og.ImageUrl != "https://example.com/images/og.png" {
+		t.Errorf("Og = %+v", page.Og)
+	}
+	if page.Site.Title != "My nippo" || page.Site.Language != "en" || page.Site.Description != core.DefaultSiteDescription {
+		t.Errorf("Site = %+v", page.Site)
+	}
+	index := mockTemplate.saved["index.html"].(interactor.Content)
+	if index.Og.Title != "My nippo" || index.Description != core.DefaultSiteDescription {
+		t.Errorf("index = %+v", index)
+	}
+
+	feed := string(mockFileProvider.written["feed.xml"])
+	for _, want := range []string{"<rss", "<title>My nippo</title>", "<language>en</language>", "<title>15th / My nippo</title>"} {
+		if !strings.Contains(feed, want) {
+			t.Errorf("feed.xml doesn't contain %q:\n%s", want, feed)
+		}
+	}
+	if strings.Count(feed, "<item>") != 1 {
+		t.Errorf("feed.xml has %d items, want 1", strings.Count(feed, "<item>"))
+	}
+
+	// A template with an unknown field stops the build before any page
+	core.Cfg.Site.PageTitle = "{{.Name}}"
+	mockTemplate.saved = nil
+	i.Handle(&port.BuildCommandUseCaseInputData{Force: true})
+	if mockPres.summaryError == nil || !strings.Contains(mockPres.summaryError.Error(), "page_title") {
+		t.Errorf("Summary() error = %v, want an invalid page_title error", mockPres.summaryError)
+	}
+	if len(mockTemplate.saved) != 0 {
+		t.Errorf("pages saved with an invalid page_title: %v", slices.Sorted(maps.Keys(mockTemplate.saved)))
+	}
+}
+
+func TestBuildCommandInteractor_Handle_Taxonomies(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	core.Cfg.Project.DriveFolderId = "test-folder-id"
+	core.Cfg.Project.SiteUrl = "https://example.com"
+
+	mockTemplate := &mockTemplateService{}
+	mockFileProvider := &mockLocalFileProvider{}
+	mockQuery := &mockLocalNippoQuery{nippos: []model.Nippo{
+		{Date: model.NewNippoDate("2024-01-14.md"), Content: []byte("---\ncategory: work\ntags: [Go, release]\n---\n# 14th")},
+		{Date: model.NewNippoDate("2024-01-15.md"), Content: []byte("---\ntags: go\n---\n# 15th")},
+		{Date: model.NewNippoDate("2024-01-16.md"), Content: []byte("# 16th")},
+	}}
+	mockAssetRepo := &mockAssetRepository{}
+	mockPres := &mockBuildCommandPresenter{}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		AssetRepository:       mockAssetRepo,
+		LocalNippoQuery:       mockQuery,
+		NippoFacade:           &mockNippoFacade{response: &service.NippoFacadeReponse{}},
+		TemplateService:       mockTemplate,
+		LocalFileProvider:     mockFileProvider,
+		BuildCommandPresenter: mockPres,
+	})
+	i, _ := interactor.NewBuildCommandInteractor(injector)
+	i.Handle(&port.BuildCommandUseCaseInputData{})
+	if mockPres.summaryError != nil {
+		t.Fatalf("Summary() error = %v", mockPres.summaryError)
+	}
+
+	tags := mockTemplate.saved["tags.html"].(interactor.Taxonomy)
+	wantTerms := []interactor.TermLink{{Name: "Go", Url: "/tags/go", Count: 2}, {Name: "release", Url: "/tags/release", Count: 1}}
+	if !slices.Equal(tags.Terms, wantTerms) {
+		t.Errorf("Terms = %+v, want %+v", tags.Terms, wantTerms)
+	}
+	goPage := mockTemplate.saved["go.html"].(interactor.Term)
+	wantEntries := []interactor.EntryLink{{Url: "/20240115", Date: "2024-01-15", Title: "15th"}, {Url: "/20240114", Date: "2024-01-14", Title: "14th"}}
+	if goPage.Url != "https://example.com/tags/go" || goPage.FeedUrl != "/tags/go.xml" || !slices.Equal(goPage.Entries, wantEntries) {
+		t.Errorf("page of go = %+v", goPage)
+	}
+	if _, ok := mockTemplate.saved["work.html"].(interactor.Term); !ok {
+		t.Error("the page of the category work wasn't saved")
+	}
+	day := mockTemplate.saved["20240114.html"].(interactor.Content)
+	wantDayTerms := []interactor.TermLink{{Name: "work", Url: "/categories/work", Count: 1}, {Name: "Go", Url: "/tags/go", Count: 2}, {Name: "release", Url: "/tags/release", Count: 1}}
+	if !slices.Equal(day.Terms, wantDayTerms) {
+		t.Errorf("Terms of the 14th = %+v, want %+v", day.Terms, wantDayTerms)
+	}
+
+	feed := string(mockFileProvider.written["feed.xml"])
+	for _, want := range []string{`<category term="work"></category>`, `<category term="Go"></category>`, `<category term="release"></category>`} {
+		if !strings.Contains(feed, want) {
+			t.Errorf("feed.xml doesn't contain %s:\n%s", want, feed)
+		}
+	}
+	if goFeed := string(mockFileProvider.written["go.xml"]); strings.Count(goFeed, "<entry>") != 2 {
+		t.Errorf("go.xml has %d entries, want 2:\n%s", strings.Count(goFeed, "<entry>"), goFeed)
+	}
+
+	// A tag no nippo has any more is removed with its feed
+	mockQuery.nippos[0].Content = []byte("# 14th")
+	i.Handle(&port.BuildCommandUseCaseInputData{})
+	if mockPres.summaryError != nil {
+		t.Fatalf("Summary() error = %v", mockPres.summaryError)
+	}
+	for _, want := range []string{"categories", "categories/work", "tags/release"} {
+		if !slices.Contains(mockAssetRepo.removedPages, want) {
+			t.Errorf("removed pages = %v, want %s", mockAssetRepo.removedPages, want)
+		}
+	}
+	if slices.Contains(mockAssetRepo.removedPages, "tags/go") {
+		t.Errorf("removed pages = %v, want tags/go kept", mockAssetRepo.removedPages)
+	}
+}
+
+func TestBuildCommandInteractor_Handle_EntryTitles(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	core.Cfg.Project.DriveFolderId = "test-folder-id"
+	core.Cfg.Project.SiteUrl = "https://example.com"
+	core.Cfg.Site.ExcerptLength = 10
+
+	mockTemplate := &mockTemplateService{}
+	mockFileProvider := &mockLocalFileProvider{}
+	mockPres := &mockBuildCommandPresenter{}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		AssetRepository: &mockAssetRepository{},
+		LocalNippoQuery: &mockLocalNippoQuery{nippos: []model.Nippo{
+			{Date: model.NewNippoDate("2024-01-14.md"), Content: []byte("---\ntitle: Release day\nsummary: Shipped v1.\n---\nNotes.\n")},
+			{Date: model.NewNippoDate("2024-01-15.md"), Content: []byte("# 雨の日\n\n今日は一日中雨が降っていたので家で本を読んだ。\n")},
+			{Date: model.NewNippoDate("2024-01-16.md"), Content: []byte("```\ncode only\n```\n")},
+		}},
+		NippoFacade:           &mockNippoFacade{response: &service.NippoFacadeReponse{}},
+		TemplateService:       mockTemplate,
+		LocalFileProvider:     mockFileProvider,
+		BuildCommandPresenter: mockPres,
+	})
+	i, _ := interactor.NewBuildCommandInteractor(injector)
+	i.Handle(&port.BuildCommandUseCaseInputData{})
+	if mockPres.summaryError != nil {
+		t.Fatalf("Summary() error = %v", mockPres.summaryError)
+	}
+
+	tests := []struct {
+		page            string
+		wantPageTitle   string
+		wantOgTitle     string
+		wantDescription string
+	}{
+		{"20240114.html", "Release day", "Release day / nippo", "Shipped v1."},
+		{"20240115.html", "雨の日", "雨の日 / nippo", "今日は一日中雨が降…"},
+		// Without a title and text, the date and day_description stand in
+		{"20240116.html", "2024-01-16", "2024-01-16 / nippo", "Daily report for 2024-01-16."},
+	}
+	for _, tt := range tests {
+		page := mockTemplate.saved[tt.page].(interactor.Content)
+		if page.PageTitle != tt.wantPageTitle || page.Og.Title != tt.wantOgTitle {
+			t.Errorf("%s: PageTitle = %q, Og.Title = %q, want %q and %q", tt.page, page.PageTitle, page.Og.Title, tt.wantPageTitle, tt.wantOgTitle)
+		}
+		if page.Description != tt.wantDescription || page.Og.Description != tt.wantDescription {
+			t.Errorf("%s: Description = %q, Og.Description = %q, want %q", tt.page, page.Description, page.Og.Description, tt.wantDescription)
+		}
+	}
+
+	feed := string(mockFileProvider.written["feed.xml"])
+	for _, want := range []string{"<title>Release day / nippo</title>", "<summary type=\"html\">Shipped v1.</summary>", "<title>雨の日 / nippo</title>"} {
+		if !strings.Contains(feed, want) {
+			t.Errorf("feed.xml doesn't contain %s:\n%s", want, feed)
+		}
+	}
+
+	archive := mockTemplate.saved["202401.html"].(interactor.Archive)
+	var titles []string
+	for _, week := range archive.Calender.Weeks {
+		for _, day := range week {
+			if day.HasContent {
+				titles = append(titles, day.Title)
+			}
+		}
+	}
+	if want := []string{"Release day", "雨の日", ""}; !slices.Equal(titles, want) {
+		t.Errorf("titles in the archive = %q, want %q", titles, want)
+	}
+}
+
+// BenchmarkBuildCommandInteractor_Handle renders every page of a synthetic site
+// of 5,000 nippo with the real templates, on one worker and then on the default
+// number of workers. The ratio of sequential to parallel is the speedup.
+func BenchmarkBuildCommandInteractor_Handle(b *testing.B) {
+	env := core.SetupTestEnv(b)
+	defer env.Cleanup()
+
+	core.Cfg.Project.DriveFolderId = "test-folder-id"
+	core.Cfg.Project.SiteUrl = "https://example.com"
+	templateDir := filepath.Join(env.DataDir, "templates")
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		b.Fatal(err)
+	}
+	templates := map[string]string{
+		"layout.html":   `{{define "layout"}}<html><head><title>{{.PageTitle}}</title></head><body>{{template "content" .}}</body></html>{{end}}`,
+		"index.html":    `{{define "index"}}<h1>{{.Date}}</h1>{{.Content}}{{end}}`,
+		"nippo.html":    `{{define "nippo"}}<h1>{{.Date}}</h1>{{.Content}}{{end}}`,
+		"calender.html": `{{define "calender"}}{{range .Calender.Weeks}}{{range .}}{{if .HasContent}}<a href="/{{.Date.PathString}}">{{.Count}}</a>{{end}}{{end}}{{end}}{{end}}`,
+	}
+	for name, content := range templates {
+		if err := os.WriteFile(filepath.Join(templateDir, name), []byte(content), 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	start := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	nippos := make([]model.Nippo, 5000)
+	for n := range nippos {
+		date := start.AddDate(0, 0, n)
+		nippos[n] = model.Nippo{
+			Date: model.NewNippoDate(date.Format("2006-01-02") + ".md"),
+			Content: fmt.Appendf(nil, "---\ncreated: %s\ntags: [daily]\n---\n# %s\n\n%s\n\n- [x] done\n- [ ] todo\n\n```go\nfmt.Println(%d)\n```\n",
+				date.Format(time.RFC3339), date.Format("Jan 2"), strings.Repeat("Some *notes* with a [link](https://example.com). ", 20), n),
+		}
+	}
+
+	for _, bm := range []struct {
+		name        string
+		concurrency int
+	}{
+		{"sequential", 1},
+		{"parallel", 0},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			core.Cfg.Build.Concurrency = bm.concurrency
+			mockPres := &mockBuildCommandPresenter{}
+			injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+				AssetRepository:       &mockAssetRepository{},
+				LocalNippoQuery:       &mockLocalNippoQuery{nippos: nippos},
+				NippoFacade:           &mockNippoFacade{response: &service.NippoFacadeReponse{}},
+				LocalFileProvider:     &mockLocalFileProvider{},
+				BuildCommandPresenter: mockPres,
+			})
+			i, _ := interactor.NewBuildCommandInteractor(injector)
+			for b.Loop() {
+				i.Handle(&port.BuildCommandUseCaseInputData{Force: true})
+				if mockPres.summaryError != nil {
+					b.Fatalf("Summary() error = %v", mockPres.summaryError)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildCommandInteractor_Handle_SyncChanges(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	core.Cfg.Project.DriveFolderId = "test-folder-id"
+	core.Cfg.Project.SiteUrl = "https://example.com"
+
+	mockAssetRepo := &mockAssetRepository{cleanBuildCacheErr: fmt.Errorf("clean error")}
+	mockNippoService := &mockNippoFacade{response: &service.NippoFacadeReponse{
+		Result: &service.NippoFacadeResponseResult{
+			Changes: []model.SyncChange{
+				{Type: model.SyncChangeAdded, FileId: "file1", Name: "2024-01-15.md"},
+				{Type: model.SyncChangeRenamed, FileId: "file2", Name: "2024-01-17.md", OldName: "2024-01-16.md"},
+				{Type: model.SyncChangeDeleted, FileId: "file3", Name: "2024-01-18.md"},
+			},
+			Failed: []model.SyncChange{
+				{Type: model.SyncChangeUpdated, FileId: "file4", Name: "2024-01-19.md"},
+			},
+		},
+	}}
+	mockPres := &mockBuildCommandPresenter{}
+
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		AssetRepository:       mockAssetRepo,
+		LocalNippoQuery:       &mockLocalNippoQuery{},
+		NippoFacade:           mockNippoService,
+		TemplateService:       &mockTemplateService{},
+		LocalFileProvider:     &mockLocalFileProvider{},
+		BuildCommandPresenter: mockPres,
+	})
+
+	i, _ := interactor.NewBuildCommandInteractor(injector)
+	i.Handle(&port.BuildCommandUseCaseInputData{})
+
+	if !mockPres.summaryCalled {
+		t.Fatal("Summary() was not called")
+	}
+	if len(mockPres.summaryChanged) != 3 {
+		t.Fatalf("Summary() got %d changed files, want 3", len(mockPres.summaryChanged))
+	}
+	renamed := mockPres.summaryChanged[1]
+	if renamed.Kind != "renamed" || renamed.OldName != "2024-01-16.md" || renamed.Id != "file2" {
+		t.Errorf("renamed change = %+v", renamed)
+	}
+	if mockPres.summaryChanged[2].Kind != "deleted" {
+		t.Errorf("deleted change kind = %q", mockPres.summaryChanged[2].Kind)
+	}
+	if len(mockPres.summaryFailed) != 1 || mockPres.summaryFailed[0].Name != "2024-01-19.md" {
+		t.Errorf("Summary() failed files = %+v", mockPres.summaryFailed)
+	}
+}
+
+// Test BuildCommandInteractor syncing from a local source directory end to end, without Google Drive
+func TestBuildCommandInteractor_Handle_LocalSource(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	sourceDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(sourceDir, "2024"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "2024", "2024-01-15.md"), []byte("# 15"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "2024-01-16.md"), []byte("# 16"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	core.Cfg.Source.Type = core.SourceTypeLocal
+	core.Cfg.Source.Path = sourceDir
+	core.Cfg.Project.SiteUrl = "https://example.com"
+
+	build := func() *mockBuildCommandPresenter {
+		mockPres := &mockBuildCommandPresenter{}
+		injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+			// Stop right after the sync, the site itself is not rendered here
+			AssetRepository:       &mockAssetRepository{cleanBuildCacheErr: fmt.Errorf("stop after sync")},
+			TemplateService:       &mockTemplateService{},
+			BuildCommandPresenter: mockPres,
+		})
+		i, err := interactor.NewBuildCommandInteractor(injector)
+		if err != nil {
+			t.Fatalf("NewBuildCommandInteractor() error = %v", err)
+		}
+		i.Handle(&port.BuildCommandUseCaseInputData{})
+		if mockPres.suspendCalled {
+			t.Fatal("Suspend() should not be called")
+		}
+		return mockPres
+	}
+
+	first := build()
+	if len(first.summaryChanged) != 2 {
+		t.Fatalf("first build changed %d files, want 2: %+v", len(first.summaryChanged), first.summaryChanged)
+	}
+	cached, err := os.ReadFile(filepath.Join(env.CacheDir, "md", "2024-01-15.md"))
+	if err != nil || string(cached) != "# 15" {
+		t.Fatalf("cached nippo = %q, %v", cached, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(sourceDir, "2024", "2024-01-15.md"), []byte("# 15 edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(sourceDir, "2024-01-16.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	second := build()
+	kinds := map[string]string{}
+	for _, change := range second.summaryChanged {
+		kinds[change.Name] = change.Kind
+	}
+	if kinds["2024-01-15.md"] != "updated" || kinds["2024-01-16.md"] != "deleted" || len(kinds) != 2 {
+		t.Errorf("second build changes = %+v", second.summaryChanged)
+	}
+	if _, err := os.Stat(filepath.Join(env.CacheDir, "md", "2024-01-16.md")); !os.IsNotExist(err) {
+		t.Error("deleted nippo should be removed from the cache")
+	}
+}
+
 // Test BuildCommandInteractor when CleanBuildCache fails
 func TestBuildCommandInteractor_Handle_CleanBuildCacheError(t *testing.T) {
 	env := core.SetupTestEnv(t)
@@ -1453,13 +2697,54 @@ func TestBuildCommandInteractor_Handle_CleanBuildCacheError(t *testing.T) {
 	}
 }
 
-// Test AuthInteractor Handle with missing data directory
-func TestAuthInteractor_Handle_CreateDataDirError(t *testing.T) {
+// Test AuthInteractor Handle with missing data directory
+func TestAuthInteractor_Handle_CreateDataDirError(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	// Use an invalid path that can't be created
+	core.Cfg.Paths.DataDir = "/nonexistent/path/that/cannot/be/created/deep/nested"
+
+	mockPres := &mockAuthPresenter{}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		AuthPresenter: mockPres,
+	})
+
+	i, _ := interactor.NewAuthInteractor(injector)
+	i.Handle(&port.AuthUseCaseInputData{})
+
+	if !mockPres.suspendCalled {
+		t.Error("Suspend() should be called when data dir creation fails")
+	}
+}
+
+// Test AuthInteractor Handle with valid data dir but no credentials
+func TestAuthInteractor_Handle_NoCredentials(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	// DataDir is set by SetupTestEnv, but no credentials file exists
+
+	mockPres := &mockAuthPresenter{}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		AuthPresenter: mockPres,
+	})
+
+	i, _ := interactor.NewAuthInteractor(injector)
+	i.Handle(&port.AuthUseCaseInputData{})
+
+	if !mockPres.suspendCalled {
+		t.Error("Suspend() should be called when credentials.json is missing")
+	}
+}
+
+// Test AuthInteractor Handle with invalid credentials file
+func TestAuthInteractor_Handle_InvalidCredentials(t *testing.T) {
 	env := core.SetupTestEnv(t)
 	defer env.Cleanup()
 
-	// Use an invalid path that can't be created
-	core.Cfg.Paths.DataDir = "/nonexistent/path/that/cannot/be/created/deep/nested"
+	// Create invalid credentials file
+	env.CreateCredentialsFile(t, "invalid json")
 
 	mockPres := &mockAuthPresenter{}
 	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
@@ -1470,37 +2755,96 @@ func TestAuthInteractor_Handle_CreateDataDirError(t *testing.T) {
 	i.Handle(&port.AuthUseCaseInputData{})
 
 	if !mockPres.suspendCalled {
-		t.Error("Suspend() should be called when data dir creation fails")
+		t.Error("Suspend() should be called when credentials.json is invalid")
 	}
 }
 
-// Test AuthInteractor Handle with valid data dir but no credentials
-func TestAuthInteractor_Handle_NoCredentials(t *testing.T) {
+func TestAuthStatusInteractor_Handle(t *testing.T) {
 	env := core.SetupTestEnv(t)
 	defer env.Cleanup()
 
-	// DataDir is set by SetupTestEnv, but no credentials file exists
+	expiry := time.Date(2024, 1, 15, 21, 0, 0, 0, time.UTC)
+	provider := &mockGoogleAuthProvider{status: &gateway.GoogleAuthStatus{
+		Mode:            gateway.AuthModeOAuth,
+		Path:            "/data/token.json",
+		Authenticated:   true,
+		Account:         "nippo@example.com",
+		Scopes:          []string{"https://www.googleapis.com/auth/drive"},
+		Expiry:          expiry,
+		HasRefreshToken: true,
+	}}
+	textPres := &mockAuthStatusPresenter{}
+	jsonPres := &mockAuthStatusPresenter{}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		GoogleAuthProvider:  provider,
+		AuthStatusPresenter: textPres,
+	})
+	do.OverrideNamed(injector, presenter.AuthStatusJSONPresenterName, func(do.Injector) (presenter.AuthStatusPresenter, error) {
+		return jsonPres, nil
+	})
 
-	mockPres := &mockAuthPresenter{}
+	i, err := interactor.NewAuthStatusInteractor(injector)
+	if err != nil {
+		t.Fatalf("NewAuthStatusInteractor() error = %v", err)
+	}
+	i.Handle(&port.AuthStatusUseCaseInputData{})
+	if textPres.output == nil || jsonPres.output != nil {
+		t.Fatal("Handle() should show the status with the text presenter")
+	}
+	output := textPres.output
+	if output.Mode != string(gateway.AuthModeOAuth) || output.Account != "nippo@example.com" || !output.Expiry.Equal(expiry) || !output.HasRefreshToken {
+		t.Errorf("output = %+v", output)
+	}
+
+	i.Handle(&port.AuthStatusUseCaseInputData{JSON: true})
+	if jsonPres.output == nil {
+		t.Error("Handle() with JSON should show the status with the JSON presenter")
+	}
+
+	provider.err = fmt.Errorf("unable to read token")
+	i.Handle(&port.AuthStatusUseCaseInputData{})
+	if textPres.suspendErr == nil {
+		t.Error("Suspend() should be called when the status cannot be read")
+	}
+}
+
+func TestAuthLogoutInteractor_Handle(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	provider := &mockGoogleAuthProvider{loggedOut: true}
+	mockPres := &mockAuthLogoutPresenter{}
 	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
-		AuthPresenter: mockPres,
+		GoogleAuthProvider:  provider,
+		AuthLogoutPresenter: mockPres,
 	})
 
-	i, _ := interactor.NewAuthInteractor(injector)
-	i.Handle(&port.AuthUseCaseInputData{})
+	i, err := interactor.NewAuthLogoutInteractor(injector)
+	if err != nil {
+		t.Fatalf("NewAuthLogoutInteractor() error = %v", err)
+	}
+	i.Handle(&port.AuthLogoutUseCaseInputData{JSON: true})
+	if mockPres.output == nil || !mockPres.output.LoggedOut {
+		t.Fatalf("output = %+v, want logged out", mockPres.output)
+	}
+	if mockPres.output.Path != filepath.Join(core.Cfg.GetDataDir(), "token.json") {
+		t.Errorf("Path = %q, want token.json in the data dir", mockPres.output.Path)
+	}
 
-	if !mockPres.suspendCalled {
-		t.Error("Suspend() should be called when credentials.json is missing")
+	provider.err = fmt.Errorf("unable to revoke token")
+	i.Handle(&port.AuthLogoutUseCaseInputData{})
+	if mockPres.suspendErr == nil {
+		t.Error("Suspend() should be called when the token cannot be revoked")
 	}
 }
 
-// Test AuthInteractor Handle with invalid credentials file
-func TestAuthInteractor_Handle_InvalidCredentials(t *testing.T) {
+// Test AuthInteractor Handle with a service account key that does not exist
+func TestAuthInteractor_Handle_ServiceAccountKeyNotFound(t *testing.T) {
 	env := core.SetupTestEnv(t)
 	defer env.Cleanup()
 
-	// Create invalid credentials file
-	env.CreateCredentialsFile(t, "invalid json")
+	// No credentials.json is needed for a service account
+	core.Cfg.Auth.ServiceAccountKey = filepath.Join(t.TempDir(), "missing.json")
 
 	mockPres := &mockAuthPresenter{}
 	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
@@ -1511,7 +2855,10 @@ func TestAuthInteractor_Handle_InvalidCredentials(t *testing.T) {
 	i.Handle(&port.AuthUseCaseInputData{})
 
 	if !mockPres.suspendCalled {
-		t.Error("Suspend() should be called when credentials.json is invalid")
+		t.Error("Suspend() should be called when the service account key is missing")
+	}
+	if mockPres.completeCalled {
+		t.Error("Complete() should not be called when the service account key is missing")
 	}
 }
 
@@ -1544,6 +2891,166 @@ func TestDoctorInteractor_Handle_AllChecks(t *testing.T) {
 	}
 }
 
+func TestDoctorInteractor_Handle_LocalSource(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	env.CreateConfigFile(t, "[source]\ntype = \"local\"")
+	sourceDir := t.TempDir()
+	core.Cfg.Source.Type = core.SourceTypeLocal
+	core.Cfg.Source.Path = sourceDir
+
+	mockPres := &mockDoctorPresenter{}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		DoctorPresenter: mockPres,
+	})
+
+	i, _ := interactor.NewDoctorInteractor(injector)
+	i.Handle(&port.DoctorUseCaseInputData{})
+
+	checks := map[string]port.DoctorCheck{}
+	for _, check := range mockPres.output.Checks {
+		checks[check.Item] = check
+	}
+	if check, ok := checks["Source folder"]; !ok || check.Status != port.DoctorCheckStatusPass || check.Message != sourceDir {
+		t.Errorf("Source folder check = %+v", check)
+	}
+	for _, item := range []string{"Drive folder", "credentials.json", "token.json"} {
+		if _, ok := checks[item]; ok {
+			t.Errorf("%s should not be checked for a local source", item)
+		}
+	}
+}
+
+func TestDoctorInteractor_Handle_GitSourceNotRepository(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	env.CreateConfigFile(t, "[source]\ntype = \"git\"")
+	core.Cfg.Source.Type = core.SourceTypeGit
+	core.Cfg.Source.Path = t.TempDir()
+
+	mockPres := &mockDoctorPresenter{}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		DoctorPresenter: mockPres,
+	})
+
+	i, _ := interactor.NewDoctorInteractor(injector)
+	i.Handle(&port.DoctorUseCaseInputData{})
+
+	found := false
+	for _, check := range mockPres.output.Checks {
+		if check.Item == "credentials.json" {
+			t.Error("credentials.json should not be checked for a git source")
+		}
+		if check.Item == "Source repository" {
+			found = true
+			if check.Status != port.DoctorCheckStatusFail {
+				t.Errorf("Source repository check = %+v, want a failure", check)
+			}
+		}
+	}
+	if !found {
+		t.Error("Source repository should be checked")
+	}
+}
+
+func TestDoctorInteractor_Handle_AuthMode(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	env.CreateConfigFile(t, "[project]\ndrive_folder_id = \"test\"")
+
+	mockPres := &mockDoctorPresenter{}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		DoctorPresenter: mockPres,
+	})
+	i, _ := interactor.NewDoctorInteractor(injector)
+	i.Handle(&port.DoctorUseCaseInputData{})
+
+	checks := map[string]port.DoctorCheck{}
+	for _, check := range mockPres.output.Checks {
+		checks[check.Item] = check
+	}
+	if check := checks["Auth mode"]; check.Message != string(gateway.AuthModeOAuth) {
+		t.Errorf("Auth mode check = %+v, want %q", check, gateway.AuthModeOAuth)
+	}
+	if _, ok := checks["credentials.json"]; !ok {
+		t.Error("credentials.json should be checked for an OAuth token")
+	}
+
+	// A service account key replaces credentials.json and token.json
+	keyPath := filepath.Join(t.TempDir(), "service-account.json")
+	if err := os.WriteFile(keyPath, []byte("invalid json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	core.Cfg.Auth.ServiceAccountKey = keyPath
+	i.Handle(&port.DoctorUseCaseInputData{})
+
+	checks = map[string]port.DoctorCheck{}
+	for _, check := range mockPres.output.Checks {
+		checks[check.Item] = check
+	}
+	if check := checks["Auth mode"]; check.Message != string(gateway.AuthModeServiceAccount) {
+		t.Errorf("Auth mode check = %+v, want %q", check, gateway.AuthModeServiceAccount)
+	}
+	if check, ok := checks["Service account key"]; !ok || check.Status != port.DoctorCheckStatusFail {
+		t.Errorf("Service account key check = %+v, want a failure for an invalid key", check)
+	}
+	for _, item := range []string{"credentials.json", "token.json"} {
+		if _, ok := checks[item]; ok {
+			t.Errorf("%s should not be checked for a service account", item)
+		}
+	}
+}
+
+func TestDoctorInteractor_Handle_TokenScopes(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+
+	env.CreateConfigFile(t, "[project]\ndrive_folder_id = \"test\"")
+	dataDir := core.Cfg.GetDataDir()
+
+	mockPres := &mockDoctorPresenter{}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		DoctorPresenter: mockPres,
+	})
+	i, _ := interactor.NewDoctorInteractor(injector)
+
+	tests := []struct {
+		scope  string
+		status port.DoctorCheckStatus
+	}{
+		{"", port.DoctorCheckStatusWarn},
+		{"https://www.googleapis.com/auth/drive.readonly", port.DoctorCheckStatusPass},
+		{"https://www.googleapis.com/auth/drive", port.DoctorCheckStatusWarn},
+		{"https://www.googleapis.com/auth/drive.readonly https://www.googleapis.com/auth/gmail.readonly", port.DoctorCheckStatusWarn},
+	}
+	for _, tt := range tests {
+		tok := &oauth2.Token{AccessToken: "access"}
+		if tt.scope != "" {
+			tok = tok.WithExtra(map[string]any{"scope": tt.scope})
+		}
+		if err := gateway.SaveToken(dataDir, gateway.TokenPath(dataDir), tok); err != nil {
+			t.Fatal(err)
+		}
+		i.Handle(&port.DoctorUseCaseInputData{})
+
+		var found bool
+		for _, check := range mockPres.output.Checks {
+			if check.Item == "Token scopes" {
+				found = true
+				if check.Status != tt.status {
+					t.Errorf("scope %q: Token scopes check = %+v, want status %v", tt.scope, check, tt.status)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("scope %q: Token scopes should be checked", tt.scope)
+		}
+	}
+}
+
 // Test DeployCommandInteractor - vercel command not available in tests
 func TestDeployCommandInteractor_Handle_VercelNotInstalled(t *testing.T) {
 	env := core.SetupTestEnv(t)
@@ -1612,6 +3119,10 @@ func TestInitSettingInteractor_Handle_NewConfig(t *testing.T) {
 			"main",                 // Branch
 			"template",             // TemplatePath
 			"static",               // AssetPath
+			"My nippo",             // Site title
+			"alice",                // Author
+			"",                     // Description
+			"en",                   // Language
 		},
 	}
 
@@ -1627,6 +3138,13 @@ func TestInitSettingInteractor_Handle_NewConfig(t *testing.T) {
 	if !mockPres.promptCalled {
 		t.Error("Prompt() was not called")
 	}
+	site := core.Cfg.Site
+	if site.Title != "My nippo" || site.Author != "alice" || site.Language != "en" {
+		t.Errorf("site settings = %+v, want the answers", site)
+	}
+	if site.Description != core.DefaultSiteDescription || site.OgpImage != core.DefaultSiteOgpImage {
+		t.Errorf("site settings = %+v, want the defaults for the rest", site)
+	}
 }
 
 // Test FormatCommandInteractor with file that has missing created field
@@ -1650,6 +3168,7 @@ func TestFormatCommandInteractor_Handle_MissingCreatedField(t *testing.T) {
 	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
 		RemoteNippoQuery:       mockRemoteQuery,
 		FormatCommandPresenter: mockPres,
+		FormatPlanPresenter:    &mockFormatPlanPresenter{},
 	})
 
 	i, _ := interactor.NewFormatCommandInteractor(injector)
@@ -1659,3 +3178,542 @@ func TestFormatCommandInteractor_Handle_MissingCreatedField(t *testing.T) {
 		t.Error("Summary() was not called")
 	}
 }
+
+func newTestCreateInteractor(t *testing.T, query *mockRemoteNippoQuery) (port.CreateCommandUseCase, *mockCreateCommandPresenter) {
+	t.Helper()
+	mockPres := &mockCreateCommandPresenter{}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		RemoteNippoQuery:       query,
+		DriveFileProvider:      &retryDriveFileProvider{},
+		CreateCommandPresenter: mockPres,
+	})
+	i, err := interactor.NewCreateCommandInteractor(injector)
+	if err != nil {
+		t.Fatalf("NewCreateCommandInteractor() error = %v", err)
+	}
+	return i, mockPres
+}
+
+func TestCreateCommandInteractor_Handle(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+	core.Cfg.Project.DriveFolderId = "root"
+	core.Cfg.New.Subfolder = core.NewSubfolderMonth
+
+	template := "---\ntags: [daily]\n---\n\n# {{.Date}} {{.Weekday}}\n"
+	if err := os.WriteFile(filepath.Join(env.DataDir, "new.md"), []byte(template), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	query := &mockRemoteNippoQuery{}
+	i, mockPres := newTestCreateInteractor(t, query)
+	i.Handle(&port.CreateCommandUseCaseInputData{Date: "2024-01-15"})
+
+	if mockPres.err != nil {
+		t.Fatalf("Handle() error = %v", mockPres.err)
+	}
+	if len(query.created) != 1 || query.createdPaths[0] != "2024/01/2024-01-15.md" {
+		t.Fatalf("created = %v at %v, want one nippo in 2024/01", query.created, query.createdPaths)
+	}
+	content := string(query.created[0].Content)
+	fm, body, err := model.ParseFrontMatter(query.created[0].Content)
+	if err != nil || fm == nil {
+		t.Fatalf("ParseFrontMatter(%q) = %v, %v", content, fm, err)
+	}
+	if created := fm.Created.Local(); created.Year() != 2024 || created.Month() != 1 || created.Day() != 15 {
+		t.Errorf("created = %v, want the date of the nippo", fm.Created)
+	}
+	if fm.Raw["tags"] == nil || string(body) != "# 2024-01-15 Monday\n" {
+		t.Errorf("content = %q, want the rendered template", content)
+	}
+	if mockPres.output == nil || mockPres.output.Message != "Created 2024/01/2024-01-15.md" || mockPres.output.FileId != "new-id" {
+		t.Errorf("output = %+v", mockPres.output)
+	}
+}
+
+func TestCreateCommandInteractor_Handle_DefaultsToToday(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+	core.Cfg.Project.DriveFolderId = "root"
+
+	query := &mockRemoteNippoQuery{}
+	i, mockPres := newTestCreateInteractor(t, query)
+	i.Handle(&port.CreateCommandUseCaseInputData{})
+
+	if mockPres.err != nil {
+		t.Fatalf("Handle() error = %v", mockPres.err)
+	}
+	today := time.Now().Format("2006-01-02")
+	if len(query.created) != 1 || query.created[0].Date.FileString() != today || query.createdPaths[0] != today+".md" {
+		t.Fatalf("created = %v at %v, want today's nippo in the source folder", query.created, query.createdPaths)
+	}
+	// Without new.md, the default template is used
+	if !strings.Contains(string(query.created[0].Content), "# "+query.created[0].Date.TitleString()) {
+		t.Errorf("content = %q, want the default template", query.created[0].Content)
+	}
+}
+
+func TestCreateCommandInteractor_Handle_NamePatterns(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+	core.Cfg.Project.DriveFolderId = "root"
+	core.Cfg.Source.NamePatterns = []string{"YYYY/MM/DD", "YYYY-MM-DD"}
+
+	// Another nippo of the day, named with a suffix, is no reason to stop
+	evening := model.Nippo{Date: model.NewNippoDate("2024-01-15_evening.md"), RemoteFile: &drive.File{Id: "evening-id", Name: "2024-01-15_evening.md"}}
+	query := &mockRemoteNippoQuery{nippos: []model.Nippo{evening}}
+	i, mockPres := newTestCreateInteractor(t, query)
+	i.Handle(&port.CreateCommandUseCaseInputData{Date: "2024-01-15"})
+
+	if mockPres.err != nil {
+		t.Fatalf("Handle() error = %v", mockPres.err)
+	}
+	if len(query.createdPaths) != 1 || query.createdPaths[0] != "2024/01/15.md" {
+		t.Errorf("createdPaths = %v, want the first name pattern", query.createdPaths)
+	}
+
+	// A nippo of the date named with another pattern already exists
+	existing := model.Nippo{Date: model.NewNippoDate("2024-01-16.md"), RemoteFile: &drive.File{Id: "existing-id", Name: "16.md"}}
+	query = &mockRemoteNippoQuery{nippos: []model.Nippo{existing}}
+	i, mockPres = newTestCreateInteractor(t, query)
+	i.Handle(&port.CreateCommandUseCaseInputData{Date: "2024-01-16"})
+
+	if !errors.Is(mockPres.err, repository.ErrNippoExists) || len(query.created) != 0 {
+		t.Errorf("Handle() error = %v with %v created, want ErrNippoExists", mockPres.err, query.created)
+	}
+}
+
+func TestCreateCommandInteractor_Handle_Errors(t *testing.T) {
+	env := core.SetupTestEnv(t)
+	defer env.Cleanup()
+	core.Cfg.Project.DriveFolderId = "root"
+
+	existing := model.Nippo{RemoteFile: &drive.File{Id: "existing-id", Name: "2024-01-15.md"}}
+	tests := []struct {
+		name    string
+		date    string
+		query   *mockRemoteNippoQuery
+		setup   func()
+		wantErr error
+	}{
+		{name: "invalid date", date: "2024-13-01", query: &mockRemoteNippoQuery{}},
+		{name: "existing nippo", date: "2024-01-15", query: &mockRemoteNippoQuery{nippos: []model.Nippo{existing}}, wantErr: repository.ErrNippoExists},
+		{name: "create conflict", date: "2024-01-16", query: &mockRemoteNippoQuery{createErr: repository.ErrNippoExists}, wantErr: repository.ErrNippoExists},
+		{name: "missing configured template", date: "2024-01-16", query: &mockRemoteNippoQuery{}, setup: func() {
+			core.Cfg.New.Template = "missing.md"
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+			i, mockPres := newTestCreateInteractor(t, tt.query)
+			i.Handle(&port.CreateCommandUseCaseInputData{Date: tt.date})
+
+			if mockPres.err == nil {
+				t.Fatal("Handle() expected error")
+			}
+			if tt.wantErr != nil && !errors.Is(mockPres.err, tt.wantErr) {
+				t.Errorf("Handle() error = %v, want %v", mockPres.err, tt.wantErr)
+			}
+			if len(tt.query.created) != 0 {
+				t.Errorf("created = %v, want nothing", tt.query.created)
+			}
+		})
+	}
+}
+
+func TestNewCreateCommandInteractor_RequiresWriteAccess(t *testing.T) {
+	mockDrive := &retryDriveFileProvider{}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		RemoteNippoQuery:       &mockRemoteNippoQuery{},
+		DriveFileProvider:      mockDrive,
+		CreateCommandPresenter: &mockCreateCommandPresenter{},
+	})
+	if _, err := interactor.NewCreateCommandInteractor(injector); err != nil {
+		t.Fatalf("NewCreateCommandInteractor() error = %v", err)
+	}
+	if mockDrive.access != gateway.DriveReadWrite {
+		t.Errorf("access = %v, want read-write", mockDrive.access)
+	}
+}
+
+// workdirRemoteNippoQuery keeps remote nippo with their content, so the
+// working directory can be pulled from and pushed to it
+type workdirRemoteNippoQuery struct {
+	mockRemoteNippoQuery
+	files    map[string]*drive.File
+	contents map[string][]byte
+	updated  []string
+}
+
+func newWorkdirRemoteNippoQuery(contents map[string]string) *workdirRemoteNippoQuery {
+	m := &workdirRemoteNippoQuery{files: map[string]*drive.File{}, contents: map[string][]byte{}}
+	for name, content := range contents {
+		m.put(name, content)
+	}
+	return m
+}
+
+// put adds or changes the remote file at filePath, bumping its version as Drive does
+func (m *workdirRemoteNippoQuery) put(filePath, content string) {
+	file, ok := m.files[filePath]
+	if !ok {
+		file = &drive.File{Id: "id-" + filePath, Name: path.Base(filePath)}
+		m.files[filePath] = file
+	}
+	file.Md5Checksum = model.ContentMd5([]byte(content))
+	file.Version++
+	m.contents[filePath] = []byte(content)
+}
+
+func (m *workdirRemoteNippoQuery) List(param *repository.QueryListParam, option *repository.QueryListOption) ([]model.Nippo, error) {
+	naming, err := m.Naming()
+	if err != nil {
+		return nil, err
+	}
+	var nippoList []model.Nippo
+	for filePath, file := range m.files {
+		copied := *file
+		date, _ := naming.Parse(filePath)
+		nippoList = append(nippoList, model.Nippo{Date: date, RemoteFile: &copied, RemotePath: filePath})
+	}
+	return nippoList, nil
+}
+
+func (m *workdirRemoteNippoQuery) Download(nippo *model.Nippo) error {
+	nippo.Content = m.contents[strings.TrimPrefix(nippo.RemoteFile.Id, "id-")]
+	return nil
+}
+
+func (m *workdirRemoteNippoQuery) Update(nippo *model.Nippo, content []byte) error {
+	filePath := strings.TrimPrefix(nippo.RemoteFile.Id, "id-")
+	m.put(filePath, string(content))
+	m.updated = append(m.updated, filePath)
+	return nil
+}
+
+func (m *workdirRemoteNippoQuery) Create(folder, filePath string, nippo *model.Nippo) error {
+	if err := m.mockRemoteNippoQuery.Create(folder, filePath, nippo); err != nil {
+		return err
+	}
+	m.put(filePath, string(nippo.Content))
+	return nil
+}
+
+func setupWorkdirTest(t *testing.T) (string, func()) {
+	t.Helper()
+	env := core.SetupTestEnv(t)
+	core.Cfg.Project.DriveFolderId = "root"
+	core.Cfg.Workdir.Path = filepath.Join(t.TempDir(), "nippo")
+	return core.Cfg.Workdir.Path, env.Cleanup
+}
+
+func runPull(t *testing.T, query repository.RemoteNippoQuery) *mockPullCommandPresenter {
+	t.Helper()
+	mockPres := &mockPullCommandPresenter{}
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		RemoteNippoQuery:     query,
+		DriveFileProvider:    &retryDriveFileProvider{},
+		PullCommandPresenter: mockPres,
+	})
+	i, err := interactor.NewPullCommandInteractor(injector)
+	if err != nil {
+		t.Fatalf("NewPullCommandInteractor() error = %v", err)
+	}
+	i.Handle(&port.PullCommandUseCaseInputData{})
+	if mockPres.err != nil {
+		t.Fatalf("pull error = %v", mockPres.err)
+	}
+	return mockPres
+}
+
+func runPush(t *testing.T, query repository.RemoteNippoQuery, mockPres *mockPushCommandPresenter, onConflict port.ConflictResolution) {
+	t.Helper()
+	injector := inject.NewTestInjector(&inject.TestBasePackageOptions{
+		RemoteNippoQuery:     query,
+		DriveFileProvider:    &retryDriveFileProvider{},
+		PushCommandPresenter: mockPres,
+	})
+	i, err := interactor.NewPushCommandInteractor(injector)
+	if err != nil {
+		t.Fatalf("NewPushCommandInteractor() error = %v", err)
+	}
+	i.Handle(&port.PushCommandUseCaseInputData{OnConflict: onConflict})
+	if mockPres.err != nil {
+		t.Fatalf("push error = %v", mockPres.err)
+	}
+}
+
+func readWorkdirFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func workdirActions(files []port.WorkdirFileResult) map[string]port.WorkdirFileAction {
+	actions := map[string]port.WorkdirFileAction{}
+	for _, file := range files {
+		actions[file.Name] = file.Action
+	}
+	return actions
+}
+
+func TestPullCommandInteractor_Handle(t *testing.T) {
+	dir, cleanup := setupWorkdirTest(t)
+	defer cleanup()
+
+	query := newWorkdirRemoteNippoQuery(map[string]string{
+		"2024-01-15.md": "first\n",
+		"2024-01-16.md": "second\n",
+		"2024-01-17.md": "third\n",
+	})
+	mockPres := runPull(t, query)
+	if got := readWorkdirFile(t, dir, "2024-01-15.md"); got != "first\n" {
+		t.Errorf("2024-01-15.md = %q", got)
+	}
+	if len(mockPres.output.Files) != 3 {
+		t.Errorf("files = %+v, want 3 downloaded", mockPres.output.Files)
+	}
+
+	// Pulling again downloads nothing
+	mockPres = runPull(t, query)
+	if len(mockPres.output.Files) != 0 || !strings.Contains(mockPres.output.Message, "already up to date") {
+		t.Errorf("output = %+v, want nothing pulled", mockPres.output)
+	}
+
+	// A remote change is pulled, local edits are kept and remote deletions are mirrored
+	query.put("2024-01-15.md", "first, edited remotely\n")
+	query.put("2024-01-16.md", "second, edited remotely\n")
+	delete(query.files, "2024-01-17.md")
+	if err := os.WriteFile(filepath.Join(dir, "2024-01-16.md"), []byte("second, edited locally\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mockPres = runPull(t, query)
+
+	want := map[string]port.WorkdirFileAction{
+		"2024-01-15.md": port.WorkdirFileDownloaded,
+		"2024-01-16.md": port.WorkdirFileKept,
+		"2024-01-17.md": port.WorkdirFileRemoved,
+	}
+	if got := workdirActions(mockPres.output.Files); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("actions = %v, want %v", got, want)
+	}
+	if got := readWorkdirFile(t, dir, "2024-01-15.md"); got != "first, edited remotely\n" {
+		t.Errorf("2024-01-15.md = %q, want the remote change", got)
+	}
+	if got := readWorkdirFile(t, dir, "2024-01-16.md"); got != "second, edited locally\n" {
+		t.Errorf("2024-01-16.md = %q, want the local edit", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2024-01-17.md")); !os.IsNotExist(err) {
+		t.Errorf("2024-01-17.md should be removed, got %v", err)
+	}
+}
+
+func TestPushCommandInteractor_Handle(t *testing.T) {
+	dir, cleanup := setupWorkdirTest(t)
+	defer cleanup()
+	core.Cfg.New.Subfolder = core.NewSubfolderYear
+
+	query := newWorkdirRemoteNippoQuery(map[string]string{
+		"2024-01-15.md": "first\n",
+		"2024-01-16.md": "second\n",
+	})
+	runPull(t, query)
+
+	writeFile := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("2024-01-15.md", "first, edited locally\n")
+	writeFile("2024-01-18.md", "new\n")
+	writeFile("2024-01-18_evening.md", "new, later that day\n")
+	writeFile("memo.md", "not a nippo\n")
+
+	mockPres := &mockPushCommandPresenter{}
+	runPush(t, query, mockPres, port.ConflictAsk)
+
+	want := map[string]port.WorkdirFileAction{
+		"2024-01-15.md":              port.WorkdirFileUploaded,
+		"2024/2024-01-18.md":         port.WorkdirFileCreated,
+		"2024/2024-01-18_evening.md": port.WorkdirFileCreated,
+		"memo.md":                    port.WorkdirFileSkipped,
+	}
+	if got := workdirActions(mockPres.output.Files); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("actions = %v, want %v", got, want)
+	}
+	if string(query.contents["2024-01-15.md"]) != "first, edited locally\n" {
+		t.Errorf("remote 2024-01-15.md = %q, want the local edit without conflicts", query.contents["2024-01-15.md"])
+	}
+	if fmt.Sprint(query.createdPaths) != "[2024/2024-01-18.md 2024/2024-01-18_evening.md]" {
+		t.Errorf("createdPaths = %v, want the local names in the year subfolder", query.createdPaths)
+	}
+	// The new files are moved locally to where they were created
+	if got := readWorkdirFile(t, dir, "2024/2024-01-18.md"); got != "new\n" {
+		t.Errorf("2024/2024-01-18.md = %q, want the new file", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2024-01-18.md")); !os.IsNotExist(err) {
+		t.Errorf("2024-01-18.md should be moved into the subfolder, got %v", err)
+	}
+	for _, file := range mockPres.output.Files {
+		if file.Name == "memo.md" && !strings.Contains(file.Detail, "YYYY-MM-DD, optionally followed by a suffix") {
+			t.Errorf("memo.md detail = %q, want the accepted names", file.Detail)
+		}
+	}
+	if len(mockPres.asked) != 0 {
+		t.Errorf("asked = %v, want no conflicts", mockPres.asked)
+	}
+
+	// Pushed files are recorded, so neither pull nor push sees changes
+	if pulled := runPull(t, query); len(pulled.output.Files) != 0 {
+		t.Errorf("pull after push = %+v, want nothing", pulled.output.Files)
+	}
+	mockPres = &mockPushCommandPresenter{}
+	runPush(t, query, mockPres, port.ConflictAsk)
+	if got := workdirActions(mockPres.output.Files); len(got) != 1 || got["memo.md"] != port.WorkdirFileSkipped {
+		t.Errorf("push again = %v, want only memo.md skipped", got)
+	}
+}
+
+func TestPushCommandInteractor_Handle_FolderNaming(t *testing.T) {
+	dir, cleanup := setupWorkdirTest(t)
+	defer cleanup()
+	core.Cfg.Source.NamePatterns = []string{"YYYY/MM/DD"}
+
+	// Every file is named after its day, so names repeat across folders
+	query := newWorkdirRemoteNippoQuery(map[string]string{
+		"2024/01/15.md": "january\n",
+		"2024/02/15.md": "february\n",
+	})
+	pulled := runPull(t, query)
+	want := map[string]port.WorkdirFileAction{
+		"2024/01/15.md": port.WorkdirFileDownloaded,
+		"2024/02/15.md": port.WorkdirFileDownloaded,
+	}
+	if got := workdirActions(pulled.output.Files); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("pull actions = %v, want %v", got, want)
+	}
+	if got := readWorkdirFile(t, dir, "2024/02/15.md"); got != "february\n" {
+		t.Errorf("2024/02/15.md = %q", got)
+	}
+
+	writeFile := func(name, content string) {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("2024/02/15.md", "february, edited locally\n")
+	writeFile("2024/01/16.md", "new\n")
+
+	mockPres := &mockPushCommandPresenter{}
+	runPush(t, query, mockPres, port.ConflictAsk)
+	want = map[string]port.WorkdirFileAction{
+		"2024/01/16.md": port.WorkdirFileCreated,
+		"2024/02/15.md": port.WorkdirFileUploaded,
+	}
+	if got := workdirActions(mockPres.output.Files); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("push actions = %v, want %v", got, want)
+	}
+	if fmt.Sprint(query.createdPaths) != "[2024/01/16.md]" || string(query.contents["2024/01/15.md"]) != "january\n" {
+		t.Errorf("createdPaths = %v, 2024/01/15.md = %q", query.createdPaths, query.contents["2024/01/15.md"])
+	}
+	if pulled := runPull(t, query); len(pulled.output.Files) != 0 {
+		t.Errorf("pull after push = %+v, want nothing", pulled.output.Files)
+	}
+}
+
+func TestPushCommandInteractor_Handle_Conflicts(t *testing.T) {
+	tests := []struct {
+		resolution port.ConflictResolution
+		wantRemote string
+		wantLocal  string
+		wantCopy   bool
+		wantAction port.WorkdirFileAction
+	}{
+		{port.ConflictKeepLocal, "local\n", "local\n", false, port.WorkdirFileUploaded},
+		{port.ConflictKeepRemote, "remote\n", "remote\n", false, port.WorkdirFileDownloaded},
+		{port.ConflictWriteBoth, "remote\n", "local\n", true, port.WorkdirFileBoth},
+	}
+	for _, tt := range tests {
+		for _, ask := range []bool{true, false} {
+			t.Run(fmt.Sprintf("%s ask=%v", tt.resolution, ask), func(t *testing.T) {
+				dir, cleanup := setupWorkdirTest(t)
+				defer cleanup()
+
+				query := newWorkdirRemoteNippoQuery(map[string]string{"2024-01-15.md": "base\n"})
+				runPull(t, query)
+				query.put("2024-01-15.md", "remote\n")
+				if err := os.WriteFile(filepath.Join(dir, "2024-01-15.md"), []byte("local\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+
+				mockPres := &mockPushCommandPresenter{resolution: tt.resolution}
+				onConflict := tt.resolution
+				if ask {
+					onConflict = port.ConflictAsk
+				}
+				runPush(t, query, mockPres, onConflict)
+
+				if ask != (len(mockPres.asked) == 1) {
+					t.Errorf("asked = %v, want asked=%v", mockPres.asked, ask)
+				}
+				if got := workdirActions(mockPres.output.Files)["2024-01-15.md"]; got != tt.wantAction {
+					t.Errorf("action = %q, want %q", got, tt.wantAction)
+				}
+				if got := string(query.contents["2024-01-15.md"]); got != tt.wantRemote {
+					t.Errorf("remote = %q, want %q", got, tt.wantRemote)
+				}
+				if got := readWorkdirFile(t, dir, "2024-01-15.md"); got != tt.wantLocal {
+					t.Errorf("local = %q, want %q", got, tt.wantLocal)
+				}
+				_, err := os.Stat(filepath.Join(dir, "2024-01-15.remote.md"))
+				if tt.wantCopy != (err == nil) {
+					t.Errorf("remote copy exists = %v, want %v", err == nil, tt.wantCopy)
+				}
+
+				// After writing both, the merged file is pushed without another conflict
+				if tt.wantCopy {
+					mockPres = &mockPushCommandPresenter{}
+					runPush(t, query, mockPres, port.ConflictAsk)
+					if len(mockPres.asked) != 0 || string(query.contents["2024-01-15.md"]) != "local\n" {
+						t.Errorf("push after writing both asked %v, remote = %q", mockPres.asked, query.contents["2024-01-15.md"])
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestNewPullPushCommandInteractor_RequireAccess(t *testing.T) {
+	pullDrive := &retryDriveFileProvider{access: gateway.DriveReadWrite}
+	if _, err := interactor.NewPullCommandInteractor(inject.NewTestInjector(&inject.TestBasePackageOptions{
+		RemoteNippoQuery:     &mockRemoteNippoQuery{},
+		DriveFileProvider:    pullDrive,
+		PullCommandPresenter: &mockPullCommandPresenter{},
+	})); err != nil {
+		t.Fatalf("NewPullCommandInteractor() error = %v", err)
+	}
+	if pullDrive.access != gateway.DriveReadOnly {
+		t.Errorf("pull access = %v, want read-only", pullDrive.access)
+	}
+
+	pushDrive := &retryDriveFileProvider{}
+	if _, err := interactor.NewPushCommandInteractor(inject.NewTestInjector(&inject.TestBasePackageOptions{
+		RemoteNippoQuery:     &mockRemoteNippoQuery{},
+		DriveFileProvider:    pushDrive,
+		PushCommandPresenter: &mockPushCommandPresenter{},
+	})); err != nil {
+		t.Fatalf("NewPushCommandInteractor() error = %v", err)
+	}
+	if pushDrive.access != gateway.DriveReadWrite {
+		t.Errorf("push access = %v, want read-write", pushDrive.access)
+	}
+}
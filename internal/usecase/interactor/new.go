@@ -0,0 +1,158 @@
+package interactor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/c18t/nippo-cli/internal/adapter/gateway"
+	"github.com/c18t/nippo-cli/internal/adapter/presenter"
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/domain/model"
+	"github.com/c18t/nippo-cli/internal/domain/repository"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+)
+
+type createCommandInteractor struct {
+	remoteNippoQuery repository.RemoteNippoQuery      `do:""`
+	presenter        presenter.CreateCommandPresenter `do:""`
+}
+
+func NewCreateCommandInteractor(i do.Injector) (port.CreateCommandUseCase, error) {
+	remoteNippoQuery, err := do.Invoke[repository.RemoteNippoQuery](i)
+	if err != nil {
+		return nil, err
+	}
+	p, err := do.Invoke[presenter.CreateCommandPresenter](i)
+	if err != nil {
+		return nil, err
+	}
+	driveFileProvider, err := do.Invoke[gateway.DriveFileProvider](i)
+	if err != nil {
+		return nil, err
+	}
+	// New uploads the nippo to Drive
+	driveFileProvider.RequireAccess(gateway.DriveReadWrite)
+	return &createCommandInteractor{
+		remoteNippoQuery: remoteNippoQuery,
+		presenter:        p,
+	}, nil
+}
+
+func (u *createCommandInteractor) Handle(input *port.CreateCommandUseCaseInputData) {
+	output := &port.CreateCommandUseCaseOutputData{}
+
+	loc, err := core.Cfg.Project.GetLocation()
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+	now := time.Now().In(loc)
+	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if input.Date != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", input.Date, loc)
+		if err != nil {
+			u.presenter.Suspend(fmt.Errorf("invalid date %q: expected YYYY-MM-DD", input.Date))
+			return
+		}
+		date = parsed
+	}
+	// The nippo is created now, but on its own date
+	created := time.Date(date.Year(), date.Month(), date.Day(), now.Hour(), now.Minute(), now.Second(), 0, loc)
+
+	sourceFolder, err := core.Cfg.GetSourceFolder()
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+	dir, err := core.Cfg.New.GetSubfolder(date)
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+	naming, err := u.remoteNippoQuery.Naming()
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+	filePath := path.Join(dir, naming.FileName(date))
+
+	tmpl, err := u.readTemplate()
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+	content, err := model.RenderNippoTemplate(tmpl, date, created)
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+	nippo := &model.Nippo{
+		Date:    model.NewNippoDate(date.Format("2006-01-02")),
+		Content: content,
+	}
+	output.Filename = path.Base(filePath)
+
+	output.Message = "Checking existing nippo on " + core.Cfg.Source.DisplayName() + "..."
+	u.presenter.Progress(output)
+	if err := u.checkNotExist(sourceFolder, nippo.Date); err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+
+	output.Message = "Creating " + output.Filename + " on " + core.Cfg.Source.DisplayName() + "..."
+	u.presenter.Progress(output)
+	if err := u.remoteNippoQuery.Create(sourceFolder, filePath, nippo); err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+
+	output.FileId = nippo.RemoteFile.Id
+	output.Message = fmt.Sprintf("Created %s", filePath)
+	u.presenter.Complete(output)
+}
+
+// readTemplate reads the template for new nippo, falling back to the default
+// template when new.template is not configured and new.md does not exist
+func (u *createCommandInteractor) readTemplate() (string, error) {
+	templatePath := core.Cfg.GetNewTemplatePath()
+	b, err := os.ReadFile(templatePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && core.Cfg.New.Template == "" {
+			return model.DefaultNippoTemplate, nil
+		}
+		return "", fmt.Errorf("unable to read nippo template: %w", err)
+	}
+	return string(b), nil
+}
+
+// checkNotExist looks for a nippo of date anywhere in the source folder, as it
+// may have been put into another subfolder or named with another pattern
+func (u *createCommandInteractor) checkNotExist(sourceFolder string, date model.NippoDate) error {
+	nippoList, err := u.remoteNippoQuery.List(&repository.QueryListParam{
+		Folders:        []string{sourceFolder},
+		FileExtensions: []string{"md"},
+	}, &repository.QueryListOption{Recursive: true})
+	if err != nil {
+		return err
+	}
+	for _, nippo := range nippoList {
+		if nippo.RemoteFile == nil {
+			continue
+		}
+		existing := nippo.Date
+		if existing == nil {
+			// Names the patterns don't match may still start with the date
+			if existing, err = model.ParseNippoDate(nippo.RemoteFile.Name); err != nil {
+				continue
+			}
+		}
+		if existing.FileString() == date.FileString() {
+			return fmt.Errorf("%w: %s (%s)", repository.ErrNippoExists, nippo.RemoteFile.Name, nippo.RemoteFile.Id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package interactor
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/c18t/nippo-cli/internal/adapter/gateway"
+	"github.com/c18t/nippo-cli/internal/adapter/presenter"
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/domain/model"
+	"github.com/c18t/nippo-cli/internal/domain/repository"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+)
+
+type pullCommandInteractor struct {
+	remoteNippoQuery  repository.RemoteNippoQuery    `do:""`
+	workdirRepository repository.WorkdirRepository   `do:""`
+	presenter         presenter.PullCommandPresenter `do:""`
+}
+
+func NewPullCommandInteractor(i do.Injector) (port.PullCommandUseCase, error) {
+	remoteNippoQuery, err := do.Invoke[repository.RemoteNippoQuery](i)
+	if err != nil {
+		return nil, err
+	}
+	workdirRepository, err := do.Invoke[repository.WorkdirRepository](i)
+	if err != nil {
+		return nil, err
+	}
+	p, err := do.Invoke[presenter.PullCommandPresenter](i)
+	if err != nil {
+		return nil, err
+	}
+	driveFileProvider, err := do.Invoke[gateway.DriveFileProvider](i)
+	if err != nil {
+		return nil, err
+	}
+	// Pull only downloads files
+	driveFileProvider.RequireAccess(gateway.DriveReadOnly)
+	return &pullCommandInteractor{
+		remoteNippoQuery:  remoteNippoQuery,
+		workdirRepository: workdirRepository,
+		presenter:         p,
+	}, nil
+}
+
+func (u *pullCommandInteractor) Handle(input *port.PullCommandUseCaseInputData) {
+	output := &port.PullCommandUseCaseOutputData{}
+
+	dir, err := core.Cfg.GetWorkdir(input.Dir)
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+	sourceFolder, err := core.Cfg.GetSourceFolder()
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+
+	output.Message = "Listing nippo on " + core.Cfg.Source.DisplayName() + "..."
+	u.presenter.Progress(output)
+	remote, skipped, err := listWorkdirRemote(u.remoteNippoQuery, sourceFolder)
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+	output.Files = skipped
+
+	state, local, err := loadWorkdir(u.workdirRepository, dir)
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+
+	output.Message = "Pulling into " + dir + "..."
+	u.presenter.Progress(output)
+	results, err := u.pull(dir, state, remote, local)
+	output.Files = append(output.Files, results...)
+	// Keep track of the files pulled so far even when a download failed
+	if saveErr := u.workdirRepository.SaveState(dir, state); saveErr != nil && err == nil {
+		err = fmt.Errorf("unable to save working directory state: %w", saveErr)
+	}
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+
+	output.Message = fmt.Sprintf("Pulled into %s: %s", dir, summarizeWorkdirFiles(output.Files))
+	u.presenter.Complete(output)
+}
+
+// pull downloads the remote files that are new or changed since the last pull,
+// unless they have been edited locally, and removes local copies of deleted files
+func (u *pullCommandInteractor) pull(dir string, state *model.WorkdirState, remote map[string]model.Nippo, local map[string]*model.WorkdirFile) ([]port.WorkdirFileResult, error) {
+	var results []port.WorkdirFileResult
+	for _, name := range slices.Sorted(maps.Keys(remote)) {
+		nippo := remote[name]
+		file := nippo.RemoteFile
+		localFile, exists := local[name]
+		if exists && state.LocalChanged(localFile) {
+			if _, tracked := state.Files[name]; !tracked && localFile.Md5() == file.Md5Checksum {
+				// Already the same as the remote file
+				state.Record(name, file, localFile.Md5())
+				continue
+			}
+			detail := "edited locally, run nippo push"
+			if state.RemoteChanged(name, file) {
+				detail = "changed on both sides, run nippo push to resolve"
+			}
+			results = append(results, port.WorkdirFileResult{Name: name, Action: port.WorkdirFileKept, Detail: detail})
+			continue
+		}
+		if exists && !state.RemoteChanged(name, file) {
+			continue
+		}
+
+		if err := u.remoteNippoQuery.Download(&nippo); err != nil {
+			return results, err
+		}
+		if err := u.workdirRepository.Write(dir, &model.WorkdirFile{Name: name, Content: nippo.Content}); err != nil {
+			return results, err
+		}
+		state.Record(name, file, model.ContentMd5(nippo.Content))
+		results = append(results, port.WorkdirFileResult{Name: name, Action: port.WorkdirFileDownloaded})
+	}
+
+	// Files deleted remotely since the last pull
+	for _, name := range slices.Sorted(maps.Keys(state.Files)) {
+		if _, ok := remote[name]; ok {
+			continue
+		}
+		localFile, exists := local[name]
+		changed := exists && state.LocalChanged(localFile)
+		state.Forget(name)
+		if !exists {
+			continue
+		}
+		if changed {
+			results = append(results, port.WorkdirFileResult{Name: name, Action: port.WorkdirFileKept, Detail: "deleted remotely, run nippo push to upload it again"})
+			continue
+		}
+		if err := u.workdirRepository.Remove(dir, name); err != nil {
+			return results, err
+		}
+		results = append(results, port.WorkdirFileResult{Name: name, Action: port.WorkdirFileRemoved})
+	}
+	return results, nil
+}
+
+// listWorkdirRemote lists the nippo of the source folder tree by their path
+// within it, as the working directory mirrors the tree. Drive allows several
+// files of the same name in a folder, and only the first one is mirrored.
+func listWorkdirRemote(query repository.RemoteNippoQuery, sourceFolder string) (map[string]model.Nippo, []port.WorkdirFileResult, error) {
+	nippoList, err := query.List(&repository.QueryListParam{
+		Folders:        []string{sourceFolder},
+		FileExtensions: []string{"md"},
+	}, &repository.QueryListOption{Recursive: true})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	remote := make(map[string]model.Nippo, len(nippoList))
+	var skipped []port.WorkdirFileResult
+	for _, nippo := range nippoList {
+		if nippo.RemoteFile == nil {
+			continue
+		}
+		name := cmp.Or(nippo.RemotePath, nippo.RemoteFile.Name)
+		if _, ok := remote[name]; ok {
+			skipped = append(skipped, port.WorkdirFileResult{Name: name, Action: port.WorkdirFileSkipped, Detail: "duplicate path " + nippo.RemoteFile.Id})
+			continue
+		}
+		remote[name] = nippo
+	}
+	return remote, skipped, nil
+}
+
+// loadWorkdir loads the state and the files of the working directory
+func loadWorkdir(repo repository.WorkdirRepository, dir string) (*model.WorkdirState, map[string]*model.WorkdirFile, error) {
+	state, err := repo.LoadState(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	files, err := repo.List(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	local := make(map[string]*model.WorkdirFile, len(files))
+	for i := range files {
+		local[files[i].Name] = &files[i]
+	}
+	return state, local, nil
+}
+
+// summarizeWorkdirFiles counts the results by action, such as "2 downloaded, 1 kept"
+func summarizeWorkdirFiles(files []port.WorkdirFileResult) string {
+	counts := map[port.WorkdirFileAction]int{}
+	for _, file := range files {
+		counts[file.Action]++
+	}
+	summary := ""
+	for _, action := range []port.WorkdirFileAction{
+		port.WorkdirFileDownloaded, port.WorkdirFileUploaded, port.WorkdirFileCreated,
+		port.WorkdirFileRemoved, port.WorkdirFileBoth, port.WorkdirFileKept, port.WorkdirFileSkipped,
+	} {
+		if counts[action] == 0 {
+			continue
+		}
+		if summary != "" {
+			summary += ", "
+		}
+		summary += fmt.Sprintf("%d %s", counts[action], action)
+	}
+	if summary == "" {
+		return "already up to date"
+	}
+	return summary
+}
@@ -0,0 +1,241 @@
+package interactor
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/c18t/nippo-cli/internal/adapter/gateway"
+	"github.com/c18t/nippo-cli/internal/adapter/presenter"
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/c18t/nippo-cli/internal/domain/model"
+	"github.com/c18t/nippo-cli/internal/domain/repository"
+	"github.com/c18t/nippo-cli/internal/usecase/port"
+	"github.com/samber/do/v2"
+)
+
+type pushCommandInteractor struct {
+	remoteNippoQuery  repository.RemoteNippoQuery    `do:""`
+	workdirRepository repository.WorkdirRepository   `do:""`
+	presenter         presenter.PushCommandPresenter `do:""`
+}
+
+func NewPushCommandInteractor(i do.Injector) (port.PushCommandUseCase, error) {
+	remoteNippoQuery, err := do.Invoke[repository.RemoteNippoQuery](i)
+	if err != nil {
+		return nil, err
+	}
+	workdirRepository, err := do.Invoke[repository.WorkdirRepository](i)
+	if err != nil {
+		return nil, err
+	}
+	p, err := do.Invoke[presenter.PushCommandPresenter](i)
+	if err != nil {
+		return nil, err
+	}
+	driveFileProvider, err := do.Invoke[gateway.DriveFileProvider](i)
+	if err != nil {
+		return nil, err
+	}
+	// Push uploads the local changes to Drive
+	driveFileProvider.RequireAccess(gateway.DriveReadWrite)
+	return &pushCommandInteractor{
+		remoteNippoQuery:  remoteNippoQuery,
+		workdirRepository: workdirRepository,
+		presenter:         p,
+	}, nil
+}
+
+func (u *pushCommandInteractor) Handle(input *port.PushCommandUseCaseInputData) {
+	output := &port.PushCommandUseCaseOutputData{}
+
+	dir, err := core.Cfg.GetWorkdir(input.Dir)
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+	sourceFolder, err := core.Cfg.GetSourceFolder()
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+
+	state, local, err := loadWorkdir(u.workdirRepository, dir)
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+
+	output.Message = "Listing nippo on " + core.Cfg.Source.DisplayName() + "..."
+	u.presenter.Progress(output)
+	remote, _, err := listWorkdirRemote(u.remoteNippoQuery, sourceFolder)
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+
+	output.Message = "Pushing from " + dir + "..."
+	u.presenter.Progress(output)
+	pushed := map[string]string{}
+	output.Files, err = u.push(dir, sourceFolder, input.OnConflict, state, remote, local, pushed)
+	if err == nil && len(pushed) > 0 {
+		err = u.remoteNippoQuery.Flush()
+	}
+	if err == nil && len(pushed) > 0 {
+		err = u.recordPushed(sourceFolder, state, pushed)
+	}
+	// Keep track of the files pushed so far even when an upload failed
+	if saveErr := u.workdirRepository.SaveState(dir, state); saveErr != nil && err == nil {
+		err = fmt.Errorf("unable to save working directory state: %w", saveErr)
+	}
+	if err != nil {
+		u.presenter.Suspend(err)
+		return
+	}
+
+	output.Message = fmt.Sprintf("Pushed from %s: %s", dir, summarizeWorkdirFiles(output.Files))
+	u.presenter.Complete(output)
+}
+
+// push uploads the local files edited since the last pull, and creates the new
+// ones. A file also changed remotely since is resolved with onConflict. The
+// checksum of each uploaded file is added to pushed by its path.
+func (u *pushCommandInteractor) push(dir, sourceFolder string, onConflict port.ConflictResolution, state *model.WorkdirState, remote map[string]model.Nippo, local map[string]*model.WorkdirFile, pushed map[string]string) ([]port.WorkdirFileResult, error) {
+	var results []port.WorkdirFileResult
+	loc, err := core.Cfg.Project.GetLocation()
+	if err != nil {
+		return results, err
+	}
+	naming, err := u.remoteNippoQuery.Naming()
+	if err != nil {
+		return results, err
+	}
+	for _, name := range slices.Sorted(maps.Keys(local)) {
+		localFile := local[name]
+		if model.IsWorkdirConflictName(name) || !state.LocalChanged(localFile) {
+			continue
+		}
+
+		nippo, onRemote := remote[name]
+		if !onRemote {
+			date, err := naming.Parse(name)
+			if err != nil {
+				detail := fmt.Sprintf("not named after its date (%s, optionally followed by a suffix)", naming)
+				results = append(results, port.WorkdirFileResult{Name: name, Action: port.WorkdirFileSkipped, Detail: detail})
+				continue
+			}
+			result, err := u.create(dir, sourceFolder, date, loc, localFile, pushed)
+			if err != nil {
+				return results, err
+			}
+			results = append(results, result)
+			continue
+		}
+
+		file := nippo.RemoteFile
+		if localFile.Md5() == file.Md5Checksum {
+			// Already the same as the remote file
+			state.Record(name, file, localFile.Md5())
+			continue
+		}
+		if !state.RemoteChanged(name, file) {
+			if err := u.remoteNippoQuery.Update(&nippo, localFile.Content); err != nil {
+				return results, err
+			}
+			pushed[name] = localFile.Md5()
+			results = append(results, port.WorkdirFileResult{Name: name, Action: port.WorkdirFileUploaded})
+			continue
+		}
+
+		resolution := onConflict
+		if resolution == port.ConflictAsk {
+			var err error
+			if resolution, err = u.presenter.ResolveConflict(name); err != nil {
+				return results, err
+			}
+		}
+		result, err := u.resolve(dir, resolution, state, &nippo, localFile, pushed)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// create adds the new local file to the source folder at the same path. A file
+// at the top of the working directory goes in the subfolder of new nippo, as
+// with nippo new, and is moved there locally too.
+func (u *pushCommandInteractor) create(dir, sourceFolder string, date model.NippoDate, loc *time.Location, localFile *model.WorkdirFile, pushed map[string]string) (port.WorkdirFileResult, error) {
+	name := localFile.Name
+	if path.Dir(name) == "." {
+		subfolder, err := core.Cfg.New.GetSubfolder(time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc))
+		if err != nil {
+			return port.WorkdirFileResult{}, err
+		}
+		name = path.Join(subfolder, name)
+	}
+	nippo := &model.Nippo{Date: date, Content: localFile.Content}
+	if err := u.remoteNippoQuery.Create(sourceFolder, name, nippo); err != nil {
+		return port.WorkdirFileResult{}, err
+	}
+	pushed[name] = localFile.Md5()
+	if name == localFile.Name {
+		return port.WorkdirFileResult{Name: name, Action: port.WorkdirFileCreated}, nil
+	}
+	if err := u.workdirRepository.Write(dir, &model.WorkdirFile{Name: name, Content: localFile.Content}); err != nil {
+		return port.WorkdirFileResult{}, err
+	}
+	if err := u.workdirRepository.Remove(dir, localFile.Name); err != nil {
+		return port.WorkdirFileResult{}, err
+	}
+	return port.WorkdirFileResult{Name: name, Action: port.WorkdirFileCreated, Detail: "moved from " + localFile.Name}, nil
+}
+
+// resolve resolves the conflict between the local file and the remote nippo
+func (u *pushCommandInteractor) resolve(dir string, resolution port.ConflictResolution, state *model.WorkdirState, nippo *model.Nippo, localFile *model.WorkdirFile, pushed map[string]string) (port.WorkdirFileResult, error) {
+	name := localFile.Name
+	if resolution == port.ConflictKeepLocal {
+		if err := u.remoteNippoQuery.Update(nippo, localFile.Content); err != nil {
+			return port.WorkdirFileResult{}, err
+		}
+		pushed[name] = localFile.Md5()
+		return port.WorkdirFileResult{Name: name, Action: port.WorkdirFileUploaded, Detail: "conflict, kept local"}, nil
+	}
+
+	if err := u.remoteNippoQuery.Download(nippo); err != nil {
+		return port.WorkdirFileResult{}, err
+	}
+	if resolution == port.ConflictKeepRemote {
+		if err := u.workdirRepository.Write(dir, &model.WorkdirFile{Name: name, Content: nippo.Content}); err != nil {
+			return port.WorkdirFileResult{}, err
+		}
+		state.Record(name, nippo.RemoteFile, model.ContentMd5(nippo.Content))
+		return port.WorkdirFileResult{Name: name, Action: port.WorkdirFileDownloaded, Detail: "conflict, kept remote"}, nil
+	}
+
+	copyName := model.WorkdirConflictName(name)
+	if err := u.workdirRepository.Write(dir, &model.WorkdirFile{Name: copyName, Content: nippo.Content}); err != nil {
+		return port.WorkdirFileResult{}, err
+	}
+	// The remote revision has been seen, but the local file is still to be pushed
+	state.Record(name, nippo.RemoteFile, state.Files[name].LocalMd5)
+	return port.WorkdirFileResult{Name: name, Action: port.WorkdirFileBoth, Detail: "merge " + copyName + " and push again"}, nil
+}
+
+// recordPushed lists the remote files again to record the revisions the pushed
+// files were given
+func (u *pushCommandInteractor) recordPushed(sourceFolder string, state *model.WorkdirState, pushed map[string]string) error {
+	remote, _, err := listWorkdirRemote(u.remoteNippoQuery, sourceFolder)
+	if err != nil {
+		return err
+	}
+	for name, localMd5 := range pushed {
+		if nippo, ok := remote[name]; ok {
+			state.Record(name, nippo.RemoteFile, localMd5)
+		}
+	}
+	return nil
+}
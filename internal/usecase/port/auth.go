@@ -5,7 +5,12 @@ import (
 	"github.com/samber/do/v2"
 )
 
-type AuthUseCaseInputData struct{}
+type AuthUseCaseInputData struct {
+	// Device uses the OAuth device authorization flow instead of a browser on this machine
+	Device bool
+	// Write requests write access to Drive, which format needs, on top of read-only access
+	Write bool
+}
 type AuthUseCaseOutputData struct {
 	Message string
 }
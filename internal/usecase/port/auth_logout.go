@@ -0,0 +1,45 @@
+package port
+
+import (
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/samber/do/v2"
+)
+
+type AuthLogoutUseCaseInputData struct {
+	// JSON prints the result as JSON instead of plain text
+	JSON bool
+}
+
+type AuthLogoutUseCaseOutputData struct {
+	// LoggedOut reports whether a token was revoked and deleted
+	LoggedOut bool
+	// Path is the token.json that was deleted
+	Path string
+}
+
+type AuthLogoutUseCase interface {
+	core.UseCase
+	Handle(input *AuthLogoutUseCaseInputData)
+}
+
+type AuthLogoutUseCaseBus interface {
+	Handle(input *AuthLogoutUseCaseInputData)
+}
+
+type authLogoutUseCaseBus struct {
+	logout AuthLogoutUseCase
+}
+
+func NewAuthLogoutUseCaseBus(i do.Injector) (AuthLogoutUseCaseBus, error) {
+	logout, err := do.Invoke[AuthLogoutUseCase](i)
+	if err != nil {
+		return nil, err
+	}
+	return &authLogoutUseCaseBus{
+		logout: logout,
+	}, nil
+}
+
+func (bus *authLogoutUseCaseBus) Handle(input *AuthLogoutUseCaseInputData) {
+	bus.logout.Handle(input)
+}
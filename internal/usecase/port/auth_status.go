@@ -0,0 +1,53 @@
+package port
+
+import (
+	"time"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/samber/do/v2"
+)
+
+type AuthStatusUseCaseInputData struct {
+	// JSON prints the status as JSON instead of plain text
+	JSON bool
+}
+
+type AuthStatusUseCaseOutputData struct {
+	Mode string
+	// Path is token.json, or the service account key
+	Path            string
+	Authenticated   bool
+	Account         string
+	Scopes          []string
+	Expiry          time.Time
+	HasRefreshToken bool
+	// Problem explains why the credentials could not be used
+	Problem string
+}
+
+type AuthStatusUseCase interface {
+	core.UseCase
+	Handle(input *AuthStatusUseCaseInputData)
+}
+
+type AuthStatusUseCaseBus interface {
+	Handle(input *AuthStatusUseCaseInputData)
+}
+
+type authStatusUseCaseBus struct {
+	status AuthStatusUseCase
+}
+
+func NewAuthStatusUseCaseBus(i do.Injector) (AuthStatusUseCaseBus, error) {
+	status, err := do.Invoke[AuthStatusUseCase](i)
+	if err != nil {
+		return nil, err
+	}
+	return &authStatusUseCaseBus{
+		status: status,
+	}, nil
+}
+
+func (bus *authStatusUseCaseBus) Handle(input *AuthStatusUseCaseInputData) {
+	bus.status.Handle(input)
+}
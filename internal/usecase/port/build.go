@@ -12,6 +12,8 @@ type BuildUseCaseOutputData interface{}
 
 type BuildCommandUseCaseInputData struct {
 	BuildUseCaseInputData
+	// Force rebuilds every page rather than those affected by changes
+	Force bool
 }
 type BuildCommandUseCaseOutputData struct {
 	BuildUseCaseOutputData
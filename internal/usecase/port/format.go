@@ -12,6 +12,10 @@ type FormatUseCaseOutputData interface{}
 
 type FormatCommandUseCaseInputData struct {
 	FormatUseCaseInputData
+	// DryRun shows the changes instead of uploading them
+	DryRun bool
+	// JSON prints the changes of a dry run as JSON
+	JSON bool
 }
 
 // FormatFileStatus represents the result status of processing a file
@@ -21,6 +25,7 @@ const (
 	FormatFileStatusSuccess   FormatFileStatus = iota // Successfully updated
 	FormatFileStatusNoChange                          // No changes needed
 	FormatFileStatusFailed                            // Failed to process/upload
+	FormatFileStatusConflict                          // Changed remotely while processing
 )
 
 type FormatCommandUseCaseOutputData struct {
@@ -30,6 +35,10 @@ type FormatCommandUseCaseOutputData struct {
 	FileId   string
 	Status   FormatFileStatus
 	Error    error
+	// Reasons lists what each format rule fixed, as joined in Message
+	Reasons []string
+	// Diff is the unified diff of the planned change in a dry run
+	Diff string
 }
 
 type FormatCommandUseCase interface {
@@ -0,0 +1,58 @@
+package port
+
+import (
+	"time"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/samber/do/v2"
+)
+
+// FormatHistoryRun is a past format run and the files it changed
+type FormatHistoryRun struct {
+	Id        string
+	StartedAt time.Time
+	// RevertedAt is the zero time unless the run has been reverted
+	RevertedAt time.Time
+	Files      []FormatHistoryFile
+}
+
+// FormatHistoryFile is a file changed by a format run
+type FormatHistoryFile struct {
+	Name    string
+	Id      string
+	Reasons []string
+}
+
+type FormatHistoryUseCaseInputData struct{}
+
+type FormatHistoryUseCaseOutputData struct {
+	// Runs are the past format runs, the newest first
+	Runs []FormatHistoryRun
+}
+
+type FormatHistoryUseCase interface {
+	core.UseCase
+	Handle(input *FormatHistoryUseCaseInputData)
+}
+
+type FormatHistoryUseCaseBus interface {
+	Handle(input *FormatHistoryUseCaseInputData)
+}
+
+type formatHistoryUseCaseBus struct {
+	history FormatHistoryUseCase
+}
+
+func NewFormatHistoryUseCaseBus(i do.Injector) (FormatHistoryUseCaseBus, error) {
+	history, err := do.Invoke[FormatHistoryUseCase](i)
+	if err != nil {
+		return nil, err
+	}
+	return &formatHistoryUseCaseBus{
+		history: history,
+	}, nil
+}
+
+func (bus *formatHistoryUseCaseBus) Handle(input *FormatHistoryUseCaseInputData) {
+	bus.history.Handle(input)
+}
@@ -0,0 +1,62 @@
+package port
+
+import (
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/samber/do/v2"
+)
+
+// FormatRevertFileAction is what revert did with a file changed by a format run
+type FormatRevertFileAction string
+
+const (
+	FormatRevertRestored FormatRevertFileAction = "restored"
+	FormatRevertSkipped  FormatRevertFileAction = "skipped"
+	FormatRevertFailed   FormatRevertFileAction = "failed"
+)
+
+// FormatRevertFileResult is the outcome of revert for a single file
+type FormatRevertFileResult struct {
+	Name   string
+	Id     string
+	Action FormatRevertFileAction
+	// Detail explains why the file was skipped or failed
+	Detail string
+}
+
+type FormatRevertUseCaseInputData struct {
+	// RunId is the format run to revert, the latest one not reverted yet when empty
+	RunId string
+}
+
+type FormatRevertUseCaseOutputData struct {
+	Message string
+	RunId   string
+	Files   []FormatRevertFileResult
+}
+
+type FormatRevertUseCase interface {
+	core.UseCase
+	Handle(input *FormatRevertUseCaseInputData)
+}
+
+type FormatRevertUseCaseBus interface {
+	Handle(input *FormatRevertUseCaseInputData)
+}
+
+type formatRevertUseCaseBus struct {
+	revert FormatRevertUseCase
+}
+
+func NewFormatRevertUseCaseBus(i do.Injector) (FormatRevertUseCaseBus, error) {
+	revert, err := do.Invoke[FormatRevertUseCase](i)
+	if err != nil {
+		return nil, err
+	}
+	return &formatRevertUseCaseBus{
+		revert: revert,
+	}, nil
+}
+
+func (bus *formatRevertUseCaseBus) Handle(input *FormatRevertUseCaseInputData) {
+	bus.revert.Handle(input)
+}
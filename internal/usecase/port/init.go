@@ -27,6 +27,7 @@ type InitSettingUseCaseOutputData struct {
 	InitUsecaseOutputDataImpl
 	Input             interface{}
 	Project           InitSettingProject
+	Site              InitSettingSite
 	ProjectConfigured bool
 }
 type InitSettingProject struct {
@@ -43,6 +44,16 @@ type InitSettingProjectUrl string
 type InitSettingProjectBranch string
 type InitSettingProjectTemplatePath string
 type InitSettingProjectAssetPath string
+type InitSettingSite struct {
+	Title       InitSettingSiteTitle
+	Author      InitSettingSiteAuthor
+	Description InitSettingSiteDescription
+	Language    InitSettingSiteLanguage
+}
+type InitSettingSiteTitle string
+type InitSettingSiteAuthor string
+type InitSettingSiteDescription string
+type InitSettingSiteLanguage string
 
 // Confirmation prompts
 type InitSettingConfirmOverwrite bool
@@ -0,0 +1,53 @@
+package port
+
+import (
+	"fmt"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/samber/do/v2"
+)
+
+type CreateUseCaseInputData interface{}
+type CreateUseCaseOutputData interface{}
+
+type CreateCommandUseCaseInputData struct {
+	CreateUseCaseInputData
+	// Date is the date of the new nippo in YYYY-MM-DD format, today when empty
+	Date string
+}
+type CreateCommandUseCaseOutputData struct {
+	CreateUseCaseOutputData
+	Message  string
+	Filename string
+	FileId   string
+}
+type CreateCommandUseCase interface {
+	core.UseCase
+	Handle(input *CreateCommandUseCaseInputData)
+}
+
+type CreateUseCaseBus interface {
+	Handle(input CreateUseCaseInputData)
+}
+type createUseCaseBus struct {
+	command CreateCommandUseCase `do:""`
+}
+
+func NewCreateUseCaseBus(i do.Injector) (CreateUseCaseBus, error) {
+	command, err := do.Invoke[CreateCommandUseCase](i)
+	if err != nil {
+		return nil, err
+	}
+	return &createUseCaseBus{
+		command: command,
+	}, nil
+}
+
+func (bus *createUseCaseBus) Handle(input CreateUseCaseInputData) {
+	switch data := input.(type) {
+	case *CreateCommandUseCaseInputData:
+		bus.command.Handle(data)
+	default:
+		panic(fmt.Errorf("handler for '%T' is not implemented", data))
+	}
+}
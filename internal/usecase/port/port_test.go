@@ -16,6 +16,22 @@ func (m *mockAuthUseCase) Handle(input *AuthUseCaseInputData) {
 	m.handleCalled = true
 }
 
+type mockAuthStatusUseCase struct {
+	input *AuthStatusUseCaseInputData
+}
+
+func (m *mockAuthStatusUseCase) Handle(input *AuthStatusUseCaseInputData) {
+	m.input = input
+}
+
+type mockAuthLogoutUseCase struct {
+	input *AuthLogoutUseCaseInputData
+}
+
+func (m *mockAuthLogoutUseCase) Handle(input *AuthLogoutUseCaseInputData) {
+	m.input = input
+}
+
 type mockCleanCommandUseCase struct {
 	handleCalled bool
 }
@@ -24,6 +40,30 @@ func (m *mockCleanCommandUseCase) Handle(input *CleanCommandUseCaseInputData) {
 	m.handleCalled = true
 }
 
+type mockCreateCommandUseCase struct {
+	input *CreateCommandUseCaseInputData
+}
+
+func (m *mockCreateCommandUseCase) Handle(input *CreateCommandUseCaseInputData) {
+	m.input = input
+}
+
+type mockPullCommandUseCase struct {
+	input *PullCommandUseCaseInputData
+}
+
+func (m *mockPullCommandUseCase) Handle(input *PullCommandUseCaseInputData) {
+	m.input = input
+}
+
+type mockPushCommandUseCase struct {
+	input *PushCommandUseCaseInputData
+}
+
+func (m *mockPushCommandUseCase) Handle(input *PushCommandUseCaseInputData) {
+	m.input = input
+}
+
 type mockDeployCommandUseCase struct {
 	handleCalled bool
 }
@@ -106,6 +146,42 @@ func TestAuthUseCaseOutputData(t *testing.T) {
 	}
 }
 
+func TestAuthStatusUseCaseBus_Handle(t *testing.T) {
+	mock := &mockAuthStatusUseCase{}
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (AuthStatusUseCase, error) {
+		return mock, nil
+	})
+
+	bus, err := NewAuthStatusUseCaseBus(injector)
+	if err != nil {
+		t.Fatalf("NewAuthStatusUseCaseBus() error = %v", err)
+	}
+	bus.Handle(&AuthStatusUseCaseInputData{JSON: true})
+
+	if mock.input == nil || !mock.input.JSON {
+		t.Errorf("Handle() input = %+v, want it passed to the use case", mock.input)
+	}
+}
+
+func TestAuthLogoutUseCaseBus_Handle(t *testing.T) {
+	mock := &mockAuthLogoutUseCase{}
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (AuthLogoutUseCase, error) {
+		return mock, nil
+	})
+
+	bus, err := NewAuthLogoutUseCaseBus(injector)
+	if err != nil {
+		t.Fatalf("NewAuthLogoutUseCaseBus() error = %v", err)
+	}
+	bus.Handle(&AuthLogoutUseCaseInputData{})
+
+	if mock.input == nil {
+		t.Error("Handle() did not call logout.Handle()")
+	}
+}
+
 // Clean tests
 
 func TestNewCleanUseCaseBus(t *testing.T) {
@@ -602,3 +678,90 @@ func TestUpdateCommandUseCaseOutputData(t *testing.T) {
 		t.Errorf("Message = %q, want %q", output.Message, "test message")
 	}
 }
+
+// Create tests
+
+func TestCreateUseCaseBus_Handle(t *testing.T) {
+	mock := &mockCreateCommandUseCase{}
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (CreateCommandUseCase, error) {
+		return mock, nil
+	})
+
+	bus, err := NewCreateUseCaseBus(injector)
+	if err != nil {
+		t.Fatalf("NewCreateUseCaseBus() error = %v", err)
+	}
+	bus.Handle(&CreateCommandUseCaseInputData{Date: "2024-01-15"})
+	if mock.input == nil || mock.input.Date != "2024-01-15" {
+		t.Errorf("Handle() passed %+v, want the input data", mock.input)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Handle() should panic for unknown input type")
+		}
+	}()
+	bus.Handle("unknown type")
+}
+
+// Pull and push tests
+
+func TestPullUseCaseBus_Handle(t *testing.T) {
+	mock := &mockPullCommandUseCase{}
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (PullCommandUseCase, error) {
+		return mock, nil
+	})
+
+	bus, err := NewPullUseCaseBus(injector)
+	if err != nil {
+		t.Fatalf("NewPullUseCaseBus() error = %v", err)
+	}
+	bus.Handle(&PullCommandUseCaseInputData{Dir: "/tmp/nippo"})
+	if mock.input == nil || mock.input.Dir != "/tmp/nippo" {
+		t.Errorf("Handle() passed %+v, want the input data", mock.input)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Handle() should panic for unknown input type")
+		}
+	}()
+	bus.Handle("unknown type")
+}
+
+func TestPushUseCaseBus_Handle(t *testing.T) {
+	mock := &mockPushCommandUseCase{}
+	injector := do.New()
+	do.Provide(injector, func(_ do.Injector) (PushCommandUseCase, error) {
+		return mock, nil
+	})
+
+	bus, err := NewPushUseCaseBus(injector)
+	if err != nil {
+		t.Fatalf("NewPushUseCaseBus() error = %v", err)
+	}
+	bus.Handle(&PushCommandUseCaseInputData{OnConflict: ConflictWriteBoth})
+	if mock.input == nil || mock.input.OnConflict != ConflictWriteBoth {
+		t.Errorf("Handle() passed %+v, want the input data", mock.input)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Handle() should panic for unknown input type")
+		}
+	}()
+	bus.Handle("unknown type")
+}
+
+func TestParseConflictResolution(t *testing.T) {
+	for _, s := range []string{"", "local", "remote", "both"} {
+		if got, err := ParseConflictResolution(s); err != nil || string(got) != s {
+			t.Errorf("ParseConflictResolution(%q) = %q, %v", s, got, err)
+		}
+	}
+	if _, err := ParseConflictResolution("theirs"); err == nil {
+		t.Error("ParseConflictResolution() expected error for an unknown value")
+	}
+}
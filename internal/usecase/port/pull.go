@@ -0,0 +1,73 @@
+package port
+
+import (
+	"fmt"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/samber/do/v2"
+)
+
+// WorkdirFileAction is what pull or push did with a file of the working directory
+type WorkdirFileAction string
+
+const (
+	WorkdirFileDownloaded WorkdirFileAction = "downloaded"
+	WorkdirFileRemoved    WorkdirFileAction = "removed"
+	WorkdirFileKept       WorkdirFileAction = "kept"
+	WorkdirFileUploaded   WorkdirFileAction = "uploaded"
+	WorkdirFileCreated    WorkdirFileAction = "created"
+	WorkdirFileBoth       WorkdirFileAction = "wrote both"
+	WorkdirFileSkipped    WorkdirFileAction = "skipped"
+)
+
+// WorkdirFileResult is the outcome of pull or push for a single file
+type WorkdirFileResult struct {
+	Name   string
+	Action WorkdirFileAction
+	// Detail explains the action, such as why local changes were kept
+	Detail string
+}
+
+type PullUseCaseInputData interface{}
+type PullUseCaseOutputData interface{}
+
+type PullCommandUseCaseInputData struct {
+	PullUseCaseInputData
+	// Dir overrides the working directory of the config
+	Dir string
+}
+type PullCommandUseCaseOutputData struct {
+	PullUseCaseOutputData
+	Message string
+	Files   []WorkdirFileResult
+}
+type PullCommandUseCase interface {
+	core.UseCase
+	Handle(input *PullCommandUseCaseInputData)
+}
+
+type PullUseCaseBus interface {
+	Handle(input PullUseCaseInputData)
+}
+type pullUseCaseBus struct {
+	command PullCommandUseCase `do:""`
+}
+
+func NewPullUseCaseBus(i do.Injector) (PullUseCaseBus, error) {
+	command, err := do.Invoke[PullCommandUseCase](i)
+	if err != nil {
+		return nil, err
+	}
+	return &pullUseCaseBus{
+		command: command,
+	}, nil
+}
+
+func (bus *pullUseCaseBus) Handle(input PullUseCaseInputData) {
+	switch data := input.(type) {
+	case *PullCommandUseCaseInputData:
+		bus.command.Handle(data)
+	default:
+		panic(fmt.Errorf("handler for '%T' is not implemented", data))
+	}
+}
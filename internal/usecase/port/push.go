@@ -0,0 +1,79 @@
+package port
+
+import (
+	"fmt"
+
+	"github.com/c18t/nippo-cli/internal/core"
+	"github.com/samber/do/v2"
+)
+
+// ConflictResolution is how push resolves a file changed both locally and on
+// the remote since the last pull
+type ConflictResolution string
+
+const (
+	// ConflictAsk asks for each conflicting file
+	ConflictAsk ConflictResolution = ""
+	// ConflictKeepLocal overwrites the remote file with the local one
+	ConflictKeepLocal ConflictResolution = "local"
+	// ConflictKeepRemote overwrites the local file with the remote one
+	ConflictKeepRemote ConflictResolution = "remote"
+	// ConflictWriteBoth keeps the local file and writes the remote one next to it
+	ConflictWriteBoth ConflictResolution = "both"
+)
+
+// ParseConflictResolution parses the value of --on-conflict
+func ParseConflictResolution(s string) (ConflictResolution, error) {
+	switch r := ConflictResolution(s); r {
+	case ConflictAsk, ConflictKeepLocal, ConflictKeepRemote, ConflictWriteBoth:
+		return r, nil
+	default:
+		return "", fmt.Errorf("invalid conflict resolution %q: expected local, remote or both", s)
+	}
+}
+
+type PushUseCaseInputData interface{}
+type PushUseCaseOutputData interface{}
+
+type PushCommandUseCaseInputData struct {
+	PushUseCaseInputData
+	// Dir overrides the working directory of the config
+	Dir string
+	// OnConflict resolves every conflict without asking unless it is ConflictAsk
+	OnConflict ConflictResolution
+}
+type PushCommandUseCaseOutputData struct {
+	PushUseCaseOutputData
+	Message string
+	Files   []WorkdirFileResult
+}
+type PushCommandUseCase interface {
+	core.UseCase
+	Handle(input *PushCommandUseCaseInputData)
+}
+
+type PushUseCaseBus interface {
+	Handle(input PushUseCaseInputData)
+}
+type pushUseCaseBus struct {
+	command PushCommandUseCase `do:""`
+}
+
+func NewPushUseCaseBus(i do.Injector) (PushUseCaseBus, error) {
+	command, err := do.Invoke[PushCommandUseCase](i)
+	if err != nil {
+		return nil, err
+	}
+	return &pushUseCaseBus{
+		command: command,
+	}, nil
+}
+
+func (bus *pushUseCaseBus) Handle(input PushUseCaseInputData) {
+	switch data := input.(type) {
+	case *PushCommandUseCaseInputData:
+		bus.command.Handle(data)
+	default:
+		panic(fmt.Errorf("handler for '%T' is not implemented", data))
+	}
+}
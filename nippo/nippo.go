@@ -4,7 +4,12 @@ This file is part of CLI application nippo-cli.
 */
 package main
 
-import "github.com/c18t/nippo-cli/cmd"
+import (
+	// The timezone setting works on CI runners without a time zone database
+	_ "time/tzdata"
+
+	"github.com/c18t/nippo-cli/cmd"
+)
 
 // assign -ldflags on build
 var version string